@@ -54,6 +54,12 @@ The command-line parameters to vtbackup specify a policy for when a new backup
 is needed, and when old backups should be removed. If the existing backups
 already satisfy the policy, then vtbackup will do nothing and return success
 immediately.
+
+With --verify, vtbackup checks an existing backup instead of taking a new one:
+ 1. Restore the backup into a temporary mysqld.
+ 2. Run CHECK TABLE on all the tables, and compare the row counts and checksums
+    recorded in the backup MANIFEST (see --backup-checksum-sample-size).
+ 3. Record the result in the backup storage, next to the backup.
 */
 package main
 
@@ -107,6 +113,8 @@ var (
 	initialBackup       bool
 	allowFirstBackup    bool
 	restartBeforeBackup bool
+	verify              bool
+	verifyBackupName    string
 	// vttablet-like flags
 	initDbNameOverride string
 	initKeyspace       string
@@ -135,6 +143,8 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&initialBackup, "initial_backup", initialBackup, "Instead of restoring from backup, initialize an empty database with the provided init_db_sql_file and upload a backup of that for the shard, if the shard has no backups yet. This can be used to seed a brand new shard with an initial, empty backup. If any backups already exist for the shard, this will be considered a successful no-op. This can only be done before the shard exists in topology (i.e. before any tablets are deployed).")
 	fs.BoolVar(&allowFirstBackup, "allow_first_backup", allowFirstBackup, "Allow this job to take the first backup of an existing shard.")
 	fs.BoolVar(&restartBeforeBackup, "restart_before_backup", restartBeforeBackup, "Perform a mysqld clean/full restart after applying binlogs, but before taking the backup. Only makes sense to work around xtrabackup bugs.")
+	fs.BoolVar(&verify, "verify", verify, "Instead of taking a new backup, restore an existing one into a temporary mysqld, check its tables, and record the result in the backup storage next to the backup.")
+	fs.StringVar(&verifyBackupName, "verify-backup-name", verifyBackupName, "Name of the backup to check with --verify. Defaults to the most recent complete backup.")
 	// vttablet-like flags
 	fs.StringVar(&initDbNameOverride, "init_db_name_override", initDbNameOverride, "(init parameter) override the name of the db used by vttablet")
 	fs.StringVar(&initKeyspace, "init_keyspace", initKeyspace, "(init parameter) keyspace to use for this tablet")
@@ -200,10 +210,19 @@ func main() {
 	topoServer := topo.Open()
 	defer topoServer.Close()
 
+	backupDir := mysqlctl.GetBackupDir(initKeyspace, initShard)
+	if verify {
+		if err := verifyBackup(ctx, backupStorage, backupDir); err != nil {
+			log.Errorf("Backup verification failed: %v", err)
+			exit.Return(1)
+		}
+		log.Info("Exiting.")
+		return
+	}
+
 	// Try to take a backup, if it's been long enough since the last one.
 	// Skip pruning if backup wasn't fully successful. We don't want to be
 	// deleting things if the backup process is not healthy.
-	backupDir := mysqlctl.GetBackupDir(initKeyspace, initShard)
 	doBackup, err := shouldBackup(ctx, topoServer, backupStorage, backupDir)
 	if err != nil {
 		log.Errorf("Can't take backup: %v", err)
@@ -232,7 +251,9 @@ func main() {
 	log.Info("Exiting.")
 }
 
-func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage backupstorage.BackupStorage) error {
+// startTemporaryMysqld starts a mysqld in a temporary data dir, as if we are mysqlctld
+// provisioning a fresh tablet. The returned function shuts it down and removes the data dir.
+func startTemporaryMysqld(ctx context.Context) (*topodatapb.TabletAlias, *mysqlctl.Mysqld, *mysqlctl.Mycnf, func(), error) {
 	// This is an imaginary tablet alias. The value doesn't matter for anything,
 	// except that we generate a random UID to ensure the target backup
 	// directory is unique if multiple vtbackup instances are launched for the
@@ -240,7 +261,7 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 	// storage location.
 	bigN, err := rand.Int(rand.Reader, big.NewInt(math.MaxUint32))
 	if err != nil {
-		return fmt.Errorf("can't generate random tablet UID: %v", err)
+		return nil, nil, nil, nil, fmt.Errorf("can't generate random tablet UID: %v", err)
 	}
 	tabletAlias := &topodatapb.TabletAlias{
 		Cell: "vtbackup",
@@ -251,27 +272,29 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 	// every invocation of vtbackup starts with a clean slate, and it does not
 	// accumulate garbage (and run out of disk space) if it's restarted.
 	tabletDir := mysqlctl.TabletDir(tabletAlias.Uid)
-	defer func() {
+	removeTabletDir := func() {
 		log.Infof("Removing temporary tablet directory: %v", tabletDir)
 		if err := os.RemoveAll(tabletDir); err != nil {
 			log.Warningf("Failed to remove temporary tablet directory: %v", err)
 		}
-	}()
+	}
 
 	// Start up mysqld as if we are mysqlctld provisioning a fresh tablet.
 	mysqld, mycnf, err := mysqlctl.CreateMysqldAndMycnf(tabletAlias.Uid, mysqlSocket, mysqlPort)
 	if err != nil {
-		return fmt.Errorf("failed to initialize mysql config: %v", err)
+		removeTabletDir()
+		return nil, nil, nil, nil, fmt.Errorf("failed to initialize mysql config: %v", err)
 	}
 	initCtx, initCancel := context.WithTimeout(ctx, mysqlTimeout)
 	defer initCancel()
 	initMysqldAt := time.Now()
 	if err := mysqld.Init(initCtx, mycnf, initDBSQLFile); err != nil {
-		return fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
+		removeTabletDir()
+		return nil, nil, nil, nil, fmt.Errorf("failed to initialize mysql data dir and start mysqld: %v", err)
 	}
 	durationByPhase.Set("InitMySQLd", int64(time.Since(initMysqldAt).Seconds()))
 	// Shut down mysqld when we're done.
-	defer func() {
+	cleanup := func() {
 		// Be careful not to use the original context, because we don't want to
 		// skip shutdown just because we timed out waiting for other things.
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -279,7 +302,17 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 		if err := mysqld.Shutdown(ctx, mycnf, false); err != nil {
 			log.Errorf("failed to shutdown mysqld: %v", err)
 		}
-	}()
+		removeTabletDir()
+	}
+	return tabletAlias, mysqld, mycnf, cleanup, nil
+}
+
+func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage backupstorage.BackupStorage) error {
+	tabletAlias, mysqld, mycnf, cleanup, err := startTemporaryMysqld(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
 	extraEnv := map[string]string{
 		"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias),
@@ -494,6 +527,99 @@ func takeBackup(ctx context.Context, topoServer *topo.Server, backupStorage back
 	return nil
 }
 
+// verifyBackup restores a backup into a temporary mysqld, checks the restored data,
+// and records the result in the backup storage.
+func verifyBackup(ctx context.Context, backupStorage backupstorage.BackupStorage, backupDir string) error {
+	backups, err := backupStorage.ListBackups(ctx, backupDir)
+	if err != nil {
+		return fmt.Errorf("can't list backups: %v", err)
+	}
+	var backup backupstorage.BackupHandle
+	if verifyBackupName == "" {
+		backup = lastCompleteBackup(ctx, backups)
+	} else {
+		for _, bh := range backups {
+			if bh.Name() == verifyBackupName {
+				backup = bh
+				break
+			}
+		}
+	}
+	if backup == nil {
+		return fmt.Errorf("no backup to verify found in %v", backupDir)
+	}
+	manifest, err := mysqlctl.GetBackupManifest(ctx, backup)
+	if err != nil {
+		return fmt.Errorf("can't get MANIFEST of backup %v: %v", backup.Name(), err)
+	}
+	if manifest.Incremental {
+		return fmt.Errorf("backup %v is incremental, only full backups can be verified", backup.Name())
+	}
+	// The restore picks the most recent full backup taken at or before the given time.
+	backupTime, err := time.Parse(time.RFC3339, manifest.BackupTime)
+	if err != nil {
+		return fmt.Errorf("can't parse time of backup %v: %v", backup.Name(), err)
+	}
+
+	verification := mysqlctl.NewBackupVerification(backup.Name())
+	checkErr := checkBackup(ctx, manifest, backupTime, verification)
+	if checkErr != nil {
+		verification.Success = false
+		verification.Problems = append(verification.Problems, checkErr.Error())
+	}
+	if err := mysqlctl.SaveBackupVerification(ctx, backupStorage, initKeyspace, initShard, verification); err != nil {
+		return fmt.Errorf("can't record verification of backup %v: %v", backup.Name(), err)
+	}
+	if !verification.Success {
+		return fmt.Errorf("backup %v is not valid: %v", backup.Name(), strings.Join(verification.Problems, "; "))
+	}
+	log.Infof("Backup %v is valid: %v tables checked, %v table checksums compared.", backup.Name(), verification.CheckedTables, verification.ComparedChecksums)
+	return nil
+}
+
+// checkBackup restores the given backup into a temporary mysqld, and checks its tables.
+func checkBackup(ctx context.Context, manifest *mysqlctl.BackupManifest, backupTime time.Time, verification *mysqlctl.BackupVerification) error {
+	tabletAlias, mysqld, mycnf, cleanup, err := startTemporaryMysqld(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	dbName := initDbNameOverride
+	if dbName == "" {
+		dbName = fmt.Sprintf("vt_%s", initKeyspace)
+	}
+	log.Infof("Restoring backup %v", verification.BackupName)
+	restoreAt := time.Now()
+	restoredManifest, err := mysqlctl.Restore(ctx, mysqlctl.RestoreParams{
+		Cnf:                 mycnf,
+		Mysqld:              mysqld,
+		Logger:              logutil.NewConsoleLogger(),
+		Concurrency:         concurrency,
+		HookExtraEnv:        map[string]string{"TABLET_ALIAS": topoproto.TabletAliasString(tabletAlias)},
+		DeleteBeforeRestore: true,
+		DbName:              dbName,
+		Keyspace:            initKeyspace,
+		Shard:               initShard,
+		StartTime:           backupTime,
+		Stats:               backupstats.RestoreStats(),
+	})
+	if err != nil {
+		return fmt.Errorf("can't restore from backup: %v", err)
+	}
+	if restoredManifest.HashKey() != manifest.HashKey() {
+		return fmt.Errorf("another backup taken at %v was restored: %v", manifest.BackupTime, restoredManifest.HashKey())
+	}
+	durationByPhase.Set("RestoreBackupToVerify", int64(time.Since(restoreAt).Seconds()))
+
+	checkAt := time.Now()
+	if err := mysqlctl.CheckRestoredBackup(ctx, mysqld, manifest, verification, logutil.NewConsoleLogger()); err != nil {
+		return err
+	}
+	durationByPhase.Set("CheckRestoredBackup", int64(time.Since(checkAt).Seconds()))
+	return nil
+}
+
 func resetReplication(ctx context.Context, pos mysql.Position, mysqld mysqlctl.MysqlDaemon) error {
 	cmds := []string{
 		"STOP SLAVE",
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// RequeueDeadLetterMessages makes a RequeueDeadLetterMessages gRPC call to a vtctld.
+	RequeueDeadLetterMessages = &cobra.Command{
+		Use:   "RequeueDeadLetterMessages --table <message-table> [--ids <id1,id2,...>] <keyspace>",
+		Short: "Moves the messages of the dead letter table of a message table back to the message table, on every shard of the keyspace.",
+		Long: `Moves the messages of the dead letter table of a message table back to the message table, on every shard of the keyspace.
+The messages are sent again right away, with all of their retries. If no ids are given, all the messages of the dead letter table are requeued.`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandRequeueDeadLetterMessages,
+	}
+)
+
+var requeueDeadLetterMessagesOptions = struct {
+	Table string
+	IDs   []string
+}{}
+
+func commandRequeueDeadLetterMessages(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RequeueDeadLetterMessages(commandCtx, &vtctldatapb.RequeueDeadLetterMessagesRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Table:    requeueDeadLetterMessagesOptions.Table,
+		Ids:      requeueDeadLetterMessagesOptions.IDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	RequeueDeadLetterMessages.Flags().StringVar(&requeueDeadLetterMessagesOptions.Table, "table", "", "The message table whose dead letters to requeue.")
+	RequeueDeadLetterMessages.MarkFlagRequired("table")
+	RequeueDeadLetterMessages.Flags().StringSliceVar(&requeueDeadLetterMessagesOptions.IDs, "ids", nil, "The ids of the messages to requeue. All of them are requeued if none are given.")
+	Root.AddCommand(RequeueDeadLetterMessages)
+}
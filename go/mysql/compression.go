@@ -0,0 +1,308 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Compression algorithms of the compressed protocol.
+const (
+	// CompressionZlib is negotiated with CapabilityClientCompress.
+	CompressionZlib = "zlib"
+
+	// CompressionZstd is negotiated with CapabilityClientZstdCompressionAlgorithm.
+	CompressionZstd = "zstd"
+
+	// DefaultZstdCompressionLevel is the zstd level MySQL uses by default.
+	DefaultZstdCompressionLevel = 3
+)
+
+const (
+	// compressedHeaderSize is the size of the header of a compressed packet:
+	// 3 bytes of compressed length, 1 byte of sequence, and 3 bytes of uncompressed length.
+	compressedHeaderSize = 7
+
+	// minCompressLength is the size under which payloads are not worth compressing.
+	// It is the same as MIN_COMPRESS_LENGTH in MySQL.
+	minCompressLength = 50
+)
+
+// ValidateCompressionAlgorithms checks a comma separated list of compression algorithms,
+// as used by the flags, and returns its elements.
+func ValidateCompressionAlgorithms(algorithms string) ([]string, error) {
+	var result []string
+	for _, algorithm := range strings.Split(algorithms, ",") {
+		algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+		switch algorithm {
+		case "":
+		case CompressionZlib, CompressionZstd:
+			result = append(result, algorithm)
+		default:
+			return nil, fmt.Errorf("unknown compression algorithm: %s", algorithm)
+		}
+	}
+	return result, nil
+}
+
+var (
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+
+	zstdEncodersMu sync.Mutex
+	zstdEncoders   = map[zstd.EncoderLevel]*zstd.Encoder{}
+)
+
+// getZstdDecoder returns the decoder shared by all connections, since DecodeAll
+// can be used concurrently.
+func getZstdDecoder() *zstd.Decoder {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+	return zstdDecoder
+}
+
+// getZstdEncoder returns the encoder for a level, which is shared by all connections
+// using that level, since EncodeAll can be used concurrently.
+func getZstdEncoder(level zstd.EncoderLevel) (*zstd.Encoder, error) {
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+
+	if enc, ok := zstdEncoders[level]; ok {
+		return enc, nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	zstdEncoders[level] = enc
+	return enc, nil
+}
+
+// compressedConn implements the compressed protocol. It sits between the packet
+// layer of a Conn and the network: the packets written to it are handled as a
+// stream of bytes, which is cut into compressed packets, and the compressed packets
+// read from the network are decompressed back into a stream of packets.
+// A compressed packet can contain several packets, or only part of one.
+type compressedConn struct {
+	algorithm string
+	zstdLevel zstd.EncoderLevel
+
+	r io.Reader
+	w io.Writer
+
+	// sequence is the sequence number of the compressed packets.
+	// It is independent of the sequence number of the packets they contain.
+	sequence uint8
+
+	header [compressedHeaderSize]byte
+
+	// pending is the decompressed data that has not been read yet.
+	pending []byte
+	// readBuf and decompressBuf hold the last compressed packet that was read.
+	readBuf       []byte
+	decompressBuf []byte
+	payload       bytes.Reader
+	zlibReader    io.ReadCloser
+
+	// writeBuf holds the compressed packet being written, header included.
+	writeBuf   bytes.Buffer
+	zlibWriter *zlib.Writer
+	zstdBuf    []byte
+}
+
+func newCompressedConn(algorithm string, zstdLevel int, r io.Reader, w io.Writer) *compressedConn {
+	return &compressedConn{
+		algorithm: algorithm,
+		zstdLevel: zstd.EncoderLevelFromZstd(zstdLevel),
+		r:         r,
+		w:         w,
+	}
+}
+
+// Read implements io.Reader, returning decompressed data.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	for len(cc.pending) == 0 {
+		if err := cc.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.pending)
+	cc.pending = cc.pending[n:]
+	return n, nil
+}
+
+func (cc *compressedConn) readCompressedPacket() error {
+	// Errors from reading the header are returned as is, so the
+	// callers can tell when the connection was closed.
+	if _, err := io.ReadFull(cc.r, cc.header[:]); err != nil {
+		return err
+	}
+	length := int(uint32(cc.header[0]) | uint32(cc.header[1])<<8 | uint32(cc.header[2])<<16)
+	uncompressedLength := int(uint32(cc.header[4]) | uint32(cc.header[5])<<8 | uint32(cc.header[6])<<16)
+
+	// The peer's next packet continues our sequence, but MySQL doesn't
+	// check the sequence of compressed packets strictly, neither do we.
+	cc.sequence = cc.header[3] + 1
+
+	if cap(cc.readBuf) < length {
+		cc.readBuf = make([]byte, length)
+	}
+	cc.readBuf = cc.readBuf[:length]
+	if _, err := io.ReadFull(cc.r, cc.readBuf); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+	}
+
+	if uncompressedLength == 0 {
+		// The payload was too small to be compressed.
+		cc.pending = cc.readBuf
+		return nil
+	}
+
+	if cap(cc.decompressBuf) < uncompressedLength {
+		cc.decompressBuf = make([]byte, uncompressedLength)
+	}
+	cc.decompressBuf = cc.decompressBuf[:uncompressedLength]
+
+	switch cc.algorithm {
+	case CompressionZlib:
+		cc.payload.Reset(cc.readBuf)
+		if cc.zlibReader == nil {
+			zr, err := zlib.NewReader(&cc.payload)
+			if err != nil {
+				return vterrors.Wrapf(err, "cannot decompress packet")
+			}
+			cc.zlibReader = zr
+		} else if err := cc.zlibReader.(zlib.Resetter).Reset(&cc.payload, nil); err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+		if _, err := io.ReadFull(cc.zlibReader, cc.decompressBuf); err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+	case CompressionZstd:
+		out, err := getZstdDecoder().DecodeAll(cc.readBuf, cc.decompressBuf[:0])
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot decompress packet")
+		}
+		if len(out) != uncompressedLength {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "decompressed packet has length %v, expected %v", len(out), uncompressedLength)
+		}
+		cc.decompressBuf = out
+	}
+	cc.pending = cc.decompressBuf
+	return nil
+}
+
+// Write implements io.Writer, sending the data as compressed packets.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > MaxPacketSize {
+			chunk = chunk[:MaxPacketSize]
+		}
+		if err := cc.writeCompressedPacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writeCompressedPacket(data []byte) error {
+	defer func() {
+		// don't hold on to the buffer used by a huge packet
+		if cc.writeBuf.Cap() > 4*connBufferSize {
+			cc.writeBuf = bytes.Buffer{}
+			cc.zstdBuf = nil
+		}
+	}()
+
+	cc.writeBuf.Reset()
+	cc.writeBuf.Write(cc.header[:])
+
+	uncompressedLength := 0
+	if len(data) >= minCompressLength {
+		if err := cc.compress(data); err != nil {
+			return err
+		}
+		uncompressedLength = len(data)
+		if cc.writeBuf.Len()-compressedHeaderSize >= len(data) {
+			// not worth it, send the data as is
+			uncompressedLength = 0
+		}
+	}
+	if uncompressedLength == 0 {
+		cc.writeBuf.Truncate(compressedHeaderSize)
+		cc.writeBuf.Write(data)
+	}
+
+	buf := cc.writeBuf.Bytes()
+	length := len(buf) - compressedHeaderSize
+	buf[0] = byte(length)
+	buf[1] = byte(length >> 8)
+	buf[2] = byte(length >> 16)
+	buf[3] = cc.sequence
+	buf[4] = byte(uncompressedLength)
+	buf[5] = byte(uncompressedLength >> 8)
+	buf[6] = byte(uncompressedLength >> 16)
+
+	if n, err := cc.w.Write(buf); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	} else if n != len(buf) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Write(compressed packet) returned a short write: %v < %v", n, len(buf))
+	}
+	cc.sequence++
+	return nil
+}
+
+// compress appends the compressed data to writeBuf
+func (cc *compressedConn) compress(data []byte) error {
+	switch cc.algorithm {
+	case CompressionZlib:
+		if cc.zlibWriter == nil {
+			cc.zlibWriter = zlib.NewWriter(&cc.writeBuf)
+		} else {
+			cc.zlibWriter.Reset(&cc.writeBuf)
+		}
+		if _, err := cc.zlibWriter.Write(data); err != nil {
+			return vterrors.Wrapf(err, "cannot compress packet")
+		}
+		if err := cc.zlibWriter.Close(); err != nil {
+			return vterrors.Wrapf(err, "cannot compress packet")
+		}
+	case CompressionZstd:
+		enc, err := getZstdEncoder(cc.zstdLevel)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot compress packet")
+		}
+		cc.zstdBuf = enc.EncodeAll(data, cc.zstdBuf[:0])
+		cc.writeBuf.Write(cc.zstdBuf)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttls"
+)
+
+func TestValidateCompressionAlgorithms(t *testing.T) {
+	algorithms, err := ValidateCompressionAlgorithms("")
+	require.NoError(t, err)
+	assert.Empty(t, algorithms)
+
+	algorithms, err = ValidateCompressionAlgorithms("zstd, ZLIB")
+	require.NoError(t, err)
+	assert.Equal(t, []string{CompressionZstd, CompressionZlib}, algorithms)
+
+	_, err = ValidateCompressionAlgorithms("zlib,lz4")
+	require.EqualError(t, err, "unknown compression algorithm: lz4")
+}
+
+func TestCompressedConnRoundTrip(t *testing.T) {
+	payloads := map[string][]byte{
+		"empty":      {},
+		"small":      []byte("select 1"),
+		"repetitive": bytes.Repeat([]byte("compress me "), 10000),
+		"multiple":   []byte(strings.Repeat("x", MaxPacketSize+100)),
+	}
+	for _, algorithm := range []string{CompressionZlib, CompressionZstd} {
+		for name, payload := range payloads {
+			t.Run(algorithm+"/"+name, func(t *testing.T) {
+				var network bytes.Buffer
+				writer := newCompressedConn(algorithm, DefaultZstdCompressionLevel, nil, &network)
+				n, err := writer.Write(payload)
+				require.NoError(t, err)
+				assert.Equal(t, len(payload), n)
+
+				// The same connection can be used again after a packet that was cut.
+				_, err = writer.Write([]byte("after"))
+				require.NoError(t, err)
+
+				if len(payload) >= minCompressLength {
+					assert.Less(t, network.Len(), len(payload), "payload was not compressed")
+				}
+
+				reader := newCompressedConn(algorithm, DefaultZstdCompressionLevel, &network, nil)
+				got := make([]byte, len(payload)+len("after"))
+				_, err = io.ReadFull(reader, got)
+				require.NoError(t, err)
+				assert.True(t, bytes.Equal(append(payload, "after"...), got))
+				assert.Equal(t, writer.sequence, reader.sequence)
+
+				_, err = reader.Read(got)
+				assert.Equal(t, io.EOF, err)
+			})
+		}
+	}
+}
+
+func TestCompressedConnUncompressedHeader(t *testing.T) {
+	// Small payloads are sent as is, with an uncompressed length of 0.
+	var network bytes.Buffer
+	writer := newCompressedConn(CompressionZlib, 0, nil, &network)
+	_, err := writer.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 0, 0, 0, 'a', 'b', 'c'}, network.Bytes())
+}
+
+func TestCompressedProtocol(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{
+		{Password: "password1"},
+	}
+	defer authServer.close()
+
+	tcases := []struct {
+		server      []string
+		client      string
+		compression string
+	}{
+		{server: nil, client: CompressionZlib, compression: ""},
+		{server: []string{CompressionZlib}, client: "", compression: ""},
+		{server: []string{CompressionZlib}, client: CompressionZlib, compression: CompressionZlib},
+		{server: []string{CompressionZlib}, client: CompressionZstd, compression: ""},
+		{server: []string{CompressionZstd}, client: CompressionZstd, compression: CompressionZstd},
+		{server: []string{CompressionZlib, CompressionZstd}, client: CompressionZstd, compression: CompressionZstd},
+	}
+	for _, tcase := range tcases {
+		t.Run(strings.Join(tcase.server, ",")+"/"+tcase.client, func(t *testing.T) {
+			l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false)
+			require.NoError(t, err)
+			defer l.Close()
+			l.CompressionAlgorithms = tcase.server
+			go l.Accept()
+
+			params := &ConnParams{
+				Host:                 l.Addr().(*net.TCPAddr).IP.String(),
+				Port:                 l.Addr().(*net.TCPAddr).Port,
+				Uname:                "user1",
+				Pass:                 "password1",
+				SslMode:              vttls.Disabled,
+				Compression:          tcase.client,
+				ZstdCompressionLevel: 5,
+			}
+			conn, err := Connect(context.Background(), params)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			if tcase.compression == "" {
+				assert.Nil(t, conn.compression)
+			} else {
+				require.NotNil(t, conn.compression)
+				assert.Equal(t, tcase.compression, conn.compression.algorithm)
+			}
+
+			// Run a few queries, with small and large results in both directions.
+			for i := 0; i < 3; i++ {
+				result, err := conn.ExecuteFetch("select rows", 10000, true)
+				require.NoError(t, err)
+				assert.Equal(t, selectRowsResult.Rows, result.Rows)
+
+				query := benchmarkQueryPrefix + strings.Repeat("abcdefgh", 100000)
+				result, err = conn.ExecuteFetch(query, 10000, true)
+				require.NoError(t, err)
+				require.Len(t, result.Rows, 1)
+				assert.Equal(t, query, result.Rows[0][0].ToString())
+			}
+
+			// Send a ComQuit to avoid the error message on the server side.
+			conn.writeComQuit()
+		})
+	}
+}
@@ -156,8 +156,8 @@ type Conn struct {
 	// the client and the server, and currently in use.
 	// It is set during the initial handshake.
 	//
-	// It is only used for CapabilityClientDeprecateEOF
-	// and CapabilityClientFoundRows.
+	// It is only used for CapabilityClientDeprecateEOF,
+	// CapabilityClientFoundRows and the compression capabilities.
 	Capabilities uint32
 
 	// closed is set to true when Close() is called on the connection.
@@ -199,6 +199,13 @@ type Conn struct {
 	// enableQueryInfo controls whether we parse the INFO field in QUERY_OK packets
 	// See: ConnParams.EnableQueryInfo
 	enableQueryInfo bool
+
+	// compression implements the compressed protocol, once it has been
+	// negotiated during the handshake. It is nil for uncompressed connections.
+	compression *compressedConn
+
+	// zstdCompressionLevel is the compression level used when zstd is negotiated.
+	zstdCompressionLevel int
 }
 
 // splitStatementFunciton is the function that is used to split the statement in case of a multi-statement query.
@@ -276,7 +283,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.netWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -320,7 +327,16 @@ func (c *Conn) getWriter() (w io.Writer, unget func()) {
 		}
 	}
 	c.bufMu.Unlock()
-	return c.conn, func() {}
+	return c.netWriter(), func() {}
+}
+
+// netWriter returns the writer the packets go to: the connection itself,
+// or the compression layer on top of it.
+func (c *Conn) netWriter() io.Writer {
+	if c.compression != nil {
+		return c.compression
+	}
+	return c.conn
 }
 
 // startFlushTimer must be called while holding lock on bufMu.
@@ -347,8 +363,12 @@ func (c *Conn) stopFlushTimer() {
 }
 
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// depending on which buffer size was passed to newServerConn, or the compression
+// layer on top of them.
 func (c *Conn) getReader() io.Reader {
+	if c.compression != nil {
+		return c.compression
+	}
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
@@ -377,7 +397,12 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 
 	sequence := uint8(c.header[3])
 	if sequence != c.sequence {
-		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
+		// MySQL doesn't keep the sequence of the packets inside compressed
+		// packets consistent, so we only check it on uncompressed connections.
+		if c.compression == nil {
+			return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
+		}
+		c.sequence = sequence
 	}
 
 	c.sequence++
@@ -674,13 +699,32 @@ func (c *Conn) recycleWritePacket() {
 	c.currentEphemeralPolicy = ephemeralUnused
 }
 
+// resetSequence resets the sequence numbers at the start of a new command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	if c.compression != nil {
+		c.compression.sequence = 0
+	}
+}
+
+// enableCompression switches to the compressed protocol if it was negotiated.
+// Both sides must call it right after the handshake succeeds.
+func (c *Conn) enableCompression() {
+	switch {
+	case c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		c.compression = newCompressedConn(CompressionZstd, c.zstdCompressionLevel, c.getReader(), c.conn)
+	case c.Capabilities&CapabilityClientCompress != 0:
+		c.compression = newCompressedConn(CompressionZlib, 0, c.getReader(), c.conn)
+	}
+}
+
 // writeComQuit writes a Quit message for the server, to indicate we
 // want to close the connection.
 // Client -> Server.
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -886,7 +930,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -57,6 +57,15 @@ type ConnParams struct {
 	// for informative purposes. It has no programmatic value. Returning this field is
 	// disabled by default.
 	EnableQueryInfo bool
+
+	// Compression is the algorithm to use for the compressed protocol,
+	// CompressionZlib or CompressionZstd. The connection is not compressed
+	// if it is empty, or if the server doesn't support the algorithm.
+	Compression string `json:"compression,omitempty"`
+
+	// ZstdCompressionLevel is the compression level to use with zstd.
+	// DefaultZstdCompressionLevel is used if it is not set.
+	ZstdCompressionLevel int `json:"zstd_compression_level,omitempty"`
 }
 
 // EnableSSL will set the right flag on the parameters.
@@ -49,6 +49,7 @@ const (
 	InstantAddDropColumnFlavorCapability
 	InstantChangeColumnDefaultFlavorCapability
 	InstantExpandEnumCapability
+	InstantReorderColumnFlavorCapability
 	MySQLJSONFlavorCapability
 	MySQLUpgradeInServerFlavorCapability
 	DynamicRedoLogCapacityFlavorCapability // supported in MySQL 8.0.30 and above: https://dev.mysql.com/doc/relnotes/mysql/8.0/en/news-8-0-30.html
@@ -228,7 +229,7 @@ func GetFlavor(serverVersion string, flavorFunc func() flavor) (f flavor, capabl
 	}
 	return f,
 		func(capability FlavorCapability) (bool, error) {
-			return f.supportsCapability(serverVersion, capability)
+			return f.supportsCapability(canonicalVersion, capability)
 		}, canonicalVersion
 }
 
@@ -275,6 +275,17 @@ func (mariadbFlavor) readBinlogEvent(c *Conn) (BinlogEvent, error) {
 // supportsCapability is part of the Flavor interface.
 func (mariadbFlavor) supportsCapability(serverVersion string, capability FlavorCapability) (bool, error) {
 	switch capability {
+	case InstantDDLFlavorCapability,
+		InstantAddLastColumnFlavorCapability,
+		InstantAddDropVirtualColumnFlavorCapability,
+		InstantChangeColumnDefaultFlavorCapability,
+		InstantExpandEnumCapability:
+		// supported in MariaDB 10.3 and above: https://mariadb.com/kb/en/instant-add-column-for-innodb/
+		return ServerVersionAtLeast(serverVersion, 10, 3)
+	case InstantAddDropColumnFlavorCapability,
+		InstantReorderColumnFlavorCapability:
+		// supported in MariaDB 10.4 and above: https://mariadb.com/kb/en/instant-add-column-for-innodb/
+		return ServerVersionAtLeast(serverVersion, 10, 4)
 	default:
 		return false, nil
 	}
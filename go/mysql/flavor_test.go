@@ -160,6 +160,36 @@ func TestGetFlavor(t *testing.T) {
 			capability: DisableRedoLogFlavorCapability,
 			isCapable:  false,
 		},
+		{
+			version:    "8.0.30",
+			capability: InstantReorderColumnFlavorCapability,
+			isCapable:  false,
+		},
+		{
+			version:    "10.2.9-MariaDB",
+			capability: InstantDDLFlavorCapability,
+			isCapable:  false,
+		},
+		{
+			version:    "10.3.2-MariaDB-log",
+			capability: InstantAddLastColumnFlavorCapability,
+			isCapable:  true,
+		},
+		{
+			version:    "10.3.2-MariaDB-log",
+			capability: InstantAddDropColumnFlavorCapability,
+			isCapable:  false,
+		},
+		{
+			version:    "5.5.5-10.4.12-MariaDB",
+			capability: InstantReorderColumnFlavorCapability,
+			isCapable:  true,
+		},
+		{
+			version:    "5.5.5-10.4.12-MariaDB",
+			capability: TransactionalGtidExecutedFlavorCapability,
+			isCapable:  false,
+		},
 	}
 	for _, tc := range testcases {
 		name := fmt.Sprintf("%s %v", tc.version, tc.capability)
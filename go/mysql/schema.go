@@ -68,7 +68,7 @@ from information_schema.columns
 where table_schema = database()`
 
 	// fetchColumns are the columns we fetch
-	fetchColumns = "table_name, column_name, data_type, collation_name"
+	fetchColumns = "table_name, column_name, data_type, collation_name, column_key"
 
 	// FetchUpdatedTables queries fetches all information about updated tables
 	FetchUpdatedTables = `select  ` + fetchColumns + `
@@ -114,6 +114,20 @@ order by table_name, ordinal_position`
 
 	// FetchViews queries fetches all views
 	FetchViews = `select table_name, view_definition, create_statement from _vt.views where table_schema = database()`
+
+	// fetchForeignKeys reads the foreign key constraints, one row per column, from information_schema
+	fetchForeignKeys = `select kcu.table_name, kcu.constraint_name, kcu.column_name, kcu.referenced_table_name, kcu.referenced_column_name, rc.update_rule, rc.delete_rule
+from information_schema.key_column_usage as kcu
+	join information_schema.referential_constraints as rc on kcu.constraint_schema = rc.constraint_schema and kcu.table_name = rc.table_name and kcu.constraint_name = rc.constraint_name
+where kcu.table_schema = database() and kcu.referenced_table_name is not null`
+
+	// FetchUpdatedForeignKeys queries fetches the foreign keys defined on updated tables
+	FetchUpdatedForeignKeys = fetchForeignKeys + ` and kcu.table_name in ::tableNames
+order by kcu.table_name, kcu.constraint_name, kcu.ordinal_position`
+
+	// FetchForeignKeys queries fetches all foreign keys
+	FetchForeignKeys = fetchForeignKeys + `
+order by kcu.table_name, kcu.constraint_name, kcu.ordinal_position`
 )
 
 // BaseShowTablesFields contains the fields returned by a BaseShowTables or a BaseShowTablesForTable command.
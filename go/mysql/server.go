@@ -203,6 +203,10 @@ type Listener struct {
 	// RequireSecureTransport configures the server to reject connections from insecure clients
 	RequireSecureTransport bool
 
+	// CompressionAlgorithms are the algorithms (CompressionZlib, CompressionZstd)
+	// clients can use for the compressed protocol. It is disabled if empty.
+	CompressionAlgorithms []string
+
 	// PreHandleFunc is called for each incoming connection, immediately after
 	// accepting a new connection. By default it's no-op. Useful for custom
 	// connection inspection or TLS termination. The returned connection is
@@ -510,6 +514,9 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		return
 	}
 
+	// Everything after the handshake uses the compressed protocol, if negotiated.
+	c.enableCompression()
+
 	// Record how long we took to establish the connection
 	timings.Record(connectTimingKey, acceptTime)
 
@@ -549,6 +556,21 @@ func (l *Listener) isShutdown() bool {
 	return l.shutdown.Get()
 }
 
+// compressionCapabilities returns the capability flags of the compression
+// algorithms that are enabled for this listener.
+func (l *Listener) compressionCapabilities() uint32 {
+	var capabilities uint32
+	for _, algorithm := range l.CompressionAlgorithms {
+		switch algorithm {
+		case CompressionZlib:
+			capabilities |= CapabilityClientCompress
+		case CompressionZstd:
+			capabilities |= CapabilityClientZstdCompressionAlgorithm
+		}
+	}
+	return capabilities
+}
+
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
 func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, enableTLS bool) ([]byte, error) {
@@ -568,6 +590,7 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, en
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(c.listener.compressionCapabilities())
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -686,6 +709,16 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 	// after SSL negotiation, do not overwrite capabilities.
 	if firstTime {
 		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows)
+
+		// Use the compression the client asked for, if we support it.
+		// zstd is preferred if the client asked for both.
+		compression := clientFlags & l.compressionCapabilities()
+		if compression&CapabilityClientZstdCompressionAlgorithm != 0 {
+			c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+			c.zstdCompressionLevel = DefaultZstdCompressionLevel
+		} else {
+			c.Capabilities |= compression & CapabilityClientCompress
+		}
 	}
 
 	// set connection capability for executing multi statements
@@ -785,8 +818,18 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		if _, attrsEnd, err := parseConnAttrs(data, pos); err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
+			pos = len(data)
+		} else {
+			pos = attrsEnd
+		}
+	}
+
+	// The zstd compression level comes last, if the client asked for zstd.
+	if c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0 {
+		if level, _, ok := readByte(data, pos); ok && level > 0 {
+			c.zstdCompressionLevel = int(level)
 		}
 	}
 
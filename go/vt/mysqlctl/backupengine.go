@@ -117,6 +117,9 @@ type RestoreParams struct {
 	// RestoreToPos hints that a point in time recovery is requested, to recover up to the specific given pos.
 	// When empty, the restore is a normal from full backup
 	RestoreToPos mysql.Position
+	// RestoreToTimestamp hints that a point in time recovery is requested, to recover up to the last
+	// transaction at or before the given time. It cannot be used along with RestoreToPos.
+	RestoreToTimestamp time.Time
 	// When DryRun is set, no restore actually takes place; but some of its steps are validated.
 	DryRun bool
 	// Stats let's restore engines report detailed restore timings.
@@ -136,13 +139,14 @@ func (p RestoreParams) Copy() RestoreParams {
 		p.Shard,
 		p.StartTime,
 		p.RestoreToPos,
+		p.RestoreToTimestamp,
 		p.DryRun,
 		p.Stats,
 	}
 }
 
 func (p *RestoreParams) IsIncrementalRecovery() bool {
-	return !p.RestoreToPos.IsZero()
+	return !p.RestoreToPos.IsZero() || !p.RestoreToTimestamp.IsZero()
 }
 
 // RestoreEngine is the interface to restore a backup with a given engine.
@@ -253,6 +257,10 @@ type BackupManifest struct {
 	// Incremental indicates whether this is an incremental backup
 	Incremental bool
 
+	// IncrementalDetails is only applicable to incremental backups, and holds the timestamps
+	// of the transactions found in the backed up binary logs.
+	IncrementalDetails *IncrementalBackupDetails `json:",omitempty"`
+
 	// BackupTime is when the backup was taken in UTC time (RFC 3339 format)
 	BackupTime string
 
@@ -268,6 +276,23 @@ type BackupManifest struct {
 	Keyspace string
 
 	Shard string
+
+	// TableChecksums holds the row counts and checksums of a sample of the tables, at
+	// the backup position. They are compared by the verification of the backup.
+	TableChecksums []TableChecksum `json:",omitempty"`
+}
+
+// IncrementalBackupDetails describes the binary logs of an incremental backup. Timestamps are in
+// RFC 3339 format, UTC, and have the precision of the binary log events: one second.
+type IncrementalBackupDetails struct {
+	// FirstTimestamp is the time of the first transaction in the binary logs
+	FirstTimestamp string
+	// FirstTimestampBinlog is the binary log in which the first transaction was found
+	FirstTimestampBinlog string
+	// LastTimestamp is the time of the last transaction in the binary logs
+	LastTimestamp string
+	// LastTimestampBinlog is the binary log in which the last transaction was found
+	LastTimestampBinlog string
 }
 
 func (m *BackupManifest) HashKey() string {
@@ -412,6 +437,14 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 					continue
 				}
 			}
+			var finishedTime time.Time
+			if !params.RestoreToTimestamp.IsZero() {
+				finishedTime, err = backupFinishedTime(bm)
+				if err != nil {
+					params.Logger.Warningf("Restore: skipping backup %v/%v with invalid time %v: %v", backupDir, bh.Name(), bm.FinishedTime, err)
+					continue
+				}
+			}
 
 			switch {
 			case checkBackupTime:
@@ -426,6 +459,12 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 					// this is the most recent backup which is <= desired position
 					return index
 				}
+			case !params.RestoreToTimestamp.IsZero():
+				// restore to specific time, the backup position must not have any transaction past that time
+				if !finishedTime.After(params.RestoreToTimestamp) {
+					// this is the most recent backup which is <= desired time
+					return index
+				}
 			default:
 				// restore latest full backup
 				params.Logger.Infof("Restore: found latest backup %v %v to restore", bh.Directory(), bh.Name())
@@ -438,6 +477,9 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 		if checkBackupTime {
 			params.Logger.Errorf("No valid backup found before time %v", params.StartTime.Format(BackupTimestampFormat))
 		}
+		if !params.RestoreToTimestamp.IsZero() {
+			params.Logger.Errorf("No valid backup found before time %v", params.RestoreToTimestamp.Format(time.RFC3339))
+		}
 		// There is at least one attempted backup, but none could be read.
 		// This implies there is data we ought to have, so it's not safe to start
 		// up empty.
@@ -448,6 +490,17 @@ func FindBackupToRestore(ctx context.Context, params RestoreParams, bhs []backup
 	restorePath := &RestorePath{
 		manifestHandleMap: manifestHandleMap,
 	}
+	if !params.RestoreToTimestamp.IsZero() {
+		// restore to a timestamp (using incremental backups):
+		// the incremental backups that follow the full backup are applied until the one
+		// whose binary logs go past the given time.
+		manifests, err := FindPITRToTimePath(params.RestoreToTimestamp, manifests)
+		if err != nil {
+			return nil, err
+		}
+		restorePath.manifests = manifests
+		return restorePath, nil
+	}
 	if params.RestoreToPos.IsZero() {
 		// restoring from a single full backup:
 		restorePath.Add(manifests[0])
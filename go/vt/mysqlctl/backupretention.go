@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// BackupRetentionPolicy defines which backups of a shard are kept. A full backup is kept if
+// any of the rules retains it. An incremental backup is kept as long as it holds transactions
+// past the oldest kept full backup, since it is needed for a point in time recovery from it.
+// The backups without a MANIFEST are never pruned, since they may be in progress.
+type BackupRetentionPolicy struct {
+	// Full is the number of most recent full backups to keep. It is at least 1.
+	Full int
+	// Daily is the number of days, today included, for which the most recent
+	// full backup of the day is kept. Days are in UTC.
+	Daily int
+	// Weekly is the number of weeks, this week included, for which the most
+	// recent full backup of the week is kept. Weeks start on Monday.
+	Weekly int
+}
+
+// ParseBackupRetentionPolicy parses a policy in the format "full=3,daily=7,weekly=4".
+// The rules that are not given default to 0, except "full" which defaults to 1.
+func ParseBackupRetentionPolicy(policy string) (*BackupRetentionPolicy, error) {
+	p := &BackupRetentionPolicy{Full: 1}
+	for _, rule := range strings.Split(policy, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid backup retention rule %q: expected <name>=<count>", rule)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("invalid backup retention rule %q: the count must be a non-negative integer", rule)
+		}
+		switch strings.TrimSpace(name) {
+		case "full":
+			p.Full = count
+		case "daily":
+			p.Daily = count
+		case "weekly":
+			p.Weekly = count
+		default:
+			return nil, fmt.Errorf("invalid backup retention rule %q: expected one of full, daily, weekly", rule)
+		}
+	}
+	if p.Full < 1 {
+		return nil, fmt.Errorf("invalid backup retention policy %q: at least one full backup must be kept", policy)
+	}
+	return p, nil
+}
+
+// String returns the policy in the format parsed by ParseBackupRetentionPolicy.
+func (p *BackupRetentionPolicy) String() string {
+	return fmt.Sprintf("full=%d,daily=%d,weekly=%d", p.Full, p.Daily, p.Weekly)
+}
+
+// retentionCandidate is a complete backup, considered for pruning.
+type retentionCandidate struct {
+	name     string
+	manifest *BackupManifest
+	time     time.Time
+}
+
+// startOfDay returns the start of the UTC day of t.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// startOfWeek returns the start of the UTC week of t, weeks starting on Monday.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// selectBackupsToPrune returns the names of the candidates that the policy doesn't retain.
+// The candidates must be sorted by time, oldest first.
+func selectBackupsToPrune(policy *BackupRetentionPolicy, candidates []retentionCandidate, now time.Time) []string {
+	retained := make(map[string]bool, len(candidates))
+	var oldestRetainedFull *retentionCandidate
+
+	today, thisWeek := startOfDay(now), startOfWeek(now)
+	fullCount := 0
+	retainedDays := make(map[time.Time]bool)
+	retainedWeeks := make(map[time.Time]bool)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		c := &candidates[i]
+		if c.manifest.Incremental {
+			continue
+		}
+		fullCount++
+		keep := fullCount <= policy.Full
+
+		// The most recent full backup of the day or week is the first one seen, going backwards.
+		day, week := startOfDay(c.time), startOfWeek(c.time)
+		if days := int(today.Sub(day) / (24 * time.Hour)); days < policy.Daily && !retainedDays[day] {
+			retainedDays[day] = true
+			keep = true
+		}
+		if weeks := int(thisWeek.Sub(week) / (7 * 24 * time.Hour)); weeks < policy.Weekly && !retainedWeeks[week] {
+			retainedWeeks[week] = true
+			keep = true
+		}
+		if keep {
+			retained[c.name] = true
+			oldestRetainedFull = c
+		}
+	}
+
+	var prune []string
+	for _, c := range candidates {
+		if retained[c.name] {
+			continue
+		}
+		if c.manifest.Incremental {
+			// Keep the incremental backups that may be applied on top of a retained full backup.
+			if oldestRetainedFull == nil || c.manifest.Position.GTIDSet == nil || oldestRetainedFull.manifest.Position.GTIDSet == nil ||
+				!oldestRetainedFull.manifest.Position.GTIDSet.Contains(c.manifest.Position.GTIDSet) {
+				continue
+			}
+		}
+		prune = append(prune, c.name)
+	}
+	return prune
+}
+
+// BackupsToPrune returns the backups that the policy doesn't retain, among the given
+// backups of a shard, oldest first. The backups are ordered by the time of their MANIFEST,
+// since their names don't always sort by time, e.g. when they are taken by several tablets.
+func BackupsToPrune(ctx context.Context, policy *BackupRetentionPolicy, bhs []backupstorage.BackupHandle, now time.Time, logger logutil.Logger) []backupstorage.BackupHandle {
+	handles := make(map[string]backupstorage.BackupHandle, len(bhs))
+	candidates := make([]retentionCandidate, 0, len(bhs))
+	for _, bh := range bhs {
+		bm, err := GetBackupManifest(ctx, bh)
+		if err != nil {
+			logger.Infof("Not pruning backup %v: can't read MANIFEST: %v", bh.Name(), err)
+			continue
+		}
+		backupTime, err := time.Parse(time.RFC3339, bm.BackupTime)
+		if err != nil {
+			logger.Warningf("Not pruning backup %v: invalid time %v: %v", bh.Name(), bm.BackupTime, err)
+			continue
+		}
+		handles[bh.Name()] = bh
+		candidates = append(candidates, retentionCandidate{name: bh.Name(), manifest: bm, time: backupTime})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].time.Before(candidates[j].time)
+	})
+
+	var prune []backupstorage.BackupHandle
+	for _, name := range selectBackupsToPrune(policy, candidates, now) {
+		prune = append(prune, handles[name])
+	}
+	return prune
+}
+
+// PruneBackups removes the backups of a shard that the policy doesn't retain, along with
+// their verifications. With dryRun, nothing is removed. It returns the names of the
+// pruned backups, or the ones that would be pruned with dryRun.
+func PruneBackups(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, policy *BackupRetentionPolicy, dryRun bool, logger logutil.Logger) ([]string, error) {
+	backupDir := GetBackupDir(keyspace, shard)
+	bhs, err := bs.ListBackups(ctx, backupDir)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't list backups of %v", backupDir)
+	}
+
+	var pruned []string
+	for _, bh := range BackupsToPrune(ctx, policy, bhs, time.Now(), logger) {
+		if dryRun {
+			logger.Infof("Would remove backup %v from %v, not retained by policy %v", bh.Name(), backupDir, policy)
+			pruned = append(pruned, bh.Name())
+			continue
+		}
+		logger.Infof("Removing backup %v from %v, not retained by policy %v", bh.Name(), backupDir, policy)
+		if err := bs.RemoveBackup(ctx, backupDir, bh.Name()); err != nil {
+			return pruned, vterrors.Wrapf(err, "can't remove backup %v from %v", bh.Name(), backupDir)
+		}
+		pruned = append(pruned, bh.Name())
+		if err := bs.RemoveBackup(ctx, GetBackupVerificationDir(keyspace, shard), bh.Name()); err != nil {
+			logger.Warningf("Can't remove the verification of backup %v: %v", bh.Name(), err)
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+func TestParseBackupRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   *BackupRetentionPolicy
+		err    string
+	}{
+		{policy: "", want: &BackupRetentionPolicy{Full: 1}},
+		{policy: "full=3,daily=7,weekly=4", want: &BackupRetentionPolicy{Full: 3, Daily: 7, Weekly: 4}},
+		{policy: " daily = 7 , weekly=4", want: &BackupRetentionPolicy{Full: 1, Daily: 7, Weekly: 4}},
+		{policy: "full=0", err: "at least one full backup must be kept"},
+		{policy: "full", err: "expected <name>=<count>"},
+		{policy: "full=-1", err: "the count must be a non-negative integer"},
+		{policy: "monthly=3", err: "expected one of full, daily, weekly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			policy, err := ParseBackupRetentionPolicy(tt.policy)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+			assert.Equal(t, tt.want.String(), policy.String())
+		})
+	}
+}
+
+func TestSelectBackupsToPrune(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+	position := func(last int) mysql.Position {
+		pos, err := mysql.DecodePosition(fmt.Sprintf("MySQL56/16b1039f-22b6-11ed-b765-0a43f95f28a3:1-%d", last))
+		require.NoError(t, err)
+		return pos
+	}
+	full := func(name string, backupTime time.Time, last int) retentionCandidate {
+		return retentionCandidate{name: name, time: backupTime, manifest: &BackupManifest{Position: position(last)}}
+	}
+	incremental := func(name string, backupTime time.Time, last int) retentionCandidate {
+		return retentionCandidate{name: name, time: backupTime, manifest: &BackupManifest{Position: position(last), Incremental: true}}
+	}
+	day := func(days int, hour int) time.Time {
+		return time.Date(2023, 3, 15-days, hour, 0, 0, 0, time.UTC)
+	}
+	candidates := []retentionCandidate{
+		full("week-3", day(21, 1), 100),         // Wednesday, 3 weeks ago
+		full("week-2", day(14, 1), 200),         // Wednesday, 2 weeks ago
+		full("week-1-monday", day(9, 1), 250),   // Monday, last week
+		full("week-1-tuesday", day(8, 1), 280),  // Tuesday, last week
+		incremental("inc-280", day(8, 2), 290),  // on top of week-1-tuesday
+		full("day-2-morning", day(2, 1), 300),   // Monday
+		full("day-2-evening", day(2, 20), 310),  // Monday
+		incremental("inc-310", day(2, 21), 320), // on top of day-2-evening
+		full("day-1", day(1, 1), 400),
+		incremental("inc-400", day(1, 2), 410),
+		full("today", day(0, 1), 500),
+		incremental("inc-500", day(0, 2), 510),
+	}
+
+	tests := []struct {
+		policy *BackupRetentionPolicy
+		want   []string
+	}{{
+		// the incremental backups before the oldest retained full backup are pruned
+		policy: &BackupRetentionPolicy{Full: 1},
+		want:   []string{"week-3", "week-2", "week-1-monday", "week-1-tuesday", "inc-280", "day-2-morning", "day-2-evening", "inc-310", "day-1", "inc-400"},
+	}, {
+		// the incremental backups after the oldest retained full backup are kept
+		policy: &BackupRetentionPolicy{Full: 3},
+		want:   []string{"week-3", "week-2", "week-1-monday", "week-1-tuesday", "inc-280", "day-2-morning"},
+	}, {
+		// the most recent full backup of each of the last 3 days
+		policy: &BackupRetentionPolicy{Full: 1, Daily: 3},
+		want:   []string{"week-3", "week-2", "week-1-monday", "week-1-tuesday", "inc-280", "day-2-morning"},
+	}, {
+		// the most recent full backup of this week and the last 2 weeks
+		policy: &BackupRetentionPolicy{Full: 1, Weekly: 3},
+		want:   []string{"week-3", "week-1-monday", "day-2-morning", "day-2-evening", "day-1"},
+	}, {
+		policy: &BackupRetentionPolicy{Full: 2, Daily: 2, Weekly: 4},
+		want:   []string{"week-1-monday", "day-2-morning", "day-2-evening"},
+	}, {
+		policy: &BackupRetentionPolicy{Full: 20},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, selectBackupsToPrune(tt.policy, candidates, now))
+		})
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	defer func(root string) {
+		filebackupstorage.FileBackupStorageRoot = root
+	}(filebackupstorage.FileBackupStorageRoot)
+	filebackupstorage.FileBackupStorageRoot = t.TempDir()
+
+	ctx := context.Background()
+	bs := backupstorage.BackupStorageMap["file"]
+	backupDir := GetBackupDir("ks", "0")
+	now := time.Now().UTC()
+	addBackup := func(name string, manifest *BackupManifest) {
+		bh, err := bs.StartBackup(ctx, backupDir, name)
+		require.NoError(t, err)
+		if manifest != nil {
+			wc, err := bh.AddFile(ctx, backupManifestFileName, backupstorage.FileSizeUnknown)
+			require.NoError(t, err)
+			require.NoError(t, json.NewEncoder(wc).Encode(manifest))
+			require.NoError(t, wc.Close())
+		}
+		require.NoError(t, bh.EndBackup(ctx))
+	}
+	for i := 3; i >= 1; i-- {
+		addBackup(fmt.Sprintf("backup%d", 4-i), &BackupManifest{BackupTime: now.Add(-time.Duration(i) * time.Hour).Format(time.RFC3339)})
+	}
+	// in progress
+	addBackup("backup4", nil)
+	require.NoError(t, SaveBackupVerification(ctx, bs, "ks", "0", &BackupVerification{BackupName: "backup1", Success: true}))
+
+	policy := &BackupRetentionPolicy{Full: 1}
+	pruned, err := PruneBackups(ctx, bs, "ks", "0", policy, true, logutil.NewMemoryLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup1", "backup2"}, pruned)
+	bhs, err := bs.ListBackups(ctx, backupDir)
+	require.NoError(t, err)
+	assert.Len(t, bhs, 4)
+
+	pruned, err = PruneBackups(ctx, bs, "ks", "0", policy, false, logutil.NewMemoryLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup1", "backup2"}, pruned)
+	bhs, err = bs.ListBackups(ctx, backupDir)
+	require.NoError(t, err)
+	var names []string
+	for _, bh := range bhs {
+		names = append(names, bh.Name())
+	}
+	assert.Equal(t, []string{"backup3", "backup4"}, names)
+	verifications, err := GetBackupVerifications(ctx, bs, "ks", "0")
+	require.NoError(t, err)
+	assert.Empty(t, verifications)
+}
+
+func TestBackupsToPruneOrdersByTime(t *testing.T) {
+	defer func(root string) {
+		filebackupstorage.FileBackupStorageRoot = root
+	}(filebackupstorage.FileBackupStorageRoot)
+	filebackupstorage.FileBackupStorageRoot = t.TempDir()
+
+	ctx := context.Background()
+	bs := backupstorage.BackupStorageMap["file"]
+	backupDir := GetBackupDir("ks", "0")
+	now := time.Now().UTC()
+	// the names sort the other way around from the times, like backups of the same second by different tablets
+	for name, age := range map[string]time.Duration{"backup-zone1-101": 3 * time.Hour, "backup-zone1-102": 2 * time.Hour, "backup-zone1-100": time.Hour} {
+		bh, err := bs.StartBackup(ctx, backupDir, name)
+		require.NoError(t, err)
+		wc, err := bh.AddFile(ctx, backupManifestFileName, backupstorage.FileSizeUnknown)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(wc).Encode(&BackupManifest{BackupTime: now.Add(-age).Format(time.RFC3339)}))
+		require.NoError(t, wc.Close())
+		require.NoError(t, bh.EndBackup(ctx))
+	}
+	bhs, err := bs.ListBackups(ctx, backupDir)
+	require.NoError(t, err)
+	require.Equal(t, "backup-zone1-100", bhs[0].Name())
+
+	var pruned []string
+	for _, bh := range BackupsToPrune(ctx, &BackupRetentionPolicy{Full: 1}, bhs, now, logutil.NewMemoryLogger()) {
+		pruned = append(pruned, bh.Name())
+	}
+	assert.Equal(t, []string{"backup-zone1-101", "backup-zone1-102"}, pruned)
+}
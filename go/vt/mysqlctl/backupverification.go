@@ -0,0 +1,314 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sidecardb"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// backupVerificationFileName is the file holding the result of the verification of a backup.
+	backupVerificationFileName = "VERIFICATION"
+)
+
+var (
+	// backupChecksumSampleSize is the number of tables whose row count and checksum are recorded in the MANIFEST.
+	backupChecksumSampleSize = 0
+
+	// userTablesQuery lists the tables of the databases that are restored as they were backed up.
+	userTablesQuery = fmt.Sprintf(`SELECT table_schema, table_name FROM information_schema.tables
+WHERE table_type = 'BASE TABLE' AND table_schema NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys', '%s')
+ORDER BY table_schema, table_name`, sidecardb.SidecarDBName)
+)
+
+func init() {
+	for _, cmd := range []string{"vtbackup", "vtcombo", "vttablet", "vttestserver"} {
+		servenv.OnParseFor(cmd, registerBackupVerificationFlags)
+	}
+}
+
+func registerBackupVerificationFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&backupChecksumSampleSize, "backup-checksum-sample-size", backupChecksumSampleSize, "number of tables, picked at random, whose row count and checksum are recorded in the MANIFEST of the builtin backups, for the backup verification to compare them after restoring the backup. 0 disables the sampling.")
+}
+
+// TableChecksum is the row count and checksum of a table at the backup position.
+type TableChecksum struct {
+	Database string
+	Table    string
+	Rows     int64
+	// Checksum is the result of CHECKSUM TABLE. It is only comparable between
+	// servers of the same version, storing the rows in the same format.
+	Checksum string
+}
+
+// BackupVerification is the result of the verification of a backup. It is recorded
+// in the backup storage, under the directory returned by GetBackupVerificationDir.
+type BackupVerification struct {
+	// BackupName is the name of the verified backup.
+	BackupName string
+
+	// VerifiedTime is the time (in RFC 3339 format, UTC) of the verification.
+	VerifiedTime string
+
+	// Success is true if the backup was restored and no problem was found.
+	Success bool
+
+	// Problems lists the problems found while restoring and checking the backup.
+	Problems []string `json:",omitempty"`
+
+	// CheckedTables is the number of tables that passed CHECK TABLE.
+	CheckedTables int
+
+	// ComparedChecksums is the number of tables whose row count and checksum
+	// matched the ones recorded in the MANIFEST.
+	ComparedChecksums int
+}
+
+// NewBackupVerification returns a verification of the given backup, without any check done yet.
+func NewBackupVerification(backupName string) *BackupVerification {
+	return &BackupVerification{
+		BackupName:   backupName,
+		VerifiedTime: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// GetBackupVerificationDir returns the directory in which the verifications of
+// the backups of a shard are recorded. It's next to the backup directory rather
+// than under it, so that the verifications are not listed as backups.
+func GetBackupVerificationDir(keyspace, shard string) string {
+	return fmt.Sprintf("%v.verifications", GetBackupDir(keyspace, shard))
+}
+
+// listUserTables returns the tables of the databases that are restored as they were backed up.
+func listUserTables(ctx context.Context, mysqld MysqlDaemon) ([][2]string, error) {
+	qr, err := mysqld.FetchSuperQuery(ctx, userTablesQuery)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't list tables")
+	}
+	tables := make([][2]string, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		tables = append(tables, [2]string{row[0].ToString(), row[1].ToString()})
+	}
+	return tables, nil
+}
+
+// tableChecksum returns the row count and checksum of a table.
+func tableChecksum(ctx context.Context, mysqld MysqlDaemon, database, table string) (*TableChecksum, error) {
+	name := sqlescape.EscapeID(database) + "." + sqlescape.EscapeID(table)
+	qr, err := mysqld.FetchSuperQuery(ctx, "SELECT COUNT(*) FROM "+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 {
+		return nil, fmt.Errorf("unexpected result for the row count of %v: %v", name, qr.Rows)
+	}
+	rows, err := qr.Rows[0][0].ToInt64()
+	if err != nil {
+		return nil, err
+	}
+	qr, err = mysqld.FetchSuperQuery(ctx, "CHECKSUM TABLE "+name)
+	if err != nil {
+		return nil, err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 2 {
+		return nil, fmt.Errorf("unexpected result for the checksum of %v: %v", name, qr.Rows)
+	}
+	return &TableChecksum{
+		Database: database,
+		Table:    table,
+		Rows:     rows,
+		Checksum: qr.Rows[0][1].ToString(),
+	}, nil
+}
+
+// sampleTableChecksums returns the row count and checksum of up to sampleSize tables,
+// picked at random. The data must not change while they are computed.
+func sampleTableChecksums(ctx context.Context, mysqld MysqlDaemon, sampleSize int) ([]TableChecksum, error) {
+	tables, err := listUserTables(ctx, mysqld)
+	if err != nil {
+		return nil, err
+	}
+	rand.Shuffle(len(tables), func(i, j int) { tables[i], tables[j] = tables[j], tables[i] })
+	if len(tables) > sampleSize {
+		tables = tables[:sampleSize]
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i][0] != tables[j][0] {
+			return tables[i][0] < tables[j][0]
+		}
+		return tables[i][1] < tables[j][1]
+	})
+
+	checksums := make([]TableChecksum, 0, len(tables))
+	for _, table := range tables {
+		checksum, err := tableChecksum(ctx, mysqld, table[0], table[1])
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "can't compute the checksum of %v.%v", table[0], table[1])
+		}
+		checksums = append(checksums, *checksum)
+	}
+	return checksums, nil
+}
+
+// CheckRestoredBackup checks the data restored from a backup: it runs CHECK TABLE
+// on all the tables, and compares the row counts and checksums sampled at backup
+// time. The problems found are added to the verification. An error is only
+// returned if the checks couldn't be run.
+func CheckRestoredBackup(ctx context.Context, mysqld MysqlDaemon, manifest *BackupManifest, verification *BackupVerification, logger logutil.Logger) error {
+	tables, err := listUserTables(ctx, mysqld)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Checking %v tables", len(tables))
+	for _, table := range tables {
+		name := sqlescape.EscapeID(table[0]) + "." + sqlescape.EscapeID(table[1])
+		qr, err := mysqld.FetchSuperQuery(ctx, "CHECK TABLE "+name)
+		if err != nil {
+			verification.Problems = append(verification.Problems, fmt.Sprintf("CHECK TABLE %v failed: %v", name, err))
+			continue
+		}
+		if problem := checkTableProblem(qr.Rows); problem != "" {
+			verification.Problems = append(verification.Problems, fmt.Sprintf("CHECK TABLE %v: %v", name, problem))
+			continue
+		}
+		verification.CheckedTables++
+	}
+
+	logger.Infof("Comparing %v table checksums", len(manifest.TableChecksums))
+	for _, want := range manifest.TableChecksums {
+		got, err := tableChecksum(ctx, mysqld, want.Database, want.Table)
+		switch {
+		case err != nil:
+			verification.Problems = append(verification.Problems, fmt.Sprintf("can't compute the checksum of %v.%v: %v", want.Database, want.Table, err))
+		case got.Rows != want.Rows:
+			verification.Problems = append(verification.Problems, fmt.Sprintf("table %v.%v has %v rows, expected %v", want.Database, want.Table, got.Rows, want.Rows))
+		case got.Checksum != want.Checksum:
+			verification.Problems = append(verification.Problems, fmt.Sprintf("table %v.%v has checksum %v, expected %v", want.Database, want.Table, got.Checksum, want.Checksum))
+		default:
+			verification.ComparedChecksums++
+		}
+	}
+
+	verification.Success = len(verification.Problems) == 0
+	return nil
+}
+
+// checkTableProblem returns the problem reported by CHECK TABLE, if any. Its result has
+// the columns Table, Op, Msg_type and Msg_text, and ends with the status of the table.
+func checkTableProblem(rows [][]sqltypes.Value) string {
+	var messages []string
+	status := ""
+	for _, row := range rows {
+		if len(row) != 4 {
+			return fmt.Sprintf("unexpected result: %v", rows)
+		}
+		msgType, msgText := strings.ToLower(row[2].ToString()), row[3].ToString()
+		switch msgType {
+		case "status":
+			status = msgText
+		case "error":
+			messages = append(messages, msgText)
+		}
+	}
+	if status != "OK" && status != "Table is already up to date" {
+		messages = append(messages, fmt.Sprintf("status %q", status))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// SaveBackupVerification records the result of the verification of a backup,
+// replacing the previous one if any.
+func SaveBackupVerification(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string, verification *BackupVerification) (finalErr error) {
+	dir := GetBackupVerificationDir(keyspace, shard)
+	if err := bs.RemoveBackup(ctx, dir, verification.BackupName); err != nil {
+		return vterrors.Wrapf(err, "can't remove the previous verification of %v", verification.BackupName)
+	}
+	bh, err := bs.StartBackup(ctx, dir, verification.BackupName)
+	if err != nil {
+		return vterrors.Wrapf(err, "can't record the verification of %v", verification.BackupName)
+	}
+	defer func() {
+		if finalErr != nil {
+			bh.AbortBackup(ctx)
+		}
+	}()
+
+	data, err := json.MarshalIndent(verification, "", "  ")
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot JSON encode %v", backupVerificationFileName)
+	}
+	wc, err := bh.AddFile(ctx, backupVerificationFileName, int64(len(data)))
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot add %v to backup verification", backupVerificationFileName)
+	}
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return vterrors.Wrapf(err, "cannot write %v", backupVerificationFileName)
+	}
+	if err := wc.Close(); err != nil {
+		return vterrors.Wrapf(err, "cannot write %v", backupVerificationFileName)
+	}
+	return bh.EndBackup(ctx)
+}
+
+// GetBackupVerifications returns the recorded verifications of the backups of a shard, by backup name.
+func GetBackupVerifications(ctx context.Context, bs backupstorage.BackupStorage, keyspace, shard string) (map[string]*BackupVerification, error) {
+	bhs, err := bs.ListBackups(ctx, GetBackupVerificationDir(keyspace, shard))
+	if err != nil {
+		return nil, vterrors.Wrap(err, "can't list backup verifications")
+	}
+	verifications := make(map[string]*BackupVerification, len(bhs))
+	for _, bh := range bhs {
+		verification, err := getBackupVerification(ctx, bh)
+		if err != nil {
+			// the verification may be in progress
+			continue
+		}
+		verifications[bh.Name()] = verification
+	}
+	return verifications, nil
+}
+
+func getBackupVerification(ctx context.Context, bh backupstorage.BackupHandle) (*BackupVerification, error) {
+	file, err := bh.ReadFile(ctx, backupVerificationFileName)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "can't read %v", backupVerificationFileName)
+	}
+	defer file.Close()
+
+	verification := &BackupVerification{}
+	if err := json.NewDecoder(file).Decode(verification); err != nil {
+		return nil, vterrors.Wrapf(err, "can't decode %v", backupVerificationFileName)
+	}
+	return verification, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+)
+
+func TestSampleTableChecksums(t *testing.T) {
+	ctx := context.Background()
+	mysqld := NewFakeMysqlDaemon(nil)
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		userTablesQuery: sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_schema|table_name", "varchar|varchar"),
+			"vt_ks|t1", "vt_ks|t2", "vt_ks|t3"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t1`": sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "10"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t2`": sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "20"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t3`": sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "30"),
+		"CHECKSUM TABLE `vt_ks`.`t1`":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Checksum", "varchar|int64"), "vt_ks.t1|1"),
+		"CHECKSUM TABLE `vt_ks`.`t2`":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Checksum", "varchar|int64"), "vt_ks.t2|2"),
+		"CHECKSUM TABLE `vt_ks`.`t3`":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Checksum", "varchar|int64"), "vt_ks.t3|3"),
+	}
+
+	checksums, err := sampleTableChecksums(ctx, mysqld, 2)
+	require.NoError(t, err)
+	require.Len(t, checksums, 2)
+	assert.Less(t, checksums[0].Table, checksums[1].Table)
+	for _, checksum := range checksums {
+		switch checksum.Table {
+		case "t1":
+			assert.Equal(t, TableChecksum{Database: "vt_ks", Table: "t1", Rows: 10, Checksum: "1"}, checksum)
+		case "t2":
+			assert.Equal(t, TableChecksum{Database: "vt_ks", Table: "t2", Rows: 20, Checksum: "2"}, checksum)
+		case "t3":
+			assert.Equal(t, TableChecksum{Database: "vt_ks", Table: "t3", Rows: 30, Checksum: "3"}, checksum)
+		}
+	}
+
+	checksums, err = sampleTableChecksums(ctx, mysqld, 5)
+	require.NoError(t, err)
+	assert.Len(t, checksums, 3)
+}
+
+func TestCheckRestoredBackup(t *testing.T) {
+	ctx := context.Background()
+	checkFields := sqltypes.MakeTestFields("Table|Op|Msg_type|Msg_text", "varchar|varchar|varchar|varchar")
+	mysqld := NewFakeMysqlDaemon(nil)
+	mysqld.FetchSuperQueryMap = map[string]*sqltypes.Result{
+		userTablesQuery: sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_schema|table_name", "varchar|varchar"),
+			"vt_ks|t1", "vt_ks|t2", "vt_ks|t3"),
+		"CHECK TABLE `vt_ks`.`t1`":          sqltypes.MakeTestResult(checkFields, "vt_ks.t1|check|status|OK"),
+		"CHECK TABLE `vt_ks`.`t2`":          sqltypes.MakeTestResult(checkFields, "vt_ks.t2|check|status|OK"),
+		"CHECK TABLE `vt_ks`.`t3`":          sqltypes.MakeTestResult(checkFields, "vt_ks.t3|check|status|OK"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t1`": sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "10"),
+		"SELECT COUNT(*) FROM `vt_ks`.`t3`": sqltypes.MakeTestResult(sqltypes.MakeTestFields("count(*)", "int64"), "30"),
+		"CHECKSUM TABLE `vt_ks`.`t1`":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Checksum", "varchar|int64"), "vt_ks.t1|1"),
+		"CHECKSUM TABLE `vt_ks`.`t3`":       sqltypes.MakeTestResult(sqltypes.MakeTestFields("Table|Checksum", "varchar|int64"), "vt_ks.t3|3"),
+	}
+
+	// all good
+	verification := NewBackupVerification("backup")
+	manifest := &BackupManifest{TableChecksums: []TableChecksum{{Database: "vt_ks", Table: "t1", Rows: 10, Checksum: "1"}}}
+	require.NoError(t, CheckRestoredBackup(ctx, mysqld, manifest, verification, logutil.NewMemoryLogger()))
+	assert.True(t, verification.Success)
+	assert.Empty(t, verification.Problems)
+	assert.Equal(t, 3, verification.CheckedTables)
+	assert.Equal(t, 1, verification.ComparedChecksums)
+
+	// a corrupt table, and differences with the MANIFEST
+	mysqld.FetchSuperQueryMap["CHECK TABLE `vt_ks`.`t2`"] = sqltypes.MakeTestResult(checkFields,
+		"vt_ks.t2|check|warning|Table is marked as crashed", "vt_ks.t2|check|error|Corrupt", "vt_ks.t2|check|status|Operation failed")
+	verification = NewBackupVerification("backup")
+	manifest.TableChecksums = []TableChecksum{
+		{Database: "vt_ks", Table: "t1", Rows: 11, Checksum: "1"},
+		{Database: "vt_ks", Table: "t3", Rows: 30, Checksum: "4"},
+		{Database: "vt_ks", Table: "t4", Rows: 40, Checksum: "4"},
+	}
+	require.NoError(t, CheckRestoredBackup(ctx, mysqld, manifest, verification, logutil.NewMemoryLogger()))
+	assert.False(t, verification.Success)
+	assert.Equal(t, 2, verification.CheckedTables)
+	assert.Equal(t, 0, verification.ComparedChecksums)
+	require.Len(t, verification.Problems, 4)
+	assert.Equal(t, "CHECK TABLE `vt_ks`.`t2`: Corrupt; status \"Operation failed\"", verification.Problems[0])
+	assert.Equal(t, "table vt_ks.t1 has 10 rows, expected 11", verification.Problems[1])
+	assert.Equal(t, "table vt_ks.t3 has checksum 3, expected 4", verification.Problems[2])
+	assert.Contains(t, verification.Problems[3], "can't compute the checksum of vt_ks.t4")
+
+	// the checks can't be run
+	delete(mysqld.FetchSuperQueryMap, userTablesQuery)
+	assert.Error(t, CheckRestoredBackup(ctx, mysqld, manifest, NewBackupVerification("backup"), logutil.NewMemoryLogger()))
+}
+
+func TestSaveBackupVerification(t *testing.T) {
+	defer func(root string) {
+		filebackupstorage.FileBackupStorageRoot = root
+	}(filebackupstorage.FileBackupStorageRoot)
+	filebackupstorage.FileBackupStorageRoot = t.TempDir()
+
+	ctx := context.Background()
+	bs := backupstorage.BackupStorageMap["file"]
+
+	verifications, err := GetBackupVerifications(ctx, bs, "ks", "0")
+	require.NoError(t, err)
+	assert.Empty(t, verifications)
+
+	failed := &BackupVerification{BackupName: "backup1", VerifiedTime: "2023-01-01T00:00:00Z", Problems: []string{"can't restore"}}
+	require.NoError(t, SaveBackupVerification(ctx, bs, "ks", "0", failed))
+	succeeded := &BackupVerification{BackupName: "backup2", VerifiedTime: "2023-01-01T00:00:00Z", Success: true, CheckedTables: 3}
+	require.NoError(t, SaveBackupVerification(ctx, bs, "ks", "0", succeeded))
+
+	verifications, err = GetBackupVerifications(ctx, bs, "ks", "0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*BackupVerification{"backup1": failed, "backup2": succeeded}, verifications)
+
+	// a new verification replaces the previous one
+	reverified := &BackupVerification{BackupName: "backup1", VerifiedTime: "2023-01-02T00:00:00Z", Success: true}
+	require.NoError(t, SaveBackupVerification(ctx, bs, "ks", "0", reverified))
+	verifications, err = GetBackupVerifications(ctx, bs, "ks", "0")
+	require.NoError(t, err)
+	assert.Equal(t, reverified, verifications["backup1"])
+
+	// the verifications are not listed as backups
+	bhs, err := bs.ListBackups(ctx, GetBackupDir("ks", "0"))
+	require.NoError(t, err)
+	assert.Empty(t, bhs)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
@@ -235,3 +236,103 @@ func FindPITRPath(restoreToGTIDSet mysql.GTIDSet, manifests [](*BackupManifest))
 	}
 	return shortestPath, nil
 }
+
+// backupFinishedTime returns the time at which the backup finished, or the time at which it was taken
+// for backups created before FinishedTime was added to the manifest. A full backup does not contain
+// transactions committed after that time.
+func backupFinishedTime(manifest *BackupManifest) (time.Time, error) {
+	if manifest.FinishedTime != "" {
+		return time.Parse(time.RFC3339, manifest.FinishedTime)
+	}
+	return time.Parse(time.RFC3339, manifest.BackupTime)
+}
+
+// incrementalBackupTimestamps returns the times of the first and last transactions of an incremental backup.
+func incrementalBackupTimestamps(manifest *BackupManifest) (first time.Time, last time.Time, err error) {
+	if manifest.IncrementalDetails == nil {
+		return first, last, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "incremental backup %v...%v has no timestamps", manifest.FromPosition, manifest.Position)
+	}
+	if first, err = time.Parse(time.RFC3339, manifest.IncrementalDetails.FirstTimestamp); err != nil {
+		return first, last, vterrors.Wrapf(err, "cannot parse first timestamp of incremental backup %v...%v", manifest.FromPosition, manifest.Position)
+	}
+	if last, err = time.Parse(time.RFC3339, manifest.IncrementalDetails.LastTimestamp); err != nil {
+		return first, last, vterrors.Wrapf(err, "cannot parse last timestamp of incremental backup %v...%v", manifest.FromPosition, manifest.Position)
+	}
+	return first, last, nil
+}
+
+// FindPITRToTimePath evaluates a path to recover to restoreToTime, i.e. up to the last transaction at or before
+// that time. The path is composed of:
+// - the most recent full backup that finished at or before restoreToTime, followed by:
+// - zero or more incremental backups
+// The path is known to cover restoreToTime once it reaches a transaction past that time: either in its last
+// incremental backup, or in the incremental backup that picks up where the path ends. The binary log timestamps
+// have a one second precision, so the transaction has to be in a later second. Incremental backups without
+// transactions are stepped over.
+// The function returns an error when a path cannot be found.
+func FindPITRToTimePath(restoreToTime time.Time, manifests [](*BackupManifest)) (path [](*BackupManifest), err error) {
+	restoreToTime = restoreToTime.Truncate(time.Second)
+	sortedManifests := make([](*BackupManifest), 0, len(manifests))
+	for _, m := range manifests {
+		if m != nil {
+			sortedManifests = append(sortedManifests, m)
+		}
+	}
+	sort.SliceStable(sortedManifests, func(i, j int) bool {
+		return sortedManifests[j].Position.GTIDSet.Union(sortedManifests[i].PurgedPosition.GTIDSet).Contains(sortedManifests[i].Position.GTIDSet)
+	})
+	mostRelevantFullBackupIndex := -1 // an invalid value
+	for i, manifest := range sortedManifests {
+		if manifest.Incremental {
+			continue
+		}
+		finishedTime, err := backupFinishedTime(manifest)
+		if err != nil {
+			continue
+		}
+		if !finishedTime.After(restoreToTime) {
+			// This backup is <= desired restore point, therefore it's valid
+			mostRelevantFullBackupIndex = i
+		}
+	}
+	if mostRelevantFullBackupIndex < 0 {
+		// No full backup prior to desired restore point...
+		return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no full backup found before timestamp %v", restoreToTime.Format(time.RFC3339))
+	}
+	// All that interests us starts with mostRelevantFullBackupIndex: that's where the full backup is,
+	// and any relevant incremental backups follow that point (because manifests are sorted by backup pos, ascending)
+	sortedManifests = sortedManifests[mostRelevantFullBackupIndex:]
+	fullBackup := sortedManifests[0]
+	purgedGTIDSet := fullBackup.PurgedPosition.GTIDSet
+
+	path = append(path, fullBackup)
+	baseGTIDSet := fullBackup.Position.GTIDSet
+	for _, manifest := range sortedManifests[1:] {
+		if !IsValidIncrementalBakcup(baseGTIDSet, purgedGTIDSet, manifest) {
+			continue
+		}
+		if manifest.IncrementalDetails != nil && manifest.IncrementalDetails.FirstTimestamp == "" {
+			// The binary logs of this backup have no transactions: there is no timestamp to compare with,
+			// but the path goes through it.
+			path = append(path, manifest)
+			baseGTIDSet = baseGTIDSet.Union(manifest.Position.GTIDSet)
+			continue
+		}
+		firstTimestamp, lastTimestamp, err := incrementalBackupTimestamps(manifest)
+		if err != nil {
+			return nil, err
+		}
+		if firstTimestamp.After(restoreToTime) {
+			// This backup picks up where the path ends, and all of it is past the desired restore point:
+			// the path already has all the transactions at or before restoreToTime.
+			return path, nil
+		}
+		path = append(path, manifest)
+		baseGTIDSet = baseGTIDSet.Union(manifest.Position.GTIDSet)
+		if lastTimestamp.After(restoreToTime) {
+			// This backup goes past the desired restore point, we're done.
+			return path, nil
+		}
+	}
+	return nil, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no path found that leads to timestamp %v", restoreToTime.Format(time.RFC3339))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -461,3 +462,190 @@ func TestFindPITRPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPITRToTimePath(t *testing.T) {
+	generatePosition := func(lastGTID int) mysql.Position {
+		return mysql.MustParsePosition(mysql.Mysql56FlavorID, fmt.Sprintf("16b1039f-22b6-11ed-b765-0a43f95f28a3:1-%d", lastGTID))
+	}
+	// transaction n is committed on minute n
+	baseTime := time.Date(2023, time.June, 5, 14, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time {
+		return baseTime.Add(time.Duration(minute) * time.Minute)
+	}
+	fullManifest := func(backupPos int) *BackupManifest {
+		return &BackupManifest{
+			Position:     generatePosition(backupPos),
+			BackupTime:   at(backupPos).Format(time.RFC3339),
+			FinishedTime: at(backupPos).Format(time.RFC3339),
+		}
+	}
+	incrementalManifest := func(backupPos int, backupFromPos int) *BackupManifest {
+		return &BackupManifest{
+			Position:     generatePosition(backupPos),
+			FromPosition: generatePosition(backupFromPos),
+			Incremental:  true,
+			IncrementalDetails: &IncrementalBackupDetails{
+				FirstTimestamp: at(backupFromPos + 1).Format(time.RFC3339),
+				LastTimestamp:  at(backupPos).Format(time.RFC3339),
+			},
+		}
+	}
+	emptyIncrementalManifest := func(backupPos int, backupFromPos int) *BackupManifest {
+		return &BackupManifest{
+			Position:           generatePosition(backupPos),
+			FromPosition:       generatePosition(backupFromPos),
+			Incremental:        true,
+			IncrementalDetails: &IncrementalBackupDetails{},
+		}
+	}
+	fullBackups := []*BackupManifest{
+		fullManifest(50),
+		fullManifest(5),
+		fullManifest(80),
+		fullManifest(70),
+	}
+	incrementalBackups := []*BackupManifest{
+		incrementalManifest(34, 5),
+		incrementalManifest(38, 34),
+		incrementalManifest(52, 35),
+		incrementalManifest(60, 50),
+		incrementalManifest(70, 60),
+		incrementalManifest(82, 70),
+		incrementalManifest(92, 79),
+		incrementalManifest(95, 89),
+	}
+	tt := []struct {
+		name                       string
+		restoreToTime              time.Time
+		incrementalBackups         []*BackupManifest
+		expectFullManifest         *BackupManifest
+		expectIncrementalManifests []*BackupManifest
+		expectError                string
+	}{
+		{
+			name:               "58",
+			restoreToTime:      at(58),
+			expectFullManifest: fullManifest(50),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(52, 35),
+				incrementalManifest(60, 50),
+			},
+		},
+		{
+			name:               "58 and a half",
+			restoreToTime:      at(58).Add(30 * time.Second),
+			expectFullManifest: fullManifest(50),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(52, 35),
+				incrementalManifest(60, 50),
+			},
+		},
+		{
+			name:               "exact full backup time",
+			restoreToTime:      at(50),
+			expectFullManifest: fullManifest(50),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(52, 35),
+			},
+		},
+		{
+			name:               "28",
+			restoreToTime:      at(28),
+			expectFullManifest: fullManifest(5),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(34, 5),
+			},
+		},
+		{
+			name:               "88",
+			restoreToTime:      at(88),
+			expectFullManifest: fullManifest(80),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(82, 70),
+				incrementalManifest(92, 79),
+			},
+		},
+		{
+			name:          "next incremental backup is past the time",
+			restoreToTime: at(80).Add(30 * time.Second),
+			incrementalBackups: []*BackupManifest{
+				incrementalManifest(90, 80),
+			},
+			expectFullManifest: fullManifest(80),
+		},
+		{
+			name:          "step over incremental backup without transactions",
+			restoreToTime: at(88),
+			incrementalBackups: []*BackupManifest{
+				incrementalManifest(82, 70),
+				emptyIncrementalManifest(84, 82),
+				incrementalManifest(92, 84),
+			},
+			expectFullManifest: fullManifest(80),
+			expectIncrementalManifests: []*BackupManifest{
+				incrementalManifest(82, 70),
+				emptyIncrementalManifest(84, 82),
+				incrementalManifest(92, 84),
+			},
+		},
+		{
+			name:          "fail 2",
+			restoreToTime: at(2),
+			expectError:   "no full backup",
+		},
+		{
+			name:          "fail 95",
+			restoreToTime: at(95),
+			expectError:   "no path found",
+		},
+		{
+			name:          "fail 88 with gaps",
+			restoreToTime: at(88),
+			incrementalBackups: []*BackupManifest{
+				incrementalManifest(82, 70),
+				incrementalManifest(92, 84),
+			},
+			expectError: "no path found",
+		},
+		{
+			name:          "fail without timestamps",
+			restoreToTime: at(88),
+			incrementalBackups: []*BackupManifest{
+				{
+					Position:     generatePosition(92),
+					FromPosition: generatePosition(79),
+					Incremental:  true,
+				},
+			},
+			expectError: "has no timestamps",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.incrementalBackups == nil {
+				tc.incrementalBackups = incrementalBackups
+			}
+			var manifests []*BackupManifest
+			manifests = append(manifests, fullBackups...)
+			manifests = append(manifests, tc.incrementalBackups...)
+
+			path, err := FindPITRToTimePath(tc.restoreToTime, manifests)
+			if tc.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectError)
+				return
+			}
+			require.NoErrorf(t, err, "%v", err)
+			require.NotEmpty(t, path)
+			fullBackup := path[0]
+			require.False(t, fullBackup.Incremental)
+			assert.Equal(t, tc.expectFullManifest.Position.GTIDSet, fullBackup.Position.GTIDSet)
+			if tc.expectIncrementalManifests == nil {
+				tc.expectIncrementalManifests = []*BackupManifest{}
+			}
+			expected := BackupManifestPath(tc.expectIncrementalManifests)
+			got := BackupManifestPath(path[1:])
+			assert.Equal(t, expected, got, "expected: %s, got: %s", expected.String(), got.String())
+		})
+	}
+}
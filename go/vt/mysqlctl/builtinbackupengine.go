@@ -103,6 +103,10 @@ type builtinBackupManifest struct {
 	// false for backups that were created before the field existed, and those
 	// backups all had compression enabled.
 	SkipCompress bool
+
+	// EncryptionKeyProvider is the name of the key provider that encrypted the
+	// data keys of the files. It is empty if the backup files were not encrypted.
+	EncryptionKeyProvider string `json:",omitempty"`
 }
 
 // FileEntry is one file to backup
@@ -124,6 +128,9 @@ type FileEntry struct {
 	// ParentPath is an optional prefix to the Base path. If empty, it is ignored. Useful
 	// for writing files in a temporary directory
 	ParentPath string
+
+	// Encryption holds the encrypted data key of the file, if the backup is encrypted.
+	Encryption *FileEncryption `json:",omitempty"`
 }
 
 func init() {
@@ -301,7 +308,15 @@ func (be *BuiltinBackupEngine) executeIncrementalBackup(ctx context.Context, par
 	// incrementalBackupFromGTID is the "previous GTIDs" of the first binlog file we back up.
 	// It is a fact that incrementalBackupFromGTID is earlier or equal to params.IncrementalFromPos.
 	// In the backup manifest file, we document incrementalBackupFromGTID, not the user's requested position.
-	if err := be.backupFiles(ctx, params, bh, incrementalBackupToPosition, mysql.Position{}, incrementalBackupFromPosition, binaryLogsToBackup, serverUUID); err != nil {
+	// The timestamps of the transactions make it possible to restore the backup to a point in time.
+	var incrementalDetails *IncrementalBackupDetails
+	if mysqld, ok := params.Mysqld.(*Mysqld); ok {
+		incrementalDetails, err = mysqld.readBinlogFilesTimestamps(ctx, filepath.Dir(params.Cnf.BinLogPath), binaryLogsToBackup)
+		if err != nil {
+			return false, vterrors.Wrapf(err, "cannot read the timestamps of binary logs in incremental backup")
+		}
+	}
+	if err := be.backupFiles(ctx, params, bh, incrementalBackupToPosition, mysql.Position{}, incrementalBackupFromPosition, binaryLogsToBackup, incrementalDetails, nil, serverUUID); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -380,6 +395,16 @@ func (be *BuiltinBackupEngine) executeFullBackup(ctx context.Context, params Bac
 		return false, vterrors.Wrap(err, "can't get server uuid")
 	}
 
+	// Sample the table checksums while the data doesn't change.
+	var tableChecksums []TableChecksum
+	if backupChecksumSampleSize > 0 {
+		params.Logger.Infof("computing the checksums of %v tables", backupChecksumSampleSize)
+		if tableChecksums, err = sampleTableChecksums(ctx, params.Mysqld, backupChecksumSampleSize); err != nil {
+			// the backup is still usable, it just can't be verified as thoroughly
+			params.Logger.Warningf("can't compute the table checksums, not recording them in the MANIFEST: %v", err)
+		}
+	}
+
 	// shutdown mysqld
 	shutdownCtx, cancel := context.WithTimeout(ctx, BuiltinBackupMysqldTimeout)
 	err = params.Mysqld.Shutdown(shutdownCtx, params.Cnf, true)
@@ -389,7 +414,7 @@ func (be *BuiltinBackupEngine) executeFullBackup(ctx context.Context, params Bac
 	}
 
 	// Backup everything, capture the error.
-	backupErr := be.backupFiles(ctx, params, bh, replicationPosition, gtidPurgedPosition, mysql.Position{}, nil, serverUUID)
+	backupErr := be.backupFiles(ctx, params, bh, replicationPosition, gtidPurgedPosition, mysql.Position{}, nil, nil, tableChecksums, serverUUID)
 	usable := backupErr == nil
 
 	// Try to restart mysqld, use background context in case we timed out the original context
@@ -473,6 +498,8 @@ func (be *BuiltinBackupEngine) backupFiles(
 	purgedPosition mysql.Position,
 	fromPosition mysql.Position,
 	binlogFiles []string,
+	incrementalDetails *IncrementalBackupDetails,
+	tableChecksums []TableChecksum,
 	serverUUID string,
 ) (finalErr error) {
 
@@ -490,6 +517,13 @@ func (be *BuiltinBackupEngine) backupFiles(
 	}
 	params.Logger.Infof("found %v files to backup", len(fes))
 
+	var keyProvider KeyProvider
+	if backupEncryptionKeyProvider != "" {
+		if keyProvider, err = getKeyProvider(backupEncryptionKeyProvider); err != nil {
+			return vterrors.Wrap(err, "can't encrypt backup")
+		}
+	}
+
 	// Backup with the provided concurrency.
 	sema := sync2.NewSemaphore(params.Concurrency, 0)
 	wg := sync.WaitGroup{}
@@ -508,7 +542,7 @@ func (be *BuiltinBackupEngine) backupFiles(
 
 			// Backup the individual file.
 			name := fmt.Sprintf("%v", i)
-			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name))
+			bh.RecordError(be.backupFile(ctx, params, bh, &fes[i], name, keyProvider))
 		}(i)
 	}
 
@@ -541,23 +575,26 @@ func (be *BuiltinBackupEngine) backupFiles(
 	bm := &builtinBackupManifest{
 		// Common base fields
 		BackupManifest: BackupManifest{
-			BackupMethod:   builtinBackupEngineName,
-			Position:       replicationPosition,
-			PurgedPosition: purgedPosition,
-			FromPosition:   fromPosition,
-			Incremental:    !fromPosition.IsZero(),
-			ServerUUID:     serverUUID,
-			TabletAlias:    params.TabletAlias,
-			Keyspace:       params.Keyspace,
-			Shard:          params.Shard,
-			BackupTime:     params.BackupTime.UTC().Format(time.RFC3339),
-			FinishedTime:   time.Now().UTC().Format(time.RFC3339),
+			BackupMethod:       builtinBackupEngineName,
+			Position:           replicationPosition,
+			PurgedPosition:     purgedPosition,
+			FromPosition:       fromPosition,
+			Incremental:        !fromPosition.IsZero(),
+			IncrementalDetails: incrementalDetails,
+			ServerUUID:         serverUUID,
+			TableChecksums:     tableChecksums,
+			TabletAlias:        params.TabletAlias,
+			Keyspace:           params.Keyspace,
+			Shard:              params.Shard,
+			BackupTime:         params.BackupTime.UTC().Format(time.RFC3339),
+			FinishedTime:       time.Now().UTC().Format(time.RFC3339),
 		},
 
 		// Builtin-specific fields
-		FileEntries:       fes,
-		SkipCompress:      !backupStorageCompress,
-		CompressionEngine: CompressionEngineName,
+		FileEntries:           fes,
+		SkipCompress:          !backupStorageCompress,
+		CompressionEngine:     CompressionEngineName,
+		EncryptionKeyProvider: backupEncryptionKeyProvider,
 	}
 	data, err := json.MarshalIndent(bm, "", "  ")
 	if err != nil {
@@ -654,7 +691,7 @@ func (bp *backupPipe) ReportProgress(period time.Duration, logger logutil.Logger
 }
 
 // backupFile backs up an individual file.
-func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string) (finalErr error) {
+func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupParams, bh backupstorage.BackupHandle, fe *FileEntry, name string, keyProvider KeyProvider) (finalErr error) {
 	// Open the source file for reading.
 	openSourceAt := time.Now()
 	source, err := fe.open(params.Cnf, true)
@@ -710,6 +747,23 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 	var reader io.Reader = br
 	var writer io.Writer = bw
 
+	// Create the encryption pipe, if necessary. It comes after the compressor,
+	// since encrypted data doesn't compress.
+	var encryptor *encryptingWriter
+	if keyProvider != nil {
+		dataKey, fileEncryption, err := newFileEncryption(ctx, keyProvider)
+		if err != nil {
+			return vterrors.Wrapf(err, "can't generate data key for %v", fe.Name)
+		}
+		if encryptor, err = newEncryptingWriter(writer, dataKey); err != nil {
+			return vterrors.Wrap(err, "can't create encryptor")
+		}
+		fe.Encryption = fileEncryption
+
+		encryptStats := params.Stats.Scope(stats.Operation("Encryptor:Write"))
+		writer = ioutil.NewMeteredWriter(encryptor, encryptStats.TimedIncrementBytes)
+	}
+
 	// Create the gzip compression pipe, if necessary.
 	var compressor io.WriteCloser
 	if backupStorageCompress {
@@ -746,6 +800,15 @@ func (be *BuiltinBackupEngine) backupFile(ctx context.Context, params BackupPara
 		params.Stats.Scope(stats.Operation("Compressor:Close")).TimedIncrement(time.Since(closeCompressorAt))
 	}
 
+	// Close the encryptor to write the last chunk.
+	if encryptor != nil {
+		closeEncryptorAt := time.Now()
+		if err = encryptor.Close(); err != nil {
+			return vterrors.Wrap(err, "cannot close encryptor")
+		}
+		params.Stats.Scope(stats.Operation("Encryptor:Close")).TimedIncrement(time.Since(closeEncryptorAt))
+	}
+
 	// Close the backupPipe to finish writing on destination.
 	closeWriterAt := time.Now()
 	if err = bw.Close(); err != nil {
@@ -781,7 +844,8 @@ func (be *BuiltinBackupEngine) executeRestoreFullBackup(ctx context.Context, par
 
 // executeRestoreIncrementalBackup executes a restore of an incremental backup, and expect to run on top of a full backup's restore.
 // It restores any (zero or more) binary log files and applies them onto the underlying database one at a time, but only applies those transactions
-// that fall within params.RestoreToPos.GTIDSet. The rest (typically a suffix of the last binary log) are discarded.
+// that fall within params.RestoreToPos.GTIDSet, or that come up to the last one at or before params.RestoreToTimestamp. The rest
+// (typically a suffix of the last binary log) are discarded.
 // The underlying mysql database is expected to be up and running.
 func (be *BuiltinBackupEngine) executeRestoreIncrementalBackup(ctx context.Context, params RestoreParams, bh backupstorage.BackupHandle, bm builtinBackupManifest) error {
 	params.Logger.Infof("Restoring incremental backup to position: %v", bm.Position)
@@ -792,16 +856,34 @@ func (be *BuiltinBackupEngine) executeRestoreIncrementalBackup(ctx context.Conte
 	if !ok {
 		return vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "expected: Mysqld")
 	}
+	var binlogFiles []string
 	for _, fe := range bm.FileEntries {
 		fe.ParentPath = createdDir
 		binlogFile, err := fe.fullPath(params.Cnf)
 		if err != nil {
 			return vterrors.Wrap(err, "failed to restore file")
 		}
-		if err := mysqld.applyBinlogFile(binlogFile, params.RestoreToPos.GTIDSet); err != nil {
+		defer os.Remove(binlogFile)
+		binlogFiles = append(binlogFiles, binlogFile)
+	}
+	includeGTIDs := params.RestoreToPos.GTIDSet
+	if !params.RestoreToTimestamp.IsZero() && len(binlogFiles) > 0 {
+		// The transactions are applied up to the last one at or before the timestamp, which is
+		// found by scanning the binary logs, since their timestamps are not monotonic.
+		gtids, err := mysqld.readBinlogFilesGTIDsUpTo(ctx, binlogFiles, params.RestoreToTimestamp)
+		if err != nil {
+			return vterrors.Wrap(err, "failed to read the GTIDs of binlog files")
+		}
+		if gtids.String() == "" {
+			params.Logger.Infof("No transaction to apply in incremental backup at or before %v", params.RestoreToTimestamp.Format(time.RFC3339))
+			binlogFiles = nil
+		}
+		includeGTIDs = gtids
+	}
+	for _, binlogFile := range binlogFiles {
+		if err := mysqld.applyBinlogFile(binlogFile, includeGTIDs); err != nil {
 			return vterrors.Wrap(err, "failed to extract binlog file")
 		}
-		defer os.Remove(binlogFile)
 		params.Logger.Infof("Applied binlog file: %v", binlogFile)
 	}
 	if err != nil {
@@ -942,6 +1024,28 @@ func (be *BuiltinBackupEngine) restoreFile(ctx context.Context, params RestorePa
 
 	bufferedDest := bufio.NewWriterSize(timedDest, int(builtinBackupFileWriteBufferSize))
 
+	// Create the decryptor if needed.
+	if bm.EncryptionKeyProvider != "" {
+		if fe.Encryption == nil {
+			return vterrors.Errorf(vtrpc.Code_INTERNAL, "missing data key for encrypted file %v", fe.Name)
+		}
+		keyProvider, err := getKeyProvider(bm.EncryptionKeyProvider)
+		if err != nil {
+			return vterrors.Wrap(err, "can't decrypt backup")
+		}
+		dataKey, err := keyProvider.UnwrapKey(ctx, fe.Encryption.KeyID, fe.Encryption.WrappedKey)
+		if err != nil {
+			return vterrors.Wrapf(err, "can't decrypt data key of %v", fe.Name)
+		}
+		decryptor, err := newDecryptingReader(reader, dataKey)
+		if err != nil {
+			return vterrors.Wrap(err, "can't create decryptor")
+		}
+
+		decryptStats := params.Stats.Scope(stats.Operation("Decryptor:Read"))
+		reader = ioutil.NewMeteredReader(decryptor, decryptStats.TimedIncrementBytes)
+	}
+
 	// Create the uncompresser if needed.
 	if !bm.SkipCompress {
 		var decompressor io.ReadCloser
@@ -0,0 +1,396 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/servenv"
+)
+
+// The builtin backup engine encrypts the backup files with envelope encryption: every file is encrypted
+// with its own random data key, using AES-256-GCM, and the data key is itself encrypted by a KeyProvider
+// holding the master keys. The encrypted data key is recorded in the FileEntry of the MANIFEST.
+//
+// A file is encrypted in chunks of encryptionChunkSize bytes, each sealed with a nonce made of its
+// sequence number. The last chunk, which is shorter and may be empty, is sealed with a distinct
+// additional data, so a truncated file can't be decrypted.
+
+const (
+	// KeyfileKeyProvider is the name of the key provider reading the master keys from --backup-encryption-keyfile.
+	KeyfileKeyProvider = "keyfile"
+
+	encryptionDataKeySize = 32
+	encryptionChunkSize   = 64 * 1024
+)
+
+var (
+	// backupEncryptionKeyProvider is the name of the key provider used to encrypt the backups. Backups
+	// are not encrypted when empty. The restores use the key provider recorded in the MANIFEST.
+	backupEncryptionKeyProvider string
+	// backupEncryptionKeyfile is the file holding the master keys of the keyfile key provider.
+	backupEncryptionKeyfile string
+
+	// KeyProviderMap contains the registered key providers, by name.
+	KeyProviderMap = map[string]KeyProvider{
+		KeyfileKeyProvider: &keyfileKeyProvider{},
+	}
+
+	errTruncatedEncryptedFile = errors.New("encrypted file is truncated")
+)
+
+func init() {
+	for _, cmd := range []string{"vtbackup", "vtcombo", "vttablet", "vttestserver"} {
+		servenv.OnParseFor(cmd, registerBackupEncryptionFlags)
+	}
+}
+
+func registerBackupEncryptionFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&backupEncryptionKeyProvider, "backup-encryption-key-provider", backupEncryptionKeyProvider, "key provider used to encrypt the files of the builtin backup engine. Backups are not encrypted when empty. Supported values: 'keyfile', or the name of a key provider registered by a plugin.")
+	fs.StringVar(&backupEncryptionKeyfile, "backup-encryption-keyfile", backupEncryptionKeyfile, "file holding the master keys of the 'keyfile' backup encryption key provider, one hex encoded 32-byte key per line. The first key encrypts the new backups, all of them can decrypt the existing ones.")
+}
+
+// KeyProvider encrypts and decrypts the data keys of the backup files with master keys.
+type KeyProvider interface {
+	// WrapKey encrypts a data key. It returns the id of the master key that encrypted it,
+	// along with the encrypted data key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+
+	// UnwrapKey decrypts a data key that was encrypted by WrapKey with the given master key.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// KMSClient is the interface to a key management service. The master keys never leave the
+// service, which encrypts and decrypts the data keys itself.
+type KMSClient interface {
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// NewKMSKeyProvider returns a KeyProvider encrypting the data keys with the given master key of a
+// key management service. Plugins register it in KeyProviderMap under the name of their choice.
+func NewKMSKeyProvider(client KMSClient, keyID string) KeyProvider {
+	return &kmsKeyProvider{client: client, keyID: keyID}
+}
+
+type kmsKeyProvider struct {
+	client KMSClient
+	keyID  string
+}
+
+// WrapKey is part of the KeyProvider interface.
+func (p *kmsKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrappedKey, err := p.client.Encrypt(ctx, p.keyID, dataKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot encrypt data key with KMS key %v: %w", p.keyID, err)
+	}
+	return p.keyID, wrappedKey, nil
+}
+
+// UnwrapKey is part of the KeyProvider interface.
+func (p *kmsKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	dataKey, err := p.client.Decrypt(ctx, keyID, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt data key with KMS key %v: %w", keyID, err)
+	}
+	return dataKey, nil
+}
+
+// keyfileKeyProvider encrypts the data keys with AES-256-GCM, using master keys read from --backup-encryption-keyfile.
+// The id of a master key is derived from its SHA-256 hash.
+type keyfileKeyProvider struct{}
+
+func (p *keyfileKeyProvider) readKeys() (keyIDs []string, keys [][]byte, err error) {
+	if backupEncryptionKeyfile == "" {
+		return nil, nil, fmt.Errorf("--backup-encryption-keyfile is required by the %q backup encryption key provider", KeyfileKeyProvider)
+	}
+	data, err := os.ReadFile(backupEncryptionKeyfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := hex.DecodeString(line)
+		if err != nil || len(key) != encryptionDataKeySize {
+			return nil, nil, fmt.Errorf("invalid key in %v: keys must be %d hex encoded bytes", backupEncryptionKeyfile, encryptionDataKeySize)
+		}
+		sum := sha256.Sum256(key)
+		keyIDs = append(keyIDs, "sha256:"+hex.EncodeToString(sum[:8]))
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("no key found in %v", backupEncryptionKeyfile)
+	}
+	return keyIDs, keys, nil
+}
+
+// WrapKey is part of the KeyProvider interface.
+func (p *keyfileKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	keyIDs, keys, err := p.readKeys()
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := newAEAD(keys[0])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return keyIDs[0], aead.Seal(nonce, nonce, dataKey, []byte(keyIDs[0])), nil
+}
+
+// UnwrapKey is part of the KeyProvider interface.
+func (p *keyfileKeyProvider) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	keyIDs, keys, err := p.readKeys()
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if keyIDs[i] != keyID {
+			continue
+		}
+		aead, err := newAEAD(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		if len(wrappedKey) < aead.NonceSize() {
+			return nil, fmt.Errorf("invalid encrypted data key")
+		}
+		nonce, ciphertext := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+		return aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	}
+	return nil, fmt.Errorf("key %v not found in %v", keyID, backupEncryptionKeyfile)
+}
+
+// FileEncryption holds the encrypted data key of a backup file.
+type FileEncryption struct {
+	// KeyID is the id of the master key that encrypted the data key.
+	KeyID string
+	// WrappedKey is the encrypted data key.
+	WrappedKey []byte
+}
+
+// getKeyProvider returns the registered key provider with the given name.
+func getKeyProvider(name string) (KeyProvider, error) {
+	provider, ok := KeyProviderMap[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backup encryption key provider %q", name)
+	}
+	return provider, nil
+}
+
+// newFileEncryption generates a data key for a file, and encrypts it with the key provider.
+func newFileEncryption(ctx context.Context, provider KeyProvider) (dataKey []byte, fileEncryption *FileEncryption, err error) {
+	dataKey = make([]byte, encryptionDataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+	keyID, wrappedKey, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataKey, &FileEncryption{KeyID: keyID, WrappedKey: wrappedKey}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the given chunk. The data key is only used for one file,
+// so the nonces only have to be unique within the file.
+func chunkNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+// chunkAdditionalData tells the last chunk of a file apart from the others.
+func chunkAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptingWriter encrypts the data written to it, and writes it to the underlying writer.
+// Close must be called to write the last chunk; it does not close the underlying writer.
+type encryptingWriter struct {
+	w    io.Writer
+	aead cipher.AEAD
+	buf  []byte
+	seq  uint64
+}
+
+func newEncryptingWriter(w io.Writer, dataKey []byte) (*encryptingWriter, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		w:    w,
+		aead: aead,
+		buf:  make([]byte, 0, encryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := copy(ew.buf[len(ew.buf):encryptionChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+		// a full chunk is only sealed once more data comes, since the last chunk has to be sealed as such
+		if len(ew.buf) == encryptionChunkSize && len(p) > 0 {
+			if err := ew.seal(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (ew *encryptingWriter) seal(last bool) error {
+	sealed := ew.aead.Seal(ew.buf[:0], chunkNonce(ew.aead, ew.seq), ew.buf, chunkAdditionalData(last))
+	ew.seq++
+	ew.buf = ew.buf[:0]
+	_, err := ew.w.Write(sealed)
+	return err
+}
+
+func (ew *encryptingWriter) Close() error {
+	if len(ew.buf) == encryptionChunkSize {
+		if err := ew.seal(false); err != nil {
+			return err
+		}
+	}
+	return ew.seal(true)
+}
+
+// decryptingReader decrypts the data read from the underlying reader.
+type decryptingReader struct {
+	r    io.Reader
+	aead cipher.AEAD
+	buf  []byte
+	// plain is the decrypted data of the current chunk that was not read yet.
+	plain []byte
+	seq   uint64
+	done  bool
+}
+
+func newDecryptingReader(r io.Reader, dataKey []byte) (*decryptingReader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{
+		r:    r,
+		aead: aead,
+		buf:  make([]byte, encryptionChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.plain) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.plain)
+	dr.plain = dr.plain[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk.
+func (dr *decryptingReader) open() error {
+	n, err := io.ReadFull(dr.r, dr.buf)
+	last := false
+	switch err {
+	case nil:
+	case io.ErrUnexpectedEOF:
+		// only the last chunk is shorter
+		last = true
+	case io.EOF:
+		return errTruncatedEncryptedFile
+	default:
+		return err
+	}
+	if !last {
+		// a full chunk is the last one if nothing follows it
+		var next [1]byte
+		m, err := dr.r.Read(next[:])
+		for m == 0 && err == nil {
+			m, err = dr.r.Read(next[:])
+		}
+		switch {
+		case m == 1:
+			dr.r = io.MultiReader(bytes.NewReader(next[:]), dr.r)
+		case err == io.EOF:
+			last = true
+		default:
+			return err
+		}
+	}
+	// the full chunks are decrypted in place, but the last one is kept intact to look into a failure
+	dst := dr.buf[:0]
+	if last {
+		dst = nil
+	}
+	plain, err := dr.aead.Open(dst, chunkNonce(dr.aead, dr.seq), dr.buf[:n], chunkAdditionalData(last))
+	if err != nil {
+		if !last {
+			return fmt.Errorf("cannot decrypt chunk %d: %w", dr.seq, err)
+		}
+		// the file may have been cut right after a chunk
+		if _, err := dr.aead.Open(nil, chunkNonce(dr.aead, dr.seq), dr.buf[:n], chunkAdditionalData(false)); err == nil {
+			return errTruncatedEncryptedFile
+		}
+		return fmt.Errorf("cannot decrypt chunk %d: %w", dr.seq, err)
+	}
+	dr.seq++
+	dr.plain = plain
+	dr.done = last
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysqlctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encrypt(t *testing.T, dataKey, data []byte) []byte {
+	var buf bytes.Buffer
+	ew, err := newEncryptingWriter(&buf, dataKey)
+	require.NoError(t, err)
+	// write in uneven pieces to exercise the chunking
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		_, err := ew.Write(data[:n])
+		require.NoError(t, err)
+		data = data[n:]
+	}
+	require.NoError(t, ew.Close())
+	return buf.Bytes()
+}
+
+func decrypt(dataKey, ciphertext []byte) ([]byte, error) {
+	dr, err := newDecryptingReader(bytes.NewReader(ciphertext), dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(dr)
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	dataKey := make([]byte, encryptionDataKeySize)
+	_, err := rand.Read(dataKey)
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, 1000, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3 * encryptionChunkSize, 3*encryptionChunkSize + 12345} {
+		t.Run(fmt.Sprintf("%d", size), func(t *testing.T) {
+			data := make([]byte, size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			ciphertext := encrypt(t, dataKey, data)
+			assert.False(t, size > 16 && bytes.Contains(ciphertext, data[:16]), "data is not encrypted")
+
+			plaintext, err := decrypt(dataKey, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, data, append([]byte{}, plaintext...))
+
+			// a file cut at a chunk boundary is detected
+			chunk := encryptionChunkSize + 16
+			if len(ciphertext) > chunk {
+				_, err = decrypt(dataKey, ciphertext[:chunk])
+				assert.ErrorIs(t, err, errTruncatedEncryptedFile)
+			}
+			_, err = decrypt(dataKey, nil)
+			assert.ErrorIs(t, err, errTruncatedEncryptedFile)
+
+			// a file cut in the middle of a chunk is detected
+			_, err = decrypt(dataKey, ciphertext[:len(ciphertext)-1])
+			assert.Error(t, err)
+
+			// trailing data is detected
+			_, err = decrypt(dataKey, append(append([]byte{}, ciphertext...), 0))
+			assert.Error(t, err)
+
+			// altered data is detected
+			altered := append([]byte{}, ciphertext...)
+			altered[len(altered)/2] ^= 1
+			_, err = decrypt(dataKey, altered)
+			assert.Error(t, err)
+
+			// another key can't decrypt
+			otherKey := append([]byte{}, dataKey...)
+			otherKey[0] ^= 1
+			_, err = decrypt(otherKey, ciphertext)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeyfileKeyProvider(t *testing.T) {
+	defer func(keyfile string) {
+		backupEncryptionKeyfile = keyfile
+	}(backupEncryptionKeyfile)
+
+	ctx := context.Background()
+	provider, err := getKeyProvider(KeyfileKeyProvider)
+	require.NoError(t, err)
+
+	backupEncryptionKeyfile = ""
+	_, _, err = provider.WrapKey(ctx, []byte("data key"))
+	assert.ErrorContains(t, err, "--backup-encryption-keyfile is required")
+
+	newKey := func() string {
+		key := make([]byte, encryptionDataKeySize)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		return hex.EncodeToString(key)
+	}
+	oldKey, currentKey := newKey(), newKey()
+	backupEncryptionKeyfile = path.Join(t.TempDir(), "keys")
+	writeKeys := func(content string) {
+		require.NoError(t, os.WriteFile(backupEncryptionKeyfile, []byte(content), 0600))
+	}
+
+	writeKeys(oldKey + "\n")
+	dataKey, fileEncryption, err := newFileEncryption(ctx, provider)
+	require.NoError(t, err)
+	oldKeyID := fileEncryption.KeyID
+
+	// after a rotation, the new key encrypts and the old one can still decrypt
+	writeKeys("# current key\n" + currentKey + "\n\n" + oldKey + "\n")
+	keyID, wrappedKey, err := provider.WrapKey(ctx, dataKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKeyID, keyID)
+	unwrapped, err := provider.UnwrapKey(ctx, oldKeyID, fileEncryption.WrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+	unwrapped, err = provider.UnwrapKey(ctx, keyID, wrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// the key id is authenticated
+	_, err = provider.UnwrapKey(ctx, oldKeyID, wrappedKey)
+	assert.Error(t, err)
+
+	// once removed, the old key can't decrypt anymore
+	writeKeys(currentKey + "\n")
+	_, err = provider.UnwrapKey(ctx, oldKeyID, fileEncryption.WrappedKey)
+	assert.ErrorContains(t, err, "not found")
+
+	writeKeys("not a key\n")
+	_, _, err = provider.WrapKey(ctx, dataKey)
+	assert.ErrorContains(t, err, "invalid key")
+}
+
+// fakeKMSClient is a KMSClient with its master keys in memory.
+type fakeKMSClient struct {
+	keys map[string][]byte
+}
+
+func (c *fakeKMSClient) Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	key, ok := c.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %v", keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *fakeKMSClient) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	key, ok := c.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %v", keyID)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeKMSClient{keys: map[string][]byte{
+		"key1": bytes.Repeat([]byte{1}, 32),
+		"key2": bytes.Repeat([]byte{2}, 32),
+	}}
+	KeyProviderMap["fakekms"] = NewKMSKeyProvider(client, "key1")
+	defer delete(KeyProviderMap, "fakekms")
+
+	provider, err := getKeyProvider("fakekms")
+	require.NoError(t, err)
+	dataKey, fileEncryption, err := newFileEncryption(ctx, provider)
+	require.NoError(t, err)
+	assert.Equal(t, "key1", fileEncryption.KeyID)
+
+	// the key used to encrypt is the one recorded, regardless of the provider's current key
+	unwrapped, err := NewKMSKeyProvider(client, "key2").UnwrapKey(ctx, fileEncryption.KeyID, fileEncryption.WrappedKey)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	_, _, err = NewKMSKeyProvider(client, "key3").WrapKey(ctx, dataKey)
+	assert.ErrorContains(t, err, "unknown key key3")
+
+	_, err = getKeyProvider("unknown")
+	assert.ErrorContains(t, err, `unknown backup encryption key provider "unknown"`)
+}
@@ -50,6 +50,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/mysqlctl/mysqlctlclient"
 	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	vtenv "vitess.io/vitess/go/vt/env"
 )
@@ -1217,11 +1218,13 @@ func (mysqld *Mysqld) applyBinlogFile(binlogFile string, includeGTIDs mysql.GTID
 			return err
 		}
 		args := []string{}
-		if gtids := includeGTIDs.String(); gtids != "" {
-			args = append(args,
-				"--include-gtids",
-				gtids,
-			)
+		if includeGTIDs != nil {
+			if gtids := includeGTIDs.String(); gtids != "" {
+				args = append(args,
+					"--include-gtids",
+					gtids,
+				)
+			}
 		}
 		args = append(args, binlogFile)
 
@@ -1272,3 +1275,205 @@ func (mysqld *Mysqld) applyBinlogFile(binlogFile string, includeGTIDs mysql.GTID
 	}
 	return nil
 }
+
+// binlogGTIDEventRegexp matches the header of a GTID event, as printed by mysqlbinlog, e.g.:
+// #230605 16:06:34 server id 1  end_log_pos 233 CRC32 0x8c5c6c0a 	GTID	last_committed=0	sequence_number=1
+var binlogGTIDEventRegexp = regexp.MustCompile(`^#(\d{6})\s+(\d{1,2}):(\d{2}):(\d{2})\s+server id\s+\d+\s+end_log_pos\s+\d+.*\sGTID\s`)
+
+// binlogGTIDNextRegexp matches the statement that sets the GTID of a transaction, which follows its GTID event, e.g.:
+// SET @@SESSION.GTID_NEXT= '16b1039f-22b6-11ed-b765-0a43f95f28a3:51'/*!*/;
+var binlogGTIDNextRegexp = regexp.MustCompile(`^SET @@SESSION\.GTID_NEXT= '([0-9a-fA-F-]+:\d+)'`)
+
+// parseBinlogEventTime returns the time of an event matched by binlogGTIDEventRegexp.
+func parseBinlogEventTime(line []byte, match [][]byte) (time.Time, error) {
+	hour, _ := strconv.Atoi(string(match[2]))
+	ts, err := time.Parse("060102 15:04:05", fmt.Sprintf("%s %02d:%s:%s", match[1], hour, match[3], match[4]))
+	if err != nil {
+		return ts, vterrors.Wrapf(err, "cannot parse binary log event time in %q", line)
+	}
+	return ts, nil
+}
+
+// parseBinlogTransactionTimestamps reads the output of mysqlbinlog, and returns the times of the first
+// and last transactions, i.e. of their GTID events. The times are zero if there is no transaction.
+// When firstOnly is set, it returns as soon as it finds the first transaction.
+// mysqlbinlog is expected to print the times in UTC.
+func parseBinlogTransactionTimestamps(r io.Reader, firstOnly bool) (first time.Time, last time.Time, err error) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadSlice('\n')
+		if match := binlogGTIDEventRegexp.FindSubmatch(line); match != nil {
+			ts, parseErr := parseBinlogEventTime(line, match)
+			if parseErr != nil {
+				return first, last, parseErr
+			}
+			if first.IsZero() {
+				first = ts
+				if firstOnly {
+					return first, ts, nil
+				}
+			}
+			last = ts
+		}
+		// lines longer than the buffer are statements, not event headers
+		for err == bufio.ErrBufferFull {
+			_, err = br.ReadSlice('\n')
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			return first, last, nil
+		default:
+			return first, last, err
+		}
+	}
+}
+
+// parseBinlogGTIDsUpTo reads the output of mysqlbinlog for a binary log that follows the ones of the seen GTIDs,
+// and returns the seen GTIDs with those of the binary log, along with the GTIDs up to the last transaction at or
+// before stopTime, in the order of the binary logs. upTo is nil if there is no such transaction in the binary log.
+// The transaction timestamps are not monotonic: the transactions stamped after stopTime that come before that one
+// are part of the set, and the ones that come after it are not. The timestamps have a one second precision, so the
+// transactions of stopTime's second are included.
+// mysqlbinlog is expected to print the times in UTC.
+func parseBinlogGTIDsUpTo(r io.Reader, stopTime time.Time, seen mysql.GTIDSet) (all mysql.GTIDSet, upTo mysql.GTIDSet, err error) {
+	stopTime = stopTime.Truncate(time.Second)
+	all = seen
+	included := false
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadSlice('\n')
+		if match := binlogGTIDEventRegexp.FindSubmatch(line); match != nil {
+			ts, parseErr := parseBinlogEventTime(line, match)
+			if parseErr != nil {
+				return nil, nil, parseErr
+			}
+			included = !ts.After(stopTime)
+		} else if match := binlogGTIDNextRegexp.FindSubmatch(line); match != nil {
+			gtid, parseErr := mysql.ParseGTID(mysql.Mysql56FlavorID, string(match[1]))
+			if parseErr != nil {
+				return nil, nil, vterrors.Wrapf(parseErr, "cannot parse binary log GTID in %q", line)
+			}
+			all = all.AddGTID(gtid)
+			if included {
+				upTo = all
+			}
+		}
+		// lines longer than the buffer are statements, not event headers
+		for err == bufio.ErrBufferFull {
+			_, err = br.ReadSlice('\n')
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			return all, upTo, nil
+		default:
+			return nil, nil, err
+		}
+	}
+}
+
+// runMysqlbinlog runs mysqlbinlog on the given binary log, with the rows decoded and the times printed in UTC,
+// and passes its output to parse. When parse is done before the end of the output, mysqlbinlog is stopped.
+func (mysqld *Mysqld) runMysqlbinlog(ctx context.Context, binlogFile string, parse func(r io.Reader) (done bool, err error)) error {
+	dir, err := vtenv.VtMysqlRoot()
+	if err != nil {
+		return err
+	}
+	env, err := buildLdPaths()
+	if err != nil {
+		return err
+	}
+	name, err := binaryPath(dir, "mysqlbinlog")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, "--base64-output=decode-rows", binlogFile)
+	cmd.Dir = dir
+	// mysqlbinlog reads and prints times in the local time zone
+	cmd.Env = append(env, "TZ=UTC")
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done, err := parse(pipe)
+	if err != nil || done {
+		// there is no need for the rest of the output
+		cancel()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return vterrors.Wrapf(err, "mysqlbinlog failed on %v", binlogFile)
+	}
+	return nil
+}
+
+// readBinlogTransactionTimestamps runs mysqlbinlog on the given binary log, and returns the times of its
+// first and last transactions. See parseBinlogTransactionTimestamps.
+func (mysqld *Mysqld) readBinlogTransactionTimestamps(ctx context.Context, binlogFile string, firstOnly bool) (first time.Time, last time.Time, err error) {
+	err = mysqld.runMysqlbinlog(ctx, binlogFile, func(r io.Reader) (bool, error) {
+		first, last, err = parseBinlogTransactionTimestamps(r, firstOnly)
+		return firstOnly, err
+	})
+	return first, last, err
+}
+
+// readBinlogFilesGTIDsUpTo returns the GTIDs of the transactions of the given binary logs up to the last one at
+// or before stopTime, in the order of the binary logs. The set is empty if there is no such transaction.
+// See parseBinlogGTIDsUpTo.
+func (mysqld *Mysqld) readBinlogFilesGTIDsUpTo(ctx context.Context, binlogFiles []string, stopTime time.Time) (mysql.GTIDSet, error) {
+	var all, upTo mysql.GTIDSet = mysql.Mysql56GTIDSet{}, mysql.Mysql56GTIDSet{}
+	for _, binlogFile := range binlogFiles {
+		err := mysqld.runMysqlbinlog(ctx, binlogFile, func(r io.Reader) (bool, error) {
+			var fileUpTo mysql.GTIDSet
+			var err error
+			if all, fileUpTo, err = parseBinlogGTIDsUpTo(r, stopTime, all); fileUpTo != nil {
+				upTo = fileUpTo
+			}
+			return false, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return upTo, nil
+}
+
+// readBinlogFilesTimestamps returns the times of the first and last transactions found in the given binary logs,
+// along with the binary logs they were found in. Only the binary logs up to the first transaction, and from the last
+// transaction, are read. The details are empty if there is no transaction in the binary logs.
+func (mysqld *Mysqld) readBinlogFilesTimestamps(ctx context.Context, binlogsDir string, binlogFiles []string) (*IncrementalBackupDetails, error) {
+	details := &IncrementalBackupDetails{}
+	for _, binlogFile := range binlogFiles {
+		first, _, err := mysqld.readBinlogTransactionTimestamps(ctx, filepath.Join(binlogsDir, binlogFile), true)
+		if err != nil {
+			return nil, err
+		}
+		if !first.IsZero() {
+			details.FirstTimestamp = first.Format(time.RFC3339)
+			details.FirstTimestampBinlog = binlogFile
+			break
+		}
+	}
+	if details.FirstTimestamp == "" {
+		return details, nil
+	}
+	for i := len(binlogFiles) - 1; i >= 0; i-- {
+		_, last, err := mysqld.readBinlogTransactionTimestamps(ctx, filepath.Join(binlogsDir, binlogFiles[i]), false)
+		if err != nil {
+			return nil, err
+		}
+		if !last.IsZero() {
+			details.LastTimestamp = last.Format(time.RFC3339)
+			details.LastTimestampBinlog = binlogFiles[i]
+			break
+		}
+	}
+	return details, nil
+}
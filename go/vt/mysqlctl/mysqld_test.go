@@ -17,8 +17,16 @@ limitations under the License.
 package mysqlctl
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
 )
 
 type testcase struct {
@@ -139,3 +147,125 @@ func TestAssumeVersionString(t *testing.T) {
 	}
 
 }
+
+func TestParseBinlogTransactionTimestamps(t *testing.T) {
+	output := strings.Join([]string{
+		"# The proper term is pseudo_replica_mode, but we use this compatibility alias",
+		"/*!50530 SET @@SESSION.PSEUDO_SLAVE_MODE=1*/;",
+		"# at 4",
+		"#230605 14:02:11 server id 1  end_log_pos 126 CRC32 0x3a1e2f40 	Start: binlog v 4, server v 8.0.31 created 230605 14:02:11",
+		"# at 126",
+		"#230605 14:02:11 server id 1  end_log_pos 197 CRC32 0x1d0c5c9e 	Previous-GTIDs",
+		"# 16b1039f-22b6-11ed-b765-0a43f95f28a3:1-50",
+		"# at 197",
+		"#230605  9:06:34 server id 1  end_log_pos 276 CRC32 0x8c5c6c0a 	GTID	last_committed=0	sequence_number=1	rbr_only=yes	original_committed_timestamp=1685955994123456",
+		"SET @@SESSION.GTID_NEXT= '16b1039f-22b6-11ed-b765-0a43f95f28a3:51'/*!*/;",
+		"#230605  9:06:34 server id 1  end_log_pos 351 CRC32 0x0c3b3e5a 	Query	thread_id=8	exec_time=0	error_code=0",
+		"BEGIN",
+		"#230605  9:06:34 server id 1  end_log_pos 382 CRC32 0x5b8e1c0d 	Xid = 21",
+		"COMMIT/*!*/;",
+		"# at 382",
+		"#230605 14:32:07 server id 1  end_log_pos 461 CRC32 0x4f5e6d7c 	GTID	last_committed=1	sequence_number=2	rbr_only=yes",
+		"SET @@SESSION.GTID_NEXT= '16b1039f-22b6-11ed-b765-0a43f95f28a3:52'/*!*/;",
+		"insert into t values ('" + strings.Repeat("x", 10000) + "')",
+		"#230605 14:33:00 server id 1  end_log_pos 500 CRC32 0x4f5e6d7d 	Rotate to binlog.000002  pos: 4",
+		"",
+	}, "\n")
+
+	first, last, err := parseBinlogTransactionTimestamps(strings.NewReader(output), false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.June, 5, 9, 6, 34, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2023, time.June, 5, 14, 32, 7, 0, time.UTC), last)
+
+	first, last, err = parseBinlogTransactionTimestamps(strings.NewReader(output), true)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.June, 5, 9, 6, 34, 0, time.UTC), first)
+	assert.Equal(t, first, last)
+
+	first, last, err = parseBinlogTransactionTimestamps(strings.NewReader("# at 4\n#230605 14:02:11 server id 1  end_log_pos 126 	Start: binlog v 4\n"), false)
+	require.NoError(t, err)
+	assert.True(t, first.IsZero())
+	assert.True(t, last.IsZero())
+}
+
+func TestParseBinlogGTIDsUpTo(t *testing.T) {
+	transaction := func(ts string, sequence int) string {
+		return strings.Join([]string{
+			fmt.Sprintf("#230605 %s server id 1  end_log_pos 276 CRC32 0x8c5c6c0a 	GTID	last_committed=0	sequence_number=1", ts),
+			fmt.Sprintf("SET @@SESSION.GTID_NEXT= '16b1039f-22b6-11ed-b765-0a43f95f28a3:%d'/*!*/;", sequence),
+			"BEGIN",
+			"COMMIT/*!*/;",
+		}, "\n")
+	}
+	binlog1 := strings.Join([]string{
+		"#230605 14:02:11 server id 1  end_log_pos 126 CRC32 0x3a1e2f40 	Start: binlog v 4, server v 8.0.31 created 230605 14:02:11",
+		transaction("14:10:00", 51),
+		// the timestamps are not monotonic
+		transaction("14:30:00", 52),
+		transaction("14:20:00", 53),
+		transaction("14:20:30", 54),
+		transaction("14:25:00", 55),
+		"SET @@SESSION.GTID_NEXT= 'AUTOMATIC' /* added by mysqlbinlog */ /*!*/;",
+		"",
+	}, "\n")
+	binlog2 := strings.Join([]string{
+		transaction("14:40:00", 56),
+		transaction("14:21:00", 57),
+		"",
+	}, "\n")
+	at := func(ts string) time.Time {
+		tm, err := time.Parse("15:04:05", ts)
+		require.NoError(t, err)
+		return time.Date(2023, time.June, 5, tm.Hour(), tm.Minute(), tm.Second(), 500*int(time.Millisecond), time.UTC)
+	}
+	seen, err := mysql.ParseMysql56GTIDSet("16b1039f-22b6-11ed-b765-0a43f95f28a3:1-50")
+	require.NoError(t, err)
+
+	// wantUpTo and wantUpToAfter2 are the GTIDs up to the stop time after the first and second binary logs,
+	// the empty string meaning that there is no transaction at or before the stop time yet.
+	tcases := []struct {
+		stopTime       string
+		wantUpTo       string
+		wantUpToAfter2 string
+	}{
+		{
+			stopTime: "14:05:00",
+		}, {
+			// the transaction stamped 14:30:00 comes before the last one at or before the time
+			stopTime:       "14:20:00",
+			wantUpTo:       "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-53",
+			wantUpToAfter2: "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-53",
+		}, {
+			stopTime:       "14:40:00",
+			wantUpTo:       "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-55",
+			wantUpToAfter2: "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-57",
+		}, {
+			// a later binary log has a transaction at or before the time
+			stopTime:       "14:21:00",
+			wantUpTo:       "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-54",
+			wantUpToAfter2: "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-57",
+		},
+	}
+	gtidsString := func(gtids mysql.GTIDSet) string {
+		if gtids == nil {
+			return ""
+		}
+		return gtids.String()
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.stopTime, func(t *testing.T) {
+			all, upTo, err := parseBinlogGTIDsUpTo(strings.NewReader(binlog1), at(tcase.stopTime), seen)
+			require.NoError(t, err)
+			assert.Equal(t, "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-55", all.String())
+			assert.Equal(t, tcase.wantUpTo, gtidsString(upTo))
+
+			all, upTo2, err := parseBinlogGTIDsUpTo(strings.NewReader(binlog2), at(tcase.stopTime), all)
+			require.NoError(t, err)
+			assert.Equal(t, "16b1039f-22b6-11ed-b765-0a43f95f28a3:1-57", all.String())
+			if upTo2 != nil {
+				upTo = upTo2
+			}
+			assert.Equal(t, tcase.wantUpToAfter2, gtidsString(upTo))
+		})
+	}
+}
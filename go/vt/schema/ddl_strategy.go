@@ -29,6 +29,7 @@ var (
 
 const (
 	declarativeFlag        = "declarative"
+	declarativeSchemaFlag  = "declarative-schema"
 	skipTopoFlag           = "skip-topo" // legacy. Kept for backwards compatibility, but unused
 	singletonFlag          = "singleton"
 	singletonContextFlag   = "singleton-context"
@@ -38,6 +39,7 @@ const (
 	inOrderCompletionFlag  = "in-order-completion"
 	allowConcurrentFlag    = "allow-concurrent"
 	preferInstantDDL       = "prefer-instant-ddl"
+	preferInplaceDDL       = "prefer-inplace-ddl"
 	fastRangeRotationFlag  = "fast-range-rotation"
 	vreplicationTestSuite  = "vreplication-test-suite"
 	allowForeignKeysFlag   = "unsafe-allow-foreign-keys"
@@ -132,6 +134,11 @@ func (setting *DDLStrategySetting) IsDeclarative() bool {
 	return setting.hasFlag(declarativeFlag)
 }
 
+// IsDeclarativeSchema checks if strategy options include --declarative-schema
+func (setting *DDLStrategySetting) IsDeclarativeSchema() bool {
+	return setting.hasFlag(declarativeSchemaFlag)
+}
+
 // IsSingleton checks if strategy options include --singleton
 func (setting *DDLStrategySetting) IsSingleton() bool {
 	return setting.hasFlag(singletonFlag)
@@ -172,6 +179,12 @@ func (setting *DDLStrategySetting) IsPreferInstantDDL() bool {
 	return setting.hasFlag(preferInstantDDL)
 }
 
+// IsPreferInplaceDDL checks if strategy options include --prefer-inplace-ddl. Without it, ALTERs only
+// bypass the online schema change when they run with ALGORITHM=INSTANT, see IsPreferInstantDDL()
+func (setting *DDLStrategySetting) IsPreferInplaceDDL() bool {
+	return setting.hasFlag(preferInplaceDDL)
+}
+
 // IsFastRangeRotationFlag checks if strategy options include --fast-range-rotation
 func (setting *DDLStrategySetting) IsFastRangeRotationFlag() bool {
 	return setting.hasFlag(fastRangeRotationFlag)
@@ -194,6 +207,7 @@ func (setting *DDLStrategySetting) RuntimeOptions() []string {
 	for _, opt := range opts {
 		switch {
 		case isFlag(opt, declarativeFlag):
+		case isFlag(opt, declarativeSchemaFlag):
 		case isFlag(opt, skipTopoFlag):
 		case isFlag(opt, singletonFlag):
 		case isFlag(opt, singletonContextFlag):
@@ -203,6 +217,7 @@ func (setting *DDLStrategySetting) RuntimeOptions() []string {
 		case isFlag(opt, inOrderCompletionFlag):
 		case isFlag(opt, allowConcurrentFlag):
 		case isFlag(opt, preferInstantDDL):
+		case isFlag(opt, preferInplaceDDL):
 		case isFlag(opt, fastRangeRotationFlag):
 		case isFlag(opt, vreplicationTestSuite):
 		case isFlag(opt, allowForeignKeysFlag):
@@ -44,12 +44,14 @@ func TestParseDDLStrategy(t *testing.T) {
 		strategy             DDLStrategy
 		options              string
 		isDeclarative        bool
+		isDeclarativeSchema  bool
 		isSingleton          bool
 		isPostponeLaunch     bool
 		isPostponeCompletion bool
 		isInOrderCompletion  bool
 		isAllowConcurrent    bool
 		fastOverRevertible   bool
+		preferInplaceDDL     bool
 		fastRangeRotation    bool
 		allowForeignKeys     bool
 		runtimeOptions       string
@@ -103,6 +105,14 @@ func TestParseDDLStrategy(t *testing.T) {
 			runtimeOptions:   "--max-load=Threads_running=100",
 			isDeclarative:    true,
 		},
+		{
+			strategyVariable:    "vitess --declarative-schema --allow-concurrent",
+			strategy:            DDLStrategyVitess,
+			options:             "--declarative-schema --allow-concurrent",
+			runtimeOptions:      "",
+			isDeclarativeSchema: true,
+			isAllowConcurrent:   true,
+		},
 		{
 			strategyVariable: "pt-osc -singleton",
 			strategy:         DDLStrategyPTOSC,
@@ -152,6 +162,13 @@ func TestParseDDLStrategy(t *testing.T) {
 			runtimeOptions:     "",
 			fastOverRevertible: true,
 		},
+		{
+			strategyVariable: "vitess --prefer-inplace-ddl",
+			strategy:         DDLStrategyVitess,
+			options:          "--prefer-inplace-ddl",
+			runtimeOptions:   "",
+			preferInplaceDDL: true,
+		},
 		{
 			strategyVariable:  "vitess --fast-range-rotation",
 			strategy:          DDLStrategyVitess,
@@ -174,11 +191,13 @@ func TestParseDDLStrategy(t *testing.T) {
 			assert.Equal(t, ts.strategy, setting.Strategy)
 			assert.Equal(t, ts.options, setting.Options)
 			assert.Equal(t, ts.isDeclarative, setting.IsDeclarative())
+			assert.Equal(t, ts.isDeclarativeSchema, setting.IsDeclarativeSchema())
 			assert.Equal(t, ts.isSingleton, setting.IsSingleton())
 			assert.Equal(t, ts.isPostponeCompletion, setting.IsPostponeCompletion())
 			assert.Equal(t, ts.isPostponeLaunch, setting.IsPostponeLaunch())
 			assert.Equal(t, ts.isAllowConcurrent, setting.IsAllowConcurrent())
 			assert.Equal(t, ts.fastOverRevertible, setting.IsPreferInstantDDL())
+			assert.Equal(t, ts.preferInplaceDDL, setting.IsPreferInplaceDDL())
 			assert.Equal(t, ts.fastRangeRotation, setting.IsFastRangeRotationFlag())
 			assert.Equal(t, ts.allowForeignKeys, setting.IsAllowForeignKeysFlag())
 
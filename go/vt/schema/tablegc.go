@@ -117,13 +117,18 @@ func GenerateRenameStatement(fromTableName string, state TableGCState, t time.Ti
 	return GenerateRenameStatementWithUUID(fromTableName, state, "", t)
 }
 
+// ParseGCTableState parses a (case insensitive) GC state name, e.g. "hold"
+func ParseGCTableState(name string) (state TableGCState, ok bool) {
+	state, ok = gcStates[strings.ToUpper(name)]
+	return state, ok
+}
+
 // ParseGCLifecycle parses a comma separated list of gc states and returns a map of indicated states
 func ParseGCLifecycle(gcLifecycle string) (states map[TableGCState]bool, err error) {
 	states = make(map[TableGCState]bool)
 	tokens := textutil.SplitDelimitedList(gcLifecycle)
 	for _, token := range tokens {
-		token = strings.ToUpper(token)
-		state, ok := gcStates[token]
+		state, ok := ParseGCTableState(token)
 		if !ok {
 			return states, fmt.Errorf("Unknown GC state: %s", token)
 		}
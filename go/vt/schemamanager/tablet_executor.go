@@ -22,10 +22,13 @@ import (
 	"sync"
 	"time"
 
+	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
 	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
@@ -348,6 +351,12 @@ func (exec *TabletExecutor) Execute(ctx context.Context, sqls []string) *Execute
 		}
 	}()
 
+	if exec.ddlStrategySetting != nil && exec.ddlStrategySetting.IsDeclarativeSchema() {
+		// The SQLs are the desired schema, not a list of changes. There is nothing to preflight.
+		exec.executeDeclarativeSchema(ctx, sqls, &execResult)
+		return &execResult
+	}
+
 	// Make sure the schema changes introduce a table definition change.
 	if err := exec.preflightSchemaChanges(ctx, sqls); err != nil {
 		execResult.ExecutorErr = err.Error()
@@ -435,6 +444,132 @@ func (exec *TabletExecutor) Execute(ctx context.Context, sqls []string) *Execute
 	return &execResult
 }
 
+// declarativeSchemaStatements validates the SQLs of a declarative schema migration, which are the CREATE TABLE and
+// CREATE VIEW statements of the full desired schema of the keyspace. It returns them along with a DROP statement
+// for each table and view of the keyspace that is not part of the desired schema.
+func (exec *TabletExecutor) declarativeSchemaStatements(ctx context.Context, sqls []string) ([]string, error) {
+	if exec.hasProvidedUUIDs() {
+		return nil, fmt.Errorf("--declarative-schema does not support provided UUIDs")
+	}
+	switch exec.ddlStrategySetting.Strategy {
+	case schema.DDLStrategyVitess, schema.DDLStrategyOnline:
+	default:
+		return nil, fmt.Errorf("--declarative-schema is only supported by the vitess strategy, found: %s", exec.ddlStrategySetting.Strategy)
+	}
+	if exec.ddlStrategySetting.IsPreferInstantDDL() || exec.ddlStrategySetting.IsPreferInplaceDDL() || exec.ddlStrategySetting.IsFastRangeRotationFlag() {
+		return nil, fmt.Errorf("--declarative-schema does not support --prefer-instant-ddl, --prefer-inplace-ddl nor --fast-range-rotation")
+	}
+	if exec.migrationContext == "" {
+		return nil, fmt.Errorf("--declarative-schema requires a migration context")
+	}
+	stmts := make([]sqlparser.Statement, 0, len(sqls))
+	for _, sql := range sqls {
+		stmt, err := sqlparser.Parse(sql)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sql: %s, got error: %v", sql, err)
+		}
+		switch stmt.(type) {
+		case *sqlparser.CreateTable, *sqlparser.CreateView:
+		default:
+			return nil, fmt.Errorf("--declarative-schema only accepts CREATE TABLE and CREATE VIEW statements, found: %s", sql)
+		}
+		stmts = append(stmts, stmt)
+	}
+	desiredSchema, err := schemadiff.NewSchemaFromStatements(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &tabletmanagerdatapb.GetSchemaRequest{IncludeViews: true, TableSchemaOnly: true}
+	dbSchema, err := exec.tmc.GetSchema(ctx, exec.tablets[0], req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get database schema, error: %v", err)
+	}
+	statements := append([]string{}, sqls...)
+	for _, td := range dbSchema.TableDefinitions {
+		if desiredSchema.Entity(td.Name) != nil || schema.IsInternalOperationTableName(td.Name) {
+			continue
+		}
+		if td.Type == tmutils.TableView {
+			statements = append(statements, fmt.Sprintf("drop view %s", sqlescape.EscapeID(td.Name)))
+		} else {
+			statements = append(statements, fmt.Sprintf("drop table %s", sqlescape.EscapeID(td.Name)))
+		}
+	}
+	return statements, nil
+}
+
+// executeDeclarativeSchema submits a declarative schema migration: one migration per table and view to create or
+// drop, all of them in the migration context of the executor. The migrations are submitted with --postpone-launch,
+// and are only launched once all of them are submitted, so that the tablets evaluate and cut over the group as a
+// whole. With --postpone-launch, launching them is left to the user.
+func (exec *TabletExecutor) executeDeclarativeSchema(ctx context.Context, sqls []string, execResult *ExecuteResult) {
+	statements, err := exec.declarativeSchemaStatements(ctx, sqls)
+	if err != nil {
+		execResult.ExecutorErr = err.Error()
+		return
+	}
+	options := exec.ddlStrategySetting.Options
+	if !exec.ddlStrategySetting.IsPostponeLaunch() {
+		options = options + " --postpone-launch"
+	}
+	strategySetting := schema.NewDDLStrategySetting(exec.ddlStrategySetting.Strategy, options)
+
+	rl := timer.NewRateLimiter(topo.RemoteOperationTimeout / 4)
+	defer rl.Stop()
+	renewLock := func() bool {
+		if err := rl.Do(func() error { return topo.CheckKeyspaceLockedAndRenew(ctx, exec.keyspace) }); err != nil {
+			execResult.ExecutorErr = vterrors.Wrapf(err, "CheckKeyspaceLocked in ApplySchemaKeyspace %v", exec.keyspace).Error()
+			return false
+		}
+		return true
+	}
+	for index, sql := range statements {
+		if !renewLock() {
+			return
+		}
+		if index < len(sqls) {
+			execResult.CurSQLIndex = index
+		}
+		stmt, err := sqlparser.ParseStrictDDL(sql)
+		if err != nil {
+			execResult.ExecutorErr = err.Error()
+			return
+		}
+		ddlStmt, ok := stmt.(sqlparser.DDLStatement)
+		if !ok {
+			execResult.ExecutorErr = fmt.Sprintf("unexpected statement: %s", sql)
+			return
+		}
+		onlineDDLs, err := schema.NewOnlineDDLs(exec.keyspace, sql, ddlStmt, strategySetting, exec.migrationContext, "")
+		if err != nil {
+			execResult.ExecutorErr = err.Error()
+			return
+		}
+		for _, onlineDDL := range onlineDDLs {
+			exec.executeOnAllTablets(ctx, execResult, onlineDDL.SQL, true)
+			if len(execResult.FailedShards) > 0 {
+				return
+			}
+			execResult.UUIDs = append(execResult.UUIDs, onlineDDL.UUID)
+			exec.logger.Printf("%s\n", onlineDDL.UUID)
+		}
+	}
+	if exec.ddlStrategySetting.IsPostponeLaunch() {
+		return
+	}
+	for _, uuid := range execResult.UUIDs {
+		if !renewLock() {
+			return
+		}
+		launch := &sqlparser.AlterMigration{Type: sqlparser.LaunchMigrationType, UUID: uuid}
+		exec.executeOnAllTablets(ctx, execResult, sqlparser.String(launch), true)
+		if len(execResult.FailedShards) > 0 {
+			return
+		}
+	}
+}
+
 // executeOnAllTablets runs a query on all tablets, synchronously. This can be a long running operation.
 func (exec *TabletExecutor) executeOnAllTablets(ctx context.Context, execResult *ExecuteResult, sql string, viaQueryService bool) {
 	var wg sync.WaitGroup
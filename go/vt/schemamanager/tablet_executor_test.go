@@ -301,3 +301,82 @@ func TestIsOnlineSchemaDDL(t *testing.T) {
 		}
 	}
 }
+
+func TestDeclarativeSchemaStatements(t *testing.T) {
+	fakeTmc := newFakeTabletManagerClient()
+	fakeTmc.AddSchemaDefinition("vt_test_keyspace", &tabletmanagerdatapb.SchemaDefinition{
+		TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+			{Name: "t1", Type: tmutils.TableBaseTable},
+			{Name: "t2", Type: tmutils.TableBaseTable},
+			{Name: "v1", Type: tmutils.TableView},
+			{Name: "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410", Type: tmutils.TableBaseTable},
+		},
+	})
+	ctx := context.Background()
+	sqls := []string{
+		"create table t1 (id int primary key)",
+		"create view v2 as select id from t1",
+	}
+
+	tcases := []struct {
+		ddlStrategy      string
+		migrationContext string
+		sqls             []string
+		expect           []string
+		expectErr        string
+	}{
+		{
+			ddlStrategy:      "vitess --declarative-schema",
+			migrationContext: "ctx",
+			sqls:             sqls,
+			expect:           append(append([]string{}, sqls...), "drop table `t2`", "drop view `v1`"),
+		},
+		{
+			ddlStrategy:      "gh-ost --declarative-schema",
+			migrationContext: "ctx",
+			sqls:             sqls,
+			expectErr:        "only supported by the vitess strategy",
+		},
+		{
+			ddlStrategy:      "vitess --declarative-schema --prefer-instant-ddl",
+			migrationContext: "ctx",
+			sqls:             sqls,
+			expectErr:        "does not support --prefer-instant-ddl",
+		},
+		{
+			ddlStrategy: "vitess --declarative-schema",
+			sqls:        sqls,
+			expectErr:   "requires a migration context",
+		},
+		{
+			ddlStrategy:      "vitess --declarative-schema",
+			migrationContext: "ctx",
+			sqls:             []string{"alter table t1 add column i int"},
+			expectErr:        "only accepts CREATE TABLE and CREATE VIEW statements",
+		},
+		{
+			ddlStrategy:      "vitess --declarative-schema",
+			migrationContext: "ctx",
+			sqls:             []string{"create view v2 as select id from t3"},
+			expectErr:        "unresolved",
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.ddlStrategy, func(t *testing.T) {
+			executor := NewTabletExecutor(tcase.migrationContext, newFakeTopo(t), fakeTmc, logutil.NewConsoleLogger(), testWaitReplicasTimeout)
+			err := executor.Open(ctx, "test_keyspace")
+			assert.NoError(t, err)
+			defer executor.Close()
+			err = executor.SetDDLStrategy(tcase.ddlStrategy)
+			assert.NoError(t, err)
+
+			statements, err := executor.declarativeSchemaStatements(ctx, tcase.sqls)
+			if tcase.expectErr != "" {
+				assert.ErrorContains(t, err, tcase.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tcase.expect, statements)
+		})
+	}
+}
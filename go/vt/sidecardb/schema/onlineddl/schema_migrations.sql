@@ -69,6 +69,7 @@ CREATE TABLE IF NOT EXISTS _vt.schema_migrations
     `cutover_attempts`                int unsigned     NOT NULL DEFAULT '0',
     `is_immediate_operation`          tinyint unsigned NOT NULL DEFAULT '0',
     `reviewed_timestamp`              timestamp        NULL DEFAULT NULL,
+    `alter_algorithm`                 varchar(32)      NOT NULL DEFAULT '',
     PRIMARY KEY (`id`),
     UNIQUE KEY `uuid_idx` (`migration_uuid`),
     KEY `keyspace_shard_idx` (`keyspace`(64), `shard`(64)),
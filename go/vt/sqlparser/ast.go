@@ -140,7 +140,7 @@ type (
 
 	// With contains the lists of common table expression and specifies if it is recursive or not
 	With struct {
-		ctes      []*CommonTableExpr
+		CTEs      []*CommonTableExpr
 		Recursive bool
 	}
 
@@ -2723,32 +2723,45 @@ type (
 		GetArgs() Exprs
 	}
 
+	// WindowFunc is implemented by all expressions that can be evaluated over a window.
+	// For aggregate functions, GetOverClause returns nil unless they are used with OVER.
+	WindowFunc interface {
+		Expr
+		GetOverClause() *OverClause
+	}
+
 	Count struct {
-		Args     Exprs
-		Distinct bool
+		Args       Exprs
+		Distinct   bool
+		OverClause *OverClause
 	}
 
 	CountStar struct {
+		OverClause *OverClause
 	}
 
 	Avg struct {
-		Arg      Expr
-		Distinct bool
+		Arg        Expr
+		Distinct   bool
+		OverClause *OverClause
 	}
 
 	Max struct {
-		Arg      Expr
-		Distinct bool
+		Arg        Expr
+		Distinct   bool
+		OverClause *OverClause
 	}
 
 	Min struct {
-		Arg      Expr
-		Distinct bool
+		Arg        Expr
+		Distinct   bool
+		OverClause *OverClause
 	}
 
 	Sum struct {
-		Arg      Expr
-		Distinct bool
+		Arg        Expr
+		Distinct   bool
+		OverClause *OverClause
 	}
 
 	BitAnd struct {
@@ -2791,6 +2804,19 @@ type (
 		Arg Expr
 	}
 
+	// JSONArrayAgg represents a call to JSON_ARRAYAGG
+	JSONArrayAgg struct {
+		Expr       Expr
+		OverClause *OverClause
+	}
+
+	// JSONObjectAgg represents a call to JSON_OBJECTAGG
+	JSONObjectAgg struct {
+		Key        Expr
+		Value      Expr
+		OverClause *OverClause
+	}
+
 	// GroupConcatExpr represents a call to GROUP_CONCAT
 	GroupConcatExpr struct {
 		Distinct  bool
@@ -3040,6 +3066,8 @@ func (*StdSamp) iExpr()                            {}
 func (*VarPop) iExpr()                             {}
 func (*VarSamp) iExpr()                            {}
 func (*Variance) iExpr()                           {}
+func (*JSONArrayAgg) iExpr()                       {}
+func (*JSONObjectAgg) iExpr()                      {}
 func (*Variable) iExpr()                           {}
 func (*PointExpr) iExpr()                          {}
 func (*LineStringExpr) iExpr()                     {}
@@ -3123,6 +3151,8 @@ func (stdS *StdSamp) GetArg() Expr              { return stdS.Arg }
 func (varP *VarPop) GetArg() Expr               { return varP.Arg }
 func (varS *VarSamp) GetArg() Expr              { return varS.Arg }
 func (variance *Variance) GetArg() Expr         { return variance.Arg }
+func (jaa *JSONArrayAgg) GetArg() Expr          { return jaa.Expr }
+func (joa *JSONObjectAgg) GetArg() Expr         { return joa.Key }
 
 func (sum *Sum) GetArgs() Exprs                   { return Exprs{sum.Arg} }
 func (min *Min) GetArgs() Exprs                   { return Exprs{min.Arg} }
@@ -3141,6 +3171,8 @@ func (stdS *StdSamp) GetArgs() Exprs              { return Exprs{stdS.Arg} }
 func (varP *VarPop) GetArgs() Exprs               { return Exprs{varP.Arg} }
 func (varS *VarSamp) GetArgs() Exprs              { return Exprs{varS.Arg} }
 func (variance *Variance) GetArgs() Exprs         { return Exprs{variance.Arg} }
+func (jaa *JSONArrayAgg) GetArgs() Exprs          { return Exprs{jaa.Expr} }
+func (joa *JSONObjectAgg) GetArgs() Exprs         { return Exprs{joa.Key, joa.Value} }
 
 func (sum *Sum) IsDistinct() bool                   { return sum.Distinct }
 func (min *Min) IsDistinct() bool                   { return min.Distinct }
@@ -3159,6 +3191,8 @@ func (stdS *StdSamp) IsDistinct() bool              { return false }
 func (varP *VarPop) IsDistinct() bool               { return false }
 func (varS *VarSamp) IsDistinct() bool              { return false }
 func (variance *Variance) IsDistinct() bool         { return false }
+func (jaa *JSONArrayAgg) IsDistinct() bool          { return false }
+func (joa *JSONObjectAgg) IsDistinct() bool         { return false }
 
 func (sum *Sum) AggrName() string                   { return "sum" }
 func (min *Min) AggrName() string                   { return "min" }
@@ -3177,6 +3211,22 @@ func (stdS *StdSamp) AggrName() string              { return "stddev_samp" }
 func (varP *VarPop) AggrName() string               { return "var_pop" }
 func (varS *VarSamp) AggrName() string              { return "var_samp" }
 func (variance *Variance) AggrName() string         { return "variance" }
+func (jaa *JSONArrayAgg) AggrName() string          { return "json_arrayagg" }
+func (joa *JSONObjectAgg) AggrName() string         { return "json_objectagg" }
+
+func (sum *Sum) GetOverClause() *OverClause                     { return sum.OverClause }
+func (min *Min) GetOverClause() *OverClause                     { return min.OverClause }
+func (max *Max) GetOverClause() *OverClause                     { return max.OverClause }
+func (avg *Avg) GetOverClause() *OverClause                     { return avg.OverClause }
+func (cStar *CountStar) GetOverClause() *OverClause             { return cStar.OverClause }
+func (count *Count) GetOverClause() *OverClause                 { return count.OverClause }
+func (jaa *JSONArrayAgg) GetOverClause() *OverClause            { return jaa.OverClause }
+func (joa *JSONObjectAgg) GetOverClause() *OverClause           { return joa.OverClause }
+func (node *ArgumentLessWindowExpr) GetOverClause() *OverClause { return node.OverClause }
+func (node *FirstOrLastValueExpr) GetOverClause() *OverClause   { return node.OverClause }
+func (node *NtileExpr) GetOverClause() *OverClause              { return node.OverClause }
+func (node *NTHValueExpr) GetOverClause() *OverClause           { return node.OverClause }
+func (node *LagLeadExpr) GetOverClause() *OverClause            { return node.OverClause }
 
 // Exprs represents a list of value expressions.
 // It's not a valid expression because it's not parenthesized.
@@ -615,22 +615,24 @@ func (node *ColName) Equal(c *ColName) bool {
 
 // Aggregates is a map of all aggregate functions.
 var Aggregates = map[string]bool{
-	"avg":          true,
-	"bit_and":      true,
-	"bit_or":       true,
-	"bit_xor":      true,
-	"count":        true,
-	"group_concat": true,
-	"max":          true,
-	"min":          true,
-	"std":          true,
-	"stddev_pop":   true,
-	"stddev_samp":  true,
-	"stddev":       true,
-	"sum":          true,
-	"var_pop":      true,
-	"var_samp":     true,
-	"variance":     true,
+	"avg":            true,
+	"bit_and":        true,
+	"bit_or":         true,
+	"bit_xor":        true,
+	"count":          true,
+	"group_concat":   true,
+	"json_arrayagg":  true,
+	"json_objectagg": true,
+	"max":            true,
+	"min":            true,
+	"std":            true,
+	"stddev_pop":     true,
+	"stddev_samp":    true,
+	"stddev":         true,
+	"sum":            true,
+	"var_pop":        true,
+	"var_samp":       true,
+	"variance":       true,
 }
 
 // IsAggregate returns true if the function is an aggregate.
@@ -1972,6 +1974,28 @@ func encodeSQLString(val string) string {
 	return sqltypes.EncodeStringSQL(val)
 }
 
+// GetSeparator returns the decoded separator of the GROUP_CONCAT call,
+// defaulting to "," when none was specified.
+func (node *GroupConcatExpr) GetSeparator() string {
+	if node.Separator == "" {
+		return ","
+	}
+	enc := strings.TrimPrefix(node.Separator, " separator ")
+	enc = strings.TrimSuffix(strings.TrimPrefix(enc, "'"), "'")
+	var sep strings.Builder
+	for i := 0; i < len(enc); i++ {
+		ch := enc[i]
+		if ch == '\\' && i+1 < len(enc) {
+			if decoded := sqltypes.SQLDecodeMap[enc[i+1]]; decoded != sqltypes.DontEscape {
+				ch = decoded
+				i++
+			}
+		}
+		sep.WriteByte(ch)
+	}
+	return sep.String()
+}
+
 // ToString prints the list of table expressions as a string
 // To be used as an alternate for String for []TableExpr
 func ToString(exprs []TableExpr) string {
@@ -1999,19 +2023,42 @@ func formatAddress(address string) string {
 	return buf.String()
 }
 
-// ContainsAggregation returns true if the expression contains aggregation
+// ContainsAggregation returns true if the expression contains aggregation.
+// Aggregate functions used with an OVER clause are window functions and are not counted.
 func ContainsAggregation(e SQLNode) bool {
 	hasAggregates := false
 	_ = Walk(func(node SQLNode) (kontinue bool, err error) {
-		if _, isAggregate := node.(AggrFunc); isAggregate {
+		if aggr, isAggregate := node.(AggrFunc); isAggregate && !IsWindowFunc(aggr) {
 			hasAggregates = true
 			return false, nil
 		}
-		return true, nil
+		// the aggregations of a subquery are computed by the subquery itself
+		_, isSubquery := node.(*Subquery)
+		return !isSubquery, nil
 	}, e)
 	return hasAggregates
 }
 
+// IsWindowFunc returns true if the node is a function evaluated over a window
+func IsWindowFunc(node SQLNode) bool {
+	wf, ok := node.(WindowFunc)
+	return ok && wf.GetOverClause() != nil
+}
+
+// ContainsWindowFunc returns true if the expression contains a window function
+func ContainsWindowFunc(e SQLNode) bool {
+	hasWindowFunc := false
+	_ = Walk(func(node SQLNode) (kontinue bool, err error) {
+		if IsWindowFunc(node) {
+			hasWindowFunc = true
+			return false, nil
+		}
+		_, isSubq := node.(*Subquery)
+		return !isSubq, nil
+	}, e)
+	return hasWindowFunc
+}
+
 // GetFirstSelect gets the first select statement
 func GetFirstSelect(selStmt SelectStatement) *Select {
 	if selStmt == nil {
@@ -2060,6 +2107,12 @@ func (es *ExtractedSubquery) GetHasValuesArg() string {
 
 }
 
+// GetAlternative returns the expression that replaces the subquery once it has been pulled out,
+// i.e. the expression that uses the subquery's bind variables instead of the subquery.
+func (es *ExtractedSubquery) GetAlternative() Expr {
+	return es.alternative
+}
+
 func (es *ExtractedSubquery) updateAlternative() {
 	switch original := es.Original.(type) {
 	case *ExistsExpr:
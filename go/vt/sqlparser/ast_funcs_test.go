@@ -134,3 +134,24 @@ func TestSQLTypeToQueryType(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupConcatGetSeparator(t *testing.T) {
+	tcs := []struct {
+		query string
+		want  string
+	}{
+		{query: "select group_concat(a) from t", want: ","},
+		{query: "select group_concat(a separator '') from t", want: ""},
+		{query: "select group_concat(a separator ':') from t", want: ":"},
+		{query: "select group_concat(a separator '\\n') from t", want: "\n"},
+		{query: "select group_concat(a separator '''') from t", want: "'"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.query, func(t *testing.T) {
+			stmt, err := Parse(tc.query)
+			require.NoError(t, err)
+			gc := stmt.(*Select).SelectExprs[0].(*AliasedExpr).Expr.(*GroupConcatExpr)
+			require.Equal(t, tc.want, gc.GetSeparator())
+		})
+	}
+}
@@ -19,6 +19,7 @@ package sqlparser
 import (
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -48,6 +49,12 @@ const (
 	DirectiveVExplainRunDMLQueries = "EXECUTE_DML_QUERIES"
 	// DirectiveConsolidator enables the query consolidator.
 	DirectiveConsolidator = "CONSOLIDATOR"
+	// DirectiveCacheTTL lets vtgate cache the result of a select for the given duration.
+	DirectiveCacheTTL = "CACHE_TTL"
+	// DirectiveDeliverAt sets the time at which the messages inserted into a message table are first sent.
+	DirectiveDeliverAt = "DELIVER_AT"
+	// DirectiveDeliverAfter delays the first send of the messages inserted into a message table by the given duration.
+	DirectiveDeliverAfter = "DELIVER_AFTER"
 )
 
 func isNonSpace(r rune) bool {
@@ -396,3 +403,21 @@ func Consolidator(stmt Statement) querypb.ExecuteOptions_Consolidator {
 	}
 	return querypb.ExecuteOptions_CONSOLIDATOR_UNSPECIFIED
 }
+
+// ResultCacheTTL returns the duration for which vtgate may cache the result of the statement.
+// It is zero unless the statement is a plain select with a valid CACHE_TTL directive.
+func ResultCacheTTL(stmt Statement) time.Duration {
+	sel, ok := stmt.(*Select)
+	if !ok || sel.Comments == nil || sel.Lock != NoLock || sel.Into != nil {
+		return 0
+	}
+	val, isSet := sel.Comments.Directives().GetString(DirectiveCacheTTL, "")
+	if !isSet {
+		return 0
+	}
+	ttl, err := time.ParseDuration(val)
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -496,3 +497,27 @@ func TestConsolidator(t *testing.T) {
 		})
 	}
 }
+
+func TestResultCacheTTL(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected time.Duration
+	}{
+		{"select * from users", 0},
+		{"select /*vt+ CACHE_TTL=10s */ * from users", 10 * time.Second},
+		{"select /*vt+ CACHE_TTL=1m30s */ * from users", 90 * time.Second},
+		{"select /*vt+ CACHE_TTL=invalid */ * from users", 0},
+		{"select /*vt+ CACHE_TTL=-1s */ * from users", 0},
+		{"select /*vt+ CACHE_TTL=10s */ * from users for update", 0},
+		{"select /*vt+ CACHE_TTL=10s */ * from users into outfile 'x'", 0},
+		{"update /*vt+ CACHE_TTL=10s */ users set name=1", 0},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := Parse(test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ResultCacheTTL(stmt))
+		})
+	}
+}
@@ -347,6 +347,7 @@ var keywords = []keyword{
 	{"json_array", JSON_ARRAY},
 	{"json_array_append", JSON_ARRAY_APPEND},
 	{"json_array_insert", JSON_ARRAY_INSERT},
+	{"json_arrayagg", JSON_ARRAYAGG},
 	{"json_contains", JSON_CONTAINS},
 	{"json_contains_path", JSON_CONTAINS_PATH},
 	{"json_depth", JSON_DEPTH},
@@ -358,6 +359,7 @@ var keywords = []keyword{
 	{"json_merge_patch", JSON_MERGE_PATCH},
 	{"json_merge_preserve", JSON_MERGE_PRESERVE},
 	{"json_object", JSON_OBJECT},
+	{"json_objectagg", JSON_OBJECTAGG},
 	{"json_overlaps", JSON_OVERLAPS},
 	{"json_pretty", JSON_PRETTY},
 	{"json_remove", JSON_REMOVE},
@@ -3180,6 +3180,12 @@ var (
 	}, {
 		input:  "SELECT LAG(val, 10) OVER w, LEAD('val', null) OVER w, LEAD(val, 1, ASCII(1)) OVER w FROM numbers",
 		output: "select lag(val, 10) over w, lead('val', null) over w, lead(val, 1, ASCII(1)) over w from numbers",
+	}, {
+		input:  "SELECT id, SUM(val) OVER (PARTITION BY id ORDER BY ts), COUNT(*) OVER w, AVG(val) OVER (), MIN(val) OVER w, MAX(DISTINCT val) OVER w FROM numbers",
+		output: "select id, sum(val) over ( partition by id order by ts asc), count(*) over w, avg(val) over (), min(val) over w, max(distinct val) over w from numbers",
+	}, {
+		input:  "SELECT COUNT(val, id) OVER (PARTITION BY id) FROM numbers",
+		output: "select count(val, id) over ( partition by id) from numbers",
 	}, {
 		input:  "SELECT val, ROW_NUMBER() OVER (ORDER BY val) AS 'row_number' FROM numbers WINDOW w AS (ORDER BY val);",
 		output: "select val, row_number() over ( order by val asc) as `row_number` from numbers window w AS ( order by val asc)",
@@ -3276,6 +3282,16 @@ var (
 		input: "select var_samp(a) from products",
 	}, {
 		input: "select variance(a) from products",
+	}, {
+		input: "select json_arrayagg(a) from products",
+	}, {
+		input: "select json_objectagg(a, b) from products",
+	}, {
+		input:  "select JSON_ARRAYAGG(a) over (partition by b) from products",
+		output: "select json_arrayagg(a) over ( partition by b) from products",
+	}, {
+		input:  "select json_arrayagg, json_objectagg from products",
+		output: "select `json_arrayagg`, `json_objectagg` from products",
 	}, {
 		input:  "SELECT FORMAT_BYTES(512), FORMAT_BYTES(18446644073709551615), FORMAT_BYTES(@j), FORMAT_BYTES('asd'), FORMAT_BYTES(TRIM('str'))",
 		output: "select format_bytes(512), format_bytes(18446644073709551615), format_bytes(@j), format_bytes('asd'), format_bytes(trim('str')) from dual",
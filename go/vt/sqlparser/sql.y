@@ -357,7 +357,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %token <str> JSON_ARRAY JSON_OBJECT JSON_QUOTE
 %token <str> JSON_DEPTH JSON_TYPE JSON_LENGTH JSON_VALID
 %token <str> JSON_ARRAY_APPEND JSON_ARRAY_INSERT JSON_INSERT JSON_MERGE JSON_MERGE_PATCH JSON_MERGE_PRESERVE JSON_REMOVE JSON_REPLACE JSON_SET JSON_UNQUOTE
-%token <str> COUNT AVG MAX MIN SUM GROUP_CONCAT BIT_AND BIT_OR BIT_XOR STD STDDEV STDDEV_POP STDDEV_SAMP VAR_POP VAR_SAMP VARIANCE
+%token <str> COUNT AVG MAX MIN SUM GROUP_CONCAT BIT_AND BIT_OR BIT_XOR STD STDDEV STDDEV_POP STDDEV_SAMP VAR_POP VAR_SAMP VARIANCE JSON_ARRAYAGG JSON_OBJECTAGG
 %token <str> REGEXP_INSTR REGEXP_LIKE REGEXP_REPLACE REGEXP_SUBSTR
 %token <str> ExtractValue UpdateXML
 %token <str> GET_LOCK RELEASE_LOCK RELEASE_ALL_LOCKS IS_FREE_LOCK IS_USED_LOCK
@@ -438,7 +438,7 @@ func bindVariable(yylex yyLexer, bvar string) {
 %type <framePoint> frame_point
 %type <frameClause> frame_clause frame_clause_opt
 %type <windowSpecification> window_spec
-%type <overClause> over_clause
+%type <overClause> over_clause over_clause_opt
 %type <nullTreatmentType> null_treatment_type
 %type <nullTreatmentClause> null_treatment_clause null_treatment_clause_opt
 %type <fromFirstLastType> from_first_last_type
@@ -704,11 +704,11 @@ load_statement:
 with_clause:
   WITH with_list
   {
-	$$ = &With{ctes: $2, Recursive: false}
+	$$ = &With{CTEs: $2, Recursive: false}
   }
 | WITH RECURSIVE with_list
   {
-	$$ = &With{ctes: $3, Recursive: true}
+	$$ = &With{CTEs: $3, Recursive: true}
   }
 
 with_clause_opt:
@@ -5614,6 +5614,12 @@ sql_id_opt window_partition_clause_opt order_by_opt frame_clause_opt
     $$ = &WindowSpecification{ Name: $1, PartitionClause: $2, OrderClause: $3, FrameClause: $4}
   }
 
+over_clause_opt:
+  {
+    $$ = nil
+  }
+| over_clause
+
 over_clause:
   OVER openb window_spec closeb
   {
@@ -5893,29 +5899,29 @@ UTC_DATE func_paren_opt
   {
     $$ = &CurTimeFuncExpr{Name:NewIdentifierCI("current_time"), Fsp: $2}
   }
-| COUNT openb '*' closeb
+| COUNT openb '*' closeb over_clause_opt
   {
-    $$ = &CountStar{}
+    $$ = &CountStar{OverClause:$5}
   }
-| COUNT openb distinct_opt expression_list closeb
+| COUNT openb distinct_opt expression_list closeb over_clause_opt
   {
-    $$ = &Count{Distinct:$3, Args:$4}
+    $$ = &Count{Distinct:$3, Args:$4, OverClause:$6}
   }
-| MAX openb distinct_opt expression closeb
+| MAX openb distinct_opt expression closeb over_clause_opt
   {
-    $$ = &Max{Distinct:$3, Arg:$4}
+    $$ = &Max{Distinct:$3, Arg:$4, OverClause:$6}
   }
-| MIN openb distinct_opt expression closeb
+| MIN openb distinct_opt expression closeb over_clause_opt
   {
-    $$ = &Min{Distinct:$3, Arg:$4}
+    $$ = &Min{Distinct:$3, Arg:$4, OverClause:$6}
   }
-| SUM openb distinct_opt expression closeb
+| SUM openb distinct_opt expression closeb over_clause_opt
   {
-    $$ = &Sum{Distinct:$3, Arg:$4}
+    $$ = &Sum{Distinct:$3, Arg:$4, OverClause:$6}
   }
-| AVG openb distinct_opt expression closeb
+| AVG openb distinct_opt expression closeb over_clause_opt
   {
-    $$ = &Avg{Distinct:$3, Arg:$4}
+    $$ = &Avg{Distinct:$3, Arg:$4, OverClause:$6}
   }
 | BIT_AND openb expression closeb
   {
@@ -5957,6 +5963,14 @@ UTC_DATE func_paren_opt
      {
        $$ = &Variance{Arg:$3}
      }
+| JSON_ARRAYAGG openb expression closeb over_clause_opt
+  {
+    $$ = &JSONArrayAgg{Expr:$3, OverClause:$5}
+  }
+| JSON_OBJECTAGG openb expression ',' expression closeb over_clause_opt
+  {
+    $$ = &JSONObjectAgg{Key:$3, Value:$5, OverClause:$7}
+  }
 | GROUP_CONCAT openb distinct_opt expression_list order_by_opt separator_opt limit_opt closeb
   {
     $$ = &GroupConcatExpr{Distinct: $3, Exprs: $4, OrderBy: $5, Separator: $6, Limit: $7}
@@ -7685,6 +7699,7 @@ non_reserved_keyword:
 | ISOLATION
 | JSON
 | JSON_ARRAY %prec FUNCTION_CALL_NON_KEYWORD
+| JSON_ARRAYAGG %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_ARRAY_APPEND %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_ARRAY_INSERT %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_CONTAINS %prec FUNCTION_CALL_NON_KEYWORD
@@ -7697,6 +7712,7 @@ non_reserved_keyword:
 | JSON_MERGE_PATCH %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_MERGE_PRESERVE %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_OBJECT %prec FUNCTION_CALL_NON_KEYWORD
+| JSON_OBJECTAGG %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_OVERLAPS %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_PRETTY %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_QUOTE %prec FUNCTION_CALL_NON_KEYWORD
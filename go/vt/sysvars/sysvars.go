@@ -78,9 +78,10 @@ var (
 	VersionComment = SystemVariable{Name: "version_comment"}
 
 	// Read After Write settings
-	ReadAfterWriteGTID    = SystemVariable{Name: "read_after_write_gtid"}
-	ReadAfterWriteTimeOut = SystemVariable{Name: "read_after_write_timeout"}
-	SessionTrackGTIDs     = SystemVariable{Name: "session_track_gtids", IdentifierAsString: true}
+	ReadAfterWriteGTID        = SystemVariable{Name: "read_after_write_gtid"}
+	ReadAfterWriteTimeOut     = SystemVariable{Name: "read_after_write_timeout"}
+	ReadAfterWriteConsistency = SystemVariable{Name: "read_after_write_consistency", IsBoolean: true, Default: off}
+	SessionTrackGTIDs         = SystemVariable{Name: "session_track_gtids", IdentifierAsString: true}
 
 	VitessAware = []SystemVariable{
 		Autocommit,
@@ -98,6 +99,7 @@ var (
 		SessionEnableSystemSettings,
 		ReadAfterWriteGTID,
 		ReadAfterWriteTimeOut,
+		ReadAfterWriteConsistency,
 		SessionTrackGTIDs,
 		QueryTimeout,
 	}
@@ -55,6 +55,12 @@ func init() {
 		params: "<keyspace/shard> <backup name>",
 		help:   "Removes a backup for the BackupStorage.",
 	})
+	addCommand("Shards", command{
+		name:   "PruneBackups",
+		method: commandPruneBackups,
+		params: "[--dry_run] --policy=<policy> <keyspace/shard>",
+		help:   "Removes the backups of a shard that are not retained by the given policy, in the format 'full=3,daily=7,weekly=4': keep the 3 most recent full backups, plus the most recent full backup of each of the last 7 days and 4 weeks. The incremental backups that can be applied on top of a retained full backup are kept. With --dry_run, only lists the backups that would be removed.",
+	})
 	addCommand("Tablets", command{
 		name:   "Backup",
 		method: commandBackup,
@@ -64,8 +70,8 @@ func init() {
 	addCommand("Tablets", command{
 		name:   "RestoreFromBackup",
 		method: commandRestoreFromBackup,
-		params: "[--backup_timestamp=yyyy-MM-dd.HHmmss] [--restore_to_pos=<pos>] [--dry_run] <tablet alias>",
-		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time. If '--restore_to_pos' is given, then a point in time restore based on one full backup followed by zero or more incremental backups. dry-run only validates restore steps without actually restoring data",
+		params: "[--backup_timestamp=yyyy-MM-dd.HHmmss] [--restore_to_pos=<pos>] [--restore_to_timestamp=<RFC3339 timestamp>] [--dry_run] <tablet alias>",
+		help:   "Stops mysqld and restores the data from the latest backup or if a timestamp is specified then the most recent backup at or before that time. If '--restore_to_pos' or '--restore_to_timestamp' is given, then a point in time restore based on one full backup followed by zero or more incremental backups. dry-run only validates restore steps without actually restoring data",
 	})
 }
 
@@ -184,6 +190,40 @@ func commandRemoveBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *p
 	return err
 }
 
+func commandPruneBackups(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.FlagSet, args []string) error {
+	policyStr := subFlags.String("policy", "", "The backup retention policy, e.g. 'full=3,daily=7,weekly=4'")
+	dryRun := subFlags.Bool("dry_run", false, "Only lists the backups that would be removed")
+
+	if err := subFlags.Parse(args); err != nil {
+		return err
+	}
+	if subFlags.NArg() != 1 {
+		return fmt.Errorf("action PruneBackups requires <keyspace/shard>")
+	}
+	if *policyStr == "" {
+		return fmt.Errorf("action PruneBackups requires --policy")
+	}
+
+	keyspace, shard, err := topoproto.ParseKeyspaceShard(subFlags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	resp, err := wr.VtctldServer().PruneBackups(ctx, &vtctldatapb.PruneBackupsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+		Policy:   *policyStr,
+		DryRun:   *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range resp.Backups {
+		wr.Logger().Printf("%v\n", name)
+	}
+	return nil
+}
+
 // backupRestoreEventStreamLogger takes backup restore events from the
 // vtctldserver and emits them via logutil.LogEvent, preserving legacy behavior.
 type backupRestoreEventStreamLogger struct {
@@ -202,6 +242,7 @@ func (b *backupRestoreEventStreamLogger) Send(resp *vtctldatapb.RestoreFromBacku
 func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFlags *pflag.FlagSet, args []string) error {
 	backupTimestampStr := subFlags.String("backup_timestamp", "", "Use the backup taken at or before this timestamp rather than using the latest backup.")
 	restoreToPos := subFlags.String("restore_to_pos", "", "Run a point in time recovery that ends with the given position. This will attempt to use one full backup followed by zero or more incremental backups")
+	restoreToTimestampStr := subFlags.String("restore_to_timestamp", "", "Run a point in time recovery that ends with the last transaction at or before the given timestamp, in RFC3339 format (e.g. '2006-01-02T15:04:05Z'). This will attempt to use one full backup followed by zero or more incremental backups")
 	dryRun := subFlags.Bool("dry_run", false, "Only validate restore steps, do not actually restore data")
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -222,6 +263,15 @@ func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFla
 		}
 	}
 
+	var restoreToTimestamp time.Time
+	if *restoreToTimestampStr != "" {
+		var err error
+		restoreToTimestamp, err = time.Parse(time.RFC3339, *restoreToTimestampStr)
+		if err != nil {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, fmt.Sprintf("unable to parse the restore timestamp value provided of '%s'", *restoreToTimestampStr))
+		}
+	}
+
 	tabletAlias, err := topoproto.ParseTabletAlias(subFlags.Arg(0))
 	if err != nil {
 		return err
@@ -236,6 +286,9 @@ func commandRestoreFromBackup(ctx context.Context, wr *wrangler.Wrangler, subFla
 	if !backupTime.IsZero() {
 		req.BackupTime = protoutil.TimeToProto(backupTime)
 	}
+	if !restoreToTimestamp.IsZero() {
+		req.RestoreToTimestamp = protoutil.TimeToProto(restoreToTimestamp)
+	}
 
 	return wr.VtctldServer().RestoreFromBackup(req, &backupRestoreEventStreamLogger{logger: wr.Logger(), ctx: ctx})
 }
@@ -37,6 +37,7 @@ import (
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
@@ -60,6 +61,7 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
+	tabletserverschema "vitess.io/vitess/go/vt/vttablet/tabletserver/schema"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -2339,6 +2341,40 @@ func (s *VtctldServer) PlannedReparentShard(ctx context.Context, req *vtctldatap
 	return resp, err
 }
 
+// PruneBackups is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) PruneBackups(ctx context.Context, req *vtctldatapb.PruneBackupsRequest) (resp *vtctldatapb.PruneBackupsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.PruneBackups")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("shard", req.Shard)
+	span.Annotate("policy", req.Policy)
+	span.Annotate("dry_run", req.DryRun)
+
+	policy, err := mysqlctl.ParseBackupRetentionPolicy(req.Policy)
+	if err != nil {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "%v", err)
+		return nil, err
+	}
+
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer bs.Close()
+
+	pruned, err := mysqlctl.PruneBackups(ctx, bs, req.Keyspace, req.Shard, policy, req.DryRun, logutil.NewConsoleLogger())
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.PruneBackupsResponse{
+		Backups: pruned,
+	}, nil
+}
+
 // RebuildKeyspaceGraph is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RebuildKeyspaceGraph(ctx context.Context, req *vtctldatapb.RebuildKeyspaceGraphRequest) (resp *vtctldatapb.RebuildKeyspaceGraphResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RebuildKeyspaceGraph")
@@ -2708,6 +2744,200 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
+// RequeueDeadLetterMessages is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RequeueDeadLetterMessages(ctx context.Context, req *vtctldatapb.RequeueDeadLetterMessagesRequest) (resp *vtctldatapb.RequeueDeadLetterMessagesResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RequeueDeadLetterMessages")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("table", req.Table)
+	span.Annotate("num_ids", len(req.Ids))
+
+	if req.Keyspace == "" || req.Table == "" {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "keyspace and table must be set")
+		return nil, err
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.RequeueDeadLetterMessagesResponse{
+		RowsRequeuedByShard: make(map[string]uint64, len(shards)),
+	}
+
+	// The shards are requeued serially, and we stop at the first failure. Requeuing
+	// is idempotent, so the command can simply be run again.
+	for _, shard := range shards {
+		rows, err := s.requeueDeadLetterMessages(ctx, req.Keyspace, shard, req.Table, req.Ids)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.RowsRequeuedByShard[shard] = rows
+	}
+
+	return resp, nil
+}
+
+// requeueDeadLetterMessages moves the messages of the dead letter table of a message table back to the
+// message table on the primary of a shard, and returns the number of messages requeued. The messages are
+// moved in batches, each batch being inserted and removed from the dead letter table in one transaction.
+func (s *VtctldServer) requeueDeadLetterMessages(ctx context.Context, keyspace string, shard string, table string, ids []string) (uint64, error) {
+	si, err := s.ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		return 0, err
+	}
+
+	if !si.HasPrimary() {
+		return 0, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "no primary tablet for shard %v/%v", keyspace, shard)
+	}
+
+	primary, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return 0, fmt.Errorf("cannot lookup primary tablet %v for shard %v/%v: %w", topoproto.TabletAliasString(si.PrimaryAlias), keyspace, shard, err)
+	}
+
+	sd, err := s.tmc.GetSchema(ctx, primary.Tablet, &tabletmanagerdatapb.GetSchemaRequest{Tables: []string{table}})
+	if err != nil {
+		return 0, err
+	}
+
+	var td *tabletmanagerdatapb.TableDefinition
+	for _, t := range sd.TableDefinitions {
+		if t.Name == table {
+			td = t
+			break
+		}
+	}
+
+	if td == nil {
+		return 0, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "table %v not found on %v", table, topoproto.TabletAliasString(primary.Alias))
+	}
+
+	deadLetterTable, err := messageDeadLetterTable(td)
+	if err != nil {
+		return 0, err
+	}
+
+	if deadLetterTable == "" {
+		return 0, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "table %v has no vt_dead_letter_table", table)
+	}
+
+	var rows uint64
+	for {
+		qr, err := s.tmc.ExecuteFetchAsDba(ctx, primary.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(selectDeadLetterMessageIDsQuery(deadLetterTable, ids)),
+			DbName:  topoproto.TabletDbName(primary.Tablet),
+			MaxRows: requeueDeadLetterMessagesBatchSize,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("cannot read dead letter messages of %v on %v: %w", table, topoproto.TabletAliasString(primary.Alias), err)
+		}
+
+		batch := make([]string, 0, len(qr.Rows))
+		for _, row := range sqltypes.Proto3ToResult(qr).Rows {
+			batch = append(batch, row[0].ToString())
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		if _, err := s.tmc.ExecuteFetchAsDba(ctx, primary.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:  []byte(requeueDeadLetterMessagesQuery(table, deadLetterTable, td.Columns, batch)),
+			DbName: topoproto.TabletDbName(primary.Tablet),
+		}); err != nil {
+			return 0, fmt.Errorf("cannot requeue dead letter messages of %v on %v: %w", table, topoproto.TabletAliasString(primary.Alias), err)
+		}
+
+		rows += uint64(len(batch))
+		if len(batch) < requeueDeadLetterMessagesBatchSize {
+			break
+		}
+	}
+
+	return rows, nil
+}
+
+// messageDeadLetterTable returns the dead letter table of a message table, which is given by its comment.
+func messageDeadLetterTable(td *tabletmanagerdatapb.TableDefinition) (string, error) {
+	stmt, err := sqlparser.ParseStrictDDL(td.Schema)
+	if err != nil {
+		return "", err
+	}
+
+	create, ok := stmt.(*sqlparser.CreateTable)
+	if !ok || create.TableSpec == nil {
+		return "", vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "%v is not a table", td.Name)
+	}
+
+	for _, option := range create.TableSpec.Options {
+		if strings.EqualFold(option.Name, "comment") && option.Value != nil {
+			return tabletserverschema.MessageDeadLetterTable(option.Value.Val), nil
+		}
+	}
+
+	return "", nil
+}
+
+// requeueDeadLetterMessagesBatchSize is the number of messages moved back to the message table per transaction.
+const requeueDeadLetterMessagesBatchSize = 1000
+
+// selectDeadLetterMessageIDsQuery returns the query reading the ids of the next batch of messages of the
+// dead letter table to requeue, restricted to the given ids if there are any.
+func selectDeadLetterMessageIDsQuery(deadLetterTable string, ids []string) string {
+	where := ""
+	if len(ids) > 0 {
+		where = " where id in " + encodeIDs(ids)
+	}
+
+	return fmt.Sprintf("select id from %s%s order by id limit %d", sqlescape.EscapeID(deadLetterTable), where, requeueDeadLetterMessagesBatchSize)
+}
+
+// requeueDeadLetterMessagesQuery returns the transaction that moves the messages with the given ids from
+// the dead letter table back to the message table. The messages are reset so that they are sent right
+// away and get all of their retries again. A message that is in the message table already fails the whole
+// transaction, rather than being dropped from the dead letter table without being requeued.
+func requeueDeadLetterMessagesQuery(table string, deadLetterTable string, columns []string, ids []string) string {
+	insertColumns := make([]string, 0, len(columns))
+	selectColumns := make([]string, 0, len(columns))
+	for _, column := range columns {
+		insertColumns = append(insertColumns, sqlescape.EscapeID(column))
+		switch strings.ToLower(column) {
+		case "epoch":
+			selectColumns = append(selectColumns, "0")
+		case "time_next":
+			selectColumns = append(selectColumns, fmt.Sprintf("%d", time.Now().UnixNano()))
+		case "time_acked":
+			selectColumns = append(selectColumns, "null")
+		default:
+			selectColumns = append(selectColumns, sqlescape.EscapeID(column))
+		}
+	}
+
+	where := " where id in " + encodeIDs(ids)
+	return strings.Join([]string{
+		"begin",
+		fmt.Sprintf("insert into %s (%s) select %s from %s%s",
+			sqlescape.EscapeID(table), strings.Join(insertColumns, ", "), strings.Join(selectColumns, ", "), sqlescape.EscapeID(deadLetterTable), where),
+		fmt.Sprintf("delete from %s%s", sqlescape.EscapeID(deadLetterTable), where),
+		"commit",
+	}, "; ")
+}
+
+func encodeIDs(ids []string) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, sqltypes.EncodeStringSQL(id))
+	}
+
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
 func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
 	defer span.Finish()
@@ -2729,9 +2959,10 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	span.Annotate("shard", ti.Shard)
 
 	r := &tabletmanagerdatapb.RestoreFromBackupRequest{
-		BackupTime:   req.BackupTime,
-		RestoreToPos: req.RestoreToPos,
-		DryRun:       req.DryRun,
+		BackupTime:         req.BackupTime,
+		RestoreToPos:       req.RestoreToPos,
+		RestoreToTimestamp: req.RestoreToTimestamp,
+		DryRun:             req.DryRun,
 	}
 	logStream, err := s.tmc.RestoreFromBackup(ctx, ti.Tablet, r)
 	if err != nil {
@@ -2760,7 +2991,7 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 			if mysqlctl.DisableActiveReparents {
 				return nil
 			}
-			if req.RestoreToPos != "" && !req.DryRun {
+			if (req.RestoreToPos != "" || req.RestoreToTimestamp != nil) && !req.DryRun {
 				// point in time recovery. Do not restore replication
 				return nil
 			}
@@ -6374,6 +6374,79 @@ func TestPlannedReparentShard(t *testing.T) {
 	}
 }
 
+func TestPruneBackups(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer()
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	now := time.Now().UTC()
+	setup := func() {
+		testutil.BackupStorage.Backups = map[string][]string{
+			"testkeyspace/-": {"backup1", "backup2", "backup3", "backup4"},
+		}
+		testutil.BackupStorage.Files = map[string]string{}
+		for i, name := range []string{"backup1", "backup2", "backup3"} {
+			backupTime := now.Add(-time.Duration(3-i) * time.Hour).Format(time.RFC3339)
+			testutil.BackupStorage.Files["testkeyspace/-/"+name+"/MANIFEST"] = fmt.Sprintf(`{"BackupTime": %q}`, backupTime)
+		}
+		// backup4 has no MANIFEST, it may be in progress
+	}
+	defer func() {
+		testutil.BackupStorage.Files = nil
+	}()
+	backupNames := func() []string {
+		resp, err := vtctld.GetBackups(ctx, &vtctldatapb.GetBackupsRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+		})
+		require.NoError(t, err)
+
+		var names []string
+		for _, bi := range resp.Backups {
+			names = append(names, bi.Name)
+		}
+		return names
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		setup()
+		resp, err := vtctld.PruneBackups(ctx, &vtctldatapb.PruneBackupsRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Policy:   "full=2",
+			DryRun:   true,
+		})
+		require.NoError(t, err)
+		utils.MustMatch(t, []string{"backup1"}, resp.Backups)
+		utils.MustMatch(t, []string{"backup1", "backup2", "backup3", "backup4"}, backupNames(), "expected no backup to be removed")
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		setup()
+		resp, err := vtctld.PruneBackups(ctx, &vtctldatapb.PruneBackupsRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Policy:   "full=1",
+		})
+		require.NoError(t, err)
+		utils.MustMatch(t, []string{"backup1", "backup2"}, resp.Backups)
+		utils.MustMatch(t, []string{"backup3", "backup4"}, backupNames(), "expected \"backup1\" and \"backup2\" to be removed")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		setup()
+		_, err := vtctld.PruneBackups(ctx, &vtctldatapb.PruneBackupsRequest{
+			Keyspace: "testkeyspace",
+			Shard:    "-",
+			Policy:   "full=0",
+		})
+		assert.Error(t, err)
+		utils.MustMatch(t, []string{"backup1", "backup2", "backup3", "backup4"}, backupNames(), "expected no backup to be removed")
+	})
+}
+
 func TestRebuildKeyspaceGraph(t *testing.T) {
 	t.Parallel()
 
@@ -8258,6 +8331,184 @@ func TestReparentTablet(t *testing.T) {
 	}
 }
 
+func TestRequeueDeadLetterMessages(t *testing.T) {
+	t.Parallel()
+
+	messageSchema := func(comment string) *tabletmanagerdatapb.SchemaDefinition {
+		return &tabletmanagerdatapb.SchemaDefinition{
+			TableDefinitions: []*tabletmanagerdatapb.TableDefinition{
+				{
+					Name:    "msg",
+					Schema:  "CREATE TABLE `msg` (`id` bigint NOT NULL, `time_next` bigint, `epoch` bigint, `time_acked` bigint, `message` varchar(128), PRIMARY KEY (`id`)) COMMENT='" + comment + "'",
+					Columns: []string{"id", "time_next", "epoch", "time_acked", "message"},
+				},
+			},
+		}
+	}
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  200,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.RequeueDeadLetterMessagesRequest
+		expected  *vtctldatapb.RequeueDeadLetterMessagesResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: &testutil.TabletManagerClient{
+				GetSchemaResults: map[string]struct {
+					Schema *tabletmanagerdatapb.SchemaDefinition
+					Error  error
+				}{
+					"zone1-0000000100": {
+						Schema: messageSchema("vitess_message,vt_max_retries=3,vt_dead_letter_table=msg_dead"),
+					},
+					"zone1-0000000200": {
+						Schema: messageSchema("vitess_message,vt_max_retries=3,vt_dead_letter_table=msg_dead"),
+					},
+				},
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")),
+					},
+					"zone1-0000000200": {
+						Response: &querypb.QueryResult{},
+					},
+				},
+			},
+			req: &vtctldatapb.RequeueDeadLetterMessagesRequest{
+				Keyspace: "testkeyspace",
+				Table:    "msg",
+			},
+			expected: &vtctldatapb.RequeueDeadLetterMessagesResponse{
+				RowsRequeuedByShard: map[string]uint64{
+					"-80": 2,
+					"80-": 0,
+				},
+			},
+		},
+		{
+			name: "no dead letter table",
+			tmc: &testutil.TabletManagerClient{
+				GetSchemaResults: map[string]struct {
+					Schema *tabletmanagerdatapb.SchemaDefinition
+					Error  error
+				}{
+					"zone1-0000000100": {
+						Schema: messageSchema("vitess_message"),
+					},
+					"zone1-0000000200": {
+						Schema: messageSchema("vitess_message"),
+					},
+				},
+			},
+			req: &vtctldatapb.RequeueDeadLetterMessagesRequest{
+				Keyspace: "testkeyspace",
+				Table:    "msg",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "query error",
+			tmc: &testutil.TabletManagerClient{
+				GetSchemaResults: map[string]struct {
+					Schema *tabletmanagerdatapb.SchemaDefinition
+					Error  error
+				}{
+					"zone1-0000000100": {
+						Schema: messageSchema("vitess_message,vt_max_retries=3,vt_dead_letter_table=msg_dead"),
+					},
+					"zone1-0000000200": {
+						Schema: messageSchema("vitess_message,vt_max_retries=3,vt_dead_letter_table=msg_dead"),
+					},
+				},
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			req: &vtctldatapb.RequeueDeadLetterMessagesRequest{
+				Keyspace: "testkeyspace",
+				Table:    "msg",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no table",
+			tmc:  &testutil.TabletManagerClient{},
+			req: &vtctldatapb.RequeueDeadLetterMessagesRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, tablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			resp, err := vtctld.RequeueDeadLetterMessages(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestRequeueDeadLetterMessagesQueries(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "select id from `msg_dead` order by id limit 1000", selectDeadLetterMessageIDsQuery("msg_dead", nil))
+	assert.Equal(t, "select id from `msg_dead` where id in ('1', 'it\\'s') order by id limit 1000", selectDeadLetterMessageIDsQuery("msg_dead", []string{"1", "it's"}))
+
+	query := requeueDeadLetterMessagesQuery("msg", "msg_dead", []string{"id", "time_next", "epoch", "time_acked", "message"}, []string{"1", "it's"})
+	assert.Regexp(t, "^begin; insert into `msg` \\(`id`, `time_next`, `epoch`, `time_acked`, `message`\\) select `id`, \\d+, 0, null, `message` from `msg_dead` where id in \\('1', 'it\\\\'s'\\); delete from `msg_dead` where id in \\('1', 'it\\\\'s'\\); commit$", query)
+
+	query = requeueDeadLetterMessagesQuery("msg", "msg_dead", []string{"id"}, []string{"1"})
+	assert.Equal(t, "begin; insert into `msg` (`id`) select `id` from `msg_dead` where id in ('1'); delete from `msg_dead` where id in ('1'); commit", query)
+}
+
 func TestRestoreFromBackup(t *testing.T) {
 	ctx := context.Background()
 	tests := []struct {
@@ -19,7 +19,10 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"io"
+	"path"
 	"sort"
+	"strings"
 
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 )
@@ -32,6 +35,9 @@ type backupStorage struct {
 	Backups map[string][]string
 	// ListBackupsError is returned from ListBackups when it is non-nil.
 	ListBackupsError error
+	// Files is a mapping of "<directory>/<backup name>/<file name>" to the
+	// contents of the files of the backups, as returned by ReadFile.
+	Files map[string]string
 }
 
 // ListBackups is part of the backupstorage.BackupStorage interface.
@@ -45,7 +51,7 @@ func (bs *backupStorage) ListBackups(ctx context.Context, dir string) ([]backups
 	for k, v := range bs.Backups {
 		if k == dir {
 			for _, name := range v {
-				handles = append(handles, &backupHandle{directory: k, name: name})
+				handles = append(handles, &backupHandle{directory: k, name: name, files: bs.Files})
 			}
 		}
 	}
@@ -87,11 +93,22 @@ type backupHandle struct {
 
 	directory string
 	name      string
+	files     map[string]string
 }
 
 func (bh *backupHandle) Directory() string { return bh.directory }
 func (bh *backupHandle) Name() string      { return bh.name }
 
+// ReadFile is part of the backupstorage.BackupHandle interface.
+func (bh *backupHandle) ReadFile(ctx context.Context, filename string) (io.ReadCloser, error) {
+	contents, ok := bh.files[path.Join(bh.directory, bh.name, filename)]
+	if !ok {
+		return nil, fmt.Errorf("no file %s in backup %s/%s", filename, bh.directory, bh.name)
+	}
+
+	return io.NopCloser(strings.NewReader(contents)), nil
+}
+
 // handlesByName implements the sort interface for backup handles by Name().
 type handlesByName []backupstorage.BackupHandle
 
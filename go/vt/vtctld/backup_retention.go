@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/timer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+)
+
+// This file enforces the backup retention policies: it periodically
+// removes the backups of every shard that its policy doesn't retain.
+
+var (
+	backupRetentionPolicies []string
+	backupRetentionInterval time.Duration
+	backupRetentionDryRun   bool
+
+	backupRetentionPruned = stats.NewCountersWithMultiLabels(
+		"BackupRetentionPruned",
+		"Number of backups removed because they were not retained by the backup retention policy",
+		[]string{"Keyspace", "Shard"})
+	backupRetentionDryRunBackups = stats.NewGaugesWithMultiLabels(
+		"BackupRetentionDryRunBackups",
+		"Number of backups that would be removed because they are not retained by the backup retention policy, at the last dry run",
+		[]string{"Keyspace", "Shard"})
+	backupRetentionErrors = stats.NewCountersWithMultiLabels(
+		"BackupRetentionErrors",
+		"Number of errors while enforcing the backup retention policy",
+		[]string{"Keyspace", "Shard"})
+)
+
+func init() {
+	for _, cmd := range []string{"vtcombo", "vtctld"} {
+		servenv.OnParseFor(cmd, registerBackupRetentionFlags)
+	}
+}
+
+func registerBackupRetentionFlags(fs *pflag.FlagSet) {
+	fs.StringArrayVar(&backupRetentionPolicies, "backup_retention_policy", backupRetentionPolicies, "Backup retention policy, in the format '[<keyspace>[/<shard>]:]full=<count>,daily=<count>,weekly=<count>': keep the most recent full backups, plus the most recent full backup of each of the last days and weeks. Without a keyspace or shard, applies to all shards. Can be repeated, the most specific policy applies. The shards without a policy are not pruned.")
+	fs.DurationVar(&backupRetentionInterval, "backup_retention_interval", backupRetentionInterval, "How often to remove the backups that are not retained by the --backup_retention_policy. 0 disables the pruning.")
+	fs.BoolVar(&backupRetentionDryRun, "backup_retention_dry_run", backupRetentionDryRun, "Only log the backups that are not retained by the --backup_retention_policy, instead of removing them.")
+}
+
+// backupRetention holds the backup retention policies, by keyspace and shard.
+type backupRetention struct {
+	defaultPolicy *mysqlctl.BackupRetentionPolicy
+	keyspaces     map[string]*mysqlctl.BackupRetentionPolicy
+	// shards are indexed by "<keyspace>/<shard>".
+	shards map[string]*mysqlctl.BackupRetentionPolicy
+}
+
+// parseBackupRetentionPolicies parses the values of --backup_retention_policy.
+func parseBackupRetentionPolicies(specs []string) (*backupRetention, error) {
+	br := &backupRetention{
+		keyspaces: make(map[string]*mysqlctl.BackupRetentionPolicy),
+		shards:    make(map[string]*mysqlctl.BackupRetentionPolicy),
+	}
+	for _, spec := range specs {
+		target, policyStr, hasTarget := strings.Cut(spec, ":")
+		if !hasTarget {
+			policyStr = target
+		}
+		policy, err := mysqlctl.ParseBackupRetentionPolicy(policyStr)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !hasTarget:
+			br.defaultPolicy = policy
+		case strings.Contains(target, "/"):
+			keyspace, shard, err := topoproto.ParseKeyspaceShard(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid backup retention policy %q: %v", spec, err)
+			}
+			br.shards[topoproto.KeyspaceShardString(keyspace, shard)] = policy
+		default:
+			br.keyspaces[target] = policy
+		}
+	}
+	return br, nil
+}
+
+// policy returns the policy of a shard, or nil if its backups are not pruned.
+func (br *backupRetention) policy(keyspace, shard string) *mysqlctl.BackupRetentionPolicy {
+	if policy, ok := br.shards[topoproto.KeyspaceShardString(keyspace, shard)]; ok {
+		return policy
+	}
+	if policy, ok := br.keyspaces[keyspace]; ok {
+		return policy
+	}
+	return br.defaultPolicy
+}
+
+// pruneBackups removes the backups of all the shards that their policy doesn't retain.
+func (br *backupRetention) pruneBackups(ctx context.Context, ts *topo.Server) {
+	bs, err := backupstorage.GetBackupStorage()
+	if err != nil {
+		log.Errorf("Backup retention: can't get backup storage: %v", err)
+		backupRetentionErrors.Add([]string{"", ""}, 1)
+		return
+	}
+	defer bs.Close()
+
+	keyspaces, err := ts.GetKeyspaces(ctx)
+	if err != nil {
+		log.Errorf("Backup retention: can't list keyspaces: %v", err)
+		backupRetentionErrors.Add([]string{"", ""}, 1)
+		return
+	}
+	logger := logutil.NewConsoleLogger()
+	for _, keyspace := range keyspaces {
+		shards, err := ts.GetShardNames(ctx, keyspace)
+		if err != nil {
+			log.Errorf("Backup retention: can't list shards of keyspace %v: %v", keyspace, err)
+			backupRetentionErrors.Add([]string{keyspace, ""}, 1)
+			continue
+		}
+		for _, shard := range shards {
+			policy := br.policy(keyspace, shard)
+			if policy == nil {
+				continue
+			}
+			labels := []string{keyspace, shard}
+			pruned, err := mysqlctl.PruneBackups(ctx, bs, keyspace, shard, policy, backupRetentionDryRun, logger)
+			if err != nil {
+				log.Errorf("Backup retention: can't prune backups of %v: %v", topoproto.KeyspaceShardString(keyspace, shard), err)
+				backupRetentionErrors.Add(labels, 1)
+			}
+			if backupRetentionDryRun {
+				backupRetentionDryRunBackups.Set(labels, int64(len(pruned)))
+			} else {
+				backupRetentionPruned.Add(labels, int64(len(pruned)))
+			}
+		}
+	}
+}
+
+// initBackupRetention starts enforcing the backup retention policies, if enabled.
+func initBackupRetention(ts *topo.Server) error {
+	if backupRetentionInterval == 0 {
+		return nil
+	}
+	br, err := parseBackupRetentionPolicies(backupRetentionPolicies)
+	if err != nil {
+		return err
+	}
+	log.Infof("Backup retention: pruning the backups every %v (dry run: %v)", backupRetentionInterval, backupRetentionDryRun)
+	t := timer.NewTimer(backupRetentionInterval)
+	t.Start(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backupRetentionInterval)
+		defer cancel()
+		br.pruneBackups(ctx, ts)
+	})
+	servenv.OnClose(t.Stop)
+	return nil
+}
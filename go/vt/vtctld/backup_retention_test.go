@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtctld
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/mysqlctl"
+)
+
+func TestBackupRetentionPolicies(t *testing.T) {
+	br, err := parseBackupRetentionPolicies(nil)
+	require.NoError(t, err)
+	assert.Nil(t, br.policy("ks1", "0"))
+
+	br, err = parseBackupRetentionPolicies([]string{
+		"full=3,daily=7,weekly=4",
+		"ks1:full=2",
+		"ks1/-80:full=5,daily=1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &mysqlctl.BackupRetentionPolicy{Full: 3, Daily: 7, Weekly: 4}, br.policy("ks2", "0"))
+	assert.Equal(t, &mysqlctl.BackupRetentionPolicy{Full: 2}, br.policy("ks1", "80-"))
+	assert.Equal(t, &mysqlctl.BackupRetentionPolicy{Full: 5, Daily: 1}, br.policy("ks1", "-80"))
+
+	br, err = parseBackupRetentionPolicies([]string{"ks1:full=2"})
+	require.NoError(t, err)
+	assert.Nil(t, br.policy("ks2", "0"))
+
+	_, err = parseBackupRetentionPolicies([]string{"ks1:full=0"})
+	assert.ErrorContains(t, err, "at least one full backup must be kept")
+	_, err = parseBackupRetentionPolicies([]string{"ks1/-80:daily"})
+	assert.ErrorContains(t, err, "expected <name>=<count>")
+}
@@ -148,5 +148,6 @@ func InitVtctld(ts *topo.Server) error {
 	// Serve the topology endpoint in the REST API at /topodata
 	initExplorer(ts)
 
-	return nil
+	// Prune the backups that are not retained by the backup retention policies
+	return initBackupRetention(ts)
 }
@@ -46,6 +46,7 @@ var (
 	VT03021 = errorWithoutState("VT03021", vtrpcpb.Code_INVALID_ARGUMENT, "ambiguous symbol reference: %v", "The given symbol is ambiguous. You can use a table qualifier to make it unambiguous.")
 	VT03022 = errorWithoutState("VT03022", vtrpcpb.Code_INVALID_ARGUMENT, "column %v not found in %v", "The given column cannot be found.")
 	VT03023 = errorWithoutState("VT03023", vtrpcpb.Code_INVALID_ARGUMENT, "INSERT not supported when targeting a key range: %s", "When targeting a range of shards, Vitess does not know which shard to send the INSERT to.")
+	VT03024 = errorWithoutState("VT03024", vtrpcpb.Code_INVALID_ARGUMENT, "invalid message delivery time: %s", "The DELIVER_AT directive takes an RFC 3339 timestamp and the DELIVER_AFTER directive a duration such as 30s. Only one of them can be used, on an INSERT of values into a message table that does not set time_next itself.")
 
 	VT05001 = errorWithState("VT05001", vtrpcpb.Code_NOT_FOUND, DbDropExists, "cannot drop database '%s'; database does not exists", "The given database does not exist; Vitess cannot drop it.")
 	VT05002 = errorWithState("VT05002", vtrpcpb.Code_NOT_FOUND, BadDb, "cannot alter database '%s'; unknown database", "The given database does not exist; Vitess cannot alter it.")
@@ -69,8 +70,10 @@ var (
 	VT09010 = errorWithoutState("VT09010", vtrpcpb.Code_FAILED_PRECONDITION, "SHOW VITESS_THROTTLER STATUS works only on primary tablet", "SHOW VITESS_THROTTLER STATUS works only on primary tablet.")
 
 	VT10001 = errorWithoutState("VT10001", vtrpcpb.Code_ABORTED, "foreign key constraints are not allowed", "Foreign key constraints are not allowed, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/.")
+	VT10002 = errorWithState("VT10002", vtrpcpb.Code_FAILED_PRECONDITION, RowIsReferenced2, "cannot delete or update a parent row: a foreign key constraint fails on table '%s'", "The row is still referenced by a child row in another keyspace, and the foreign key does not cascade the change.")
 
 	VT12001 = errorWithoutState("VT12001", vtrpcpb.Code_UNIMPLEMENTED, "unsupported: %s", "This statement is unsupported by Vitess. Please rewrite your query to use supported syntax.")
+	VT12002 = errorWithoutState("VT12002", vtrpcpb.Code_UNIMPLEMENTED, "unsupported: cross-shard foreign keys", "Vitess does not support cross shard foreign keys. The parent and child rows of a foreign key need to be routed by the same vindex.")
 
 	// VT13001 General Error
 	VT13001 = errorWithoutState("VT13001", vtrpcpb.Code_INTERNAL, "[BUG] %s", "This error should not happen and is a bug. Please file an issue on GitHub: https://github.com/vitessio/vitess/issues/new/choose.")
@@ -105,6 +108,7 @@ var (
 		VT03021,
 		VT03022,
 		VT03023,
+		VT03024,
 		VT05001,
 		VT05002,
 		VT05003,
@@ -124,7 +128,9 @@ var (
 		VT09009,
 		VT09010,
 		VT10001,
+		VT10002,
 		VT12001,
+		VT12002,
 		VT13001,
 		VT13002,
 		VT14001,
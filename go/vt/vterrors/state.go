@@ -54,6 +54,7 @@ const (
 	CantDoThisInTransaction
 	RequiresPrimaryKey
 	OperandColumns
+	RowIsReferenced2
 
 	// not found
 	BadDb
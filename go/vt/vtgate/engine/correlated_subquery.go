@@ -0,0 +1,383 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*CorrelatedSubquery)(nil)
+
+// CorrelatedSubquery executes a subquery that depends on the rows of the outer query.
+// The outer rows are fetched first, and the subquery is executed with the columns
+// listed in Vars bound as bind variables, once per distinct combination of their values.
+// Like for a PulloutSubquery, the subquery result is then exposed to the row through
+// the SubqueryResult and HasValues bind variables, for Predicate and Exprs to use.
+type CorrelatedSubquery struct {
+	Opcode PulloutOpcode
+
+	// SubqueryResult and HasValues are used to send in the subquery result
+	// when evaluating the predicate and the expressions.
+	SubqueryResult string
+	HasValues      string
+
+	Outer    Primitive `json:",omitempty"`
+	Subquery Primitive `json:",omitempty"`
+
+	// Vars are the columns of the outer rows that are bound
+	// as bind variables when executing the subquery.
+	Vars map[string]int `json:",omitempty"`
+
+	// Predicate, when set, is evaluated on every outer row, and only the rows
+	// for which it is true are returned.
+	Predicate evalengine.Expr `json:",omitempty"`
+
+	// Cols defines the returned columns. The columns of the outer
+	// rows go as -1, -2, etc. and a zero stands for the next
+	// expression of Exprs.
+	Cols []int `json:",omitempty"`
+
+	// Exprs are the columns that use the subquery result. They are evaluated on the outer rows.
+	Exprs []evalengine.Expr `json:",omitempty"`
+	// ExprNames are the names of the columns of Exprs.
+	ExprNames []string `json:",omitempty"`
+}
+
+// RouteType implements the Primitive interface
+func (cs *CorrelatedSubquery) RouteType() string {
+	return cs.Opcode.String()
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (cs *CorrelatedSubquery) GetKeyspaceName() string {
+	return cs.Outer.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (cs *CorrelatedSubquery) GetTableName() string {
+	return cs.Outer.GetTableName()
+}
+
+// Inputs implements the Primitive interface
+func (cs *CorrelatedSubquery) Inputs() []Primitive {
+	return []Primitive{cs.Outer, cs.Subquery}
+}
+
+// NeedsTransaction implements the Primitive interface
+func (cs *CorrelatedSubquery) NeedsTransaction() bool {
+	return cs.Outer.NeedsTransaction() || cs.Subquery.NeedsTransaction()
+}
+
+// TryExecute implements the Primitive interface
+func (cs *CorrelatedSubquery) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	oresult, err := vcursor.ExecutePrimitive(ctx, cs.Outer, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+	result := &sqltypes.Result{}
+	if wantfields {
+		result.Fields, err = cs.fields(ctx, vcursor, bindVars, oresult.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	loop := cs.newNestedLoop(ctx, vcursor, bindVars)
+	result.Rows, err = loop.process(oresult.Rows)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (cs *CorrelatedSubquery) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	loop := cs.newNestedLoop(ctx, vcursor, bindVars)
+	fieldsSent := !wantfields
+	return vcursor.StreamExecutePrimitive(ctx, cs.Outer, bindVars, wantfields, func(oresult *sqltypes.Result) error {
+		result := &sqltypes.Result{}
+		if !fieldsSent && oresult.Fields != nil {
+			fields, err := cs.fields(ctx, vcursor, bindVars, oresult.Fields)
+			if err != nil {
+				return err
+			}
+			result.Fields = fields
+			fieldsSent = true
+		}
+		var err error
+		result.Rows, err = loop.process(oresult.Rows)
+		if err != nil {
+			return err
+		}
+		return callback(result)
+	})
+}
+
+// GetFields implements the Primitive interface
+func (cs *CorrelatedSubquery) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	oresult, err := cs.Outer.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := cs.fields(ctx, vcursor, bindVars, oresult.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: fields}, nil
+}
+
+// fields returns the fields of the output rows. The types of the expressions are
+// computed with the subquery result bound to an empty value of the subquery column type.
+func (cs *CorrelatedSubquery) fields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, ofields []*querypb.Field) ([]*querypb.Field, error) {
+	fields := make([]*querypb.Field, len(cs.Cols))
+	for i, index := range cs.Cols {
+		if index < 0 {
+			fields[i] = ofields[-index-1]
+		}
+	}
+	if len(cs.Exprs) == 0 {
+		return fields, nil
+	}
+
+	joinVars := make(map[string]*querypb.BindVariable, len(cs.Vars))
+	for k := range cs.Vars {
+		joinVars[k] = sqltypes.NullBindVariable
+	}
+	sqVars := combineVars(bindVars, joinVars)
+	sresult, err := cs.Subquery.GetFields(ctx, vcursor, sqVars)
+	if err != nil {
+		return nil, err
+	}
+	sqType := sqltypes.Null
+	if len(sresult.Fields) > 0 {
+		sqType = sresult.Fields[0].Type
+	}
+	switch cs.Opcode {
+	case PulloutValue:
+		sqVars[cs.SubqueryResult] = &querypb.BindVariable{Type: sqType}
+	case PulloutIn, PulloutNotIn:
+		sqVars[cs.SubqueryResult] = &querypb.BindVariable{Type: querypb.Type_TUPLE}
+		sqVars[cs.HasValues] = sqltypes.Int64BindVariable(0)
+	case PulloutExists:
+		sqVars[cs.HasValues] = sqltypes.Int64BindVariable(0)
+	}
+
+	env := evalengine.EnvWithBindVars(sqVars, vcursor.ConnCollation())
+	env.Fields = ofields
+	next := 0
+	for i, index := range cs.Cols {
+		if index != 0 {
+			continue
+		}
+		expr := cs.Exprs[next]
+		name := evalengine.FormatExpr(expr)
+		if next < len(cs.ExprNames) && cs.ExprNames[next] != "" {
+			name = cs.ExprNames[next]
+		}
+		next++
+		typ, err := env.TypeOf(expr)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = &querypb.Field{Name: name, Type: typ}
+	}
+	return fields, nil
+}
+
+// nestedLoop evaluates the rows of the outer query against the results of the subquery.
+type nestedLoop struct {
+	cs       *CorrelatedSubquery
+	ctx      context.Context
+	vcursor  VCursor
+	bindVars map[string]*querypb.BindVariable
+	env      *evalengine.ExpressionEnv
+	results  *correlationCache[map[string]*querypb.BindVariable]
+}
+
+func (cs *CorrelatedSubquery) newNestedLoop(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) *nestedLoop {
+	return &nestedLoop{
+		cs:       cs,
+		ctx:      ctx,
+		vcursor:  vcursor,
+		bindVars: bindVars,
+		env:      evalengine.EnvWithBindVars(bindVars, vcursor.ConnCollation()),
+		results:  newCorrelationCache[map[string]*querypb.BindVariable](cs.Vars),
+	}
+}
+
+// process returns the output rows for the given outer rows.
+func (nl *nestedLoop) process(rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	var out []sqltypes.Row
+	for _, orow := range rows {
+		sqVars, err := nl.results.get(orow, nl.execSubquery)
+		if err != nil {
+			return nil, err
+		}
+		nl.env.BindVars = sqVars
+		nl.env.Row = orow
+
+		if nl.cs.Predicate != nil {
+			res, err := nl.env.Evaluate(nl.cs.Predicate)
+			if err != nil {
+				return nil, err
+			}
+			keep, err := isTrue(res.Value())
+			if err != nil {
+				return nil, err
+			}
+			if !keep {
+				continue
+			}
+		}
+
+		row := make(sqltypes.Row, len(nl.cs.Cols))
+		next := 0
+		for i, index := range nl.cs.Cols {
+			if index < 0 {
+				row[i] = orow[-index-1]
+				continue
+			}
+			res, err := nl.env.Evaluate(nl.cs.Exprs[next])
+			if err != nil {
+				return nil, err
+			}
+			next++
+			row[i] = res.Value()
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// execSubquery runs the subquery for the given correlated values, and returns the
+// bind variables exposing its result to the outer row.
+func (nl *nestedLoop) execSubquery(joinVars map[string]*querypb.BindVariable) (map[string]*querypb.BindVariable, error) {
+	combinedVars := combineVars(nl.bindVars, joinVars)
+	result, err := nl.vcursor.ExecutePrimitive(nl.ctx, nl.cs.Subquery, combinedVars, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPulloutBindVars(nl.cs.Opcode, result, nl.cs.SubqueryResult, nl.cs.HasValues, combinedVars); err != nil {
+		return nil, err
+	}
+	return combinedVars, nil
+}
+
+func isTrue(v sqltypes.Value) (bool, error) {
+	if v.IsNull() {
+		return false, nil
+	}
+	i, err := v.ToInt64()
+	if err != nil {
+		return false, err
+	}
+	return i != 0, nil
+}
+
+// correlationCache memoizes the result of a subquery for every distinct
+// combination of the outer values bound as its bind variables, so that
+// the subquery is only executed once for every such combination.
+type correlationCache[T any] struct {
+	vars    map[string]int
+	names   []string
+	results map[string]T
+}
+
+func newCorrelationCache[T any](vars map[string]int) *correlationCache[T] {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &correlationCache[T]{
+		vars:    vars,
+		names:   names,
+		results: make(map[string]T),
+	}
+}
+
+// get returns the cached result for the values of the row, calling exec
+// with the bind variables built from the row when there is none yet.
+func (cc *correlationCache[T]) get(row sqltypes.Row, exec func(joinVars map[string]*querypb.BindVariable) (T, error)) (T, error) {
+	var key strings.Builder
+	for _, name := range cc.names {
+		v := row[cc.vars[name]]
+		key.WriteString(strconv.Itoa(int(v.Type())))
+		key.WriteByte(':')
+		key.WriteString(strconv.Itoa(v.Len()))
+		key.WriteByte(':')
+		key.Write(v.Raw())
+	}
+	if res, ok := cc.results[key.String()]; ok {
+		return res, nil
+	}
+	joinVars := make(map[string]*querypb.BindVariable, len(cc.vars))
+	for name, col := range cc.vars {
+		joinVars[name] = sqltypes.ValueBindVariable(row[col])
+	}
+	res, err := exec(joinVars)
+	if err != nil {
+		return res, err
+	}
+	cc.results[key.String()] = res
+	return res, nil
+}
+
+func (cs *CorrelatedSubquery) description() PrimitiveDescription {
+	other := map[string]any{
+		"ProjectedIndexes": strings.Trim(strings.Join(strings.Fields(fmt.Sprint(cs.Cols)), ","), "[]"),
+	}
+	if len(cs.Vars) > 0 {
+		other["JoinVars"] = orderedStringIntMap(cs.Vars)
+	}
+	var pulloutVars []string
+	if cs.HasValues != "" {
+		pulloutVars = append(pulloutVars, cs.HasValues)
+	}
+	if cs.SubqueryResult != "" {
+		pulloutVars = append(pulloutVars, cs.SubqueryResult)
+	}
+	if len(pulloutVars) > 0 {
+		other["PulloutVars"] = pulloutVars
+	}
+	if cs.Predicate != nil {
+		other["Predicate"] = evalengine.FormatExpr(cs.Predicate)
+	}
+	if len(cs.Exprs) > 0 {
+		exprs := make([]string, 0, len(cs.Exprs))
+		for i, e := range cs.Exprs {
+			expr := evalengine.FormatExpr(e)
+			if i < len(cs.ExprNames) && cs.ExprNames[i] != "" {
+				expr += " as " + cs.ExprNames[i]
+			}
+			exprs = append(exprs, expr)
+		}
+		other["Expressions"] = exprs
+	}
+	return PrimitiveDescription{
+		OperatorType: "CorrelatedSubquery",
+		Variant:      cs.Opcode.String(),
+		Other:        other,
+	}
+}
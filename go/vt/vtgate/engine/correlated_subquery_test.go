@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func translateForTest(t *testing.T, expr string) evalengine.Expr {
+	t.Helper()
+	ast, err := sqlparser.ParseExpr(expr)
+	require.NoError(t, err)
+	e, err := evalengine.Translate(ast, &dummyTranslator{})
+	require.NoError(t, err)
+	return e
+}
+
+func TestCorrelatedSubqueryValue(t *testing.T) {
+	outerPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|val", "int64|int64"),
+				"1|10",
+				"2|20",
+				"1|15",
+				"3|1",
+			),
+		},
+	}
+	sqFields := sqltypes.MakeTestFields("max(col)", "int64")
+	sqPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqFields, "5"),
+			sqltypes.MakeTestResult(sqFields, "30"),
+			sqltypes.MakeTestResult(sqFields),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Outer:          outerPrim,
+		Subquery:       sqPrim,
+		Vars:           map[string]int{"outer_id": 0},
+		Predicate:      translateForTest(t, "`right` > :__sq1"),
+		Cols:           []int{-1, -2},
+	}
+	r, err := cs.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+
+	// the subquery is executed only once for the two rows with id 1
+	sqPrim.ExpectLog(t, []string{
+		`Execute outer_id: type:INT64 value:"1" false`,
+		`Execute outer_id: type:INT64 value:"2" false`,
+		`Execute outer_id: type:INT64 value:"3" false`,
+	})
+	require.Equal(t, `[[INT64(1) INT64(10)] [INT64(1) INT64(15)]]`, fmt.Sprintf("%v", r.Rows))
+}
+
+func TestCorrelatedSubqueryProjection(t *testing.T) {
+	outerPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|val", "int64|int64"),
+				"1|10",
+				"2|20",
+			),
+		},
+	}
+	sqFields := sqltypes.MakeTestFields("count(*)", "int64")
+	sqPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqFields),
+			sqltypes.MakeTestResult(sqFields, "3"),
+			sqltypes.MakeTestResult(sqFields, "0"),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Outer:          outerPrim,
+		Subquery:       sqPrim,
+		Vars:           map[string]int{"outer_id": 0},
+		Cols:           []int{-1, 0},
+		Exprs:          []evalengine.Expr{translateForTest(t, ":__sq1")},
+		ExprNames:      []string{"cnt"},
+	}
+	r, err := cs.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	sqPrim.ExpectLog(t, []string{
+		`GetFields outer_id: `,
+		`Execute outer_id:  true`,
+		`Execute outer_id: type:INT64 value:"1" false`,
+		`Execute outer_id: type:INT64 value:"2" false`,
+	})
+	utils.MustMatch(t, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("id|cnt", "int64|int64"),
+		"1|3",
+		"2|0",
+	), r)
+}
+
+func TestCorrelatedSubqueryNotIn(t *testing.T) {
+	outerPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields("id|val", "int64|int64"),
+				"1|10",
+				"2|20",
+				"3|30",
+			),
+		},
+	}
+	sqFields := sqltypes.MakeTestFields("col", "int64")
+	sqPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqFields, "10", "11"),
+			sqltypes.MakeTestResult(sqFields, "10", "11"),
+			sqltypes.MakeTestResult(sqFields),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutNotIn,
+		SubqueryResult: "__sq1",
+		HasValues:      "__sq_has_values1",
+		Outer:          outerPrim,
+		Subquery:       sqPrim,
+		Vars:           map[string]int{"outer_id": 0},
+		Predicate:      translateForTest(t, ":__sq_has_values1 = 0 or `right` not in ::__sq1"),
+		Cols:           []int{-1},
+	}
+	r, err := cs.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.Equal(t, `[[INT64(2)] [INT64(3)]]`, fmt.Sprintf("%v", r.Rows))
+}
+
+func TestCorrelatedSubqueryStreamExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|val", "int64|int64")
+	outerPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1|10", "2|20"),
+			sqltypes.MakeTestResult(fields, "1|30"),
+		},
+		allResultsInOneCall: true,
+	}
+	sqFields := sqltypes.MakeTestFields("col", "int64")
+	sqPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqFields, "1"),
+			sqltypes.MakeTestResult(sqFields),
+		},
+	}
+
+	cs := &CorrelatedSubquery{
+		Opcode:    PulloutExists,
+		HasValues: "__sq_has_values1",
+		Outer:     outerPrim,
+		Subquery:  sqPrim,
+		Vars:      map[string]int{"outer_id": 0},
+		Predicate: translateForTest(t, "not :__sq_has_values1 or `right` > 25"),
+		Cols:      []int{-2},
+	}
+	r, err := wrapStreamExecute(cs, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	sqPrim.ExpectLog(t, []string{
+		`Execute outer_id: type:INT64 value:"1" false`,
+		`Execute outer_id: type:INT64 value:"2" false`,
+	})
+	require.Equal(t, `[[INT64(20)] [INT64(30)]]`, fmt.Sprintf("%v", r.Rows))
+}
@@ -66,6 +66,18 @@ func (del *Delete) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	}
 }
 
+// execOnShards implements the shardedDML interface.
+func (del *Delete) execOnShards(ctx context.Context, vcursor VCursor, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	ctx, cancelFunc := addQueryTimeout(ctx, vcursor, del.QueryTimeout)
+	defer cancelFunc()
+
+	err := allowOnlyPrimary(rss...)
+	if err != nil {
+		return nil, err
+	}
+	return del.execShardsWithBindVars(ctx, del, vcursor, rss, bindVars, del.deleteVindexEntries)
+}
+
 // TryStreamExecute performs a streaming exec.
 func (del *Delete) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	res, err := del.TryExecute(ctx, vcursor, bindVars, wantfields)
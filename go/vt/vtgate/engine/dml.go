@@ -91,6 +91,27 @@ func (dml *DML) execMultiDestination(ctx context.Context, primitive Primitive, v
 	return execMultiShard(ctx, primitive, vcursor, rss, queries, dml.MultiShardAutocommit)
 }
 
+// execShardsWithBindVars executes the DML on the given shards, each of them with its own bind variables.
+func (dml *DML) execShardsWithBindVars(ctx context.Context, primitive Primitive, vcursor VCursor, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, dmlSpecialFunc func(context.Context, VCursor, map[string]*querypb.BindVariable, []*srvtopo.ResolvedShard) error) (*sqltypes.Result, error) {
+	if len(rss) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+	for i, rs := range rss {
+		err := dmlSpecialFunc(ctx, vcursor, bindVars[i], []*srvtopo.ResolvedShard{rs})
+		if err != nil {
+			return nil, err
+		}
+	}
+	queries := make([]*querypb.BoundQuery, len(rss))
+	for i := range rss {
+		queries[i] = &querypb.BoundQuery{
+			Sql:           dml.Query,
+			BindVariables: bindVars[i],
+		}
+	}
+	return execMultiShard(ctx, primitive, vcursor, rss, queries, dml.MultiShardAutocommit)
+}
+
 // RouteType returns a description of the query routing type used by the primitive
 func (dml *DML) RouteType() string {
 	return dml.Opcode.String()
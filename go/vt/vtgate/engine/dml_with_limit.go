@@ -0,0 +1,153 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/srvtopo"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// DMLValsVarName is the bind variable holding the primary keys of the rows
+// a multi-shard UPDATE or DELETE with a LIMIT changes on a shard.
+const DMLValsVarName = "__dml_vals"
+
+var _ Primitive = (*DMLWithLimit)(nil)
+
+// shardedDML is implemented by the DML primitives that can be sent
+// to a set of shards, each of them with its own bind variables.
+type shardedDML interface {
+	Primitive
+	execOnShards(ctx context.Context, vcursor VCursor, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable) (*sqltypes.Result, error)
+}
+
+// DMLWithLimit executes an UPDATE or DELETE with a LIMIT that targets several shards.
+// Input selects and locks the rows the statement changes: it is ordered by the ORDER BY
+// of the statement and limited by its LIMIT, its first column is the primary key of the
+// table and it holds the columns of the primary vindex. Every row is mapped to its shard,
+// and the DML is sent to each shard that holds some of the rows, with the primary keys
+// of the rows it holds.
+type DMLWithLimit struct {
+	Input Primitive
+
+	// DML matches the primary key of the table against DMLValsVarName.
+	DML shardedDML
+
+	// KsidVindex is the primary vindex, used to map the rows of Input to their shard.
+	KsidVindex vindexes.Vindex
+	// KsidOffsets are the offsets of the columns of KsidVindex in the rows of Input.
+	KsidOffsets []int
+
+	txNeeded
+}
+
+// TryExecute performs a non-streaming exec.
+func (d *DMLWithLimit) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	inputRes, err := vcursor.ExecutePrimitive(ctx, d.Input, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputRes.Rows) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+
+	destinations := make([]key.Destination, 0, len(inputRes.Rows))
+	ids := make([]*querypb.Value, 0, len(inputRes.Rows))
+	for _, row := range inputRes.Rows {
+		vindexValues := make([]sqltypes.Value, 0, len(d.KsidOffsets))
+		for _, offset := range d.KsidOffsets {
+			vindexValues = append(vindexValues, row[offset])
+		}
+		ksid, err := resolveKeyspaceID(ctx, vcursor, d.KsidVindex, vindexValues)
+		if err != nil {
+			return nil, err
+		}
+		if ksid == nil {
+			continue
+		}
+		destinations = append(destinations, key.DestinationKeyspaceID(ksid))
+		ids = append(ids, sqltypes.ValueToProto(row[0]))
+	}
+	if len(destinations) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+
+	rss, rowsPerShard, err := vcursor.ResolveDestinations(ctx, d.DML.GetKeyspaceName(), ids, destinations)
+	if err != nil {
+		return nil, err
+	}
+	shardBindVars := make([]map[string]*querypb.BindVariable, len(rss))
+	for i := range rss {
+		bvs := make(map[string]*querypb.BindVariable, len(bindVars)+1)
+		for k, v := range bindVars {
+			bvs[k] = v
+		}
+		bvs[DMLValsVarName] = &querypb.BindVariable{Type: querypb.Type_TUPLE, Values: rowsPerShard[i]}
+		shardBindVars[i] = bvs
+	}
+	return d.DML.execOnShards(ctx, vcursor, rss, shardBindVars)
+}
+
+// TryStreamExecute performs a streaming exec.
+func (d *DMLWithLimit) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := d.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (d *DMLWithLimit) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, fmt.Errorf("BUG: unreachable code for DMLWithLimit")
+}
+
+// Inputs returns the input primitives for this DMLWithLimit
+func (d *DMLWithLimit) Inputs() []Primitive {
+	return []Primitive{d.Input, d.DML}
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (d *DMLWithLimit) RouteType() string {
+	return "DMLWithLimit"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (d *DMLWithLimit) GetKeyspaceName() string {
+	return d.DML.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (d *DMLWithLimit) GetTableName() string {
+	return d.DML.GetTableName()
+}
+
+func (d *DMLWithLimit) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType: "DMLWithLimit",
+		Other: map[string]any{
+			"KsidVindex":  d.KsidVindex.String(),
+			"KsidOffsets": d.KsidOffsets,
+		},
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestDMLWithLimit(t *testing.T) {
+	vindex, _ := vindexes.NewHash("", nil)
+	del := &Delete{
+		DML: &DML{
+			RoutingParameters: &RoutingParameters{
+				Opcode: Scatter,
+				Keyspace: &vindexes.Keyspace{
+					Name:    "ks",
+					Sharded: true,
+				},
+			},
+			Query: "dummy_delete",
+		},
+	}
+	input := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk|id", "int64|int64"), "10|1", "40|4", "20|2")},
+	}
+	dwl := &DMLWithLimit{
+		Input:       input,
+		DML:         del,
+		KsidVindex:  vindex,
+		KsidOffsets: []int{1},
+	}
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"-20", "20-", "-20"}
+	_, err := dwl.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [type:INT64 value:"10" type:INT64 value:"40" type:INT64 value:"20"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(d2fd8867d50d2dfe),DestinationKeyspaceID(06e7ea22ce92708f)`,
+		`ExecuteMultiShard ks.-20: dummy_delete {__dml_vals: type:TUPLE values:{type:INT64 value:"10"} values:{type:INT64 value:"20"}} ks.20-: dummy_delete {__dml_vals: type:TUPLE values:{type:INT64 value:"40"}} true false`,
+	})
+
+	// Nothing to change.
+	input.rewind()
+	input.results = []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("pk|id", "int64|int64"))}
+	vc = newDMLTestVCursor("-20", "20-")
+	_, err = dwl.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, nil)
+
+	// Failure case
+	input.rewind()
+	input.results = nil
+	input.sendErr = errors.New("input_error")
+	_, err = dwl.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "input_error")
+}
@@ -42,6 +42,7 @@ import (
 
 var testMaxMemoryRows = 100
 var testIgnoreMaxMemoryRows = false
+var testHashJoinMemoryLimit int64
 
 var _ VCursor = (*noopVCursor)(nil)
 var _ SessionActions = (*noopVCursor)(nil)
@@ -169,6 +170,10 @@ func (t *noopVCursor) SetReadAfterWriteTimeout(f float64) {
 	panic("implement me")
 }
 
+func (t *noopVCursor) SetReadAfterWriteConsistency(context.Context, bool) error {
+	panic("implement me")
+}
+
 func (t *noopVCursor) SetSessionTrackGTIDs(b bool) {
 	panic("implement me")
 }
@@ -275,6 +280,10 @@ func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
 
+func (t *noopVCursor) HashJoinMemoryLimit() int64 {
+	return testHashJoinMemoryLimit
+}
+
 func (t *noopVCursor) GetKeyspace() string {
 	return ""
 }
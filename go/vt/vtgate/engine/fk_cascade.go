@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*FkCascade)(nil)
+
+// FkChild is a child table of a foreign key, whose parent rows are changed by a DML.
+type FkChild struct {
+	// BVName is the list bind variable that Exec uses for the values of the parent column.
+	BVName string
+	// Col is the offset of the parent column in the result of the Selection.
+	Col int
+	// Restrict is set when the foreign key does not allow the change of the parent rows.
+	// Exec is then a SELECT looking for child rows, and the DML fails if it finds any.
+	// Otherwise, Exec is the DML that cascades the change to the child rows.
+	Restrict bool
+	Exec     Primitive
+}
+
+// FkCascade enforces the foreign keys whose child table lives in another keyspace than the
+// parent table changed by an UPDATE or DELETE, since MySQL does not see both tables.
+// Selection reads the parent column values of the rows the DML changes. The restrict
+// checks run first, then the child DMLs, and at last the DML on the parent table itself.
+type FkCascade struct {
+	Selection Primitive
+	Children  []*FkChild
+	Parent    Primitive
+
+	txNeeded
+}
+
+// TryExecute performs a non-streaming exec.
+func (fkc *FkCascade) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	selectionRes, err := vcursor.ExecutePrimitive(ctx, fkc.Selection, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+
+	childBindVars := make([]map[string]*querypb.BindVariable, len(fkc.Children))
+	for i, child := range fkc.Children {
+		values := distinctColumnValues(selectionRes.Rows, child.Col)
+		if len(values) == 0 {
+			// no parent row is changed, or none of them can be referenced.
+			continue
+		}
+		bvs := make(map[string]*querypb.BindVariable, len(bindVars)+1)
+		for k, v := range bindVars {
+			bvs[k] = v
+		}
+		bvs[child.BVName] = &querypb.BindVariable{Type: querypb.Type_TUPLE, Values: values}
+		childBindVars[i] = bvs
+	}
+
+	for i, child := range fkc.Children {
+		if !child.Restrict || childBindVars[i] == nil {
+			continue
+		}
+		res, err := vcursor.ExecutePrimitive(ctx, child.Exec, childBindVars[i], false)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Rows) > 0 {
+			return nil, vterrors.VT10002(child.Exec.GetTableName())
+		}
+	}
+
+	for i, child := range fkc.Children {
+		if child.Restrict || childBindVars[i] == nil {
+			continue
+		}
+		if _, err := vcursor.ExecutePrimitive(ctx, child.Exec, childBindVars[i], false); err != nil {
+			return nil, err
+		}
+	}
+
+	return vcursor.ExecutePrimitive(ctx, fkc.Parent, bindVars, wantfields)
+}
+
+// distinctColumnValues returns the distinct, non-null values of the column at offset col.
+// A NULL never references a parent row, so it is left out.
+func distinctColumnValues(rows []sqltypes.Row, col int) []*querypb.Value {
+	var values []*querypb.Value
+	seen := make(map[string]any, len(rows))
+	for _, row := range rows {
+		val := row[col]
+		if val.IsNull() {
+			continue
+		}
+		key := val.String()
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = nil
+		values = append(values, sqltypes.ValueToProto(val))
+	}
+	return values
+}
+
+// TryStreamExecute performs a streaming exec.
+func (fkc *FkCascade) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := fkc.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (fkc *FkCascade) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.VT13001("unreachable code for FkCascade")
+}
+
+// Inputs returns the input primitives for this FkCascade
+func (fkc *FkCascade) Inputs() []Primitive {
+	inputs := []Primitive{fkc.Selection}
+	for _, child := range fkc.Children {
+		inputs = append(inputs, child.Exec)
+	}
+	return append(inputs, fkc.Parent)
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (fkc *FkCascade) RouteType() string {
+	return "FkCascade"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (fkc *FkCascade) GetKeyspaceName() string {
+	return fkc.Parent.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (fkc *FkCascade) GetTableName() string {
+	return fkc.Parent.GetTableName()
+}
+
+func (fkc *FkCascade) description() PrimitiveDescription {
+	var children []map[string]any
+	for _, child := range fkc.Children {
+		children = append(children, map[string]any{
+			"BvName":   child.BVName,
+			"Col":      child.Col,
+			"Restrict": child.Restrict,
+		})
+	}
+	return PrimitiveDescription{
+		OperatorType: "FkCascade",
+		Other: map[string]any{
+			"Children": children,
+		},
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestFkCascade(t *testing.T) {
+	fields := sqltypes.MakeTestFields("a|b", "int64|varchar")
+	selection := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(fields, "1|x", "2|null", "1|y")},
+	}
+	restrict := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"))},
+	}
+	cascade := &fakePrimitive{
+		results: []*sqltypes.Result{{RowsAffected: 3}},
+	}
+	parent := &fakePrimitive{
+		results: []*sqltypes.Result{{RowsAffected: 2}},
+	}
+	fkc := &FkCascade{
+		Selection: selection,
+		Children: []*FkChild{
+			{BVName: "fkc_vals", Col: 0, Exec: cascade},
+			{BVName: "fkc_vals1", Col: 1, Restrict: true, Exec: restrict},
+		},
+		Parent: parent,
+	}
+	require.True(t, fkc.NeedsTransaction())
+
+	bv := map[string]*querypb.BindVariable{"v": sqltypes.Int64BindVariable(1)}
+	res, err := fkc.TryExecute(context.Background(), &noopVCursor{}, bv, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.RowsAffected)
+
+	selection.ExpectLog(t, []string{`Execute v: type:INT64 value:"1" false`})
+	restrict.ExpectLog(t, []string{`Execute fkc_vals1: type:TUPLE values:{type:VARCHAR value:"x"} values:{type:VARCHAR value:"y"} v: type:INT64 value:"1" false`})
+	cascade.ExpectLog(t, []string{`Execute fkc_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} v: type:INT64 value:"1" false`})
+	parent.ExpectLog(t, []string{`Execute v: type:INT64 value:"1" false`})
+
+	// a child row exists, nothing gets changed.
+	selection.rewind()
+	restrict.rewind()
+	cascade.rewind()
+	parent.rewind()
+	restrict.results = []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")}
+	_, err = fkc.TryExecute(context.Background(), &noopVCursor{}, bv, false)
+	require.EqualError(t, err, "VT10002: cannot delete or update a parent row: a foreign key constraint fails on table 'fakeTable'")
+	require.Equal(t, vterrors.RowIsReferenced2, vterrors.ErrState(err))
+	cascade.ExpectLog(t, nil)
+	parent.ExpectLog(t, nil)
+
+	// no parent row to change, the children are not looked at.
+	selection.rewind()
+	restrict.rewind()
+	parent.rewind()
+	selection.results = []*sqltypes.Result{sqltypes.MakeTestResult(fields)}
+	_, err = fkc.TryExecute(context.Background(), &noopVCursor{}, bv, false)
+	require.NoError(t, err)
+	restrict.ExpectLog(t, nil)
+	cascade.ExpectLog(t, nil)
+	parent.ExpectLog(t, []string{`Execute v: type:INT64 value:"1" false`})
+}
@@ -35,6 +35,8 @@ var _ Primitive = (*HashJoin)(nil)
 // The key to the map is the hashcode of the value for column that we are joining by.
 // Then the RHS is fetched, and we can check if the rows from the RHS matches any from the LHS.
 // When they match by hash code, we double-check that we are not working with a false positive by comparing the values.
+// When the LHS does not fit in the memory limit of the vcursor, both sides are partitioned to disk
+// and joined one partition at a time, see hashJoinTable.
 type HashJoin struct {
 	Opcode JoinOpcode
 
@@ -49,8 +51,15 @@ type HashJoin struct {
 	// For the right query, they're 1, 2, etc.
 	// If Cols is {-1, -2, 1, 2}, it means that
 	// the returned result will be {Left0, Left1, Right0, Right1}.
+	// A zero stands for the next expression of Exprs.
 	Cols []int `json:",omitempty"`
 
+	// Exprs are the columns computed from both sides of the join. They are evaluated
+	// on the output row, and refer to the columns of Cols that come from the left or right results.
+	Exprs []evalengine.Expr `json:",omitempty"`
+	// ExprNames are the names of the columns of Exprs.
+	ExprNames []string `json:",omitempty"`
+
 	// The keys correspond to the column offset in the inputs where
 	// the join columns can be found
 	LHSKey, RHSKey int
@@ -71,9 +80,12 @@ func (hj *HashJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 	}
 
 	// build the probe table from the LHS result
-	probeTable, err := hj.buildProbeTable(lresult)
-	if err != nil {
-		return nil, err
+	table := newHashJoinTable(hj, vcursor.HashJoinMemoryLimit())
+	defer table.close()
+	for _, row := range lresult.Rows {
+		if err := table.add(row); err != nil {
+			return nil, err
+		}
 	}
 
 	rresult, err := vcursor.ExecutePrimitive(ctx, hj.Right, bindVars, wantfields)
@@ -81,73 +93,47 @@ func (hj *HashJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 		return nil, err
 	}
 
-	result := &sqltypes.Result{
-		Fields: joinFields(lresult.Fields, rresult.Fields, hj.Cols),
-	}
-
-	for _, currentRHSRow := range rresult.Rows {
-		joinVal := currentRHSRow[hj.RHSKey]
-		if joinVal.IsNull() {
-			continue
-		}
-		hashcode, err := evalengine.NullsafeHashcode(joinVal, hj.Collation, hj.ComparisonType)
+	joiner := hj.newJoiner(bindVars, vcursor, lresult.Fields, rresult.Fields)
+	result := &sqltypes.Result{}
+	if wantfields {
+		result.Fields, err = joiner.fields()
 		if err != nil {
 			return nil, err
 		}
-		lftRows := probeTable[hashcode]
-		for _, currentLHSRow := range lftRows {
-			lhsVal := currentLHSRow[hj.LHSKey]
-			// hash codes can give false positives, so we need to check with a real comparison as well
-			cmp, err := evalengine.NullsafeCompare(joinVal, lhsVal, collations.Unknown)
-			if err != nil {
-				return nil, err
-			}
-
-			if cmp == 0 {
-				// we have a match!
-				result.Rows = append(result.Rows, joinRows(currentLHSRow, currentRHSRow, hj.Cols))
-			}
-		}
 	}
-
-	return result, nil
-}
-
-func (hj *HashJoin) buildProbeTable(lresult *sqltypes.Result) (map[evalengine.HashCode][]sqltypes.Row, error) {
-	probeTable := map[evalengine.HashCode][]sqltypes.Row{}
-	for _, current := range lresult.Rows {
-		joinVal := current[hj.LHSKey]
-		if joinVal.IsNull() {
-			continue
-		}
-		hashcode, err := evalengine.NullsafeHashcode(joinVal, hj.Collation, hj.ComparisonType)
+	emit := func(lrow, rrow sqltypes.Row) error {
+		row, err := joiner.join(lrow, rrow)
 		if err != nil {
+			return err
+		}
+		result.Rows = append(result.Rows, row)
+		return nil
+	}
+	for _, row := range rresult.Rows {
+		if err := table.probe(row, emit); err != nil {
 			return nil, err
 		}
-		probeTable[hashcode] = append(probeTable[hashcode], current)
 	}
-	return probeTable, nil
+	if err := table.finish(emit); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // TryStreamExecute implements the Primitive interface
 func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	// build the probe table from the LHS result
-	probeTable := map[evalengine.HashCode][]sqltypes.Row{}
+	table := newHashJoinTable(hj, vcursor.HashJoinMemoryLimit())
+	defer table.close()
 	var lfields []*querypb.Field
 	err := vcursor.StreamExecutePrimitive(ctx, hj.Left, bindVars, wantfields, func(result *sqltypes.Result) error {
 		if len(lfields) == 0 && len(result.Fields) != 0 {
 			lfields = result.Fields
 		}
-		for _, current := range result.Rows {
-			joinVal := current[hj.LHSKey]
-			if joinVal.IsNull() {
-				continue
-			}
-			hashcode, err := evalengine.NullsafeHashcode(joinVal, hj.Collation, hj.ComparisonType)
-			if err != nil {
+		for _, row := range result.Rows {
+			if err := table.add(row); err != nil {
 				return err
 			}
-			probeTable[hashcode] = append(probeTable[hashcode], current)
 		}
 		return nil
 	})
@@ -155,43 +141,139 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 		return err
 	}
 
-	return vcursor.StreamExecutePrimitive(ctx, hj.Right, bindVars, wantfields, func(result *sqltypes.Result) error {
+	var joiner *hashJoiner
+	var rows []sqltypes.Row
+	emit := func(lrow, rrow sqltypes.Row) error {
+		row, err := joiner.join(lrow, rrow)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	}
+	err = vcursor.StreamExecutePrimitive(ctx, hj.Right, bindVars, wantfields, func(result *sqltypes.Result) error {
 		// compare the results coming from the RHS with the probe-table
 		res := &sqltypes.Result{}
-		if len(result.Fields) != 0 {
-			res = &sqltypes.Result{
-				Fields: joinFields(lfields, result.Fields, hj.Cols),
-			}
-		}
-		for _, currentRHSRow := range result.Rows {
-			joinVal := currentRHSRow[hj.RHSKey]
-			if joinVal.IsNull() {
-				continue
-			}
-			hashcode, err := evalengine.NullsafeHashcode(joinVal, hj.Collation, hj.ComparisonType)
-			if err != nil {
-				return err
-			}
-			lftRows := probeTable[hashcode]
-			for _, currentLHSRow := range lftRows {
-				lhsVal := currentLHSRow[hj.LHSKey]
-				// hash codes can give false positives, so we need to check with a real comparison as well
-				cmp, err := evalengine.NullsafeCompare(joinVal, lhsVal, hj.Collation)
+		if joiner == nil && len(result.Fields) != 0 {
+			joiner = hj.newJoiner(bindVars, vcursor, lfields, result.Fields)
+			if wantfields {
+				fields, err := joiner.fields()
 				if err != nil {
 					return err
 				}
-
-				if cmp == 0 {
-					// we have a match!
-					res.Rows = append(res.Rows, joinRows(currentLHSRow, currentRHSRow, hj.Cols))
-				}
+				res.Fields = fields
 			}
 		}
+		if joiner == nil {
+			joiner = hj.newJoiner(bindVars, vcursor, lfields, nil)
+		}
+		rows = nil
+		for _, row := range result.Rows {
+			if err := table.probe(row, emit); err != nil {
+				return err
+			}
+		}
+		res.Rows = rows
 		if len(res.Rows) != 0 || len(res.Fields) != 0 {
 			return callback(res)
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if joiner == nil {
+		joiner = hj.newJoiner(bindVars, vcursor, lfields, nil)
+	}
+	rows = nil
+	if err := table.finish(emit); err != nil {
+		return err
+	}
+	if len(rows) != 0 {
+		return callback(&sqltypes.Result{Rows: rows})
+	}
+	return nil
+}
+
+// hashJoiner builds the output rows of a hash join out of the matching rows of both sides.
+type hashJoiner struct {
+	hj      *HashJoin
+	lfields []*querypb.Field
+	rfields []*querypb.Field
+	env     *evalengine.ExpressionEnv
+}
+
+func (hj *HashJoin) newJoiner(bindVars map[string]*querypb.BindVariable, vcursor VCursor, lfields, rfields []*querypb.Field) *hashJoiner {
+	j := &hashJoiner{hj: hj, lfields: lfields, rfields: rfields}
+	if len(hj.Exprs) > 0 {
+		j.env = evalengine.EnvWithBindVars(bindVars, vcursor.ConnCollation())
+	}
+	return j
+}
+
+func (j *hashJoiner) fields() ([]*querypb.Field, error) {
+	fields := make([]*querypb.Field, len(j.hj.Cols))
+	for i, index := range j.hj.Cols {
+		switch {
+		case index < 0:
+			fields[i] = j.lfields[-index-1]
+		case index > 0:
+			fields[i] = j.rfields[index-1]
+		}
+	}
+	if len(j.hj.Exprs) == 0 {
+		return fields, nil
+	}
+	j.env.Fields = fields
+	next := 0
+	for i, index := range j.hj.Cols {
+		if index != 0 {
+			continue
+		}
+		expr := j.hj.Exprs[next]
+		name := evalengine.FormatExpr(expr)
+		if next < len(j.hj.ExprNames) && j.hj.ExprNames[next] != "" {
+			name = j.hj.ExprNames[next]
+		}
+		next++
+		typ, err := j.env.TypeOf(expr)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = &querypb.Field{Name: name, Type: typ}
+	}
+	return fields, nil
+}
+
+// join returns the output row of the given rows, the right row is nil for the unmatched rows of a left join.
+func (j *hashJoiner) join(lrow, rrow sqltypes.Row) (sqltypes.Row, error) {
+	if len(j.hj.Exprs) == 0 {
+		return joinRows(lrow, rrow, j.hj.Cols), nil
+	}
+	row := make(sqltypes.Row, len(j.hj.Cols))
+	for i, index := range j.hj.Cols {
+		switch {
+		case index < 0:
+			row[i] = lrow[-index-1]
+		case index > 0 && rrow != nil:
+			row[i] = rrow[index-1]
+		}
+	}
+	j.env.Row = row
+	next := 0
+	for i, index := range j.hj.Cols {
+		if index != 0 {
+			continue
+		}
+		res, err := j.env.Evaluate(j.hj.Exprs[next])
+		if err != nil {
+			return nil, err
+		}
+		next++
+		row[i] = res.Value()
+	}
+	return row, nil
 }
 
 // RouteType implements the Primitive interface
@@ -214,18 +296,19 @@ func (hj *HashJoin) GetTableName() string {
 
 // GetFields implements the Primitive interface
 func (hj *HashJoin) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
-	joinVars := make(map[string]*querypb.BindVariable)
 	lresult, err := hj.Left.GetFields(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
 	}
-	result := &sqltypes.Result{}
-	rresult, err := hj.Right.GetFields(ctx, vcursor, combineVars(bindVars, joinVars))
+	rresult, err := hj.Right.GetFields(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
 	}
-	result.Fields = joinFields(lresult.Fields, rresult.Fields, hj.Cols)
-	return result, nil
+	fields, err := hj.newJoiner(bindVars, vcursor, lresult.Fields, rresult.Fields).fields()
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: fields}, nil
 }
 
 // NeedsTransaction implements the Primitive interface
@@ -246,6 +329,17 @@ func (hj *HashJoin) description() PrimitiveDescription {
 		"Predicate":         sqlparser.String(hj.ASTPred),
 		"ComparisonType":    hj.ComparisonType.String(),
 	}
+	if len(hj.Exprs) > 0 {
+		exprs := make([]string, 0, len(hj.Exprs))
+		for i, e := range hj.Exprs {
+			expr := evalengine.FormatExpr(e)
+			if i < len(hj.ExprNames) && hj.ExprNames[i] != "" {
+				expr += " as " + hj.ExprNames[i]
+			}
+			exprs = append(exprs, expr)
+		}
+		other["Expressions"] = exprs
+	}
 	coll := collations.Local().LookupByID(hj.Collation)
 	if coll != nil {
 		other["Collation"] = coll.Name()
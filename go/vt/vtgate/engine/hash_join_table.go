@@ -0,0 +1,357 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+// hashJoinPartitions is the number of partitions that both sides of a hash join are split into
+// when the build side does not fit in memory.
+const hashJoinPartitions = 16
+
+// hashJoinTable holds the build side of a hash join, the rows of the LHS, and probes it with the rows of the RHS.
+//
+// As long as the LHS fits in the memory limit, it is kept in a hash map and the RHS rows are
+// joined as they come. Once it goes over the limit, the rows of both sides are written to
+// partition files on the local disk instead, by the hash of their join key. Matching rows
+// always land in the same partition, so the join is then done one partition at a time when
+// the RHS has been fully read, with only one partition of the LHS in memory.
+type hashJoinTable struct {
+	hj    *HashJoin
+	limit int64
+	size  int64
+
+	rows map[evalengine.HashCode][]*hashJoinRow
+	// built keeps the order of the rows of a left join, to return the unmatched ones in order.
+	built []*hashJoinRow
+	// unmatched are the rows of a left join that cannot match anything because their join key is NULL.
+	unmatched []sqltypes.Row
+
+	spill *hashJoinSpill
+}
+
+type hashJoinRow struct {
+	row     sqltypes.Row
+	matched bool
+}
+
+func newHashJoinTable(hj *HashJoin, limit int64) *hashJoinTable {
+	return &hashJoinTable{
+		hj:    hj,
+		limit: limit,
+		rows:  map[evalengine.HashCode][]*hashJoinRow{},
+	}
+}
+
+func (t *hashJoinTable) hashcode(v sqltypes.Value) (evalengine.HashCode, error) {
+	return evalengine.NullsafeHashcode(v, t.hj.Collation, t.hj.ComparisonType)
+}
+
+// add adds a row of the LHS to the table.
+func (t *hashJoinTable) add(row sqltypes.Row) error {
+	key := row[t.hj.LHSKey]
+	if key.IsNull() {
+		if t.hj.Opcode != LeftJoin {
+			return nil
+		}
+		if t.spill != nil {
+			return t.spill.unmatched.write(row)
+		}
+		t.unmatched = append(t.unmatched, row)
+		t.size += rowSize(row)
+		return t.checkLimit()
+	}
+	hashcode, err := t.hashcode(key)
+	if err != nil {
+		return err
+	}
+	if t.spill != nil {
+		return t.spill.build[hashcode%hashJoinPartitions].write(row)
+	}
+	t.insert(hashcode, row)
+	t.size += rowSize(row)
+	return t.checkLimit()
+}
+
+func (t *hashJoinTable) insert(hashcode evalengine.HashCode, row sqltypes.Row) {
+	hr := &hashJoinRow{row: row}
+	t.rows[hashcode] = append(t.rows[hashcode], hr)
+	if t.hj.Opcode == LeftJoin {
+		t.built = append(t.built, hr)
+	}
+}
+
+// checkLimit moves the table to disk if it went over the memory limit.
+func (t *hashJoinTable) checkLimit() error {
+	if t.limit <= 0 || t.size <= t.limit {
+		return nil
+	}
+	spill, err := newHashJoinSpill()
+	if err != nil {
+		return err
+	}
+	t.spill = spill
+	for hashcode, rows := range t.rows {
+		for _, hr := range rows {
+			if err := spill.build[hashcode%hashJoinPartitions].write(hr.row); err != nil {
+				return err
+			}
+		}
+	}
+	for _, row := range t.unmatched {
+		if err := spill.unmatched.write(row); err != nil {
+			return err
+		}
+	}
+	t.reset()
+	return nil
+}
+
+func (t *hashJoinTable) reset() {
+	t.rows = map[evalengine.HashCode][]*hashJoinRow{}
+	t.built = nil
+	t.unmatched = nil
+	t.size = 0
+}
+
+// probe joins a row of the RHS with the matching rows of the LHS. When the table
+// is on disk, the row is only written to its partition and is joined by finish.
+func (t *hashJoinTable) probe(row sqltypes.Row, emit func(lrow, rrow sqltypes.Row) error) error {
+	key := row[t.hj.RHSKey]
+	if key.IsNull() {
+		return nil
+	}
+	hashcode, err := t.hashcode(key)
+	if err != nil {
+		return err
+	}
+	if t.spill != nil {
+		return t.spill.probe[hashcode%hashJoinPartitions].write(row)
+	}
+	return t.probeMemory(hashcode, key, row, emit)
+}
+
+func (t *hashJoinTable) probeMemory(hashcode evalengine.HashCode, key sqltypes.Value, row sqltypes.Row, emit func(lrow, rrow sqltypes.Row) error) error {
+	for _, hr := range t.rows[hashcode] {
+		// hash codes can give false positives, so we need to check with a real comparison as well
+		cmp, err := evalengine.NullsafeCompare(key, hr.row[t.hj.LHSKey], t.hj.Collation)
+		if err != nil {
+			return err
+		}
+		if cmp == 0 {
+			// we have a match!
+			hr.matched = true
+			if err := emit(hr.row, row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// finish joins the partitions that were spilled to disk, and returns the unmatched rows of a left join.
+func (t *hashJoinTable) finish(emit func(lrow, rrow sqltypes.Row) error) error {
+	if t.spill == nil {
+		return t.emitUnmatched(emit)
+	}
+	for p := 0; p < hashJoinPartitions; p++ {
+		t.reset()
+		err := t.spill.build[p].read(func(row sqltypes.Row) error {
+			hashcode, err := t.hashcode(row[t.hj.LHSKey])
+			if err != nil {
+				return err
+			}
+			t.insert(hashcode, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		err = t.spill.probe[p].read(func(row sqltypes.Row) error {
+			key := row[t.hj.RHSKey]
+			hashcode, err := t.hashcode(key)
+			if err != nil {
+				return err
+			}
+			return t.probeMemory(hashcode, key, row, emit)
+		})
+		if err != nil {
+			return err
+		}
+		if err := t.emitUnmatched(emit); err != nil {
+			return err
+		}
+	}
+	t.reset()
+	return t.spill.unmatched.read(func(row sqltypes.Row) error {
+		return emit(row, nil)
+	})
+}
+
+func (t *hashJoinTable) emitUnmatched(emit func(lrow, rrow sqltypes.Row) error) error {
+	for _, hr := range t.built {
+		if hr.matched {
+			continue
+		}
+		if err := emit(hr.row, nil); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.unmatched {
+		if err := emit(row, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close removes the partition files of the table, if any.
+func (t *hashJoinTable) close() {
+	if t.spill != nil {
+		t.spill.close()
+	}
+}
+
+// rowSize is the approximate memory used by a row.
+func rowSize(row sqltypes.Row) int64 {
+	size := int64(24)
+	for _, v := range row {
+		size += int64(32 + len(v.Raw()))
+	}
+	return size
+}
+
+// hashJoinSpill holds the partition files of a hash join that did not fit in memory.
+type hashJoinSpill struct {
+	dir       string
+	build     [hashJoinPartitions]*hashJoinFile
+	probe     [hashJoinPartitions]*hashJoinFile
+	unmatched *hashJoinFile
+}
+
+func newHashJoinSpill() (*hashJoinSpill, error) {
+	dir, err := os.MkdirTemp("", "vtgate-hashjoin-")
+	if err != nil {
+		return nil, err
+	}
+	spill := &hashJoinSpill{dir: dir}
+	for p := 0; p < hashJoinPartitions; p++ {
+		spill.build[p] = &hashJoinFile{path: filepath.Join(dir, fmt.Sprintf("build-%d", p))}
+		spill.probe[p] = &hashJoinFile{path: filepath.Join(dir, fmt.Sprintf("probe-%d", p))}
+	}
+	spill.unmatched = &hashJoinFile{path: filepath.Join(dir, "unmatched")}
+	return spill, nil
+}
+
+func (s *hashJoinSpill) close() {
+	for p := 0; p < hashJoinPartitions; p++ {
+		s.build[p].close()
+		s.probe[p].close()
+	}
+	s.unmatched.close()
+	_ = os.RemoveAll(s.dir)
+}
+
+// hashJoinFile is a partition file. Its rows are written one after the other, as
+// the number of values of the row followed by the type, length and bytes of each value.
+type hashJoinFile struct {
+	path string
+	file *os.File
+	w    *bufio.Writer
+	buf  []byte
+}
+
+func (f *hashJoinFile) write(row sqltypes.Row) error {
+	if f.file == nil {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return err
+		}
+		f.file = file
+		f.w = bufio.NewWriter(file)
+	}
+	f.buf = binary.AppendUvarint(f.buf[:0], uint64(len(row)))
+	for _, v := range row {
+		raw := v.Raw()
+		f.buf = binary.AppendUvarint(f.buf, uint64(v.Type()))
+		f.buf = binary.AppendUvarint(f.buf, uint64(len(raw)))
+		f.buf = append(f.buf, raw...)
+	}
+	_, err := f.w.Write(f.buf)
+	return err
+}
+
+func (f *hashJoinFile) read(callback func(row sqltypes.Row) error) error {
+	if f.file == nil {
+		return nil
+	}
+	if err := f.w.Flush(); err != nil {
+		return err
+	}
+	if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f.file)
+	for {
+		count, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row := make(sqltypes.Row, count)
+		for i := range row {
+			typ, err := binary.ReadUvarint(r)
+			if err != nil {
+				return err
+			}
+			size, err := binary.ReadUvarint(r)
+			if err != nil {
+				return err
+			}
+			var raw []byte
+			if size > 0 {
+				raw = make([]byte, size)
+				if _, err := io.ReadFull(r, raw); err != nil {
+					return err
+				}
+			}
+			row[i] = sqltypes.MakeTrusted(querypb.Type(typ), raw)
+		}
+		if err := callback(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *hashJoinFile) close() {
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+}
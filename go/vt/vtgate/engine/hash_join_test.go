@@ -20,10 +20,13 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
 func TestHashJoinExecuteSameType(t *testing.T) {
@@ -145,3 +148,168 @@ func TestHashJoinExecuteDifferentType(t *testing.T) {
 		"5|c| 5.0toto|g",
 	))
 }
+
+func TestHashJoinLeftJoin(t *testing.T) {
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col1|col2",
+					"int64|varchar",
+				),
+				"1|a",
+				"2|b",
+				"null|c",
+				"3|d",
+			),
+		},
+	}
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col3|col4",
+					"int64|varchar",
+				),
+				"3|e",
+				"1|f",
+				"null|g",
+				"3|h",
+			),
+		},
+	}
+
+	jn := &HashJoin{
+		Opcode: LeftJoin,
+		Left:   leftPrim,
+		Right:  rightPrim,
+		Cols:   []int{-2, 2},
+		LHSKey: 0,
+		RHSKey: 0,
+	}
+	r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	expectResult(t, "jn.Execute", r, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col2|col4",
+			"varchar|varchar",
+		),
+		"d|e",
+		"a|f",
+		"d|h",
+		"b|null",
+		"c|null",
+	))
+}
+
+func TestHashJoinExpressions(t *testing.T) {
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"id|a",
+					"int64|int64",
+				),
+				"1|10",
+				"2|20",
+			),
+		},
+	}
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"id|b",
+					"int64|int64",
+				),
+				"1|5",
+			),
+		},
+	}
+
+	// a + b, where a and b are the second and third columns of the output row
+	expr, err := evalengine.Translate(&sqlparser.BinaryExpr{
+		Operator: sqlparser.PlusOp,
+		Left:     sqlparser.NewOffset(1, nil),
+		Right:    sqlparser.NewOffset(2, nil),
+	}, nil)
+	require.NoError(t, err)
+	jn := &HashJoin{
+		Opcode:    LeftJoin,
+		Left:      leftPrim,
+		Right:     rightPrim,
+		Cols:      []int{-1, -2, 2, 0},
+		Exprs:     []evalengine.Expr{expr},
+		ExprNames: []string{"a + b"},
+		LHSKey:    0,
+		RHSKey:    0,
+	}
+	r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	expectResult(t, "jn.Execute", r, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"id|a|b|a + b",
+			"int64|int64|int64|int64",
+		),
+		"1|10|5|15",
+		"2|20|null|null",
+	))
+}
+
+func TestHashJoinSpill(t *testing.T) {
+	defer func(limit int64) { testHashJoinMemoryLimit = limit }(testHashJoinMemoryLimit)
+	testHashJoinMemoryLimit = 1
+
+	left := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col1|col2",
+			"int64|varchar",
+		),
+		"1|a",
+		"2|b",
+		"null|c",
+		"3|d",
+	)
+	right := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col3|col4",
+			"int64|varchar",
+		),
+		"3|e",
+		"1|f",
+		"3|h",
+	)
+	newJoin := func() *HashJoin {
+		return &HashJoin{
+			Opcode:         LeftJoin,
+			Left:           &fakePrimitive{results: []*sqltypes.Result{left}},
+			Right:          &fakePrimitive{results: []*sqltypes.Result{right}},
+			Cols:           []int{-2, 2},
+			LHSKey:         0,
+			RHSKey:         0,
+			ComparisonType: querypb.Type_INT64,
+		}
+	}
+	// the rows come out partition by partition when the LHS is spilled to disk
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col2|col4",
+			"varchar|varchar",
+		),
+		"a|f",
+		"b|null",
+		"c|null",
+		"d|e",
+		"d|h",
+	)
+
+	r, err := newJoin().TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, want.Fields, r.Fields)
+	assert.ElementsMatch(t, want.Rows, r.Rows)
+
+	r, err = wrapStreamExecute(newJoin(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, want.Fields, r.Fields)
+	assert.ElementsMatch(t, want.Rows, r.Rows)
+}
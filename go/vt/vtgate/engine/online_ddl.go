@@ -82,6 +82,10 @@ func (v *OnlineDDL) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 		},
 		Rows: [][]sqltypes.Value{},
 	}
+	if v.DDLStrategySetting.IsDeclarativeSchema() {
+		// The desired schema of a keyspace is a list of statements, which is submitted through ApplySchema
+		return result, vterrors.VT12001("--declarative-schema in a single statement; use ApplySchema")
+	}
 	onlineDDLs, err := schema.NewOnlineDDLs(v.GetKeyspaceName(), v.SQL, v.DDL,
 		v.DDLStrategySetting, fmt.Sprintf("vtgate:%s", vcursor.Session().GetSessionUUID()), "",
 	)
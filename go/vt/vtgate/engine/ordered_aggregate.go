@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -106,6 +107,13 @@ type AggregateParams struct {
 	WAssigned   bool
 	CollationID collations.ID
 
+	// ExtraKeys holds the remaining keys of a distinct aggregation
+	// over more than one expression, e.g. COUNT(DISTINCT a, b).
+	ExtraKeys []*GroupByParams `json:",omitempty"`
+
+	// Separator is the string GROUP_CONCAT uses to join the values.
+	Separator string `json:",omitempty"`
+
 	Alias    string `json:",omitempty"`
 	Expr     sqlparser.Expr
 	Original *sqlparser.AliasedExpr
@@ -116,11 +124,11 @@ type AggregateParams struct {
 }
 
 func (ap *AggregateParams) isDistinct() bool {
-	return ap.Opcode == AggregateCountDistinct || ap.Opcode == AggregateSumDistinct
+	return ap.Opcode == AggregateCountDistinct || ap.Opcode == AggregateSumDistinct || ap.Opcode == AggregateGroupConcatDistinct
 }
 
 func (ap *AggregateParams) preProcess() bool {
-	return ap.Opcode == AggregateCountDistinct || ap.Opcode == AggregateSumDistinct || ap.Opcode == AggregateGtid || ap.Opcode == AggregateCount
+	return ap.isDistinct() || ap.Opcode == AggregateGtid || ap.Opcode == AggregateCount
 }
 
 func (ap *AggregateParams) String() string {
@@ -132,6 +140,9 @@ func (ap *AggregateParams) String() string {
 		collation := collations.Local().LookupByID(ap.CollationID)
 		keyCol += " COLLATE " + collation.Name()
 	}
+	for _, key := range ap.ExtraKeys {
+		keyCol += ", " + key.String()
+	}
 	dispOrigOp := ""
 	if ap.OrigOpcode != AggregateUnassigned && ap.OrigOpcode != ap.Opcode {
 		dispOrigOp = "_" + ap.OrigOpcode.String()
@@ -157,38 +168,61 @@ const (
 	AggregateGtid
 	AggregateRandom
 	AggregateCountStar
+	AggregateGroupConcat
+	AggregateGroupConcatDistinct
+	AggregateBitAnd
+	AggregateBitOr
+	AggregateBitXor
+	AggregateJSONArrayAgg
+	AggregateJSONObjectAgg
 )
 
 var (
 	// OpcodeType keeps track of the known output types for different aggregate functions
 	OpcodeType = map[AggregateOpcode]querypb.Type{
-		AggregateCountDistinct: sqltypes.Int64,
-		AggregateCount:         sqltypes.Int64,
-		AggregateCountStar:     sqltypes.Int64,
-		AggregateSumDistinct:   sqltypes.Decimal,
-		AggregateSum:           sqltypes.Decimal,
-		AggregateGtid:          sqltypes.VarChar,
+		AggregateCountDistinct:       sqltypes.Int64,
+		AggregateCount:               sqltypes.Int64,
+		AggregateCountStar:           sqltypes.Int64,
+		AggregateSumDistinct:         sqltypes.Decimal,
+		AggregateSum:                 sqltypes.Decimal,
+		AggregateGtid:                sqltypes.VarChar,
+		AggregateGroupConcat:         sqltypes.VarChar,
+		AggregateGroupConcatDistinct: sqltypes.VarChar,
+		AggregateBitAnd:              sqltypes.Uint64,
+		AggregateBitOr:               sqltypes.Uint64,
+		AggregateBitXor:              sqltypes.Uint64,
+		AggregateJSONArrayAgg:        sqltypes.TypeJSON,
+		AggregateJSONObjectAgg:       sqltypes.TypeJSON,
 	}
 	// Some predefined values
 	countZero = sqltypes.MakeTrusted(sqltypes.Int64, []byte("0"))
 	countOne  = sqltypes.MakeTrusted(sqltypes.Int64, []byte("1"))
 	sumZero   = sqltypes.MakeTrusted(sqltypes.Decimal, []byte("0"))
+	bitZero   = sqltypes.NewUint64(0)
+	bitOnes   = sqltypes.NewUint64(math.MaxUint64)
 )
 
 // SupportedAggregates maps the list of supported aggregate
 // functions to their opcodes.
 var SupportedAggregates = map[string]AggregateOpcode{
-	"count": AggregateCount,
-	"sum":   AggregateSum,
-	"min":   AggregateMin,
-	"max":   AggregateMax,
+	"count":          AggregateCount,
+	"sum":            AggregateSum,
+	"min":            AggregateMin,
+	"max":            AggregateMax,
+	"group_concat":   AggregateGroupConcat,
+	"bit_and":        AggregateBitAnd,
+	"bit_or":         AggregateBitOr,
+	"bit_xor":        AggregateBitXor,
+	"json_arrayagg":  AggregateJSONArrayAgg,
+	"json_objectagg": AggregateJSONObjectAgg,
 	// These functions don't exist in mysql, but are used
 	// to display the plan.
-	"count_distinct": AggregateCountDistinct,
-	"sum_distinct":   AggregateSumDistinct,
-	"vgtid":          AggregateGtid,
-	"count_star":     AggregateCountStar,
-	"random":         AggregateRandom,
+	"count_distinct":        AggregateCountDistinct,
+	"sum_distinct":          AggregateSumDistinct,
+	"group_concat_distinct": AggregateGroupConcatDistinct,
+	"vgtid":                 AggregateGtid,
+	"count_star":            AggregateCountStar,
+	"random":                AggregateRandom,
 }
 
 func (code AggregateOpcode) String() string {
@@ -246,7 +280,7 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 	}
 	// This code is similar to the one in StreamExecute.
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curDistincts [][]sqltypes.Value
 	for _, row := range result.Rows {
 		if current == nil {
 			current, curDistincts = convertRow(row, oa.PreProcess, oa.Aggregates, oa.AggrOnEngine)
@@ -281,7 +315,7 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 // TryStreamExecute is a Primitive function.
 func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curDistincts [][]sqltypes.Value
 	var fields []*querypb.Field
 
 	cb := func(qr *sqltypes.Result) error {
@@ -352,12 +386,12 @@ func convertFields(fields []*querypb.Field, preProcess bool, aggrs []*AggregateP
 	return fields
 }
 
-func convertRow(row []sqltypes.Value, preProcess bool, aggregates []*AggregateParams, aggrOnEngine bool) (newRow []sqltypes.Value, curDistincts []sqltypes.Value) {
+func convertRow(row []sqltypes.Value, preProcess bool, aggregates []*AggregateParams, aggrOnEngine bool) (newRow []sqltypes.Value, curDistincts [][]sqltypes.Value) {
 	if !preProcess {
 		return row, nil
 	}
 	newRow = append(newRow, row...)
-	curDistincts = make([]sqltypes.Value, len(aggregates))
+	curDistincts = make([][]sqltypes.Value, len(aggregates))
 	for index, aggr := range aggregates {
 		switch aggr.Opcode {
 		case AggregateCountStar:
@@ -371,7 +405,7 @@ func convertRow(row []sqltypes.Value, preProcess bool, aggregates []*AggregatePa
 		case AggregateCountDistinct:
 			curDistincts[index] = findComparableCurrentDistinct(row, aggr)
 			// Type is int64. Ok to call MakeTrusted.
-			if row[aggr.KeyCol].IsNull() {
+			if hasNullKey(row, aggr) {
 				newRow[aggr.Col] = countZero
 			} else {
 				newRow[aggr.Col] = countOne
@@ -395,6 +429,22 @@ func convertRow(row []sqltypes.Value, preProcess bool, aggregates []*AggregatePa
 			if err != nil {
 				newRow[aggr.Col] = sumZero
 			}
+		case AggregateGroupConcatDistinct:
+			curDistincts[index] = findComparableCurrentDistinct(row, aggr)
+			newRow[aggr.Col] = groupConcatValue(row[aggr.Col])
+		case AggregateGroupConcat:
+			if aggrOnEngine {
+				newRow[aggr.Col] = groupConcatValue(row[aggr.Col])
+			}
+		case AggregateBitAnd, AggregateBitOr, AggregateBitXor:
+			if !aggrOnEngine {
+				break
+			}
+			if row[aggr.Col].IsNull() {
+				newRow[aggr.Col], _ = createEmptyValueFor(aggr.Opcode)
+				break
+			}
+			newRow[aggr.Col] = sqltypes.NewUint64(toBitValue(row[aggr.Col]))
 		case AggregateGtid:
 			vgtid := &binlogdatapb.VGtid{}
 			vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{
@@ -410,13 +460,74 @@ func convertRow(row []sqltypes.Value, preProcess bool, aggregates []*AggregatePa
 	return newRow, curDistincts
 }
 
-func findComparableCurrentDistinct(row []sqltypes.Value, aggr *AggregateParams) sqltypes.Value {
+func findComparableCurrentDistinct(row []sqltypes.Value, aggr *AggregateParams) []sqltypes.Value {
 	curDistinct := row[aggr.KeyCol]
 	if aggr.WAssigned && !curDistinct.IsComparable() {
 		aggr.KeyCol = aggr.WCol
 		curDistinct = row[aggr.KeyCol]
 	}
-	return curDistinct
+	curDistincts := []sqltypes.Value{curDistinct}
+	for _, key := range aggr.ExtraKeys {
+		val := row[key.KeyCol]
+		if key.WeightStringCol != -1 && !val.IsComparable() {
+			key.KeyCol = key.WeightStringCol
+			val = row[key.KeyCol]
+		}
+		curDistincts = append(curDistincts, val)
+	}
+	return curDistincts
+}
+
+// hasNullKey returns true if any of the distinct keys of the aggregation is NULL in the row.
+// Such rows are not counted by distinct aggregations.
+func hasNullKey(row []sqltypes.Value, aggr *AggregateParams) bool {
+	if row[aggr.KeyCol].IsNull() {
+		return true
+	}
+	for _, key := range aggr.ExtraKeys {
+		if row[key.KeyCol].IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
+// distinctKeysEqual compares the distinct keys of the aggregation in the row with the current distinct values.
+func distinctKeysEqual(curDistincts []sqltypes.Value, row []sqltypes.Value, aggr *AggregateParams, colls map[int]collations.ID) (bool, error) {
+	cmp, err := evalengine.NullsafeCompare(curDistincts[0], row[aggr.KeyCol], colls[aggr.KeyCol])
+	if err != nil || cmp != 0 {
+		return false, err
+	}
+	for i, key := range aggr.ExtraKeys {
+		cmp, err = evalengine.NullsafeCompare(curDistincts[i+1], row[key.KeyCol], colls[key.KeyCol])
+		if err != nil || cmp != 0 {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// groupConcatValue converts a raw value to the string type GROUP_CONCAT returns
+func groupConcatValue(v sqltypes.Value) sqltypes.Value {
+	if v.IsNull() || sqltypes.IsText(v.Type()) || sqltypes.IsBinary(v.Type()) {
+		return v
+	}
+	return sqltypes.MakeTrusted(sqltypes.VarChar, v.Raw())
+}
+
+// toBitValue converts a value to the unsigned 64-bit integer the bit aggregations operate on.
+// Like MySQL, negative numbers use their two's complement and values that can't be converted count as 0.
+func toBitValue(v sqltypes.Value) uint64 {
+	if v.IsSigned() {
+		i, _ := evalengine.ToInt64(v)
+		return uint64(i)
+	}
+	u, err := evalengine.ToUint64(v)
+	if err != nil {
+		f, _ := evalengine.ToFloat64(v)
+		return uint64(int64(f))
+	}
+	return u
 }
 
 // GetFields is a Primitive function.
@@ -464,21 +575,21 @@ func (oa *OrderedAggregate) keysEqual(row1, row2 []sqltypes.Value, colls map[int
 func merge(
 	fields []*querypb.Field,
 	row1, row2 []sqltypes.Value,
-	curDistincts []sqltypes.Value,
+	curDistincts [][]sqltypes.Value,
 	colls map[int]collations.ID,
 	aggregates []*AggregateParams,
-) ([]sqltypes.Value, []sqltypes.Value, error) {
+) ([]sqltypes.Value, [][]sqltypes.Value, error) {
 	result := sqltypes.CopyRow(row1)
 	for index, aggr := range aggregates {
 		if aggr.isDistinct() {
-			if row2[aggr.KeyCol].IsNull() {
+			if hasNullKey(row2, aggr) {
 				continue
 			}
-			cmp, err := evalengine.NullsafeCompare(curDistincts[index], row2[aggr.KeyCol], colls[aggr.KeyCol])
+			equal, err := distinctKeysEqual(curDistincts[index], row2, aggr, colls)
 			if err != nil {
 				return nil, nil, err
 			}
-			if cmp == 0 {
+			if equal {
 				continue
 			}
 			curDistincts[index] = findComparableCurrentDistinct(row2, aggr)
@@ -528,6 +639,14 @@ func merge(
 			data, _ := proto.Marshal(vgtid)
 			val, _ := sqltypes.NewValue(sqltypes.VarBinary, data)
 			result[aggr.Col] = val
+		case AggregateGroupConcat, AggregateGroupConcatDistinct:
+			result[aggr.Col] = mergeGroupConcat(fields[aggr.Col].Type, row1[aggr.Col], row2[aggr.Col], aggr.Separator)
+		case AggregateBitAnd, AggregateBitOr, AggregateBitXor:
+			result[aggr.Col], err = mergeBits(aggr.Opcode, row1[aggr.Col], row2[aggr.Col])
+		case AggregateJSONArrayAgg:
+			result[aggr.Col], err = evalengine.MergeJSONArrays(row1[aggr.Col], row2[aggr.Col])
+		case AggregateJSONObjectAgg:
+			result[aggr.Col], err = evalengine.MergeJSONObjects(row1[aggr.Col], row2[aggr.Col])
 		case AggregateRandom:
 			// we just grab the first value per grouping. no need to do anything more complicated here
 		default:
@@ -540,6 +659,42 @@ func merge(
 	return result, curDistincts, nil
 }
 
+// mergeGroupConcat joins two GROUP_CONCAT values using the separator, skipping NULL values
+func mergeGroupConcat(typ querypb.Type, v1, v2 sqltypes.Value, separator string) sqltypes.Value {
+	switch {
+	case v2.IsNull():
+		return v1
+	case v1.IsNull():
+		return sqltypes.MakeTrusted(typ, v2.Raw())
+	}
+	concat := make([]byte, 0, len(v1.Raw())+len(separator)+len(v2.Raw()))
+	concat = append(concat, v1.Raw()...)
+	concat = append(concat, separator...)
+	concat = append(concat, v2.Raw()...)
+	return sqltypes.MakeTrusted(typ, concat)
+}
+
+// mergeBits combines the current result of BIT_AND, BIT_OR or BIT_XOR with the value from the next row,
+// which is either a partial result or, when aggregating on the vtgate, the raw value
+func mergeBits(opcode AggregateOpcode, v1, v2 sqltypes.Value) (sqltypes.Value, error) {
+	if v2.IsNull() {
+		return v1, nil
+	}
+	u1, err := evalengine.ToUint64(v1)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	u2 := toBitValue(v2)
+	switch opcode {
+	case AggregateBitAnd:
+		return sqltypes.NewUint64(u1 & u2), nil
+	case AggregateBitOr:
+		return sqltypes.NewUint64(u1 | u2), nil
+	default:
+		return sqltypes.NewUint64(u1 ^ u2), nil
+	}
+}
+
 func aggregateParamsToString(in any) string {
 	return in.(*AggregateParams).String()
 }
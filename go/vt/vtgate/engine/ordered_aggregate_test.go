@@ -1123,3 +1123,223 @@ func TestOrderedAggregateCollateKS(t *testing.T) {
 	)
 	assert.Equal(wantResult, result)
 }
+
+func TestOrderedAggregateGroupConcat(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|group_concat(c2 separator '-')",
+		"int64|varchar",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"1|a",
+			"1|null",
+			"1|b-c",
+			"2|null",
+			"3|null",
+			"3|d",
+			"3|",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates: []*AggregateParams{{
+			Opcode:    AggregateGroupConcat,
+			Col:       1,
+			Separator: "-",
+		}},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		fields,
+		"1|a-b-c",
+		"2|null",
+		"3|d-",
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, qr)
+}
+
+func TestOrderedAggregateGroupConcatDistinct(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"c1|c2",
+				"int64|int64",
+			),
+			"1|null",
+			"1|1",
+			"1|1",
+			"1|2",
+			"2|null",
+			"3|3",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		PreProcess: true,
+		Aggregates: []*AggregateParams{{
+			Opcode:    AggregateGroupConcatDistinct,
+			Col:       1,
+			Alias:     "group_concat(distinct c2)",
+			Separator: ",",
+		}},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|group_concat(distinct c2)",
+			"int64|varchar",
+		),
+		"1|1,2",
+		"2|null",
+		"3|3",
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, qr)
+}
+
+func TestOrderedAggregateCountDistinctMultipleKeys(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3",
+		"int64|int64|varchar",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"1|1|a",
+			"1|1|a",
+			"1|1|b",
+			"1|2|a",
+			"1|2|null",
+			"2|null|a",
+			"2|1|null",
+			"3|1|a",
+			"3|2|a",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		PreProcess: true,
+		Aggregates: []*AggregateParams{{
+			Opcode:    AggregateCountDistinct,
+			Col:       1,
+			ExtraKeys: []*GroupByParams{{KeyCol: 2, WeightStringCol: -1}},
+			Alias:     "count(distinct c2, c3)",
+		}},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Collations:  map[int]collations.ID{2: collations.CollationUtf8mb4ID},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|count(distinct c2, c3)|c3",
+			"int64|int64|varchar",
+		),
+		"1|3|a",
+		"2|0|a",
+		"3|2|a",
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, qr)
+	assert.Equal(t, "count_distinct(1, 2) AS count(distinct c2, c3)", oa.Aggregates[0].String())
+}
+
+func TestOrderedAggregateBitOperations(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"c1|c2|c3|c4",
+				"int64|int64|int64|int64",
+			),
+			"1|12|12|12",
+			"1|10|10|10",
+			"1|null|null|null",
+			"2|-1|-1|-1",
+			"3|null|null|null",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		PreProcess:   true,
+		AggrOnEngine: true,
+		Aggregates: []*AggregateParams{{
+			Opcode: AggregateBitAnd,
+			Col:    1,
+			Alias:  "bit_and(c2)",
+		}, {
+			Opcode: AggregateBitOr,
+			Col:    2,
+			Alias:  "bit_or(c2)",
+		}, {
+			Opcode: AggregateBitXor,
+			Col:    3,
+			Alias:  "bit_xor(c2)",
+		}},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|bit_and(c2)|bit_or(c2)|bit_xor(c2)",
+			"int64|uint64|uint64|uint64",
+		),
+		"1|8|14|6",
+		"2|18446744073709551615|18446744073709551615|18446744073709551615",
+		"3|18446744073709551615|0|0",
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, qr)
+}
+
+func TestOrderedAggregateJSONAggregations(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|json_arrayagg(c2)|json_objectagg(c2, c3)",
+		"int64|json|json",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			`1|[1, 2]|{"a": 1}`,
+			`1|[null]|{"b": 2}`,
+			`2|[3]|{"a": 1}`,
+			`2|[4]|{"a": 2}`,
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates: []*AggregateParams{{
+			Opcode: AggregateJSONArrayAgg,
+			Col:    1,
+		}, {
+			Opcode: AggregateJSONObjectAgg,
+			Col:    2,
+		}},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		fields,
+		`1|[1, 2, null]|{"a": 1, "b": 2}`,
+		`2|[3, 4]|{"a": 2}`,
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, qr)
+}
@@ -53,6 +53,10 @@ type (
 		// if the max memory rows override directive is set to true
 		ExceedsMaxMemoryRows(numRows int) bool
 
+		// HashJoinMemoryLimit returns the size in bytes above which the build side
+		// of a hash join is moved to disk. Zero means that it is always kept in memory.
+		HashJoinMemoryLimit() int64
+
 		// V3 functions.
 		Execute(ctx context.Context, method string, query string, bindVars map[string]*querypb.BindVariable, rollbackOnError bool, co vtgatepb.CommitOrder) (*sqltypes.Result, error)
 		AutocommitApproval() bool
@@ -161,6 +165,8 @@ type (
 		// SetReadAfterWriteGTID sets the GTID that the user expects a replica to have caught up with before answering a query
 		SetReadAfterWriteGTID(string)
 		SetReadAfterWriteTimeout(float64)
+		// SetReadAfterWriteConsistency makes the replica reads of the session wait for its own writes
+		SetReadAfterWriteConsistency(context.Context, bool) error
 		SetSessionTrackGTIDs(bool)
 
 		// HasCreatedTempTable will mark the session as having created temp tables
@@ -0,0 +1,188 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var _ Primitive = (*RecurseCTE)(nil)
+
+// RecurseCTE evaluates a recursive common table expression at the vtgate level.
+// The Seed is executed once, and then the Term is executed once for every row
+// produced by the previous iteration, with the columns of that row passed in as
+// bind variables, until an iteration produces no new rows.
+// The rows of all iterations are buffered, so the total number of rows is capped
+// by the max_memory_rows flag, which also stops runaway recursions.
+type RecurseCTE struct {
+	// Seed is the non-recursive part of the CTE.
+	Seed Primitive
+
+	// Term is the recursive part of the CTE.
+	Term Primitive
+
+	// Vars defines the bind variables that are built from the rows
+	// of the previous iteration before invoking the Term.
+	Vars map[string]int `json:",omitempty"`
+
+	// CheckCols is set when the CTE uses UNION DISTINCT: rows that have
+	// already been produced are discarded and do not recurse again.
+	CheckCols []CheckCol `json:",omitempty"`
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (r *RecurseCTE) RouteType() string {
+	return "RecurseCTE"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (r *RecurseCTE) GetKeyspaceName() string {
+	if r.Seed.GetKeyspaceName() == r.Term.GetKeyspaceName() {
+		return r.Seed.GetKeyspaceName()
+	}
+	return r.Seed.GetKeyspaceName() + "_" + r.Term.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (r *RecurseCTE) GetTableName() string {
+	return r.Seed.GetTableName()
+}
+
+// TryExecute performs a non-streaming exec.
+func (r *RecurseCTE) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	seed, err := vcursor.ExecutePrimitive(ctx, r.Seed, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{Fields: seed.Fields}
+	err = r.recurse(ctx, vcursor, bindVars, seed.Rows, func(rows []sqltypes.Row) error {
+		result.Rows = append(result.Rows, rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TryStreamExecute performs a streaming exec.
+func (r *RecurseCTE) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	// the seed must be fully read before we can start recursing
+	seed, err := vcursor.ExecutePrimitive(ctx, r.Seed, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	if wantfields {
+		if err := callback(&sqltypes.Result{Fields: seed.Fields}); err != nil {
+			return err
+		}
+	}
+	return r.recurse(ctx, vcursor, bindVars, seed.Rows, func(rows []sqltypes.Row) error {
+		return callback(&sqltypes.Result{Rows: rows})
+	})
+}
+
+// recurse runs the Term until no new rows are produced, handing the rows
+// of every iteration, starting with the seed rows, to the given function
+func (r *RecurseCTE) recurse(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, rows []sqltypes.Row, emit func([]sqltypes.Row) error) error {
+	var pt *probeTable
+	if len(r.CheckCols) > 0 {
+		pt = newProbeTable(r.CheckCols)
+	}
+
+	total := 0
+	termVars := make(map[string]*querypb.BindVariable, len(r.Vars))
+	for {
+		if pt != nil {
+			var err error
+			if rows, err = r.discardSeen(pt, rows); err != nil {
+				return err
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		total += len(rows)
+		if vcursor.ExceedsMaxMemoryRows(total) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		if err := emit(rows); err != nil {
+			return err
+		}
+
+		var next []sqltypes.Row
+		for _, row := range rows {
+			for k, col := range r.Vars {
+				termVars[k] = sqltypes.ValueBindVariable(row[col])
+			}
+			qr, err := vcursor.ExecutePrimitive(ctx, r.Term, combineVars(bindVars, termVars), false)
+			if err != nil {
+				return err
+			}
+			next = append(next, qr.Rows...)
+		}
+		rows = next
+	}
+}
+
+func (r *RecurseCTE) discardSeen(pt *probeTable, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	var unseen []sqltypes.Row
+	for _, row := range rows {
+		exists, err := pt.exists(row)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			unseen = append(unseen, row)
+		}
+	}
+	return unseen, nil
+}
+
+// GetFields fetches the field info.
+func (r *RecurseCTE) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return r.Seed.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs returns the input primitives for this recursive CTE
+func (r *RecurseCTE) Inputs() []Primitive {
+	return []Primitive{r.Seed, r.Term}
+}
+
+// NeedsTransaction implements the Primitive interface
+func (r *RecurseCTE) NeedsTransaction() bool {
+	return r.Seed.NeedsTransaction() || r.Term.NeedsTransaction()
+}
+
+func (r *RecurseCTE) description() PrimitiveDescription {
+	other := map[string]any{}
+	if len(r.Vars) > 0 {
+		other["JoinVars"] = orderedStringIntMap(r.Vars)
+	}
+	if len(r.CheckCols) > 0 {
+		other["Distinct"] = GenericJoin(r.CheckCols, checkColToString)
+	}
+	return PrimitiveDescription{
+		OperatorType: "RecurseCTE",
+		Other:        other,
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestRecurseCTEExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"id|name",
+		"int64|varchar",
+	)
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1|boss"),
+		},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "2|a", "3|b"),
+			sqltypes.MakeTestResult(fields, "4|c"),
+			sqltypes.MakeTestResult(fields),
+			sqltypes.MakeTestResult(fields),
+		},
+	}
+	bv := map[string]*querypb.BindVariable{
+		"a": sqltypes.Int64BindVariable(10),
+	}
+
+	cte := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{"cte_id": 0},
+	}
+	r, err := cte.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	seed.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" true`,
+	})
+	term.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" cte_id: type:INT64 value:"1" false`,
+		`Execute a: type:INT64 value:"10" cte_id: type:INT64 value:"2" false`,
+		`Execute a: type:INT64 value:"10" cte_id: type:INT64 value:"3" false`,
+		`Execute a: type:INT64 value:"10" cte_id: type:INT64 value:"4" false`,
+	})
+	expectResult(t, "cte.Execute", r, sqltypes.MakeTestResult(
+		fields,
+		"1|boss",
+		"2|a",
+		"3|b",
+		"4|c",
+	))
+
+	// streaming
+	seed.rewind()
+	term.rewind()
+	r, err = wrapStreamExecute(cte, &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	expectResult(t, "cte.StreamExecute", r, sqltypes.MakeTestResult(
+		fields,
+		"1|boss",
+		"2|a",
+		"3|b",
+		"4|c",
+	))
+}
+
+func TestRecurseCTEDistinct(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"id",
+		"int64",
+	)
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1", "1"),
+		},
+	}
+	// a cycle: 1 -> 2 -> 1
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "2"),
+			sqltypes.MakeTestResult(fields, "1"),
+		},
+	}
+
+	cte := &RecurseCTE{
+		Seed:      seed,
+		Term:      term,
+		Vars:      map[string]int{"cte_id": 0},
+		CheckCols: []CheckCol{{Col: 0, Collation: collations.CollationBinaryID}},
+	}
+	r, err := cte.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	term.ExpectLog(t, []string{
+		`Execute cte_id: type:INT64 value:"1" false`,
+		`Execute cte_id: type:INT64 value:"2" false`,
+	})
+	expectResult(t, "cte.Execute", r, sqltypes.MakeTestResult(
+		fields,
+		"1",
+		"2",
+	))
+}
+
+func TestRecurseCTEMaxMemoryRows(t *testing.T) {
+	saveMax := testMaxMemoryRows
+	saveIgnore := testIgnoreMaxMemoryRows
+	testMaxMemoryRows = 3
+	defer func() {
+		testMaxMemoryRows = saveMax
+		testIgnoreMaxMemoryRows = saveIgnore
+	}()
+
+	testCases := []struct {
+		ignoreMaxMemoryRows bool
+		err                 string
+	}{
+		{true, ""},
+		{false, "in-memory row count exceeded allowed limit of 3"},
+	}
+	fields := sqltypes.MakeTestFields(
+		"id",
+		"int64",
+	)
+	for _, test := range testCases {
+		seed := &fakePrimitive{
+			results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(fields, "1", "2"),
+			},
+		}
+		term := &fakePrimitive{
+			results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(fields, "3"),
+				sqltypes.MakeTestResult(fields, "4"),
+				sqltypes.MakeTestResult(fields),
+				sqltypes.MakeTestResult(fields),
+			},
+		}
+		cte := &RecurseCTE{
+			Seed: seed,
+			Term: term,
+			Vars: map[string]int{"cte_id": 0},
+		}
+
+		testIgnoreMaxMemoryRows = test.ignoreMaxMemoryRows
+		_, err := cte.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+		if testIgnoreMaxMemoryRows {
+			require.NoError(t, err)
+		} else {
+			require.EqualError(t, err, test.err)
+		}
+	}
+}
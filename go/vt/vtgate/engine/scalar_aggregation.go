@@ -95,7 +95,7 @@ func (sa *ScalarAggregate) TryExecute(ctx context.Context, vcursor VCursor, bind
 	}
 
 	var resultRow []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curDistincts [][]sqltypes.Value
 	for _, row := range result.Rows {
 		if resultRow == nil {
 			resultRow, curDistincts = convertRow(row, sa.PreProcess, sa.Aggregates, sa.AggrOnEngine)
@@ -131,7 +131,7 @@ func (sa *ScalarAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor
 		return callback(qr.Truncate(sa.TruncateColumnCount))
 	}
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curDistincts [][]sqltypes.Value
 	var fields []*querypb.Field
 	fieldsSent := false
 	var mu sync.Mutex
@@ -213,8 +213,18 @@ func createEmptyValueFor(opcode AggregateOpcode) (sqltypes.Value, error) {
 		AggregateSumDistinct,
 		AggregateSum,
 		AggregateMin,
-		AggregateMax:
+		AggregateMax,
+		AggregateGroupConcat,
+		AggregateGroupConcatDistinct,
+		AggregateJSONArrayAgg,
+		AggregateJSONObjectAgg:
 		return sqltypes.NULL, nil
+	case
+		AggregateBitOr,
+		AggregateBitXor:
+		return bitZero, nil
+	case AggregateBitAnd:
+		return bitOnes, nil
 
 	}
 	return sqltypes.NULL, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "unknown aggregation %v", opcode)
@@ -33,6 +33,7 @@ func TestEmptyRows(outer *testing.T) {
 		origOpcode  AggregateOpcode
 		expectedVal string
 		expectedTyp string
+		inputTyp    string
 	}{{
 		opcode:      AggregateCountDistinct,
 		expectedVal: "0",
@@ -62,16 +63,54 @@ func TestEmptyRows(outer *testing.T) {
 		opcode:      AggregateMin,
 		expectedVal: "null",
 		expectedTyp: "int64",
+	}, {
+		opcode:      AggregateGroupConcat,
+		expectedVal: "null",
+		expectedTyp: "varchar",
+		inputTyp:    "varchar",
+	}, {
+		opcode:      AggregateGroupConcatDistinct,
+		expectedVal: "null",
+		expectedTyp: "varchar",
+	}, {
+		opcode:      AggregateBitAnd,
+		expectedVal: "18446744073709551615",
+		expectedTyp: "uint64",
+		inputTyp:    "uint64",
+	}, {
+		opcode:      AggregateBitOr,
+		expectedVal: "0",
+		expectedTyp: "uint64",
+		inputTyp:    "uint64",
+	}, {
+		opcode:      AggregateBitXor,
+		expectedVal: "0",
+		expectedTyp: "uint64",
+		inputTyp:    "uint64",
+	}, {
+		opcode:      AggregateJSONArrayAgg,
+		expectedVal: "null",
+		expectedTyp: "json",
+		inputTyp:    "json",
+	}, {
+		opcode:      AggregateJSONObjectAgg,
+		expectedVal: "null",
+		expectedTyp: "json",
+		inputTyp:    "json",
 	}}
 
 	for _, test := range testCases {
 		outer.Run(test.opcode.String(), func(t *testing.T) {
 			assert := assert.New(t)
+			inputTyp := test.inputTyp
+			if inputTyp == "" {
+				inputTyp = "int64"
+			}
 			fp := &fakePrimitive{
 				results: []*sqltypes.Result{sqltypes.MakeTestResult(
 					sqltypes.MakeTestFields(
 						test.opcode.String(),
-						"int64",
+						inputTyp,
 					),
 					// Empty input table
 				)},
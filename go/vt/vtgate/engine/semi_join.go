@@ -45,25 +45,32 @@ type SemiJoin struct {
 	// be built from the LHS result before invoking
 	// the RHS subqquery.
 	Vars map[string]int `json:",omitempty"`
+
+	// AntiJoin is set for NOT EXISTS subqueries: only the
+	// left rows for which the RHS returns no row are kept.
+	AntiJoin bool `json:",omitempty"`
 }
 
 // TryExecute performs a non-streaming exec.
 func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
-	joinVars := make(map[string]*querypb.BindVariable)
 	lresult, err := vcursor.ExecutePrimitive(ctx, jn.Left, bindVars, wantfields)
 	if err != nil {
 		return nil, err
 	}
 	result := &sqltypes.Result{Fields: projectFields(lresult.Fields, jn.Cols)}
+	matches := newCorrelationCache[bool](jn.Vars)
 	for _, lrow := range lresult.Rows {
-		for k, col := range jn.Vars {
-			joinVars[k] = sqltypes.ValueBindVariable(lrow[col])
-		}
-		rresult, err := vcursor.ExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false)
+		matched, err := matches.get(lrow, func(joinVars map[string]*querypb.BindVariable) (bool, error) {
+			rresult, err := vcursor.ExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false)
+			if err != nil {
+				return false, err
+			}
+			return len(rresult.Rows) > 0, nil
+		})
 		if err != nil {
 			return nil, err
 		}
-		if len(rresult.Rows) > 0 {
+		if matched != jn.AntiJoin {
 			result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
 		}
 	}
@@ -72,24 +79,24 @@ func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 
 // TryStreamExecute performs a streaming exec.
 func (jn *SemiJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
-	joinVars := make(map[string]*querypb.BindVariable)
+	matches := newCorrelationCache[bool](jn.Vars)
 	err := vcursor.StreamExecutePrimitive(ctx, jn.Left, bindVars, wantfields, func(lresult *sqltypes.Result) error {
 		result := &sqltypes.Result{Fields: projectFields(lresult.Fields, jn.Cols)}
 		for _, lrow := range lresult.Rows {
-			for k, col := range jn.Vars {
-				joinVars[k] = sqltypes.ValueBindVariable(lrow[col])
-			}
-			rowAdded := false
-			err := vcursor.StreamExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false, func(rresult *sqltypes.Result) error {
-				if len(rresult.Rows) > 0 && !rowAdded {
-					result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
-					rowAdded = true
-				}
-				return nil
+			matched, err := matches.get(lrow, func(joinVars map[string]*querypb.BindVariable) (bool, error) {
+				rowFound := false
+				err := vcursor.StreamExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false, func(rresult *sqltypes.Result) error {
+					rowFound = rowFound || len(rresult.Rows) > 0
+					return nil
+				})
+				return rowFound, err
 			})
 			if err != nil {
 				return err
 			}
+			if matched != jn.AntiJoin {
+				result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
+			}
 		}
 		return callback(result)
 	})
@@ -137,10 +144,14 @@ func (jn *SemiJoin) description() PrimitiveDescription {
 	if len(jn.Vars) > 0 {
 		other["JoinVars"] = orderedStringIntMap(jn.Vars)
 	}
-	return PrimitiveDescription{
+	desc := PrimitiveDescription{
 		OperatorType: "SemiJoin",
 		Other:        other,
 	}
+	if jn.AntiJoin {
+		desc.Variant = "AntiJoin"
+	}
+	return desc
 }
 
 func projectFields(lfields []*querypb.Field, cols []int) []*querypb.Field {
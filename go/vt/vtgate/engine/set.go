@@ -526,6 +526,8 @@ func (svss *SysVarSetAware) Execute(ctx context.Context, vcursor VCursor, env *e
 			return err
 		}
 		vcursor.Session().SetReadAfterWriteTimeout(val)
+	case sysvars.ReadAfterWriteConsistency.Name:
+		err = svss.setBoolSysVar(ctx, env, vcursor.Session().SetReadAfterWriteConsistency)
 	case sysvars.SessionTrackGTIDs.Name:
 		str, err := svss.evalAsString(env)
 		if err != nil {
@@ -76,6 +76,18 @@ func (upd *Update) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	}
 }
 
+// execOnShards implements the shardedDML interface.
+func (upd *Update) execOnShards(ctx context.Context, vcursor VCursor, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	ctx, cancelFunc := addQueryTimeout(ctx, vcursor, upd.QueryTimeout)
+	defer cancelFunc()
+
+	err := allowOnlyPrimary(rss...)
+	if err != nil {
+		return nil, err
+	}
+	return upd.execShardsWithBindVars(ctx, upd, vcursor, rss, bindVars, upd.updateVindexEntries)
+}
+
 // TryStreamExecute performs a streaming exec.
 func (upd *Update) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	res, err := upd.TryExecute(ctx, vcursor, bindVars, wantfields)
@@ -0,0 +1,471 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// Window is a primitive that evaluates window functions at the vtgate level.
+// It expects the underlying primitive to feed rows sorted by the PARTITION BY
+// columns followed by the ORDER BY columns of the window. Rows are buffered one
+// partition at a time, and the result of each window function is written into
+// the column at the function's Col offset. All other columns are passed through.
+type Window struct {
+	// PartitionBy are the columns that delimit the partitions of the window.
+	PartitionBy []CheckCol
+
+	// OrderBy are the columns of the window ORDER BY clause.
+	// Rows that are equal on all of them are peers, which matters for
+	// RANK, DENSE_RANK and the aggregate window functions.
+	OrderBy []CheckCol
+
+	// Functions are the window functions to evaluate.
+	Functions []*WindowFuncParams
+
+	// TruncateColumnCount specifies the number of columns to return
+	// in the final result. Rest of the columns are truncated
+	// from the result received. If 0, no truncation happens.
+	TruncateColumnCount int `json:",omitempty"`
+
+	// Input is the primitive that will feed into this Primitive.
+	Input Primitive
+}
+
+// WindowFuncParams specify the parameters for each window function.
+type WindowFuncParams struct {
+	Opcode WindowOpcode
+
+	// Col is the column the result is written to.
+	// For functions that take an argument, it also holds the argument in the input.
+	Col int
+
+	// Offset is the N argument of LAG and LEAD.
+	Offset int
+
+	// DefaultCol holds the default value of LAG and LEAD, or -1 if there is none.
+	DefaultCol int
+
+	Alias string `json:",omitempty"`
+	Expr  sqlparser.Expr
+}
+
+// WindowOpcode is the window function opcode.
+type WindowOpcode int
+
+// These constants list the possible window function opcodes.
+const (
+	WindowUnassigned = WindowOpcode(iota)
+	WindowRowNumber
+	WindowRank
+	WindowDenseRank
+	WindowLag
+	WindowLead
+	WindowSum
+	WindowCount
+	WindowCountStar
+)
+
+// SupportedWindowFunctions maps the window functions that can be
+// evaluated at the vtgate level to their opcodes.
+var SupportedWindowFunctions = map[string]WindowOpcode{
+	"row_number": WindowRowNumber,
+	"rank":       WindowRank,
+	"dense_rank": WindowDenseRank,
+	"lag":        WindowLag,
+	"lead":       WindowLead,
+	"sum":        WindowSum,
+	"count":      WindowCount,
+	// This function doesn't exist in mysql, but is used
+	// to display the plan.
+	"count_star": WindowCountStar,
+}
+
+func (code WindowOpcode) String() string {
+	for k, v := range SupportedWindowFunctions {
+		if v == code {
+			return k
+		}
+	}
+	return "ERROR"
+}
+
+// MarshalJSON serializes the WindowOpcode as a JSON string.
+// It's used for testing and diagnostics.
+func (code WindowOpcode) MarshalJSON() ([]byte, error) {
+	return ([]byte)(fmt.Sprintf("\"%s\"", code.String())), nil
+}
+
+func (wf *WindowFuncParams) String() string {
+	var args string
+	switch wf.Opcode {
+	case WindowLag, WindowLead:
+		args = fmt.Sprintf("%d, %d", wf.Col, wf.Offset)
+		if wf.DefaultCol >= 0 {
+			args = fmt.Sprintf("%s, %d", args, wf.DefaultCol)
+		}
+	case WindowSum, WindowCount:
+		args = fmt.Sprintf("%d", wf.Col)
+	}
+	out := fmt.Sprintf("%s(%s)", wf.Opcode.String(), args)
+	if wf.Alias != "" {
+		out += " AS " + wf.Alias
+	}
+	return out
+}
+
+// resultType returns the type of the window function result given the type of its input column.
+func (wf *WindowFuncParams) resultType(input querypb.Type) querypb.Type {
+	switch wf.Opcode {
+	case WindowRowNumber, WindowRank, WindowDenseRank, WindowCount, WindowCountStar:
+		return sqltypes.Int64
+	case WindowSum:
+		if sqltypes.IsFloat(input) {
+			return sqltypes.Float64
+		}
+		return sqltypes.Decimal
+	default:
+		return input
+	}
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (w *Window) RouteType() string {
+	return w.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (w *Window) GetKeyspaceName() string {
+	return w.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (w *Window) GetTableName() string {
+	return w.Input.GetTableName()
+}
+
+// SetTruncateColumnCount sets the truncate column count.
+func (w *Window) SetTruncateColumnCount(count int) {
+	w.TruncateColumnCount = count
+}
+
+// TryExecute is a Primitive function.
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	result, err := vcursor.ExecutePrimitive(ctx, w.Input, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+	out := &sqltypes.Result{
+		Fields: w.convertFields(result.Fields),
+		Rows:   make([][]sqltypes.Value, 0, len(result.Rows)),
+	}
+
+	start := 0
+	for i := 1; i <= len(result.Rows); i++ {
+		if i < len(result.Rows) {
+			same, err := rowsEqualOn(result.Rows[start], result.Rows[i], w.PartitionBy)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		rows, err := w.evaluatePartition(result.Rows[start:i])
+		if err != nil {
+			return nil, err
+		}
+		out.Rows = append(out.Rows, rows...)
+		if vcursor.ExceedsMaxMemoryRows(len(out.Rows)) {
+			return nil, fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		start = i
+	}
+	return out.Truncate(w.TruncateColumnCount), nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	var partition []sqltypes.Row
+
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(w.TruncateColumnCount))
+	}
+
+	flush := func() error {
+		rows, err := w.evaluatePartition(partition)
+		if err != nil {
+			return err
+		}
+		partition = nil
+		return cb(&sqltypes.Result{Rows: rows})
+	}
+
+	err := vcursor.StreamExecutePrimitive(ctx, w.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		if len(qr.Fields) != 0 {
+			if err := cb(&sqltypes.Result{Fields: w.convertFields(qr.Fields)}); err != nil {
+				return err
+			}
+		}
+		for _, row := range qr.Rows {
+			if len(partition) > 0 {
+				same, err := rowsEqualOn(partition[0], row, w.PartitionBy)
+				if err != nil {
+					return err
+				}
+				if !same {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+			partition = append(partition, row)
+		}
+		if vcursor.ExceedsMaxMemoryRows(len(partition)) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(partition) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// GetFields is a Primitive function.
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	qr = &sqltypes.Result{Fields: w.convertFields(qr.Fields)}
+	return qr.Truncate(w.TruncateColumnCount), nil
+}
+
+// Inputs returns the Primitive input for this window
+func (w *Window) Inputs() []Primitive {
+	return []Primitive{w.Input}
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+func (w *Window) convertFields(fields []*querypb.Field) []*querypb.Field {
+	if fields == nil {
+		return nil
+	}
+	out := make([]*querypb.Field, len(fields))
+	copy(out, fields)
+	for _, wf := range w.Functions {
+		out[wf.Col] = &querypb.Field{
+			Name: wf.Alias,
+			Type: wf.resultType(fields[wf.Col].Type),
+		}
+	}
+	return out
+}
+
+// evaluatePartition computes all window functions over the rows of a single partition.
+// The input rows are not modified.
+func (w *Window) evaluatePartition(partition []sqltypes.Row) ([]sqltypes.Row, error) {
+	if len(partition) == 0 {
+		return nil, nil
+	}
+
+	// peerEnd[i] is the index one past the last peer of row i,
+	// and peerStart[i] the index of its first peer.
+	peerStart := make([]int, len(partition))
+	peerEnd := make([]int, len(partition))
+	start := 0
+	for i := 1; i <= len(partition); i++ {
+		if i < len(partition) {
+			same, err := rowsEqualOn(partition[start], partition[i], w.OrderBy)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				continue
+			}
+		}
+		for j := start; j < i; j++ {
+			peerStart[j] = start
+			peerEnd[j] = i
+		}
+		start = i
+	}
+
+	out := make([]sqltypes.Row, len(partition))
+	for i, row := range partition {
+		out[i] = sqltypes.CopyRow(row)
+	}
+
+	for _, wf := range w.Functions {
+		switch wf.Opcode {
+		case WindowRowNumber:
+			for i := range partition {
+				out[i][wf.Col] = sqltypes.NewInt64(int64(i + 1))
+			}
+		case WindowRank:
+			for i := range partition {
+				out[i][wf.Col] = sqltypes.NewInt64(int64(peerStart[i] + 1))
+			}
+		case WindowDenseRank:
+			rank := int64(0)
+			for i := range partition {
+				if peerStart[i] == i {
+					rank++
+				}
+				out[i][wf.Col] = sqltypes.NewInt64(rank)
+			}
+		case WindowLag, WindowLead:
+			offset := wf.Offset
+			if wf.Opcode == WindowLag {
+				offset = -offset
+			}
+			for i := range partition {
+				src := i + offset
+				switch {
+				case src >= 0 && src < len(partition):
+					out[i][wf.Col] = partition[src][wf.Col]
+				case wf.DefaultCol >= 0:
+					out[i][wf.Col] = partition[i][wf.DefaultCol]
+				default:
+					out[i][wf.Col] = sqltypes.NULL
+				}
+			}
+		case WindowSum, WindowCount, WindowCountStar:
+			if err := evaluateFrameAggregate(wf, partition, out, peerEnd); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, vterrors.VT13001(fmt.Sprintf("unexpected window function opcode: %v", wf.Opcode))
+		}
+	}
+	return out, nil
+}
+
+// evaluateFrameAggregate evaluates an aggregate window function using the default frame,
+// which spans from the start of the partition to the last peer of the current row.
+func evaluateFrameAggregate(wf *WindowFuncParams, partition, out []sqltypes.Row, peerEnd []int) error {
+	var sum sqltypes.Value
+	var count int64
+	seen := false
+	resultType := sqltypes.Decimal
+	if len(partition) > 0 && sqltypes.IsFloat(partition[0][wf.Col].Type()) {
+		resultType = sqltypes.Float64
+	}
+
+	next := 0
+	for i := range partition {
+		for ; next < peerEnd[i]; next++ {
+			val := partition[next][wf.Col]
+			switch wf.Opcode {
+			case WindowCountStar:
+				count++
+			case WindowCount:
+				if !val.IsNull() {
+					count++
+				}
+			case WindowSum:
+				if val.IsNull() {
+					continue
+				}
+				var err error
+				sum, err = evalengine.NullSafeAdd(sum, val, resultType)
+				if err != nil {
+					return err
+				}
+				seen = true
+			}
+		}
+		switch {
+		case wf.Opcode != WindowSum:
+			out[i][wf.Col] = sqltypes.NewInt64(count)
+		case seen:
+			out[i][wf.Col] = sum
+		default:
+			out[i][wf.Col] = sqltypes.NULL
+		}
+	}
+	return nil
+}
+
+// rowsEqualOn compares two rows on the given columns, falling back to the
+// weight string columns when the values cannot be compared directly.
+func rowsEqualOn(a, b sqltypes.Row, cols []CheckCol) (bool, error) {
+	for _, col := range cols {
+		cmp, err := evalengine.NullsafeCompare(a[col.Col], b[col.Col], col.Collation)
+		if err != nil {
+			_, isComparisonErr := err.(evalengine.UnsupportedComparisonError)
+			_, isCollationErr := err.(evalengine.UnsupportedCollationError)
+			if !isComparisonErr && !isCollationErr || col.WsCol == nil {
+				return false, err
+			}
+			col = col.SwitchToWeightString()
+			cmp, err = evalengine.NullsafeCompare(a[col.Col], b[col.Col], col.Collation)
+			if err != nil {
+				return false, err
+			}
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func windowFuncParamsToString(in any) string {
+	return in.(*WindowFuncParams).String()
+}
+
+func checkColToString(in any) string {
+	return in.(CheckCol).String()
+}
+
+func (w *Window) description() PrimitiveDescription {
+	other := map[string]any{
+		"Functions": GenericJoin(w.Functions, windowFuncParamsToString),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = GenericJoin(w.PartitionBy, checkColToString)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = GenericJoin(w.OrderBy, checkColToString)
+	}
+	if w.TruncateColumnCount > 0 {
+		other["ResultColumns"] = w.TruncateColumnCount
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+)
+
+func TestWindowRanking(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"grp|score|rn|rk|drk",
+				"varbinary|int64|int64|int64|int64",
+			),
+			"a|10|1|1|1",
+			"a|20|1|1|1",
+			"a|20|1|1|1",
+			"a|30|1|1|1",
+			"b|5|1|1|1",
+			"b|5|1|1|1",
+		)},
+	}
+
+	w := &Window{
+		PartitionBy: []CheckCol{{Col: 0}},
+		OrderBy:     []CheckCol{{Col: 1}},
+		Functions: []*WindowFuncParams{
+			{Opcode: WindowRowNumber, Col: 2, DefaultCol: -1, Alias: "rn"},
+			{Opcode: WindowRank, Col: 3, DefaultCol: -1, Alias: "rk"},
+			{Opcode: WindowDenseRank, Col: 4, DefaultCol: -1, Alias: "drk"},
+		},
+		Input: fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	wantResult := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|score|rn|rk|drk",
+			"varbinary|int64|int64|int64|int64",
+		),
+		"a|10|1|1|1",
+		"a|20|2|2|2",
+		"a|20|3|2|2",
+		"a|30|4|4|3",
+		"b|5|1|1|1",
+		"b|5|2|1|1",
+	)
+	utils.MustMatch(t, wantResult, result)
+}
+
+func TestWindowLagLead(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"grp|val|prev|next|def",
+				"varbinary|int64|int64|int64|int64",
+			),
+			"a|1|1|1|0",
+			"a|2|2|2|0",
+			"a|3|3|3|0",
+			"b|4|4|4|0",
+		)},
+	}
+
+	w := &Window{
+		PartitionBy: []CheckCol{{Col: 0}},
+		OrderBy:     []CheckCol{{Col: 1}},
+		Functions: []*WindowFuncParams{
+			{Opcode: WindowLag, Col: 2, Offset: 1, DefaultCol: -1, Alias: "prev"},
+			{Opcode: WindowLead, Col: 3, Offset: 2, DefaultCol: 4, Alias: "next"},
+		},
+		TruncateColumnCount: 4,
+		Input:               fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	wantResult := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|prev|next",
+			"varbinary|int64|int64|int64",
+		),
+		"a|1|null|3",
+		"a|2|1|0",
+		"a|3|2|0",
+		"b|4|null|0",
+	)
+	utils.MustMatch(t, wantResult, result)
+}
+
+func TestWindowSumAndCount(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"grp|ord|sum_val|cnt",
+		"varbinary|int64|int64|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1|10|10",
+			"a|2|20|null",
+			"a|2|null|5",
+			"a|3|30|30",
+			"b|1|null|null",
+		)},
+	}
+
+	w := &Window{
+		PartitionBy: []CheckCol{{Col: 0}},
+		OrderBy:     []CheckCol{{Col: 1}},
+		Functions: []*WindowFuncParams{
+			{Opcode: WindowSum, Col: 2, DefaultCol: -1, Alias: "sum_val"},
+			{Opcode: WindowCount, Col: 3, DefaultCol: -1, Alias: "cnt"},
+		},
+		Input: fp,
+	}
+
+	var results []*sqltypes.Result
+	err := w.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		results = append(results, qr)
+		return nil
+	})
+	require.NoError(t, err)
+
+	wantResults := sqltypes.MakeTestStreamingResults(
+		sqltypes.MakeTestFields(
+			"grp|ord|sum_val|cnt",
+			"varbinary|int64|decimal|int64",
+		),
+		"a|1|10|1",
+		"a|2|30|2",
+		"a|2|30|2",
+		"a|3|60|3",
+		"---",
+		"b|1|null|0",
+	)
+	utils.MustMatch(t, wantResults, results)
+}
+
+func TestWindowWithoutOrderBy(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"grp|total|rk",
+				"varbinary|float64|int64",
+			),
+			"a|1.5|0",
+			"a|2.5|0",
+			"b|4|0",
+		)},
+	}
+
+	w := &Window{
+		PartitionBy: []CheckCol{{Col: 0}},
+		Functions: []*WindowFuncParams{
+			{Opcode: WindowSum, Col: 1, DefaultCol: -1, Alias: "total"},
+			{Opcode: WindowRank, Col: 2, DefaultCol: -1, Alias: "rk"},
+		},
+		Input: fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	wantResult := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|total|rk",
+			"varbinary|float64|int64",
+		),
+		"a|4|1",
+		"a|4|1",
+		"b|4|1",
+	)
+	utils.MustMatch(t, wantResult, result)
+}
+
+func TestWindowMaxMemoryRows(t *testing.T) {
+	fields := sqltypes.MakeTestFields("grp|rn", "int64|int64")
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"1|0",
+			"1|0",
+			"1|0",
+			"1|0",
+		)},
+	}
+
+	w := &Window{
+		PartitionBy: []CheckCol{{Col: 0}},
+		Functions:   []*WindowFuncParams{{Opcode: WindowRowNumber, Col: 1, DefaultCol: -1}},
+		Input:       fp,
+	}
+
+	saveMax := testMaxMemoryRows
+	testMaxMemoryRows = 3
+	defer func() {
+		testMaxMemoryRows = saveMax
+	}()
+
+	_, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	assert.EqualError(t, err, "in-memory row count exceeded allowed limit of 3")
+
+	fp.rewind()
+	err = w.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error { return nil })
+	assert.EqualError(t, err, "in-memory row count exceeded allowed limit of 3")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"vitess.io/vitess/go/sqltypes"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine/internal/json"
+)
+
+// MergeJSONArrays concatenates two JSON arrays, as produced by JSON_ARRAYAGG
+// on different shards. A NULL on either side yields the other side.
+func MergeJSONArrays(v1, v2 sqltypes.Value) (sqltypes.Value, error) {
+	if v1.IsNull() {
+		return v2, nil
+	}
+	if v2.IsNull() {
+		return v1, nil
+	}
+	a1, err := parseJSONValue(v1)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	a2, err := parseJSONValue(v2)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	arr1, ok1 := a1.Array()
+	arr2, ok2 := a2.Array()
+	if !ok1 || !ok2 {
+		return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "cannot merge non-array JSON values: %s, %s", v1.String(), v2.String())
+	}
+	merged := make([]*json.Value, 0, len(arr1)+len(arr2))
+	merged = append(merged, arr1...)
+	merged = append(merged, arr2...)
+	return evalToSQLValue(json.NewArray(merged)), nil
+}
+
+// MergeJSONObjects merges two JSON objects, as produced by JSON_OBJECTAGG
+// on different shards. Like MySQL, a duplicate key keeps the last value seen.
+// A NULL on either side yields the other side.
+func MergeJSONObjects(v1, v2 sqltypes.Value) (sqltypes.Value, error) {
+	if v1.IsNull() {
+		return v2, nil
+	}
+	if v2.IsNull() {
+		return v1, nil
+	}
+	o1, err := parseJSONValue(v1)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	o2, err := parseJSONValue(v2)
+	if err != nil {
+		return sqltypes.NULL, err
+	}
+	obj1, ok1 := o1.Object()
+	obj2, ok2 := o2.Object()
+	if !ok1 || !ok2 {
+		return sqltypes.NULL, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "cannot merge non-object JSON values: %s, %s", v1.String(), v2.String())
+	}
+	obj2.Visit(func(key []byte, v *json.Value) {
+		obj1.Set(string(key), v, json.Set)
+	})
+	return evalToSQLValue(o1), nil
+}
+
+func parseJSONValue(v sqltypes.Value) (*json.Value, error) {
+	var p json.Parser
+	return p.ParseBytes(v.Raw())
+}
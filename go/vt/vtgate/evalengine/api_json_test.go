@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestMergeJSONArrays(t *testing.T) {
+	tcs := []struct {
+		v1, v2 sqltypes.Value
+		out    string
+	}{{
+		v1:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[1, "a"]`)),
+		v2:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[null, {"b": 2}]`)),
+		out: `JSON("[1, \"a\", null, {\"b\": 2}]")`,
+	}, {
+		v1:  sqltypes.NULL,
+		v2:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[1]`)),
+		out: `JSON("[1]")`,
+	}, {
+		v1:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[1]`)),
+		v2:  sqltypes.NULL,
+		out: `JSON("[1]")`,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.out, func(t *testing.T) {
+			got, err := MergeJSONArrays(tc.v1, tc.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, got.String())
+		})
+	}
+
+	_, err := MergeJSONArrays(sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`[1]`)), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": 1}`)))
+	require.Error(t, err)
+}
+
+func TestMergeJSONObjects(t *testing.T) {
+	tcs := []struct {
+		v1, v2 sqltypes.Value
+		out    string
+	}{{
+		v1:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": 1, "c": 3}`)),
+		v2:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"b": 2}`)),
+		out: `JSON("{\"a\": 1, \"b\": 2, \"c\": 3}")`,
+	}, {
+		v1:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": 1}`)),
+		v2:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": "x"}`)),
+		out: `JSON("{\"a\": \"x\"}")`,
+	}, {
+		v1:  sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": 1}`)),
+		v2:  sqltypes.NULL,
+		out: `JSON("{\"a\": 1}")`,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.out, func(t *testing.T) {
+			got, err := MergeJSONObjects(tc.v1, tc.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, got.String())
+		})
+	}
+}
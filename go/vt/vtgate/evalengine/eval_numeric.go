@@ -110,6 +110,9 @@ func evalToNumeric(e eval) evalNumeric {
 			}
 			return &evalUint64{u: binary.BigEndian.Uint64(number[:]), hexLiteral: true}
 		}
+		if isTemporalType(e.SQLType()) {
+			return temporalToNumeric(e.bytes)
+		}
 		return &evalFloat{f: parseStringToFloat(e.string())}
 	case *evalJSON:
 		switch e.Type() {
@@ -0,0 +1,533 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/evalengine/internal/decimal"
+)
+
+// Temporal values are represented as evalBytes with a DATE, DATETIME, TIMESTAMP or TIME
+// type, holding the MySQL textual representation of the value. When computing with them,
+// DATE and DATETIME values are parsed into a time.Time in UTC without any time zone
+// conversion, and TIME values are parsed into a time.Duration, since they can be negative
+// and span more than 24 hours.
+
+const (
+	// maxTimePrecision is the maximum number of fractional digits for temporal types
+	maxTimePrecision = 6
+	// maxTime is the largest value a TIME can hold: 838:59:59.999999
+	maxTime = 838*time.Hour + 59*time.Minute + 59*time.Second + 999999*time.Microsecond
+	// maxUnixTimestamp is the largest value accepted by UNIX_TIMESTAMP and FROM_UNIXTIME:
+	// 3001-01-19 03:14:07.999999 UTC
+	maxUnixTimestamp = 32536771199
+)
+
+func newEvalDate(t time.Time) *evalBytes {
+	return newEvalRaw(sqltypes.Date, t.AppendFormat(nil, "2006-01-02"), collationNumeric)
+}
+
+func newEvalDateTime(t time.Time, prec int) *evalBytes {
+	return newEvalRaw(sqltypes.Datetime, appendDateTime(nil, t, prec), collationNumeric)
+}
+
+func newEvalTime(d time.Duration, prec int) *evalBytes {
+	return newEvalRaw(sqltypes.Time, appendTime(nil, d, prec), collationNumeric)
+}
+
+func appendDateTime(buf []byte, t time.Time, prec int) []byte {
+	buf = t.AppendFormat(buf, "2006-01-02 15:04:05")
+	return appendFraction(buf, t.Nanosecond(), prec)
+}
+
+func appendTime(buf []byte, d time.Duration, prec int) []byte {
+	if d < 0 {
+		buf = append(buf, '-')
+		d = -d
+	}
+	hours := int64(d / time.Hour)
+	if hours < 10 {
+		buf = append(buf, '0')
+	}
+	buf = strconv.AppendInt(buf, hours, 10)
+	buf = append(buf, ':')
+	buf = appendTwoDigits(buf, int(d/time.Minute%60))
+	buf = append(buf, ':')
+	buf = appendTwoDigits(buf, int(d/time.Second%60))
+	return appendFraction(buf, int(d%time.Second), prec)
+}
+
+func appendTwoDigits(buf []byte, n int) []byte {
+	return append(buf, byte('0'+n/10), byte('0'+n%10))
+}
+
+func appendFraction(buf []byte, nanos int, prec int) []byte {
+	if prec <= 0 {
+		return buf
+	}
+	var frac [9]byte
+	for i := 8; i >= 0; i-- {
+		frac[i] = byte('0' + nanos%10)
+		nanos /= 10
+	}
+	buf = append(buf, '.')
+	return append(buf, frac[:prec]...)
+}
+
+// roundTime rounds the fractional seconds of t to the given precision
+func roundTime(t time.Time, prec int) time.Time {
+	return t.Round(time.Duration(pow10(9 - prec)))
+}
+
+// roundDuration rounds the fractional seconds of d to the given precision
+func roundDuration(d time.Duration, prec int) time.Duration {
+	return d.Round(time.Duration(pow10(9 - prec)))
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
+
+func isTemporalType(tt sqltypes.Type) bool {
+	switch tt {
+	case sqltypes.Date, sqltypes.Datetime, sqltypes.Timestamp, sqltypes.Time:
+		return true
+	}
+	return false
+}
+
+// temporalPrecision returns the number of fractional digits in the textual representation of a temporal value
+func temporalPrecision(raw []byte) int {
+	dot := strings.LastIndexByte(string(raw), '.')
+	if dot < 0 {
+		return 0
+	}
+	prec := len(raw) - dot - 1
+	if prec > maxTimePrecision {
+		return maxTimePrecision
+	}
+	return prec
+}
+
+// evalPrecision returns the number of fractional digits that the result of a temporal
+// function should have when e is used as its argument.
+func evalPrecision(e eval) int {
+	switch e := e.(type) {
+	case *evalBytes:
+		if isTemporalType(e.SQLType()) {
+			return temporalPrecision(e.bytes)
+		}
+		if temporalPrecision(e.bytes) > 0 {
+			return maxTimePrecision
+		}
+	case *evalDecimal:
+		if e.length > maxTimePrecision {
+			return maxTimePrecision
+		}
+		return int(e.length)
+	case *evalFloat:
+		return maxTimePrecision
+	}
+	return 0
+}
+
+// evalToDateTime converts e into a date and time, the same way MySQL converts the arguments
+// of temporal functions. It returns false when e cannot be interpreted as a valid DATETIME,
+// in which case MySQL returns NULL.
+func evalToDateTime(env *ExpressionEnv, e eval) (time.Time, bool) {
+	switch e := e.(type) {
+	case *evalBytes:
+		if e.SQLType() == sqltypes.Time {
+			d, ok := parseTime(e.string())
+			if !ok {
+				return time.Time{}, false
+			}
+			return truncateToDay(env.currentTime()).Add(d), true
+		}
+		return parseDateTime(e.string())
+	case *evalInt64:
+		return parseDateTimeNumber(strconv.FormatInt(e.i, 10))
+	case *evalUint64:
+		return parseDateTimeNumber(strconv.FormatUint(e.u, 10))
+	case *evalFloat:
+		return parseDateTimeNumber(strconv.FormatFloat(e.f, 'f', -1, 64))
+	case *evalDecimal:
+		return parseDateTimeNumber(string(e.dec.FormatMySQL(e.length)))
+	default:
+		return time.Time{}, false
+	}
+}
+
+// evalToDate converts e into a date, truncating any time part
+func evalToDate(env *ExpressionEnv, e eval) (time.Time, bool) {
+	t, ok := evalToDateTime(env, e)
+	return truncateToDay(t), ok
+}
+
+// evalToTime converts e into a TIME value, the same way MySQL converts the arguments
+// of temporal functions that expect a time. It returns false when e cannot be interpreted
+// as a valid TIME.
+func evalToTime(e eval) (time.Duration, bool) {
+	switch e := e.(type) {
+	case *evalBytes:
+		switch e.SQLType() {
+		case sqltypes.Date:
+			return 0, true
+		case sqltypes.Datetime, sqltypes.Timestamp:
+			t, ok := parseDateTime(e.string())
+			return timeOfDay(t), ok
+		}
+		return parseTime(e.string())
+	case *evalInt64:
+		return parseTimeNumber(strconv.FormatInt(e.i, 10))
+	case *evalUint64:
+		return parseTimeNumber(strconv.FormatUint(e.u, 10))
+	case *evalFloat:
+		return parseTimeNumber(strconv.FormatFloat(e.f, 'f', -1, 64))
+	case *evalDecimal:
+		return parseTimeNumber(string(e.dec.FormatMySQL(e.length)))
+	default:
+		return 0, false
+	}
+}
+
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return t.Sub(truncateToDay(t))
+}
+
+func validDateTime(year, month, day, hour, minute, second int) bool {
+	if year < 0 || year > 9999 || month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	if day > daysInMonth(year, time.Month(month)) {
+		return false
+	}
+	return hour >= 0 && hour < 24 && minute >= 0 && minute < 60 && second >= 0 && second < 60
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parseFraction parses up to 9 digits of fractional seconds into nanoseconds.
+// Digits past the microsecond are rounded, as MySQL does.
+func parseFraction(digits string) int {
+	var nanos int
+	for i := 0; i < 9; i++ {
+		nanos *= 10
+		if i < len(digits) {
+			nanos += int(digits[i] - '0')
+		}
+	}
+	if len(digits) > maxTimePrecision {
+		nanos = int(time.Duration(nanos).Round(time.Microsecond))
+	}
+	return nanos
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// splitFraction splits a numeric string into its integral and fractional parts
+func splitFraction(s string) (string, string) {
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		return s[:dot], s[dot+1:]
+	}
+	return s, ""
+}
+
+func twoDigitYear(year int) int {
+	if year < 70 {
+		return year + 2000
+	}
+	return year + 1900
+}
+
+// parseDateTime parses a string into a DATETIME, accepting all the formats MySQL accepts:
+// 'YYYY-MM-DD hh:mm:ss.ffffff' with any punctuation as delimiter, 'YYYYMMDDhhmmss.ffffff'
+// and their 2-digit year and date-only variants.
+func parseDateTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if intpart, frac := splitFraction(s); isDigits(intpart) && (frac == "" || isDigits(frac)) {
+		return parseDateTimeDigits(intpart, frac)
+	}
+
+	var parts []string
+	var separators []byte
+	for i := 0; i < len(s); {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if start == i {
+			return time.Time{}, false
+		}
+		parts = append(parts, s[start:i])
+		if i == len(s) {
+			break
+		}
+		sep := s[i]
+		i++
+		// the date and the time can be separated by any amount of whitespace
+		for sep == ' ' && i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i == len(s) {
+			return time.Time{}, false
+		}
+		separators = append(separators, sep)
+	}
+
+	var frac string
+	switch {
+	case len(parts) < 3 || len(parts) > 7:
+		return time.Time{}, false
+	case len(parts) == 7:
+		if separators[5] != '.' {
+			return time.Time{}, false
+		}
+		frac = parts[6]
+		parts = parts[:6]
+	}
+	if len(parts) > 3 && separators[2] != ' ' && separators[2] != 'T' {
+		return time.Time{}, false
+	}
+
+	year := atoi(parts[0])
+	if len(parts[0]) <= 2 {
+		year = twoDigitYear(year)
+	}
+	var hms [3]int
+	for i, p := range parts[3:] {
+		hms[i] = atoi(p)
+	}
+	return makeDateTime(year, atoi(parts[1]), atoi(parts[2]), hms[0], hms[1], hms[2], frac)
+}
+
+func parseDateTimeDigits(intpart, frac string) (time.Time, bool) {
+	var year int
+	switch len(intpart) {
+	case 6, 12:
+		year = twoDigitYear(atoi(intpart[:2]))
+		intpart = intpart[2:]
+	case 8, 14:
+		year = atoi(intpart[:4])
+		intpart = intpart[4:]
+	default:
+		return time.Time{}, false
+	}
+	var hms [3]int
+	if len(intpart) > 4 {
+		for i := range hms {
+			hms[i] = atoi(intpart[4+2*i : 6+2*i])
+		}
+	} else if frac != "" {
+		return time.Time{}, false
+	}
+	return makeDateTime(year, atoi(intpart[0:2]), atoi(intpart[2:4]), hms[0], hms[1], hms[2], frac)
+}
+
+// parseDateTimeNumber converts a number into a DATETIME the same way MySQL does:
+// the integral part is interpreted as YYMMDD, YYYYMMDD, YYMMDDhhmmss or YYYYMMDDhhmmss
+func parseDateTimeNumber(s string) (time.Time, bool) {
+	intpart, frac := splitFraction(s)
+	nr, err := strconv.ParseInt(intpart, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch {
+	case nr < 101:
+		return time.Time{}, false
+	case nr <= 691231:
+		nr = (nr + 20000000) * 1000000
+	case nr < 700101:
+		return time.Time{}, false
+	case nr <= 991231:
+		nr = (nr + 19000000) * 1000000
+	case nr < 10000101:
+		return time.Time{}, false
+	case nr <= 99991231:
+		nr = nr * 1000000
+	case nr < 101000000:
+		return time.Time{}, false
+	case nr <= 691231235959:
+		nr = nr + 20000000000000
+	case nr < 700101000000:
+		return time.Time{}, false
+	case nr <= 991231235959:
+		nr = nr + 19000000000000
+	case nr < 10000101000000 || nr > 99991231235959:
+		return time.Time{}, false
+	}
+	return makeDateTime(int(nr/10000000000), int(nr/100000000%100), int(nr/1000000%100),
+		int(nr/10000%100), int(nr/100%100), int(nr%100), frac)
+}
+
+func makeDateTime(year, month, day, hour, minute, second int, frac string) (time.Time, bool) {
+	if !validDateTime(year, month, day, hour, minute, second) || (frac != "" && !isDigits(frac)) {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, parseFraction(frac), time.UTC), true
+}
+
+// parseTime parses a string into a TIME value, accepting the formats MySQL accepts:
+// '[-][D ]hh:mm:ss.ffffff', 'hh:mm', '[-]hhmmss.ffffff', and full DATETIME values,
+// from which only the time of the day is kept.
+func parseTime(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 1 && strings.ContainsAny(s[1:], "-/") {
+		t, ok := parseDateTime(s)
+		return timeOfDay(t), ok
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	var days int
+	if sp := strings.IndexByte(s, ' '); sp > 0 {
+		if !isDigits(s[:sp]) {
+			return 0, false
+		}
+		days = atoi(s[:sp])
+		s = strings.TrimLeft(s[sp:], " ")
+	}
+
+	intpart, frac := splitFraction(s)
+	if frac != "" && !isDigits(frac) {
+		return 0, false
+	}
+
+	var hour, minute, second int
+	if strings.IndexByte(intpart, ':') >= 0 {
+		parts := strings.Split(intpart, ":")
+		if len(parts) > 3 {
+			return 0, false
+		}
+		for _, p := range parts {
+			if !isDigits(p) {
+				return 0, false
+			}
+		}
+		hour = atoi(parts[0])
+		minute = atoi(parts[1])
+		if len(parts) == 3 {
+			second = atoi(parts[2])
+		}
+	} else {
+		if !isDigits(intpart) {
+			return 0, false
+		}
+		if days > 0 {
+			// 'D hh' means days and hours
+			hour = atoi(intpart)
+		} else {
+			hour, minute, second = splitTimeNumber(atoi(intpart))
+		}
+	}
+	return makeTime(neg, days*24+hour, minute, second, frac)
+}
+
+// parseTimeNumber converts a number into a TIME the same way MySQL does:
+// the integral part is interpreted as [-]hhmmss
+func parseTimeNumber(s string) (time.Duration, bool) {
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	intpart, frac := splitFraction(s)
+	if len(intpart) > 14 {
+		return 0, false
+	}
+	if len(intpart) > 7 {
+		// large numbers are interpreted as a DATETIME, of which we keep the time
+		t, ok := parseDateTimeNumber(s)
+		return timeOfDay(t), ok && !neg
+	}
+	hour, minute, second := splitTimeNumber(atoi(intpart))
+	return makeTime(neg, hour, minute, second, frac)
+}
+
+func splitTimeNumber(n int) (hour, minute, second int) {
+	return n / 10000, n / 100 % 100, n % 100
+}
+
+func makeTime(neg bool, hour, minute, second int, frac string) (time.Duration, bool) {
+	if minute >= 60 || second >= 60 {
+		return 0, false
+	}
+	d := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(parseFraction(frac))
+	if d > maxTime {
+		d = maxTime.Truncate(time.Second)
+	}
+	if neg {
+		d = -d
+	}
+	return d, true
+}
+
+// temporalToNumeric converts the textual representation of a temporal value into the number
+// MySQL uses when a temporal value is used in a numeric context, e.g. '2023-01-02' becomes 20230102
+func temporalToNumeric(raw []byte) evalNumeric {
+	digits := make([]byte, 0, len(raw))
+	var frac []byte
+	for i, b := range raw {
+		switch {
+		case b == '-' && i == 0:
+			digits = append(digits, b)
+		case b == '.':
+			frac = raw[i+1:]
+		case b >= '0' && b <= '9' && frac == nil:
+			digits = append(digits, b)
+		}
+	}
+	if len(frac) == 0 {
+		i, _ := strconv.ParseInt(string(digits), 10, 64)
+		return newEvalInt64(i)
+	}
+	dec, err := decimal.NewFromMySQL(append(append(digits, '.'), frac...))
+	if err != nil {
+		return newEvalInt64(0)
+	}
+	return newEvalDecimalWithPrec(dec, int32(len(frac)))
+}
@@ -113,7 +113,31 @@ func (c *ConvertExpr) eval(env *ExpressionEnv) (eval, error) {
 		return evalToNumeric(e).toUint64(), nil
 	case "JSON":
 		return evalToJSON(e)
-	case "DATE", "DATETIME", "YEAR", "TIME":
+	case "DATE":
+		t, ok := evalToDate(env, e)
+		if !ok {
+			return nil, nil
+		}
+		return newEvalDate(t), nil
+	case "DATETIME":
+		t, ok := evalToDateTime(env, e)
+		if !ok {
+			return nil, nil
+		}
+		if t = roundTime(t, c.Length); t.Year() > 9999 {
+			return nil, nil
+		}
+		return newEvalDateTime(t, c.Length), nil
+	case "TIME":
+		d, ok := evalToTime(e)
+		if !ok {
+			return nil, nil
+		}
+		if d = roundDuration(d, c.Length); d > maxTime || d < -maxTime {
+			return nil, nil
+		}
+		return newEvalTime(d, c.Length), nil
+	case "YEAR":
 		return nil, c.returnUnsupportedError()
 	default:
 		panic("BUG: sqlparser emitted unknown type")
@@ -140,7 +164,13 @@ func (c *ConvertExpr) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
 		return sqltypes.Uint64, f
 	case "JSON":
 		return sqltypes.TypeJSON, f
-	case "DATE", "DATETIME", "YEAR", "TIME":
+	case "DATE":
+		return sqltypes.Date, f | flagNullable
+	case "DATETIME":
+		return sqltypes.Datetime, f | flagNullable
+	case "TIME":
+		return sqltypes.Time, f | flagNullable
+	case "YEAR":
 		return sqltypes.Null, f
 	default:
 		panic("BUG: sqlparser emitted unknown type")
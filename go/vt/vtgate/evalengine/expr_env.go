@@ -17,6 +17,8 @@ limitations under the License.
 package evalengine
 
 import (
+	"time"
+
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -32,6 +34,14 @@ type (
 		// Row and Fields should line up
 		Row    []sqltypes.Value
 		Fields []*querypb.Field
+
+		// TimeZone is the time zone used by the temporal functions that depend on the
+		// session time zone, such as NOW() or UNIX_TIMESTAMP(). Defaults to the system time zone.
+		TimeZone *time.Location
+
+		// now is the time at which the evaluation of the current statement started,
+		// so that NOW() returns the same value for every row
+		now time.Time
 	}
 )
 
@@ -48,6 +58,28 @@ func (env *ExpressionEnv) TypeOf(expr Expr) (ty sqltypes.Type, err error) {
 	return
 }
 
+// currentTime returns the current time in the session time zone, as a naive time in UTC
+func (env *ExpressionEnv) currentTime() time.Time {
+	return env.currentTimeIn(env.timeZone())
+}
+
+// currentTimeIn returns the current time in the given time zone, as a naive time in UTC
+func (env *ExpressionEnv) currentTimeIn(loc *time.Location) time.Time {
+	if env.now.IsZero() {
+		env.now = time.Now()
+	}
+	t := env.now.In(loc)
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func (env *ExpressionEnv) timeZone() *time.Location {
+	if env.TimeZone == nil {
+		return time.Local
+	}
+	return env.TimeZone
+}
+
 func (env *ExpressionEnv) collation() collations.TypedCollation {
 	return collations.TypedCollation{
 		Collation:    env.DefaultCollation,
@@ -64,3 +64,30 @@ func (call *builtinCeil) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
 		return sqltypes.Float64, f
 	}
 }
+
+type builtinSqrt struct {
+	CallExpr
+}
+
+var _ Expr = (*builtinSqrt)(nil)
+
+func (call *builtinSqrt) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	f, _ := evalToNumeric(arg).toFloat()
+	if f.f < 0 {
+		return nil, nil
+	}
+	return newEvalFloat(math.Sqrt(f.f)), nil
+}
+
+func (call *builtinSqrt) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env)
+	return sqltypes.Float64, f | flagNullable
+}
@@ -0,0 +1,1242 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine/internal/decimal"
+)
+
+type (
+	builtinNow struct {
+		CallExpr
+		utc  bool
+		prec int
+	}
+
+	builtinCurdate struct {
+		CallExpr
+		utc bool
+	}
+
+	builtinCurtime struct {
+		CallExpr
+		utc  bool
+		prec int
+	}
+
+	builtinDateMath struct {
+		CallExpr
+		unit sqlparser.IntervalTypes
+		sub  bool
+	}
+
+	builtinDateFormat struct {
+		CallExpr
+	}
+
+	builtinUnixTimestamp struct {
+		CallExpr
+	}
+
+	builtinFromUnixtime struct {
+		CallExpr
+	}
+
+	builtinDateDiff struct {
+		CallExpr
+	}
+
+	builtinTimestampDiff struct {
+		CallExpr
+		unit sqlparser.IntervalTypes
+	}
+
+	builtinExtract struct {
+		CallExpr
+		unit sqlparser.IntervalTypes
+	}
+
+	builtinConvertTz struct {
+		CallExpr
+	}
+
+	builtinDate struct {
+		CallExpr
+	}
+
+	builtinTime struct {
+		CallExpr
+	}
+
+	builtinLastDay struct {
+		CallExpr
+	}
+
+	builtinWeek struct {
+		CallExpr
+	}
+
+	builtinDatePart struct {
+		CallExpr
+		part func(t time.Time) int64
+	}
+
+	builtinTimePart struct {
+		CallExpr
+		part func(d time.Duration) int64
+	}
+
+	builtinDateName struct {
+		CallExpr
+		name func(t time.Time) string
+	}
+)
+
+var _ Expr = (*builtinNow)(nil)
+var _ Expr = (*builtinCurdate)(nil)
+var _ Expr = (*builtinCurtime)(nil)
+var _ Expr = (*builtinDateMath)(nil)
+var _ Expr = (*builtinDateFormat)(nil)
+var _ Expr = (*builtinUnixTimestamp)(nil)
+var _ Expr = (*builtinFromUnixtime)(nil)
+var _ Expr = (*builtinDateDiff)(nil)
+var _ Expr = (*builtinTimestampDiff)(nil)
+var _ Expr = (*builtinExtract)(nil)
+var _ Expr = (*builtinConvertTz)(nil)
+var _ Expr = (*builtinDate)(nil)
+var _ Expr = (*builtinTime)(nil)
+var _ Expr = (*builtinLastDay)(nil)
+var _ Expr = (*builtinWeek)(nil)
+var _ Expr = (*builtinDatePart)(nil)
+var _ Expr = (*builtinTimePart)(nil)
+var _ Expr = (*builtinDateName)(nil)
+
+func (call *builtinNow) eval(env *ExpressionEnv) (eval, error) {
+	now := env.currentTime()
+	if call.utc {
+		now = env.currentTimeIn(time.UTC)
+	}
+	return newEvalDateTime(now, call.prec), nil
+}
+
+func (call *builtinNow) typeof(_ *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Datetime, 0
+}
+
+func (call *builtinCurdate) eval(env *ExpressionEnv) (eval, error) {
+	now := env.currentTime()
+	if call.utc {
+		now = env.currentTimeIn(time.UTC)
+	}
+	return newEvalDate(now), nil
+}
+
+func (call *builtinCurdate) typeof(_ *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Date, 0
+}
+
+func (call *builtinCurtime) eval(env *ExpressionEnv) (eval, error) {
+	now := env.currentTime()
+	if call.utc {
+		now = env.currentTimeIn(time.UTC)
+	}
+	return newEvalTime(timeOfDay(now), call.prec), nil
+}
+
+func (call *builtinCurtime) typeof(_ *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Time, 0
+}
+
+// interval is the value of an INTERVAL expression, split in the parts that
+// must be added separately to a date
+type interval struct {
+	months int64
+	days   int64
+	micros int64
+}
+
+func intervalHasMonths(unit sqlparser.IntervalTypes) bool {
+	switch unit {
+	case sqlparser.IntervalYear, sqlparser.IntervalQuarter, sqlparser.IntervalMonth, sqlparser.IntervalYearMonth:
+		return true
+	}
+	return false
+}
+
+func intervalHasTime(unit sqlparser.IntervalTypes) bool {
+	switch unit {
+	case sqlparser.IntervalYear, sqlparser.IntervalQuarter, sqlparser.IntervalMonth, sqlparser.IntervalYearMonth,
+		sqlparser.IntervalWeek, sqlparser.IntervalDay:
+		return false
+	}
+	return true
+}
+
+func intervalHasMicroseconds(unit sqlparser.IntervalTypes) bool {
+	switch unit {
+	case sqlparser.IntervalMicrosecond, sqlparser.IntervalDayMicrosecond, sqlparser.IntervalHourMicrosecond,
+		sqlparser.IntervalMinuteMicrosecond, sqlparser.IntervalSecondMicrosecond:
+		return true
+	}
+	return false
+}
+
+// intervalFields returns the number of fields in the string representation of a compound interval unit
+func intervalFields(unit sqlparser.IntervalTypes) int {
+	switch unit {
+	case sqlparser.IntervalYearMonth, sqlparser.IntervalDayHour, sqlparser.IntervalHourMinute,
+		sqlparser.IntervalMinuteSecond, sqlparser.IntervalSecondMicrosecond:
+		return 2
+	case sqlparser.IntervalDayMinute, sqlparser.IntervalHourSecond, sqlparser.IntervalMinuteMicrosecond:
+		return 3
+	case sqlparser.IntervalDaySecond, sqlparser.IntervalHourMicrosecond:
+		return 4
+	case sqlparser.IntervalDayMicrosecond:
+		return 5
+	}
+	return 1
+}
+
+// parseIntervalUnit parses the unit of an INTERVAL expression
+func parseIntervalUnit(unit string) (sqlparser.IntervalTypes, bool) {
+	unit = strings.ToLower(unit)
+	for ty := sqlparser.IntervalYear; ty <= sqlparser.IntervalSecondMicrosecond; ty++ {
+		if ty.ToString() == unit {
+			return ty, true
+		}
+	}
+	return 0, false
+}
+
+// evalToInterval converts the value of an INTERVAL expression into an interval, following the rules
+// MySQL uses: simple units take an integer, except SECOND which can have a fractional part, and
+// compound units take a string where the fields are separated by any punctuation.
+// It returns the precision of the fractional seconds in the interval, and false if the value is invalid.
+func evalToInterval(e eval, unit sqlparser.IntervalTypes) (*interval, int, bool) {
+	var iv interval
+	var prec int
+
+	switch unit {
+	case sqlparser.IntervalSecond:
+		neg, secs, nanos, ok := evalToSeconds(e)
+		if !ok {
+			return nil, 0, false
+		}
+		iv.micros = secs*1000000 + nanos/1000
+		if neg {
+			iv.micros = -iv.micros
+		}
+		return &iv, evalPrecision(e), true
+	case sqlparser.IntervalYear, sqlparser.IntervalQuarter, sqlparser.IntervalMonth, sqlparser.IntervalWeek,
+		sqlparser.IntervalDay, sqlparser.IntervalHour, sqlparser.IntervalMinute, sqlparser.IntervalMicrosecond:
+		var n int64
+		if b, ok := e.(*evalBytes); ok && !isTemporalType(b.SQLType()) {
+			n = parseLeadingInt(b.string())
+		} else {
+			n = evalToNumeric(e).toInt64().i
+		}
+		switch unit {
+		case sqlparser.IntervalYear:
+			iv.months = n * 12
+		case sqlparser.IntervalQuarter:
+			iv.months = n * 3
+		case sqlparser.IntervalMonth:
+			iv.months = n
+		case sqlparser.IntervalWeek:
+			iv.days = n * 7
+		case sqlparser.IntervalDay:
+			iv.days = n
+		case sqlparser.IntervalHour:
+			iv.micros = n * int64(time.Hour/time.Microsecond)
+		case sqlparser.IntervalMinute:
+			iv.micros = n * int64(time.Minute/time.Microsecond)
+		case sqlparser.IntervalMicrosecond:
+			iv.micros = n
+			prec = maxTimePrecision
+		}
+		return &iv, prec, true
+	}
+
+	neg, fields, ok := parseIntervalFields(string(e.ToRawBytes()), intervalFields(unit), intervalHasMicroseconds(unit))
+	if !ok {
+		return nil, 0, false
+	}
+
+	const (
+		microsPerSecond = int64(time.Second / time.Microsecond)
+		microsPerMinute = int64(time.Minute / time.Microsecond)
+		microsPerHour   = int64(time.Hour / time.Microsecond)
+	)
+	switch unit {
+	case sqlparser.IntervalYearMonth:
+		iv.months = fields[0]*12 + fields[1]
+	case sqlparser.IntervalDayHour:
+		iv.days = fields[0]
+		iv.micros = fields[1] * microsPerHour
+	case sqlparser.IntervalDayMinute:
+		iv.days = fields[0]
+		iv.micros = fields[1]*microsPerHour + fields[2]*microsPerMinute
+	case sqlparser.IntervalDaySecond:
+		iv.days = fields[0]
+		iv.micros = fields[1]*microsPerHour + fields[2]*microsPerMinute + fields[3]*microsPerSecond
+	case sqlparser.IntervalDayMicrosecond:
+		iv.days = fields[0]
+		iv.micros = fields[1]*microsPerHour + fields[2]*microsPerMinute + fields[3]*microsPerSecond + fields[4]
+	case sqlparser.IntervalHourMinute:
+		iv.micros = fields[0]*microsPerHour + fields[1]*microsPerMinute
+	case sqlparser.IntervalHourSecond:
+		iv.micros = fields[0]*microsPerHour + fields[1]*microsPerMinute + fields[2]*microsPerSecond
+	case sqlparser.IntervalHourMicrosecond:
+		iv.micros = fields[0]*microsPerHour + fields[1]*microsPerMinute + fields[2]*microsPerSecond + fields[3]
+	case sqlparser.IntervalMinuteSecond:
+		iv.micros = fields[0]*microsPerMinute + fields[1]*microsPerSecond
+	case sqlparser.IntervalMinuteMicrosecond:
+		iv.micros = fields[0]*microsPerMinute + fields[1]*microsPerSecond + fields[2]
+	case sqlparser.IntervalSecondMicrosecond:
+		iv.micros = fields[0]*microsPerSecond + fields[1]
+	}
+	if intervalHasMicroseconds(unit) {
+		prec = maxTimePrecision
+	}
+	if neg {
+		iv.months, iv.days, iv.micros = -iv.months, -iv.days, -iv.micros
+	}
+	return &iv, prec, true
+}
+
+// parseIntervalFields splits the string value of a compound INTERVAL into its fields.
+// When fewer fields than expected are given, they are assigned to the smallest units.
+func parseIntervalFields(s string, count int, transformMicros bool) (bool, []int64, bool) {
+	s = strings.TrimLeft(s, " \t")
+	neg := strings.HasPrefix(s, "-")
+
+	i := 0
+	for i < len(s) && (s[i] < '0' || s[i] > '9') {
+		i++
+	}
+
+	fields := make([]int64, count)
+	var lastLength, n int
+	for n < count && i < len(s) {
+		start := i
+		var value int64
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			value = value*10 + int64(s[i]-'0')
+			i++
+		}
+		lastLength = i - start
+		fields[n] = value
+		n++
+		for i < len(s) && (s[i] < '0' || s[i] > '9') {
+			i++
+		}
+	}
+	if i < len(s) {
+		return false, nil, false
+	}
+	if n < count {
+		copy(fields[count-n:], fields[:n])
+		for j := 0; j < count-n; j++ {
+			fields[j] = 0
+		}
+	}
+	if transformMicros && lastLength > 0 && lastLength < maxTimePrecision {
+		fields[count-1] *= pow10(maxTimePrecision - lastLength)
+	}
+	return neg, fields, true
+}
+
+// parseLeadingInt parses the integer at the start of s, ignoring anything that follows it
+func parseLeadingInt(s string) int64 {
+	s = strings.TrimSpace(s)
+	end := 0
+	if end < len(s) && (s[end] == '-' || s[end] == '+') {
+		end++
+	}
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.ParseInt(s[:end], 10, 64)
+	return n
+}
+
+// evalToSeconds splits a numeric value into whole seconds and nanoseconds
+func evalToSeconds(e eval) (neg bool, secs int64, nanos int64, ok bool) {
+	dec := evalToNumeric(e).toDecimal(0, 0)
+	s := dec.dec.StringFixed(maxTimePrecision)
+	if neg = strings.HasPrefix(s, "-"); neg {
+		s = s[1:]
+	}
+	intpart, frac := splitFraction(s)
+	secs, err := strconv.ParseInt(intpart, 10, 64)
+	if err != nil {
+		return false, 0, 0, false
+	}
+	return neg, secs, int64(parseFraction(frac)), true
+}
+
+// addToDateTime adds the interval to t, clamping the day of the month like MySQL does
+// when adding months. It returns false if the result is out of the DATETIME range.
+func (iv *interval) addToDateTime(t time.Time) (time.Time, bool) {
+	if iv.months != 0 {
+		year, month, day := t.Date()
+		months := int64(year)*12 + int64(month-1) + iv.months
+		if months < 0 || months >= 10000*12 {
+			return time.Time{}, false
+		}
+		year, month = int(months/12), time.Month(months%12+1)
+		if last := daysInMonth(year, month); day > last {
+			day = last
+		}
+		t = time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+
+	const microsPerDay = int64(24 * time.Hour / time.Microsecond)
+	days := iv.days + iv.micros/microsPerDay
+	micros := iv.micros % microsPerDay
+	if days > 10000*366 || days < -10000*366 {
+		return time.Time{}, false
+	}
+	t = t.AddDate(0, 0, int(days)).Add(time.Duration(micros) * time.Microsecond)
+	if t.Year() < 0 || t.Year() > 9999 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// addToTime adds the interval to a TIME value. The interval must not contain months.
+func (iv *interval) addToTime(d time.Duration) (time.Duration, bool) {
+	const maxTimeDays = int64(maxTime / (24 * time.Hour))
+	if iv.days > maxTimeDays+1 || iv.days < -maxTimeDays-1 || iv.micros > int64(2*maxTime/time.Microsecond) || iv.micros < -int64(2*maxTime/time.Microsecond) {
+		return 0, false
+	}
+	d += time.Duration(iv.days)*24*time.Hour + time.Duration(iv.micros)*time.Microsecond
+	if d > maxTime || d < -maxTime {
+		return 0, false
+	}
+	return d, true
+}
+
+func (call *builtinDateMath) eval(env *ExpressionEnv) (eval, error) {
+	date, value, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil || value == nil {
+		return nil, nil
+	}
+
+	iv, prec, ok := evalToInterval(value, call.unit)
+	if !ok {
+		return nil, nil
+	}
+	if call.sub {
+		iv.months, iv.days, iv.micros = -iv.months, -iv.days, -iv.micros
+	}
+	if p := evalPrecision(date); p > prec {
+		prec = p
+	}
+
+	switch tt := date.SQLType(); {
+	case tt == sqltypes.Date && !intervalHasTime(call.unit):
+		t, ok := evalToDate(env, date)
+		if !ok {
+			return nil, nil
+		}
+		if t, ok = iv.addToDateTime(t); !ok {
+			return nil, nil
+		}
+		return newEvalDate(t), nil
+	case tt == sqltypes.Time && !intervalHasMonths(call.unit):
+		d, ok := evalToTime(date)
+		if !ok {
+			return nil, nil
+		}
+		if d, ok = iv.addToTime(d); !ok {
+			return nil, nil
+		}
+		return newEvalTime(d, prec), nil
+	case isTemporalType(tt):
+		t, ok := evalToDateTime(env, date)
+		if !ok {
+			return nil, nil
+		}
+		if t, ok = iv.addToDateTime(t); !ok {
+			return nil, nil
+		}
+		return newEvalDateTime(t, prec), nil
+	default:
+		// for any other argument type, the result is a string
+		t, ok := evalToDateTime(env, date)
+		if !ok {
+			return nil, nil
+		}
+		if t, ok = iv.addToDateTime(t); !ok {
+			return nil, nil
+		}
+		var buf []byte
+		if !intervalHasTime(call.unit) && !hasTimePart(date) {
+			buf = t.AppendFormat(nil, "2006-01-02")
+		} else {
+			if prec > 0 {
+				prec = maxTimePrecision
+			}
+			buf = appendDateTime(nil, t, prec)
+		}
+		return newEvalText(buf, env.collation()), nil
+	}
+}
+
+// hasTimePart returns whether a non-temporal value that is converted into a DATETIME
+// specifies a time, e.g. '2023-01-02 10:00:00' or 20230102100000
+func hasTimePart(e eval) bool {
+	s := strings.TrimSpace(string(e.ToRawBytes()))
+	s = strings.TrimPrefix(s, "-")
+	if intpart, _ := splitFraction(s); isDigits(intpart) {
+		return len(intpart) > 8
+	}
+	var groups int
+	for i := 0; i < len(s); {
+		if s[i] >= '0' && s[i] <= '9' {
+			groups++
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			continue
+		}
+		i++
+	}
+	return groups > 3
+}
+
+func (call *builtinDateMath) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	tt, f := call.Arguments[0].typeof(env)
+	switch {
+	case tt == sqltypes.Date && !intervalHasTime(call.unit):
+		return sqltypes.Date, f | flagNullable
+	case tt == sqltypes.Time && !intervalHasMonths(call.unit):
+		return sqltypes.Time, f | flagNullable
+	case isTemporalType(tt):
+		return sqltypes.Datetime, f | flagNullable
+	default:
+		return sqltypes.VarChar, f | flagNullable
+	}
+}
+
+var (
+	weekdayAbbrev = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+	monthAbbrev   = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+)
+
+const (
+	weekMondayFirst = 1 << iota
+	weekYear
+	weekFirstWeekday
+)
+
+// weekMode converts the mode argument of WEEK() into the flags used by calcWeek
+func weekMode(mode int) int {
+	mode &= 7
+	if mode&weekMondayFirst == 0 {
+		mode ^= weekFirstWeekday
+	}
+	return mode
+}
+
+// calcWeek returns the week number of t and the year the week belongs to,
+// using the same algorithm as MySQL
+func calcWeek(t time.Time, behaviour int) (int, int) {
+	mondayFirst := behaviour&weekMondayFirst != 0
+	weekYearFlag := behaviour&weekYear != 0
+	firstWeekday := behaviour&weekFirstWeekday != 0
+
+	dayOfYear := func(y int) time.Time { return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC) }
+	weekdayOf := func(d time.Time) int {
+		if mondayFirst {
+			return (int(d.Weekday()) + 6) % 7
+		}
+		return int(d.Weekday())
+	}
+	daysInYear := func(y int) int {
+		return dayOfYear(y+1).YearDay() + int(dayOfYear(y+1).Sub(dayOfYear(y))/(24*time.Hour)) - 1
+	}
+
+	year := t.Year()
+	daynr := int(t.Unix() / 86400)
+	firstDaynr := int(dayOfYear(year).Unix() / 86400)
+	weekday := weekdayOf(dayOfYear(year))
+
+	if t.Month() == time.January && t.Day() <= 7-weekday {
+		if !weekYearFlag && ((firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4)) {
+			return 0, year
+		}
+		weekYearFlag = true
+		year--
+		days := daysInYear(year)
+		firstDaynr -= days
+		weekday = (weekday + 53*7 - days) % 7
+	}
+
+	var days int
+	if (firstWeekday && weekday != 0) || (!firstWeekday && weekday >= 4) {
+		days = daynr - (firstDaynr + (7 - weekday))
+	} else {
+		days = daynr - (firstDaynr - weekday)
+	}
+
+	if weekYearFlag && days >= 52*7 {
+		weekday = (weekday + daysInYear(year)) % 7
+		if (!firstWeekday && weekday < 4) || (firstWeekday && weekday == 0) {
+			return 1, year + 1
+		}
+	}
+	return days/7 + 1, year
+}
+
+func daySuffix(day int) string {
+	if day/10 == 1 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+func hour12(hour int) int {
+	if h := hour % 12; h != 0 {
+		return h
+	}
+	return 12
+}
+
+func appendPadded(buf []byte, n int, width int) []byte {
+	s := strconv.Itoa(n)
+	for i := len(s); i < width; i++ {
+		buf = append(buf, '0')
+	}
+	return append(buf, s...)
+}
+
+// formatDate formats t using the specifiers of MySQL's DATE_FORMAT function
+func formatDate(t time.Time, format []byte) []byte {
+	buf := make([]byte, 0, len(format)*2)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			buf = append(buf, format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'a':
+			buf = append(buf, weekdayAbbrev[t.Weekday()]...)
+		case 'b':
+			buf = append(buf, monthAbbrev[t.Month()-1]...)
+		case 'c':
+			buf = strconv.AppendInt(buf, int64(t.Month()), 10)
+		case 'D':
+			buf = strconv.AppendInt(buf, int64(t.Day()), 10)
+			buf = append(buf, daySuffix(t.Day())...)
+		case 'd':
+			buf = appendPadded(buf, t.Day(), 2)
+		case 'e':
+			buf = strconv.AppendInt(buf, int64(t.Day()), 10)
+		case 'f':
+			buf = appendPadded(buf, t.Nanosecond()/1000, 6)
+		case 'H':
+			buf = appendPadded(buf, t.Hour(), 2)
+		case 'h', 'I':
+			buf = appendPadded(buf, hour12(t.Hour()), 2)
+		case 'i':
+			buf = appendPadded(buf, t.Minute(), 2)
+		case 'j':
+			buf = appendPadded(buf, t.YearDay(), 3)
+		case 'k':
+			buf = strconv.AppendInt(buf, int64(t.Hour()), 10)
+		case 'l':
+			buf = strconv.AppendInt(buf, int64(hour12(t.Hour())), 10)
+		case 'M':
+			buf = append(buf, t.Month().String()...)
+		case 'm':
+			buf = appendPadded(buf, int(t.Month()), 2)
+		case 'p':
+			buf = append(buf, t.Format("PM")...)
+		case 'r':
+			buf = appendPadded(buf, hour12(t.Hour()), 2)
+			buf = append(buf, ':')
+			buf = appendPadded(buf, t.Minute(), 2)
+			buf = append(buf, ':')
+			buf = appendPadded(buf, t.Second(), 2)
+			buf = append(buf, ' ')
+			buf = append(buf, t.Format("PM")...)
+		case 'S', 's':
+			buf = appendPadded(buf, t.Second(), 2)
+		case 'T':
+			buf = t.AppendFormat(buf, "15:04:05")
+		case 'U':
+			week, _ := calcWeek(t, weekFirstWeekday)
+			buf = appendPadded(buf, week, 2)
+		case 'u':
+			week, _ := calcWeek(t, weekMondayFirst)
+			buf = appendPadded(buf, week, 2)
+		case 'V':
+			week, _ := calcWeek(t, weekYear|weekFirstWeekday)
+			buf = appendPadded(buf, week, 2)
+		case 'v':
+			week, _ := calcWeek(t, weekYear|weekMondayFirst)
+			buf = appendPadded(buf, week, 2)
+		case 'W':
+			buf = append(buf, t.Weekday().String()...)
+		case 'w':
+			buf = strconv.AppendInt(buf, int64(t.Weekday()), 10)
+		case 'X':
+			_, year := calcWeek(t, weekYear|weekFirstWeekday)
+			buf = appendPadded(buf, year, 4)
+		case 'x':
+			_, year := calcWeek(t, weekYear|weekMondayFirst)
+			buf = appendPadded(buf, year, 4)
+		case 'Y':
+			buf = appendPadded(buf, t.Year(), 4)
+		case 'y':
+			buf = appendPadded(buf, t.Year()%100, 2)
+		default:
+			buf = append(buf, format[i])
+		}
+	}
+	return buf
+}
+
+func (call *builtinDateFormat) eval(env *ExpressionEnv) (eval, error) {
+	date, format, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil || format == nil {
+		return nil, nil
+	}
+	t, ok := evalToDateTime(env, date)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalText(formatDate(t, format.ToRawBytes()), env.collation()), nil
+}
+
+func (call *builtinDateFormat) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.VarChar, flagNullable
+}
+
+func (call *builtinUnixTimestamp) eval(env *ExpressionEnv) (eval, error) {
+	if len(call.Arguments) == 0 {
+		return newEvalInt64(env.currentTimeIn(time.UTC).Unix()), nil
+	}
+
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	t, ok := evalToDateTime(env, arg)
+	if !ok {
+		return nil, nil
+	}
+
+	prec := evalPrecision(arg)
+	ts := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), env.timeZone())
+	secs := ts.Unix()
+	if secs < 0 || secs > maxUnixTimestamp {
+		secs, prec = 0, 0
+	}
+	if prec == 0 {
+		return newEvalInt64(secs), nil
+	}
+	frac := int64(ts.Nanosecond()) / pow10(9-prec)
+	return newEvalDecimalWithPrec(decimal.New(secs*pow10(prec)+frac, int32(-prec)), int32(prec)), nil
+}
+
+func (call *builtinUnixTimestamp) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	if len(call.Arguments) == 0 {
+		return sqltypes.Int64, 0
+	}
+	tt, f := call.Arguments[0].typeof(env)
+	switch {
+	case tt == sqltypes.Decimal || sqltypes.IsFloat(tt):
+		return sqltypes.Decimal, f | flagNullable
+	default:
+		return sqltypes.Int64, f | flagNullable
+	}
+}
+
+func (call *builtinFromUnixtime) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	neg, secs, nanos, ok := evalToSeconds(args[0])
+	if !ok || neg || secs > maxUnixTimestamp {
+		return nil, nil
+	}
+	t := time.Unix(secs, nanos).In(env.timeZone())
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+
+	if len(args) == 2 {
+		return newEvalText(formatDate(t, args[1].ToRawBytes()), env.collation()), nil
+	}
+	return newEvalDateTime(t, evalPrecision(args[0])), nil
+}
+
+func (call *builtinFromUnixtime) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	if len(call.Arguments) == 2 {
+		return sqltypes.VarChar, flagNullable
+	}
+	return sqltypes.Datetime, flagNullable
+}
+
+func (call *builtinDateDiff) eval(env *ExpressionEnv) (eval, error) {
+	left, right, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	l, ok := evalToDate(env, left)
+	if !ok {
+		return nil, nil
+	}
+	r, ok := evalToDate(env, right)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalInt64((l.Unix() - r.Unix()) / 86400), nil
+}
+
+func (call *builtinDateDiff) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+// monthsBetween returns the number of whole months between start and end, which must not be before start
+func monthsBetween(start, end time.Time) int64 {
+	months := int64(end.Year()-start.Year())*12 + int64(end.Month()-start.Month())
+	if end.Day() < start.Day() || (end.Day() == start.Day() && timeOfDay(end) < timeOfDay(start)) {
+		months--
+	}
+	return months
+}
+
+func (call *builtinTimestampDiff) eval(env *ExpressionEnv) (eval, error) {
+	left, right, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	start, ok := evalToDateTime(env, left)
+	if !ok {
+		return nil, nil
+	}
+	end, ok := evalToDateTime(env, right)
+	if !ok {
+		return nil, nil
+	}
+
+	var neg bool
+	if end.Before(start) {
+		start, end, neg = end, start, true
+	}
+
+	var diff int64
+	switch call.unit {
+	case sqlparser.IntervalYear, sqlparser.IntervalQuarter, sqlparser.IntervalMonth:
+		diff = monthsBetween(start, end)
+		switch call.unit {
+		case sqlparser.IntervalYear:
+			diff /= 12
+		case sqlparser.IntervalQuarter:
+			diff /= 3
+		}
+	default:
+		micros := (end.Unix()-start.Unix())*1000000 + int64(end.Nanosecond()-start.Nanosecond())/1000
+		switch call.unit {
+		case sqlparser.IntervalWeek:
+			diff = micros / int64(7*24*time.Hour/time.Microsecond)
+		case sqlparser.IntervalDay:
+			diff = micros / int64(24*time.Hour/time.Microsecond)
+		case sqlparser.IntervalHour:
+			diff = micros / int64(time.Hour/time.Microsecond)
+		case sqlparser.IntervalMinute:
+			diff = micros / int64(time.Minute/time.Microsecond)
+		case sqlparser.IntervalSecond:
+			diff = micros / int64(time.Second/time.Microsecond)
+		case sqlparser.IntervalMicrosecond:
+			diff = micros
+		}
+	}
+	if neg {
+		diff = -diff
+	}
+	return newEvalInt64(diff), nil
+}
+
+func (call *builtinTimestampDiff) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinExtract) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	switch call.unit {
+	case sqlparser.IntervalHour, sqlparser.IntervalMinute, sqlparser.IntervalSecond, sqlparser.IntervalMicrosecond,
+		sqlparser.IntervalHourMinute, sqlparser.IntervalHourSecond, sqlparser.IntervalHourMicrosecond,
+		sqlparser.IntervalMinuteSecond, sqlparser.IntervalMinuteMicrosecond, sqlparser.IntervalSecondMicrosecond:
+		d, ok := evalToTime(arg)
+		if !ok {
+			return nil, nil
+		}
+		neg := d < 0
+		if neg {
+			d = -d
+		}
+		hour := int64(d / time.Hour)
+		minute := int64(d / time.Minute % 60)
+		second := int64(d / time.Second % 60)
+		micro := int64(d % time.Second / time.Microsecond)
+
+		var v int64
+		switch call.unit {
+		case sqlparser.IntervalHour:
+			v = hour
+		case sqlparser.IntervalMinute:
+			v = minute
+		case sqlparser.IntervalSecond:
+			v = second
+		case sqlparser.IntervalMicrosecond:
+			v = micro
+		case sqlparser.IntervalHourMinute:
+			v = hour*100 + minute
+		case sqlparser.IntervalHourSecond:
+			v = hour*10000 + minute*100 + second
+		case sqlparser.IntervalHourMicrosecond:
+			v = (hour*10000+minute*100+second)*1000000 + micro
+		case sqlparser.IntervalMinuteSecond:
+			v = minute*100 + second
+		case sqlparser.IntervalMinuteMicrosecond:
+			v = (minute*100+second)*1000000 + micro
+		case sqlparser.IntervalSecondMicrosecond:
+			v = second*1000000 + micro
+		}
+		if neg {
+			v = -v
+		}
+		return newEvalInt64(v), nil
+	}
+
+	t, ok := evalToDateTime(env, arg)
+	if !ok {
+		return nil, nil
+	}
+	year, month, day := int64(t.Year()), int64(t.Month()), int64(t.Day())
+	hms := int64(t.Hour()*10000 + t.Minute()*100 + t.Second())
+
+	var v int64
+	switch call.unit {
+	case sqlparser.IntervalYear:
+		v = year
+	case sqlparser.IntervalQuarter:
+		v = (month + 2) / 3
+	case sqlparser.IntervalMonth:
+		v = month
+	case sqlparser.IntervalWeek:
+		week, _ := calcWeek(t, weekMode(0))
+		v = int64(week)
+	case sqlparser.IntervalDay:
+		v = day
+	case sqlparser.IntervalYearMonth:
+		v = year*100 + month
+	case sqlparser.IntervalDayHour:
+		v = day*100 + int64(t.Hour())
+	case sqlparser.IntervalDayMinute:
+		v = day*10000 + hms/100
+	case sqlparser.IntervalDaySecond:
+		v = day*1000000 + hms
+	case sqlparser.IntervalDayMicrosecond:
+		v = (day*1000000+hms)*1000000 + int64(t.Nanosecond()/1000)
+	}
+	return newEvalInt64(v), nil
+}
+
+func (call *builtinExtract) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+// parseTimeZone parses a time zone as accepted by CONVERT_TZ: either an offset
+// like '+10:00', 'SYSTEM', or a named time zone
+func parseTimeZone(env *ExpressionEnv, tz string) (*time.Location, bool) {
+	if strings.EqualFold(tz, "SYSTEM") {
+		return time.Local, true
+	}
+	if len(tz) > 0 && (tz[0] == '+' || tz[0] == '-') {
+		parts := strings.Split(tz[1:], ":")
+		if len(parts) != 2 || !isDigits(parts[0]) || len(parts[1]) != 2 || !isDigits(parts[1]) {
+			return nil, false
+		}
+		hours, minutes := atoi(parts[0]), atoi(parts[1])
+		offset := hours*3600 + minutes*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		if minutes >= 60 || offset < -13*3600-59*60 || offset > 14*3600 {
+			return nil, false
+		}
+		return time.FixedZone(tz, offset), true
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+func (call *builtinConvertTz) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	t, ok := evalToDateTime(env, args[0])
+	if !ok {
+		return nil, nil
+	}
+	from, ok := parseTimeZone(env, string(args[1].ToRawBytes()))
+	if !ok {
+		return nil, nil
+	}
+	to, ok := parseTimeZone(env, string(args[2].ToRawBytes()))
+	if !ok {
+		return nil, nil
+	}
+
+	prec := evalPrecision(args[0])
+	ts := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), from)
+	if secs := ts.Unix(); secs < 0 || secs > maxUnixTimestamp {
+		// values outside of the TIMESTAMP range are not converted
+		return newEvalDateTime(t, prec), nil
+	}
+	ts = ts.In(to)
+	t = time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), time.UTC)
+	return newEvalDateTime(t, prec), nil
+}
+
+func (call *builtinConvertTz) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Datetime, flagNullable
+}
+
+func (call *builtinDate) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	t, ok := evalToDate(env, arg)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalDate(t), nil
+}
+
+func (call *builtinDate) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Date, flagNullable
+}
+
+func (call *builtinTime) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	d, ok := evalToTime(arg)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalTime(d, evalPrecision(arg)), nil
+}
+
+func (call *builtinTime) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Time, flagNullable
+}
+
+func (call *builtinLastDay) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	t, ok := evalToDate(env, arg)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalDate(time.Date(t.Year(), t.Month(), daysInMonth(t.Year(), t.Month()), 0, 0, 0, 0, time.UTC)), nil
+}
+
+func (call *builtinLastDay) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Date, flagNullable
+}
+
+func (call *builtinWeek) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+	t, ok := evalToDate(env, args[0])
+	if !ok {
+		return nil, nil
+	}
+	var mode int
+	if len(args) == 2 {
+		mode = int(evalToNumeric(args[1]).toInt64().i)
+	}
+	week, _ := calcWeek(t, weekMode(mode))
+	return newEvalInt64(int64(week)), nil
+}
+
+func (call *builtinWeek) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinDatePart) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	t, ok := evalToDateTime(env, arg)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalInt64(call.part(t)), nil
+}
+
+func (call *builtinDatePart) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinTimePart) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	d, ok := evalToTime(arg)
+	if !ok {
+		return nil, nil
+	}
+	if d < 0 {
+		d = -d
+	}
+	return newEvalInt64(call.part(d)), nil
+}
+
+func (call *builtinTimePart) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinDateName) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+	t, ok := evalToDate(env, arg)
+	if !ok {
+		return nil, nil
+	}
+	return newEvalText([]byte(call.name(t)), env.collation()), nil
+}
+
+func (call *builtinDateName) typeof(env *ExpressionEnv) (sqltypes.Type, typeFlag) {
+	return sqltypes.VarChar, flagNullable
+}
+
+var datePartFuncs = map[string]func(t time.Time) int64{
+	"year":       func(t time.Time) int64 { return int64(t.Year()) },
+	"quarter":    func(t time.Time) int64 { return int64(t.Month()+2) / 3 },
+	"month":      func(t time.Time) int64 { return int64(t.Month()) },
+	"day":        func(t time.Time) int64 { return int64(t.Day()) },
+	"dayofmonth": func(t time.Time) int64 { return int64(t.Day()) },
+	"dayofweek":  func(t time.Time) int64 { return int64(t.Weekday()) + 1 },
+	"weekday":    func(t time.Time) int64 { return int64(t.Weekday()+6) % 7 },
+	"dayofyear":  func(t time.Time) int64 { return int64(t.YearDay()) },
+}
+
+var timePartFuncs = map[string]func(d time.Duration) int64{
+	"hour":        func(d time.Duration) int64 { return int64(d / time.Hour) },
+	"minute":      func(d time.Duration) int64 { return int64(d / time.Minute % 60) },
+	"second":      func(d time.Duration) int64 { return int64(d / time.Second % 60) },
+	"microsecond": func(d time.Duration) int64 { return int64(d % time.Second / time.Microsecond) },
+}
+
+var dateNameFuncs = map[string]func(t time.Time) string{
+	"dayname":   func(t time.Time) string { return t.Weekday().String() },
+	"monthname": func(t time.Time) string { return t.Month().String() },
+}
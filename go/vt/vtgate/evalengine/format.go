@@ -210,3 +210,50 @@ func (c *ConvertUsingExpr) format(buf *formatter, depth int) {
 	buf.WriteString(collations.Local().LookupByID(c.Collation).Name())
 	buf.WriteByte(')')
 }
+
+func (call *builtinNow) format(w *formatter, depth int) {
+	w.WriteString(strings.ToUpper(call.Method))
+	w.WriteByte('(')
+	if call.prec > 0 {
+		fmt.Fprintf(w, "%d", call.prec)
+	}
+	w.WriteByte(')')
+}
+
+func (call *builtinCurtime) format(w *formatter, depth int) {
+	w.WriteString(strings.ToUpper(call.Method))
+	w.WriteByte('(')
+	if call.prec > 0 {
+		fmt.Fprintf(w, "%d", call.prec)
+	}
+	w.WriteByte(')')
+}
+
+func (call *builtinDateMath) format(w *formatter, depth int) {
+	w.WriteString(strings.ToUpper(call.Method))
+	w.WriteByte('(')
+	call.Arguments[0].format(w, depth+1)
+	w.WriteString(", INTERVAL ")
+	call.Arguments[1].format(w, depth+1)
+	w.WriteByte(' ')
+	w.WriteString(strings.ToUpper(call.unit.ToString()))
+	w.WriteByte(')')
+}
+
+func (call *builtinTimestampDiff) format(w *formatter, depth int) {
+	w.WriteString("TIMESTAMPDIFF(")
+	w.WriteString(strings.ToUpper(call.unit.ToString()))
+	w.WriteString(", ")
+	call.Arguments[0].format(w, depth+1)
+	w.WriteString(", ")
+	call.Arguments[1].format(w, depth+1)
+	w.WriteByte(')')
+}
+
+func (call *builtinExtract) format(w *formatter, depth int) {
+	w.WriteString("EXTRACT(")
+	w.WriteString(strings.ToUpper(call.unit.ToString()))
+	w.WriteString(" FROM ")
+	call.Arguments[0].format(w, depth+1)
+	w.WriteByte(')')
+}
@@ -823,3 +823,26 @@ func TestCeilandCeiling(t *testing.T) {
 		compareRemoteExpr(t, conn, fmt.Sprintf("CEILING(%s)", num))
 	}
 }
+
+func TestSqrt(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var sqrtInputs = []string{
+		"0",
+		"1",
+		"-1",
+		"4",
+		"'16'",
+		"NULL",
+		"'ABC'",
+		"2.25",
+		"1.5e0",
+		"-1.5e0",
+		"18446744073709551615",
+	}
+
+	for _, num := range sqrtInputs {
+		compareRemoteExpr(t, conn, fmt.Sprintf("SQRT(%s)", num))
+	}
+}
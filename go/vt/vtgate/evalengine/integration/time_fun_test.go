@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Vitess Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"fmt"
+	"testing"
+)
+
+var dateInputs = []string{
+	"NULL",
+	"'2023-01-07'",
+	"'2023-01-07 12:34:56'",
+	"'2023-01-07 12:34:56.789'",
+	"'2020-02-29 23:59:59.999999'",
+	"'1999-12-31T23:59:59'",
+	"'99-12-31'",
+	"'20230107'",
+	"'20230107123456'",
+	"'2023-02-30'",
+	"'not a date'",
+	"20230107",
+	"20230107123456",
+	"20230107123456.5",
+	"CAST('2023-01-07' AS DATE)",
+	"CAST('2023-01-07 12:34:56.789' AS DATETIME(3))",
+	"CAST('12:34:56' AS TIME)",
+	"CAST('-838:59:59' AS TIME)",
+}
+
+var timeInputs = []string{
+	"NULL",
+	"'12:34:56'",
+	"'12:34:56.789'",
+	"'-12:34:56'",
+	"'838:59:59'",
+	"'3 10:00:00'",
+	"'123456'",
+	"123456",
+	"123456.25",
+	"'2023-01-07 12:34:56'",
+	"CAST('100:00:00' AS TIME)",
+}
+
+var intervalUnits = []string{
+	"MICROSECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR",
+	"SECOND_MICROSECOND", "MINUTE_MICROSECOND", "MINUTE_SECOND", "HOUR_MICROSECOND", "HOUR_SECOND",
+	"HOUR_MINUTE", "DAY_MICROSECOND", "DAY_SECOND", "DAY_MINUTE", "DAY_HOUR", "YEAR_MONTH",
+}
+
+func TestBuiltinDatePart(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var funcs = []string{
+		"YEAR", "QUARTER", "MONTH", "DAY", "DAYOFMONTH", "DAYOFWEEK", "WEEKDAY", "DAYOFYEAR",
+		"DAYNAME", "MONTHNAME", "LAST_DAY", "DATE", "WEEK",
+	}
+	for _, fn := range funcs {
+		for _, d := range dateInputs {
+			compareRemoteExpr(t, conn, fmt.Sprintf("%s(%s)", fn, d))
+		}
+	}
+	for mode := 0; mode < 8; mode++ {
+		for _, d := range dateInputs {
+			compareRemoteExpr(t, conn, fmt.Sprintf("WEEK(%s, %d)", d, mode))
+		}
+	}
+}
+
+func TestBuiltinTimePart(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var funcs = []string{"HOUR", "MINUTE", "SECOND", "MICROSECOND", "TIME"}
+	for _, fn := range funcs {
+		for _, d := range timeInputs {
+			compareRemoteExpr(t, conn, fmt.Sprintf("%s(%s)", fn, d))
+		}
+	}
+}
+
+func TestBuiltinDateMath(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var intervals = []string{"1", "-1", "'1:2'", "'1 2:3:4.5'", "1.5", "'-1 1'", "NULL"}
+	for _, unit := range intervalUnits {
+		for _, d := range dateInputs {
+			for _, iv := range intervals {
+				compareRemoteExpr(t, conn, fmt.Sprintf("DATE_ADD(%s, INTERVAL %s %s)", d, iv, unit))
+				compareRemoteExpr(t, conn, fmt.Sprintf("%s - INTERVAL %s %s", d, iv, unit))
+			}
+		}
+	}
+	for _, d := range dateInputs {
+		compareRemoteExpr(t, conn, fmt.Sprintf("ADDDATE(%s, 31)", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("SUBDATE(%s, 31)", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("TIMESTAMPADD(MONTH, 1, %s)", d))
+	}
+}
+
+func TestBuiltinDateFormat(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var formats = []string{
+		"'%a %b %c %D %d %e %f %H %h %I %i %j %k %l %M %m %p'",
+		"'%r %S %s %T %U %u %V %v %W %w %X %x %Y %y %%'",
+		"'%Q %'",
+	}
+	for _, d := range dateInputs {
+		for _, f := range formats {
+			compareRemoteExpr(t, conn, fmt.Sprintf("DATE_FORMAT(%s, %s)", d, f))
+		}
+	}
+}
+
+func TestBuiltinDateDiff(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var units = []string{"MICROSECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR"}
+	for _, d1 := range dateInputs {
+		for _, d2 := range dateInputs {
+			compareRemoteExpr(t, conn, fmt.Sprintf("DATEDIFF(%s, %s)", d1, d2))
+			for _, unit := range units {
+				compareRemoteExpr(t, conn, fmt.Sprintf("TIMESTAMPDIFF(%s, %s, %s)", unit, d1, d2))
+			}
+		}
+	}
+}
+
+func TestBuiltinExtract(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	for _, unit := range intervalUnits {
+		for _, d := range dateInputs {
+			compareRemoteExpr(t, conn, fmt.Sprintf("EXTRACT(%s FROM %s)", unit, d))
+		}
+	}
+}
+
+func TestBuiltinConvertTz(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	var zones = []string{"'+00:00'", "'-05:30'", "'+13:00'", "'+14:01'", "'bogus'", "NULL"}
+	for _, d := range dateInputs {
+		for _, from := range zones {
+			for _, to := range zones {
+				compareRemoteExpr(t, conn, fmt.Sprintf("CONVERT_TZ(%s, %s, %s)", d, from, to))
+			}
+		}
+	}
+}
+
+func TestTemporalCast(t *testing.T) {
+	var conn = mysqlconn(t)
+	defer conn.Close()
+
+	for _, d := range dateInputs {
+		compareRemoteExpr(t, conn, fmt.Sprintf("CAST(%s AS DATE)", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("CAST(%s AS DATETIME)", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("CAST(%s AS DATETIME(2))", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("%s + 0", d))
+	}
+	for _, d := range timeInputs {
+		compareRemoteExpr(t, conn, fmt.Sprintf("CAST(%s AS TIME)", d))
+		compareRemoteExpr(t, conn, fmt.Sprintf("CAST(%s AS TIME(6))", d))
+	}
+}
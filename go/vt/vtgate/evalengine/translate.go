@@ -213,6 +213,13 @@ func translateLiteral(lit *sqlparser.Literal, lookup TranslationLookup) (*Litera
 }
 
 func translateBinaryExpr(binary *sqlparser.BinaryExpr, lookup TranslationLookup) (Expr, error) {
+	if iv, ok := binary.Right.(*sqlparser.IntervalExpr); ok && (binary.Operator == sqlparser.PlusOp || binary.Operator == sqlparser.MinusOp) {
+		return translateIntervalArithmetic(binary, binary.Left, iv, binary.Operator == sqlparser.MinusOp, lookup)
+	}
+	if iv, ok := binary.Left.(*sqlparser.IntervalExpr); ok && binary.Operator == sqlparser.PlusOp {
+		return translateIntervalArithmetic(binary, binary.Right, iv, false, lookup)
+	}
+
 	left, err := translateExpr(binary.Left, lookup)
 	if err != nil {
 		return nil, err
@@ -254,6 +261,26 @@ func translateBinaryExpr(binary *sqlparser.BinaryExpr, lookup TranslationLookup)
 	}
 }
 
+func translateIntervalArithmetic(binary *sqlparser.BinaryExpr, date sqlparser.Expr, iv *sqlparser.IntervalExpr, sub bool, lookup TranslationLookup) (Expr, error) {
+	unit, err := translateIntervalUnit(iv.Unit, binary)
+	if err != nil {
+		return nil, err
+	}
+	args, err := translateFuncArgs([]sqlparser.Expr{date, iv.Expr}, lookup)
+	if err != nil {
+		return nil, err
+	}
+	method := "DATE_ADD"
+	if sub {
+		method = "DATE_SUB"
+	}
+	return &builtinDateMath{
+		CallExpr: CallExpr{Arguments: args, Method: method},
+		unit:     unit,
+		sub:      sub,
+	}, nil
+}
+
 func translateTuple(tuple sqlparser.ValTuple, lookup TranslationLookup) (Expr, error) {
 	var exprs TupleExpr
 	for _, expr := range tuple {
@@ -44,6 +44,13 @@ func translateFuncArgs(fnargs []sqlparser.Expr, lookup TranslationLookup) ([]Exp
 }
 
 func translateFuncExpr(fn *sqlparser.FuncExpr, lookup TranslationLookup) (Expr, error) {
+	switch fn.Name.Lowered() {
+	case "date_add", "date_sub", "adddate", "subdate":
+		// these take an INTERVAL expression as their second argument, which
+		// cannot be translated on its own
+		return translateDateMath(fn, lookup)
+	}
+
 	var args TupleExpr
 	for _, expr := range fn.Exprs {
 		aliased, ok := expr.(*sqlparser.AliasedExpr)
@@ -102,6 +109,11 @@ func translateFuncExpr(fn *sqlparser.FuncExpr, lookup TranslationLookup) (Expr,
 			return nil, argError(method)
 		}
 		return &builtinCeil{CallExpr: call}, nil
+	case "sqrt":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinSqrt{CallExpr: call}, nil
 	case "lower", "lcase":
 		if len(args) != 1 {
 			return nil, argError(method)
@@ -159,11 +171,160 @@ func translateFuncExpr(fn *sqlparser.FuncExpr, lookup TranslationLookup) (Expr,
 		default:
 			return nil, argError(method)
 		}
+	case "curdate", "current_date", "utc_date":
+		if len(args) != 0 {
+			return nil, argError(method)
+		}
+		return &builtinCurdate{CallExpr: call, utc: method == "utc_date"}, nil
+	case "curtime":
+		prec, err := translatePrecision(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinCurtime{CallExpr: CallExpr{Method: method}, prec: prec}, nil
+	case "sysdate":
+		prec, err := translatePrecision(method, args)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinNow{CallExpr: CallExpr{Method: method}, prec: prec}, nil
+	case "date_format":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinDateFormat{CallExpr: call}, nil
+	case "unix_timestamp":
+		if len(args) > 1 {
+			return nil, argError(method)
+		}
+		return &builtinUnixTimestamp{CallExpr: call}, nil
+	case "from_unixtime":
+		switch len(args) {
+		case 1, 2:
+			return &builtinFromUnixtime{CallExpr: call}, nil
+		default:
+			return nil, argError(method)
+		}
+	case "datediff":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinDateDiff{CallExpr: call}, nil
+	case "convert_tz":
+		if len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinConvertTz{CallExpr: call}, nil
+	case "date":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinDate{CallExpr: call}, nil
+	case "time":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinTime{CallExpr: call}, nil
+	case "last_day":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinLastDay{CallExpr: call}, nil
+	case "week":
+		switch len(args) {
+		case 1, 2:
+			return &builtinWeek{CallExpr: call}, nil
+		default:
+			return nil, argError(method)
+		}
+	case "year", "quarter", "month", "day", "dayofmonth", "dayofweek", "weekday", "dayofyear":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinDatePart{CallExpr: call, part: datePartFuncs[method]}, nil
+	case "hour", "minute", "second", "microsecond":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinTimePart{CallExpr: call, part: timePartFuncs[method]}, nil
+	case "dayname", "monthname":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinDateName{CallExpr: call, name: dateNameFuncs[method]}, nil
 	default:
 		return nil, translateExprNotSupported(fn)
 	}
 }
 
+// translatePrecision returns the fractional seconds precision given as the only
+// argument of a function like NOW(3); the precision must be a constant.
+func translatePrecision(method string, args []Expr) (int, error) {
+	switch len(args) {
+	case 0:
+		return 0, nil
+	case 1:
+		lit, ok := args[0].(*Literal)
+		if !ok {
+			return 0, vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "%s: fractional seconds precision must be a constant", ErrTranslateExprNotSupported)
+		}
+		prec := evalToNumeric(lit.inner).toUint64().u
+		if prec > maxTimePrecision {
+			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Too-big precision %d specified for '%s'. Maximum is %d.", prec, method, maxTimePrecision)
+		}
+		return int(prec), nil
+	default:
+		return 0, argError(method)
+	}
+}
+
+func translateIntervalUnit(unit string, e sqlparser.Expr) (sqlparser.IntervalTypes, error) {
+	ty, ok := parseIntervalUnit(unit)
+	if !ok {
+		return 0, translateExprNotSupported(e)
+	}
+	return ty, nil
+}
+
+func translateDateMath(fn *sqlparser.FuncExpr, lookup TranslationLookup) (Expr, error) {
+	method := fn.Name.Lowered()
+	if len(fn.Exprs) != 2 {
+		return nil, argError(method)
+	}
+	date, ok := fn.Exprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil, translateExprNotSupported(fn)
+	}
+	value, ok := fn.Exprs[1].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil, translateExprNotSupported(fn)
+	}
+
+	// ADDDATE and SUBDATE can take a number of days instead of an INTERVAL
+	unit := sqlparser.IntervalDay
+	valueExpr := value.Expr
+	if iv, ok := value.Expr.(*sqlparser.IntervalExpr); ok {
+		var err error
+		unit, err = translateIntervalUnit(iv.Unit, fn)
+		if err != nil {
+			return nil, err
+		}
+		valueExpr = iv.Expr
+	} else if method == "date_add" || method == "date_sub" {
+		return nil, translateExprNotSupported(fn)
+	}
+
+	args, err := translateFuncArgs([]sqlparser.Expr{date.Expr, valueExpr}, lookup)
+	if err != nil {
+		return nil, err
+	}
+	return &builtinDateMath{
+		CallExpr: CallExpr{Arguments: args, Method: method},
+		unit:     unit,
+		sub:      method == "date_sub" || method == "subdate",
+	}, nil
+}
+
 func translateCallable(call sqlparser.Callable, lookup TranslationLookup) (Expr, error) {
 	switch call := call.(type) {
 	case *sqlparser.FuncExpr:
@@ -258,6 +419,65 @@ func translateCallable(call sqlparser.Callable, lookup TranslationLookup) (Expr,
 			Method:    "JSON_CONTAINS_PATH",
 		}}, nil
 
+	case *sqlparser.CurTimeFuncExpr:
+		method := call.Name.Lowered()
+		var args []Expr
+		if call.Fsp != nil {
+			fsp, err := translateExpr(call.Fsp, lookup)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, fsp)
+		}
+		prec, err := translatePrecision(method, args)
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case "current_time", "utc_time":
+			return &builtinCurtime{CallExpr: CallExpr{Method: method}, utc: method == "utc_time", prec: prec}, nil
+		default:
+			return &builtinNow{CallExpr: CallExpr{Method: method}, utc: method == "utc_timestamp", prec: prec}, nil
+		}
+
+	case *sqlparser.TimestampFuncExpr:
+		unit, err := translateIntervalUnit(call.Unit, call)
+		if err != nil {
+			return nil, err
+		}
+		args, err := translateFuncArgs([]sqlparser.Expr{call.Expr1, call.Expr2}, lookup)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(call.Name) {
+		case "timestampadd":
+			// TIMESTAMPADD(unit, interval, datetime) is DATE_ADD(datetime, INTERVAL interval unit)
+			return &builtinDateMath{
+				CallExpr: CallExpr{Arguments: []Expr{args[1], args[0]}, Method: "DATE_ADD"},
+				unit:     unit,
+			}, nil
+		case "timestampdiff":
+			if intervalFields(unit) > 1 {
+				return nil, translateExprNotSupported(call)
+			}
+			return &builtinTimestampDiff{
+				CallExpr: CallExpr{Arguments: args, Method: "TIMESTAMPDIFF"},
+				unit:     unit,
+			}, nil
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+
+	case *sqlparser.ExtractFuncExpr:
+		arg, err := translateExpr(call.Expr, lookup)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinExtract{
+			CallExpr: CallExpr{Arguments: []Expr{arg}, Method: "EXTRACT"},
+			unit:     call.IntervalTypes,
+		}, nil
+
 	case *sqlparser.JSONKeysExpr:
 		var args []Expr
 		doc, err := translateExpr(call.JSONDoc, lookup)
@@ -136,6 +136,8 @@ func (card cardinalityCheck) expr(expr Expr) error {
 		return card.unary(expr.Inner)
 	case *BitwiseNotExpr:
 		return card.unary(expr.Inner)
+	case *NotExpr:
+		return card.unary(expr.Inner)
 	case *ArithmeticExpr:
 		return card.binary(expr.Left, expr.Right)
 	case *LogicalExpr:
@@ -111,7 +111,13 @@ func translateConvertExpr(expr sqlparser.Expr, convertType *sqlparser.ConvertTyp
 		if err != nil {
 			return nil, err
 		}
-	case "BINARY", "DOUBLE", "REAL", "SIGNED", "SIGNED INTEGER", "UNSIGNED", "UNSIGNED INTEGER", "JSON":
+	case "DATETIME", "TIME":
+		if convert.Length > maxTimePrecision {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT,
+				"Too-big precision %d specified for '%s'. Maximum is %d.",
+				convert.Length, sqlparser.String(expr), maxTimePrecision)
+		}
+	case "BINARY", "DOUBLE", "REAL", "DATE", "SIGNED", "SIGNED INTEGER", "UNSIGNED", "UNSIGNED INTEGER", "JSON":
 		// Supported types for conv expression
 	default:
 		// For unsupported types, we should return an error on translation instead of returning an error on runtime.
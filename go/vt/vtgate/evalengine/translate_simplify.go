@@ -194,3 +194,15 @@ func simplifyExpr(env *ExpressionEnv, e Expr) (Expr, error) {
 	}
 	return e, nil
 }
+
+func (call *builtinNow) constant() bool {
+	return false
+}
+
+func (call *builtinCurdate) constant() bool {
+	return false
+}
+
+func (call *builtinCurtime) constant() bool {
+	return false
+}
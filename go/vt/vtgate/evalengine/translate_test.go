@@ -279,6 +279,84 @@ func TestEvaluate(t *testing.T) {
 	}, {
 		expression: "false is not false",
 		expected:   False,
+	}, {
+		expression: "date_add('2023-01-31', interval 1 month)",
+		expected:   sqltypes.NewVarChar("2023-02-28"),
+	}, {
+		expression: "date_sub(cast('2023-03-01' as date), interval 1 day)",
+		expected:   sqltypes.NewDate("2023-02-28"),
+	}, {
+		expression: "cast('2023-01-07 12:34:56' as date) + interval 90 minute",
+		expected:   sqltypes.NewDatetime("2023-01-07 01:30:00"),
+	}, {
+		expression: "adddate(cast('2023-01-07 12:34:56.789' as datetime(3)), 2)",
+		expected:   sqltypes.NewDatetime("2023-01-09 12:34:56.789"),
+	}, {
+		expression: "cast('2023-01-07 12:34:56' as datetime) - interval '1:2' minute_second",
+		expected:   sqltypes.NewDatetime("2023-01-07 12:33:54"),
+	}, {
+		expression: "timestampadd(second, 1.5, cast('2023-01-07 23:59:59' as datetime))",
+		expected:   sqltypes.NewDatetime("2023-01-08 00:00:00.5"),
+	}, {
+		expression: "cast('12:34:56.5' as time(0))",
+		expected:   sqltypes.NewTime("12:34:57"),
+	}, {
+		expression: "cast(20230107 as datetime)",
+		expected:   sqltypes.NewDatetime("2023-01-07 00:00:00"),
+	}, {
+		expression: "cast('2023-02-30' as date)",
+		expected:   NULL,
+	}, {
+		expression: "date_format('2023-01-01 13:04:05', '%a %b %D %Y %h:%i:%s %p %U %u %V %v %X %x %j')",
+		expected:   sqltypes.NewVarChar("Sun Jan 1st 2023 01:04:05 PM 01 00 01 52 2023 2022 001"),
+	}, {
+		expression: "datediff('2023-03-01', '2023-02-01 23:59:59')",
+		expected:   sqltypes.NewInt64(28),
+	}, {
+		expression: "timestampdiff(month, '2023-01-31', '2023-02-28')",
+		expected:   sqltypes.NewInt64(0),
+	}, {
+		expression: "timestampdiff(hour, '2023-01-02', '2023-01-01')",
+		expected:   sqltypes.NewInt64(-24),
+	}, {
+		expression: "extract(day_second from '2023-01-07 12:34:56')",
+		expected:   sqltypes.NewInt64(7123456),
+	}, {
+		expression: "extract(hour_minute from '-838:59:59')",
+		expected:   sqltypes.NewInt64(-83859),
+	}, {
+		expression: "convert_tz('2023-01-07 12:00:00', '+00:00', '-05:30')",
+		expected:   sqltypes.NewDatetime("2023-01-07 06:30:00"),
+	}, {
+		expression: "convert_tz('2023-01-07 12:00:00', '+00:00', 'bogus')",
+		expected:   NULL,
+	}, {
+		expression: "last_day('2024-02-10')",
+		expected:   sqltypes.NewDate("2024-02-29"),
+	}, {
+		expression: "week('2023-01-01', 1)",
+		expected:   sqltypes.NewInt64(0),
+	}, {
+		expression: "dayname('2023-01-07')",
+		expected:   sqltypes.NewVarChar("Saturday"),
+	}, {
+		expression: "hour('100:20:30')",
+		expected:   sqltypes.NewInt64(100),
+	}, {
+		expression: "cast('2023-01-07' as date) + 0",
+		expected:   sqltypes.NewInt64(20230107),
+	}, {
+		expression: "sqrt(16)",
+		expected:   sqltypes.NewFloat64(4),
+	}, {
+		expression: "sqrt(-4)",
+		expected:   NULL,
+	}, {
+		expression: "not :exp",
+		expected:   False,
+	}, {
+		expression: "not 0 or 1 = 2",
+		expected:   True,
 	}}
 
 	for _, test := range tests {
@@ -356,11 +434,14 @@ func TestTranslationFailures(t *testing.T) {
 		expectedErr string
 	}{
 		{
-			expression:  "cast('2023-01-07 12:34:56' as date)",
-			expectedErr: "Unsupported type conversion: DATE",
+			expression:  "cast('2023-01-07 12:34:56' as datetime(7))",
+			expectedErr: "Too-big precision 7 specified for ''2023-01-07 12:34:56''. Maximum is 6.",
+		}, {
+			expression:  "now(7)",
+			expectedErr: "Too-big precision 7 specified for 'now'. Maximum is 6.",
 		}, {
-			expression:  "cast('2023-01-07 12:34:56' as datetime(5))",
-			expectedErr: "Unsupported type conversion: DATETIME(5)",
+			expression:  "date_add('2023-01-07', 1)",
+			expectedErr: "expr cannot be translated, not supported: date_add('2023-01-07', 1)",
 		}, {
 			expression:  "cast('3.4' as FLOAT)",
 			expectedErr: "Unsupported type conversion: FLOAT",
@@ -115,6 +115,9 @@ type Executor struct {
 
 	// allowScatter will fail planning if set to false and a plan contains any scatter queries
 	allowScatter bool
+
+	// resultCache holds the results of the selects that ask for it, it is nil if it is disabled.
+	resultCache *resultCache
 }
 
 var executorOnce sync.Once
@@ -460,6 +463,12 @@ func (e *Executor) addNeededBindVars(bindVarNeeds *sqlparser.BindVarNeeds, bindV
 				v = raw.ReadAfterWriteTimeout
 			})
 			bindVars[key] = sqltypes.Float64BindVariable(v)
+		case sysvars.ReadAfterWriteConsistency.Name:
+			var v bool
+			ifReadAfterWriteExist(session, func(raw *vtgatepb.ReadAfterWrite) {
+				v = raw.ReadAfterWriteConsistency
+			})
+			bindVars[key] = sqltypes.BoolBindVariable(v)
 		case sysvars.SessionTrackGTIDs.Name:
 			v := "off"
 			ifReadAfterWriteExist(session, func(raw *vtgatepb.ReadAfterWrite) {
@@ -984,6 +993,7 @@ func (e *Executor) getPlan(ctx context.Context, vcursor *vcursorImpl, sql string
 	vcursor.SetIgnoreMaxMemoryRows(ignoreMaxMemoryRows)
 	consolidator := sqlparser.Consolidator(stmt)
 	vcursor.SetConsolidator(consolidator)
+	vcursor.SetResultCacheTTL(sqlparser.ResultCacheTTL(stmt))
 
 	setVarComment, err := prepareSetVarComment(vcursor, stmt)
 	if err != nil {
@@ -753,6 +753,8 @@ func TestSelectSystemVariables(t *testing.T) {
 		ReadAfterWriteGtid:    "a fine gtid",
 		ReadAfterWriteTimeout: 13,
 		SessionTrackGtids:     true,
+
+		ReadAfterWriteConsistency: true,
 	}
 	executor.normalize = true
 	logChan := QueryLogger.Subscribe("Test")
@@ -760,7 +762,7 @@ func TestSelectSystemVariables(t *testing.T) {
 
 	sql := "select @@autocommit, @@client_found_rows, @@skip_query_plan_cache, @@enable_system_settings, " +
 		"@@sql_select_limit, @@transaction_mode, @@workload, @@read_after_write_gtid, " +
-		"@@read_after_write_timeout, @@session_track_gtids, @@ddl_strategy, @@socket, @@query_timeout"
+		"@@read_after_write_timeout, @@read_after_write_consistency, @@session_track_gtids, @@ddl_strategy, @@socket, @@query_timeout"
 
 	result, err := executorExec(executor, sql, map[string]*querypb.BindVariable{})
 	wantResult := &sqltypes.Result{
@@ -774,6 +776,7 @@ func TestSelectSystemVariables(t *testing.T) {
 			{Name: "@@workload", Type: sqltypes.VarChar},
 			{Name: "@@read_after_write_gtid", Type: sqltypes.VarChar},
 			{Name: "@@read_after_write_timeout", Type: sqltypes.Float64},
+			{Name: "@@read_after_write_consistency", Type: sqltypes.Int64},
 			{Name: "@@session_track_gtids", Type: sqltypes.VarChar},
 			{Name: "@@ddl_strategy", Type: sqltypes.VarChar},
 			{Name: "@@socket", Type: sqltypes.VarChar},
@@ -791,6 +794,7 @@ func TestSelectSystemVariables(t *testing.T) {
 			// these have been set at the beginning of the test
 			sqltypes.NewVarChar("a fine gtid"),
 			sqltypes.NewFloat64(13),
+			sqltypes.NewInt64(1),
 			sqltypes.NewVarChar("own_gtid"),
 			sqltypes.NewVarChar(""),
 			sqltypes.NewVarChar(""),
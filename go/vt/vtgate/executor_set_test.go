@@ -257,6 +257,12 @@ func TestExecutorSet(t *testing.T) {
 	}, {
 		in:  "set @@enable_system_settings = false",
 		out: &vtgatepb.Session{Autocommit: true, EnableSystemSettings: false},
+	}, {
+		in:  "set @@read_after_write_consistency = on",
+		out: &vtgatepb.Session{Autocommit: true, ReadAfterWrite: &vtgatepb.ReadAfterWrite{ReadAfterWriteConsistency: true}},
+	}, {
+		in:  "set @@read_after_write_consistency = 0",
+		out: &vtgatepb.Session{Autocommit: true, ReadAfterWrite: &vtgatepb.ReadAfterWrite{}},
 	}, {
 		in:  "set @@socket = '/tmp/change.sock'",
 		err: "VT03010: variable 'socket' is a read only variable",
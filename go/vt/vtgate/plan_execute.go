@@ -207,12 +207,29 @@ func (e *Executor) executePlan(
 	execStart time.Time,
 ) (*sqltypes.Result, error) {
 
+	cacheKey := e.resultCacheKey(ctx, safeSession, plan, vcursor, bindVars)
+	var cacheVersion uint64
+	if cacheKey != "" {
+		if qr, ok := e.resultCache.Get(cacheKey); ok {
+			e.setLogStats(logStats, plan, vcursor, execStart, nil, qr)
+			return qr, nil
+		}
+		var ok bool
+		if cacheVersion, ok = e.resultCache.Begin(plan.TablesUsed, vcursor.tabletType); !ok {
+			cacheKey = ""
+		}
+	}
+
 	// 4: Execute!
 	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
 
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
 
+	if err == nil && cacheKey != "" {
+		e.resultCache.Set(cacheKey, plan.TablesUsed, vcursor.tabletType, cacheVersion, vcursor.resultCacheTTL, qr)
+	}
+
 	// Check if there was partial DML execution. If so, rollback the effect of the partially executed query.
 	if err != nil {
 		return nil, e.rollbackExecIfNeeded(ctx, safeSession, bindVars, logStats, err)
@@ -51,6 +51,13 @@ func (hp *horizonPlanning) pushAggregation(
 
 	case *joinGen4:
 		output = plan
+		if !canSplitAggregations(aggregations) || !canSplitAggregation(ctx, plan, aggregationExprs(grouping, aggregations)) {
+			// the grouping or the aggregations use columns from both sides of the join, or the aggregations
+			// can't be computed from partial results, so we push down the expressions and aggregate at the vtgate level
+			pushed = false
+			groupingOffsets, outputAggrsOffset, err = pushAggrInputs(ctx, plan, grouping, aggregations)
+			return
+		}
 		groupingOffsets, outputAggrsOffset, err = hp.pushAggrOnJoin(ctx, plan, grouping, aggregations)
 		return
 
@@ -59,6 +66,21 @@ func (hp *horizonPlanning) pushAggregation(
 		groupingOffsets, outputAggrsOffset, pushed, err = hp.pushAggrOnSemiJoin(ctx, plan, grouping, aggregations, ignoreOutputOrder)
 		return
 
+	case *correlatedSubquery:
+		// the rows are only known once the subquery has been evaluated for
+		// them, so the aggregation is done at the vtgate level
+		output = plan
+		pushed = false
+		groupingOffsets, outputAggrsOffset, err = pushAggrInputs(ctx, plan, grouping, aggregations)
+		return
+
+	case *distinct, *concatenateGen4:
+		// the rows of a UNION come from different sources, so the aggregation is done at the vtgate level
+		output = plan
+		pushed = false
+		groupingOffsets, outputAggrsOffset, err = pushAggrInputs(ctx, plan, grouping, aggregations)
+		return
+
 	case *simpleProjection:
 		// we just remove the simpleProjection. We are doing an OA on top anyway, so no need to clean up the output columns
 		return hp.pushAggregation(ctx, plan.input, grouping, aggregations, ignoreOutputOrder)
@@ -68,45 +90,149 @@ func (hp *horizonPlanning) pushAggregation(
 		output = plan
 		pushed = false
 
-		for _, grp := range grouping {
-			offset, wOffset, err := wrapAndPushExpr(ctx, grp.Inner, grp.WeightStrExpr, plan.input)
-			if err != nil {
-				return nil, nil, nil, false, err
-			}
-			groupingOffsets = append(groupingOffsets, offsets{
-				col:   offset,
-				wsCol: wOffset,
-			})
+		groupingOffsets, outputAggrsOffset, err = pushAggrInputs(ctx, plan.input, grouping, aggregations)
+		return
+	default:
+		err = vterrors.VT12001(fmt.Sprintf("using aggregation on top of a %T plan", plan))
+		return
+	}
+}
+
+// pushAggrInputs pushes the grouping expressions and the arguments of the aggregations as plain projections,
+// so the aggregation can be done at the vtgate level
+func pushAggrInputs(
+	ctx *plancontext.PlanningContext,
+	plan logicalPlan,
+	grouping []operators.GroupBy,
+	aggregations []operators.Aggr,
+) (groupingOffsets []offsets, outputAggrsOffset [][]offsets, err error) {
+	for _, grp := range grouping {
+		offset, wOffset, err := wrapAndPushExpr(ctx, grp.Inner, grp.WeightStrExpr, plan)
+		if err != nil {
+			return nil, nil, err
 		}
+		groupingOffsets = append(groupingOffsets, offsets{
+			col:   offset,
+			wsCol: wOffset,
+		})
+	}
 
-		for _, aggr := range aggregations {
-			var offset int
-			aggrExpr, ok := aggr.Original.Expr.(sqlparser.AggrFunc)
-			if !ok {
-				return nil, nil, nil, false, vterrors.VT13001(fmt.Sprintf("unexpected expression: %v", aggr.Original))
-			}
+	for _, aggr := range aggregations {
+		var offset int
+		aggrExpr, ok := aggr.Original.Expr.(sqlparser.AggrFunc)
+		if !ok {
+			return nil, nil, vterrors.VT13001(fmt.Sprintf("unexpected expression: %v", aggr.Original))
+		}
 
-			switch aggrExpr.(type) {
-			case *sqlparser.CountStar:
-				offset = 0
-			default:
-				if len(aggrExpr.GetArgs()) != 1 {
-					return nil, nil, nil, false, vterrors.VT13001(fmt.Sprintf("unexpected expression: %v", aggrExpr))
-				}
-				offset, _, err = pushProjection(ctx, &sqlparser.AliasedExpr{Expr: aggrExpr.GetArg() /*As: expr.As*/}, plan.input, true, true, false)
+		switch aggrExpr := aggrExpr.(type) {
+		case *sqlparser.CountStar:
+			offset = 0
+		case *sqlparser.GroupConcatExpr, *sqlparser.JSONArrayAgg, *sqlparser.JSONObjectAgg:
+			// the raw values are turned into what the aggregation would produce for a single row
+			offset, _, err = pushProjection(ctx, &sqlparser.AliasedExpr{Expr: singleRowAggrValue(aggrExpr)}, plan, true, true, false)
+		default:
+			if len(aggrExpr.GetArgs()) != 1 {
+				return nil, nil, vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s' with more than one argument", aggrExpr.AggrName()))
 			}
+			offset, _, err = pushProjection(ctx, &sqlparser.AliasedExpr{Expr: aggrExpr.GetArg() /*As: expr.As*/}, plan, true, true, false)
+		}
 
-			if err != nil {
-				return nil, nil, nil, false, err
-			}
+		if err != nil {
+			return nil, nil, err
+		}
 
-			outputAggrsOffset = append(outputAggrsOffset, []offsets{newOffset(offset)})
+		outputAggrsOffset = append(outputAggrsOffset, []offsets{newOffset(offset)})
+	}
+	return
+}
+
+// singleRowAggrValue returns the expression producing the value of the aggregation over a single row,
+// so the rows can be merged like the partial results of the aggregation would be
+func singleRowAggrValue(aggr sqlparser.AggrFunc) sqlparser.Expr {
+	var name string
+	switch aggr.(type) {
+	case *sqlparser.JSONArrayAgg:
+		name = "json_array"
+	case *sqlparser.JSONObjectAgg:
+		name = "json_object"
+	default:
+		if len(aggr.GetArgs()) == 1 {
+			return aggr.GetArg()
 		}
+		name = "concat"
+	}
+	var exprs sqlparser.SelectExprs
+	for _, arg := range aggr.GetArgs() {
+		exprs = append(exprs, &sqlparser.AliasedExpr{Expr: arg})
+	}
+	return &sqlparser.FuncExpr{Name: sqlparser.NewIdentifierCI(name), Exprs: exprs}
+}
 
-		return
+// canSplitAggregations returns false if any of the aggregations can't be computed from the partial
+// aggregations on each side of a join. Since the join repeats the rows of one side for each matching
+// row of the other side, this is the case for the aggregations that are sensitive to duplicate values.
+func canSplitAggregations(aggregations []operators.Aggr) bool {
+	for _, aggr := range aggregations {
+		switch aggr.OpCode {
+		case engine.AggregateGroupConcat, engine.AggregateGroupConcatDistinct, engine.AggregateBitXor,
+			engine.AggregateJSONArrayAgg, engine.AggregateJSONObjectAgg:
+			return false
+		}
+	}
+	return true
+}
+
+// aggregationExprs returns the grouping expressions and the arguments of the aggregations
+func aggregationExprs(grouping []operators.GroupBy, aggregations []operators.Aggr) []sqlparser.Expr {
+	var exprs []sqlparser.Expr
+	for _, grp := range grouping {
+		exprs = append(exprs, grp.Inner)
+	}
+	for _, aggr := range aggregations {
+		if _, ok := aggr.Original.Expr.(*sqlparser.CountStar); ok {
+			// count(*) is sent to both sides of a join
+			continue
+		}
+		exprs = append(exprs, aggr.Original.Expr)
+	}
+	return exprs
+}
+
+// canSplitAggregation checks if the aggregation can be broken down into partial aggregations
+// on the inputs of the plan. That is possible as long as every grouping and aggregation can be
+// solved by a single side of each join, and there is no limit under the joins
+func canSplitAggregation(ctx *plancontext.PlanningContext, plan logicalPlan, exprs []sqlparser.Expr) bool {
+	switch plan := plan.(type) {
+	case *joinGen4:
+		lhsTS := plan.Left.ContainsTables()
+		rhsTS := plan.Right.ContainsTables()
+		var lhsExprs, rhsExprs []sqlparser.Expr
+		for _, expr := range exprs {
+			deps := ctx.SemTable.RecursiveDeps(expr)
+			switch {
+			case deps.IsSolvedBy(lhsTS):
+				lhsExprs = append(lhsExprs, expr)
+			case deps.IsSolvedBy(rhsTS):
+				rhsExprs = append(rhsExprs, expr)
+			default:
+				return false
+			}
+		}
+		for _, col := range plan.LHSColumns {
+			lhsExprs = append(lhsExprs, col)
+		}
+		return canSplitAggregation(ctx, plan.Left, lhsExprs) && canSplitAggregation(ctx, plan.Right, rhsExprs)
+	case *semiJoin:
+		for _, col := range plan.LHSColumns {
+			exprs = append(exprs, col)
+		}
+		return canSplitAggregation(ctx, plan.lhs, exprs)
+	case *simpleProjection:
+		return canSplitAggregation(ctx, plan.input, exprs)
+	case *limit:
+		return false
 	default:
-		err = vterrors.VT12001(fmt.Sprintf("using aggregation on top of a %T plan", plan))
-		return
+		return true
 	}
 }
 
@@ -429,6 +555,11 @@ func isMinOrMax(in engine.AggregateOpcode) bool {
 	}
 }
 
+// isIdempotent returns true for the aggregations whose result does not change when values are repeated
+func isIdempotent(in engine.AggregateOpcode) bool {
+	return isMinOrMax(in) || in == engine.AggregateBitAnd || in == engine.AggregateBitOr
+}
+
 func isRandom(in engine.AggregateOpcode) bool {
 	return in == engine.AggregateRandom
 }
@@ -447,8 +578,8 @@ func splitAggregationsToLeftAndRight(
 		} else {
 			deps := ctx.SemTable.RecursiveDeps(aggr.Original.Expr)
 			var other *operators.Aggr
-			// if we are sending down min/max/random, we don't have to multiply the results with anything
-			if !isMinOrMax(aggr.OpCode) && !isRandom(aggr.OpCode) {
+			// if we are sending down min/max/bit_and/bit_or/random, we don't have to multiply the results with anything
+			if !isIdempotent(aggr.OpCode) && !isRandom(aggr.OpCode) {
 				other = countStarAggr()
 			}
 			switch {
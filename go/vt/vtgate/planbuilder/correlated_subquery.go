@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*correlatedSubquery)(nil)
+
+// correlatedSubquery is the logicalPlan for engine.CorrelatedSubquery.
+// It is used for the correlated subqueries whose result is needed by the
+// outer query, and that could not be merged with it.
+type correlatedSubquery struct {
+	gen4Plan
+	outer, inner logicalPlan
+	extracted    *sqlparser.ExtractedSubquery
+
+	// vars are the columns of the outer query used by the subquery
+	vars map[string]int
+
+	// predicate is the predicate of the outer query using the subquery, if any
+	predicate evalengine.Expr
+
+	// cols are the output columns, as in engine.CorrelatedSubquery.
+	// columns are the expressions using the subquery, and exprs are their translations
+	cols    []int
+	columns []*sqlparser.AliasedExpr
+	exprs   []evalengine.Expr
+}
+
+// newCorrelatedSubquery builds a new correlatedSubquery.
+func newCorrelatedSubquery(
+	ctx *plancontext.PlanningContext,
+	outer, inner logicalPlan,
+	extracted *sqlparser.ExtractedSubquery,
+	vars map[string]int,
+	predicate sqlparser.Expr,
+) (*correlatedSubquery, error) {
+	cs := &correlatedSubquery{
+		outer:     outer,
+		inner:     inner,
+		extracted: extracted,
+		vars:      vars,
+	}
+	if predicate != nil {
+		var err error
+		cs.predicate, err = evalengine.Translate(replaceExtractedSubqueries(predicate), cs.outerLookup(ctx))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+func (cs *correlatedSubquery) outerLookup(ctx *plancontext.PlanningContext) *simpleConverterLookup {
+	return &simpleConverterLookup{
+		canPushProjection: true,
+		ctx:               ctx,
+		plan:              cs.outer,
+	}
+}
+
+// usesSubquery returns true if the expression uses the result of the subquery
+func (cs *correlatedSubquery) usesSubquery(expr sqlparser.Expr) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if node == sqlparser.SQLNode(cs.extracted) {
+			found = true
+		}
+		return !found, nil
+	}, expr)
+	return found
+}
+
+// Primitive implements the logicalPlan interface
+func (cs *correlatedSubquery) Primitive() engine.Primitive {
+	exprNames := make([]string, 0, len(cs.columns))
+	for _, col := range cs.columns {
+		exprNames = append(exprNames, col.ColumnName())
+	}
+	return &engine.CorrelatedSubquery{
+		Opcode:         engine.PulloutOpcode(cs.extracted.OpCode),
+		SubqueryResult: cs.extracted.GetArgName(),
+		HasValues:      cs.extracted.GetHasValuesArg(),
+		Outer:          cs.outer.Primitive(),
+		Subquery:       cs.inner.Primitive(),
+		Vars:           cs.vars,
+		Predicate:      cs.predicate,
+		Cols:           cs.cols,
+		Exprs:          cs.exprs,
+		ExprNames:      exprNames,
+	}
+}
+
+// WireupGen4 implements the logicalPlan interface
+func (cs *correlatedSubquery) WireupGen4(ctx *plancontext.PlanningContext) error {
+	if err := cs.outer.WireupGen4(ctx); err != nil {
+		return err
+	}
+	return cs.inner.WireupGen4(ctx)
+}
+
+// Rewrite implements the logicalPlan interface
+func (cs *correlatedSubquery) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 2 {
+		return vterrors.VT13001("correlatedSubquery: wrong number of inputs")
+	}
+	cs.outer = inputs[0]
+	cs.inner = inputs[1]
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (cs *correlatedSubquery) ContainsTables() semantics.TableSet {
+	return cs.outer.ContainsTables().Merge(cs.inner.ContainsTables())
+}
+
+// Inputs implements the logicalPlan interface
+func (cs *correlatedSubquery) Inputs() []logicalPlan {
+	return []logicalPlan{cs.outer, cs.inner}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (cs *correlatedSubquery) OutputColumns() []sqlparser.SelectExpr {
+	outer := cs.outer.OutputColumns()
+	cols := make([]sqlparser.SelectExpr, 0, len(cs.cols))
+	next := 0
+	for _, col := range cs.cols {
+		if col < 0 {
+			cols = append(cols, outer[-col-1])
+			continue
+		}
+		cols = append(cols, cs.columns[next])
+		next++
+	}
+	return cols
+}
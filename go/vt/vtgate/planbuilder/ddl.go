@@ -23,11 +23,13 @@ type fkStrategy int
 const (
 	fkAllow fkStrategy = iota
 	fkDisallow
+	fkManaged
 )
 
 var fkStrategyMap = map[string]fkStrategy{
 	"allow":    fkAllow,
 	"disallow": fkDisallow,
+	"managed":  fkManaged,
 }
 
 type fkContraint struct {
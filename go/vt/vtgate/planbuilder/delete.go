@@ -21,6 +21,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
 // buildDeletePlan builds the instructions for a DELETE statement.
@@ -41,6 +42,9 @@ func buildDeletePlan(string) stmtPlanner {
 		if err != nil {
 			return nil, err
 		}
+		if err := checkV3ForeignKeys(vschema, dml.Table, func(vindexes.ChildFKInfo) bool { return true }); err != nil {
+			return nil, err
+		}
 		edel := &engine.Delete{DML: dml}
 		if dml.Opcode == engine.Unsharded {
 			return newPlanResult(edel, tables...), nil
@@ -67,7 +67,7 @@ func newFilter(ctx *plancontext.PlanningContext, plan logicalPlan, expr sqlparse
 		ctx:  ctx,
 		plan: plan,
 	}
-	predicate, err := evalengine.Translate(expr, scl)
+	predicate, err := evalengine.Translate(replaceExtractedSubqueries(expr), scl)
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +85,16 @@ func (l *filter) Primitive() engine.Primitive {
 	l.efilter.Input = l.input.Primitive()
 	return l.efilter
 }
+
+// replaceExtractedSubqueries replaces the subqueries that have been pulled out with the expressions
+// using their bind variables, so the predicate can be evaluated at the vtgate level
+func replaceExtractedSubqueries(expr sqlparser.Expr) sqlparser.Expr {
+	return sqlparser.CopyOnRewrite(expr, func(node, _ sqlparser.SQLNode) bool {
+		_, isExtracted := node.(*sqlparser.ExtractedSubquery)
+		return !isExtracted
+	}, func(cursor *sqlparser.CopyOnWriteCursor) {
+		if ext, ok := cursor.Node().(*sqlparser.ExtractedSubquery); ok {
+			cursor.Replace(ext.GetAlternative())
+		}
+	}, nil).(sqlparser.Expr)
+}
@@ -0,0 +1,418 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// isFkManaged tells if vtgate is asked to enforce the foreign keys MySQL cannot see.
+func isFkManaged(vschema plancontext.VSchema) bool {
+	return fkStrategyMap[vschema.ForeignKeyMode()] == fkManaged
+}
+
+// fkCascadePlan holds the foreign key actions of an UPDATE or DELETE,
+// until the DML on the parent table is planned.
+type fkCascadePlan struct {
+	fkc    *engine.FkCascade
+	tables []string
+}
+
+// wrap returns the plan of the parent DML, preceded by the foreign key actions.
+func (fp *fkCascadePlan) wrap(parent *planResult) *planResult {
+	if fp == nil {
+		return parent
+	}
+	fp.fkc.Parent = parent.primitive
+	return newPlanResult(fp.fkc, append(parent.tables, fp.tables...)...)
+}
+
+// fkChildStmt returns the statement run on a child table for the given foreign key, and
+// whether it is a restrict check. bvName is the list argument holding the parent values.
+type fkChildStmt func(fk vindexes.ChildFKInfo, bvName string) (sqlparser.Statement, bool, error)
+
+// planDeleteForeignKeys plans the foreign key actions of a DELETE on a table that is
+// referenced from other keyspaces. It returns nil when MySQL can take care of them.
+func planDeleteForeignKeys(
+	version querypb.ExecuteOptions_PlannerVersion,
+	del *sqlparser.Delete,
+	semTable *semantics.SemTable,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*fkCascadePlan, error) {
+	if !isFkManaged(vschema) {
+		return nil, nil
+	}
+	vTbl, err := fkTargetTable(semTable)
+	if vTbl == nil || err != nil {
+		return nil, err
+	}
+	fks, err := crossKeyspaceChildren(vTbl, func(vindexes.ChildFKInfo) bool { return true }, func(fk vindexes.ChildFKInfo) sqlparser.ReferenceAction { return fk.OnDelete })
+	if len(fks) == 0 || err != nil {
+		return nil, err
+	}
+	if del.Limit != nil {
+		return nil, vterrors.VT12001("DELETE with LIMIT on a table with foreign keys across keyspaces")
+	}
+
+	childStmt := func(fk vindexes.ChildFKInfo, bvName string) (sqlparser.Statement, bool, error) {
+		childTbl, where := fkChildTableAndWhere(fk, bvName)
+		switch fk.OnDelete {
+		case sqlparser.Cascade:
+			return &sqlparser.Delete{TableExprs: childTbl, Where: where}, false, nil
+		case sqlparser.SetNull:
+			return &sqlparser.Update{TableExprs: childTbl, Exprs: fkSetNull(fk.ChildColumns), Where: where}, false, nil
+		default:
+			return fkRestrictSelect(childTbl, where), true, nil
+		}
+	}
+	return planFkCascade(version, del.TableExprs, del.Where, fks, childStmt, reservedVars, vschema)
+}
+
+// planUpdateForeignKeys plans the foreign key actions of an UPDATE that changes columns
+// referenced from other keyspaces. It returns nil when MySQL can take care of them.
+func planUpdateForeignKeys(
+	version querypb.ExecuteOptions_PlannerVersion,
+	upd *sqlparser.Update,
+	semTable *semantics.SemTable,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*fkCascadePlan, error) {
+	if !isFkManaged(vschema) {
+		return nil, nil
+	}
+	vTbl, err := fkTargetTable(semTable)
+	if vTbl == nil || err != nil {
+		return nil, err
+	}
+	if err := checkUpdatedParents(vTbl, upd.Exprs); err != nil {
+		return nil, err
+	}
+	isUpdated := func(fk vindexes.ChildFKInfo) bool {
+		return updatesAnyColumn(upd.Exprs, fk.ParentColumns)
+	}
+	fks, err := crossKeyspaceChildren(vTbl, isUpdated, func(fk vindexes.ChildFKInfo) sqlparser.ReferenceAction { return fk.OnUpdate })
+	if len(fks) == 0 || err != nil {
+		return nil, err
+	}
+	if upd.Limit != nil {
+		return nil, vterrors.VT12001("UPDATE with LIMIT on a table with foreign keys across keyspaces")
+	}
+
+	childStmt := func(fk vindexes.ChildFKInfo, bvName string) (sqlparser.Statement, bool, error) {
+		childTbl, where := fkChildTableAndWhere(fk, bvName)
+		switch fk.OnUpdate {
+		case sqlparser.Cascade:
+			exprs, err := fkCascadeUpdateExprs(upd.Exprs, fk)
+			if err != nil {
+				return nil, false, err
+			}
+			return &sqlparser.Update{TableExprs: childTbl, Exprs: exprs, Where: where}, false, nil
+		case sqlparser.SetNull:
+			return &sqlparser.Update{TableExprs: childTbl, Exprs: fkSetNull(fk.ChildColumns), Where: where}, false, nil
+		default:
+			return fkRestrictSelect(childTbl, where), true, nil
+		}
+	}
+	return planFkCascade(version, upd.TableExprs, upd.Where, fks, childStmt, reservedVars, vschema)
+}
+
+// checkInsertForeignKeys fails an INSERT that MySQL cannot check, because the parent
+// rows can live on another shard, or that silently changes rows referenced from another keyspace.
+func checkInsertForeignKeys(ins *sqlparser.Insert, vTbl *vindexes.Table, vschema plancontext.VSchema) error {
+	if !isFkManaged(vschema) || vTbl == nil {
+		return nil
+	}
+	for _, fk := range vTbl.ParentForeignKeys {
+		if fk.CrossShard(vTbl) {
+			return vterrors.VT12002()
+		}
+	}
+	for _, fk := range vTbl.ChildForeignKeys {
+		if !fk.CrossKeyspace(vTbl) {
+			continue
+		}
+		if ins.Action == sqlparser.ReplaceAct {
+			return vterrors.VT12001("REPLACE INTO a table with foreign keys across keyspaces")
+		}
+		if updatesAnyColumn(sqlparser.UpdateExprs(ins.OnDup), fk.ParentColumns) {
+			return vterrors.VT12001("ON DUPLICATE KEY UPDATE of a column with foreign keys across keyspaces")
+		}
+	}
+	return nil
+}
+
+// checkV3ForeignKeys fails the DML that would need vtgate to enforce foreign keys,
+// which only the Gen4 planner does.
+func checkV3ForeignKeys(vschema plancontext.VSchema, tables []*vindexes.Table, affected func(vindexes.ChildFKInfo) bool) error {
+	if !isFkManaged(vschema) {
+		return nil
+	}
+	for _, vTbl := range tables {
+		if vTbl == nil {
+			continue
+		}
+		for _, fk := range vTbl.ChildForeignKeys {
+			if !affected(fk) {
+				continue
+			}
+			hidden := (isCascading(fk.OnDelete) || isCascading(fk.OnUpdate)) && reachesCrossKeyspaceChildren(fk.Table)
+			if fk.CrossKeyspace(vTbl) || fk.CrossShard(vTbl) || hidden {
+				return vterrors.VT12001("foreign keys across keyspaces or shards with the V3 planner")
+			}
+		}
+	}
+	return nil
+}
+
+// checkUpdatedParents fails an UPDATE of the columns of a foreign key whose parent rows can live on another shard.
+func checkUpdatedParents(vTbl *vindexes.Table, exprs sqlparser.UpdateExprs) error {
+	for _, fk := range vTbl.ParentForeignKeys {
+		if fk.CrossShard(vTbl) && updatesAnyColumn(exprs, fk.ChildColumns) {
+			return vterrors.VT12002()
+		}
+	}
+	return nil
+}
+
+// fkTargetTable returns the table changed by a single table DML.
+// A DML joining several tables is only planned if MySQL can take care of their foreign keys.
+func fkTargetTable(semTable *semantics.SemTable) (*vindexes.Table, error) {
+	if len(semTable.Tables) == 1 {
+		return semTable.Tables[0].GetVindexTable(), nil
+	}
+	for _, ti := range semTable.Tables {
+		if vTbl := ti.GetVindexTable(); vTbl != nil && hasUnmanagedChildren(vTbl) {
+			return nil, vterrors.VT12001("multi-table DML on a table with foreign keys across keyspaces or shards")
+		}
+	}
+	return nil, nil
+}
+
+// hasUnmanagedChildren tells if some foreign keys referencing the table are out of reach of MySQL.
+func hasUnmanagedChildren(vTbl *vindexes.Table) bool {
+	for _, fk := range vTbl.ChildForeignKeys {
+		if fk.CrossKeyspace(vTbl) || fk.CrossShard(vTbl) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossKeyspaceChildren returns the foreign keys referencing the parent table from another keyspace,
+// amongst the ones affected by the DML. It fails if one of them cannot be enforced.
+func crossKeyspaceChildren(
+	parent *vindexes.Table,
+	affected func(vindexes.ChildFKInfo) bool,
+	action func(vindexes.ChildFKInfo) sqlparser.ReferenceAction,
+) ([]vindexes.ChildFKInfo, error) {
+	var fks []vindexes.ChildFKInfo
+	for _, fk := range parent.ChildForeignKeys {
+		if !affected(fk) {
+			continue
+		}
+		if fk.CrossShard(parent) {
+			return nil, vterrors.VT12002()
+		}
+		if !fk.CrossKeyspace(parent) {
+			// MySQL takes care of it, but vtgate does not see the child rows it changes.
+			if isCascading(action(fk)) && reachesCrossKeyspaceChildren(fk.Table) {
+				return nil, vterrors.VT12001(fmt.Sprintf("foreign key cascade from %s into %s, which has foreign keys across keyspaces", parent.Name.String(), fk.Table.Name.String()))
+			}
+			continue
+		}
+		if len(fk.ChildColumns) != 1 {
+			return nil, vterrors.VT12001("multi-column foreign keys across keyspaces")
+		}
+		if action(fk) == sqlparser.SetDefault {
+			return nil, vterrors.VT12001("SET DEFAULT foreign keys across keyspaces")
+		}
+		if isCascading(action(fk)) && cascadesInto(fk.Table, parent, map[*vindexes.Table]any{}) {
+			return nil, vterrors.VT12001(fmt.Sprintf("cyclic foreign keys between %s and %s", parent.Name.String(), fk.Table.Name.String()))
+		}
+		fks = append(fks, fk)
+	}
+	return fks, nil
+}
+
+// reachesCrossKeyspaceChildren tells if a change that MySQL cascades into the table
+// can reach a table with foreign keys across keyspaces.
+func reachesCrossKeyspaceChildren(tbl *vindexes.Table) bool {
+	seen := map[*vindexes.Table]any{}
+	var visit func(tbl *vindexes.Table) bool
+	visit = func(tbl *vindexes.Table) bool {
+		if _, found := seen[tbl]; found {
+			return false
+		}
+		seen[tbl] = nil
+		for _, fk := range tbl.ChildForeignKeys {
+			if fk.CrossKeyspace(tbl) {
+				return true
+			}
+			if (isCascading(fk.OnDelete) || isCascading(fk.OnUpdate)) && visit(fk.Table) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(tbl)
+}
+
+// cascadesInto tells if a change cascading into the table from can cascade back into the table to.
+func cascadesInto(from, to *vindexes.Table, seen map[*vindexes.Table]any) bool {
+	if from == to {
+		return true
+	}
+	if _, found := seen[from]; found {
+		return false
+	}
+	seen[from] = nil
+	for _, fk := range from.ChildForeignKeys {
+		if (isCascading(fk.OnDelete) || isCascading(fk.OnUpdate)) && cascadesInto(fk.Table, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCascading(action sqlparser.ReferenceAction) bool {
+	return action == sqlparser.Cascade || action == sqlparser.SetNull
+}
+
+// planFkCascade plans the selection of the parent values and the statements on the child tables.
+func planFkCascade(
+	version querypb.ExecuteOptions_PlannerVersion,
+	tableExprs sqlparser.TableExprs,
+	where *sqlparser.Where,
+	fks []vindexes.ChildFKInfo,
+	childStmt fkChildStmt,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*fkCascadePlan, error) {
+	sel := &sqlparser.Select{
+		From:  sqlparser.CloneTableExprs(tableExprs),
+		Where: sqlparser.CloneRefOfWhere(where),
+		Lock:  sqlparser.ForUpdateLock,
+	}
+	fp := &fkCascadePlan{fkc: &engine.FkCascade{}}
+	var cols sqlparser.Columns
+	for _, fk := range fks {
+		col := fk.ParentColumns[0]
+		offset := cols.FindColumn(col)
+		if offset == -1 {
+			offset = len(cols)
+			cols = append(cols, col)
+			sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(col.String())})
+		}
+
+		bvName := reservedVars.ReserveColName(sqlparser.NewColName("fkc_vals"))
+		stmt, restrict, err := childStmt(fk, bvName)
+		if err != nil {
+			return nil, err
+		}
+		child, err := gen4Planner("", version)(stmt, reservedVars, vschema)
+		if err != nil {
+			return nil, err
+		}
+		fp.fkc.Children = append(fp.fkc.Children, &engine.FkChild{
+			BVName:   bvName,
+			Col:      offset,
+			Restrict: restrict,
+			Exec:     child.primitive,
+		})
+		fp.tables = append(fp.tables, child.tables...)
+	}
+
+	selection, err := gen4SelectStmtPlanner("", version, sel, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+	fp.fkc.Selection = selection.primitive
+	return fp, nil
+}
+
+// fkChildTableAndWhere returns the child table of the foreign key and the predicate
+// selecting the child rows that reference the values in the list argument.
+func fkChildTableAndWhere(fk vindexes.ChildFKInfo, bvName string) (sqlparser.TableExprs, *sqlparser.Where) {
+	tbl := sqlparser.TableName{
+		Name:      fk.Table.Name,
+		Qualifier: sqlparser.NewIdentifierCS(fk.Table.Keyspace.Name),
+	}
+	cond := &sqlparser.ComparisonExpr{
+		Operator: sqlparser.InOp,
+		Left:     sqlparser.NewColName(fk.ChildColumns[0].String()),
+		Right:    sqlparser.NewListArg(bvName),
+	}
+	return sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: tbl}}, sqlparser.NewWhere(sqlparser.WhereClause, cond)
+}
+
+// fkRestrictSelect looks for a child row that prevents the change of the parent rows.
+func fkRestrictSelect(childTbl sqlparser.TableExprs, where *sqlparser.Where) *sqlparser.Select {
+	return &sqlparser.Select{
+		SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: sqlparser.NewIntLiteral("1")}},
+		From:        childTbl,
+		Where:       where,
+		Limit:       &sqlparser.Limit{Rowcount: sqlparser.NewIntLiteral("1")},
+		Lock:        sqlparser.ShareModeLock,
+	}
+}
+
+func fkSetNull(cols sqlparser.Columns) sqlparser.UpdateExprs {
+	var exprs sqlparser.UpdateExprs
+	for _, col := range cols {
+		exprs = append(exprs, &sqlparser.UpdateExpr{Name: sqlparser.NewColName(col.String()), Expr: &sqlparser.NullVal{}})
+	}
+	return exprs
+}
+
+// fkCascadeUpdateExprs gives the child columns the values the parent columns are updated to.
+// These values must not depend on the parent row, since the child rows of all the
+// changed parent rows are updated at once.
+func fkCascadeUpdateExprs(parentExprs sqlparser.UpdateExprs, fk vindexes.ChildFKInfo) (sqlparser.UpdateExprs, error) {
+	var exprs sqlparser.UpdateExprs
+	for _, ue := range parentExprs {
+		idx := fk.ParentColumns.FindColumn(ue.Name.Name)
+		if idx == -1 {
+			continue
+		}
+		if !sqlparser.IsValue(ue.Expr) && !sqlparser.IsNull(ue.Expr) {
+			return nil, vterrors.VT12001(fmt.Sprintf("foreign key cascade of a non-literal update of %s", sqlparser.String(ue.Name)))
+		}
+		exprs = append(exprs, &sqlparser.UpdateExpr{
+			Name: sqlparser.NewColName(fk.ChildColumns[idx].String()),
+			Expr: sqlparser.CloneExpr(ue.Expr),
+		})
+	}
+	return exprs, nil
+}
+
+func updatesAnyColumn(exprs sqlparser.UpdateExprs, cols sqlparser.Columns) bool {
+	for _, ue := range exprs {
+		if cols.FindColumn(ue.Name.Name) != -1 {
+			return true
+		}
+	}
+	return false
+}
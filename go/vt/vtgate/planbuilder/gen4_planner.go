@@ -19,6 +19,7 @@ package planbuilder
 import (
 	"fmt"
 
+	"vitess.io/vitess/go/mysql/collations"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -51,15 +52,10 @@ func gen4SelectStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
-	switch node := stmt.(type) {
-	case *sqlparser.Select:
-		if node.With != nil {
-			return nil, vterrors.VT12001("WITH expression in SELECT statement")
-		}
-	case *sqlparser.Union:
-		if node.With != nil {
-			return nil, vterrors.VT12001("WITH expression in UNION statement")
-		}
+	// non-recursive CTEs are turned into derived tables by the semantic analysis,
+	// recursive ones need to be evaluated at the vtgate level
+	if idx := findRecursiveCTE(stmt); idx >= 0 {
+		return gen4RecursiveCTEPlanner(plannerVersion, stmt, idx, reservedVars, vschema)
 	}
 
 	sel, isSel := stmt.(*sqlparser.Select)
@@ -86,6 +82,15 @@ func gen4SelectStmtPlanner(
 		// if there was no limit, we can safely ignore the SQLCalcFoundRows directive
 		sel.SQLCalcFoundRows = false
 	}
+	if union, isUnion := stmt.(*sqlparser.Union); isUnion {
+		// SQL_CALC_FOUND_ROWS can only be used in the first SELECT of a UNION, and counts the rows of the whole UNION
+		if first := sqlparser.GetFirstSelect(union); first.SQLCalcFoundRows {
+			first.SQLCalcFoundRows = false
+			if union.Limit != nil {
+				return gen4planSQLCalcFoundRowsOnUnion(plannerVersion, union, query, reservedVars, vschema)
+			}
+		}
+	}
 
 	getPlan := func(selStatement sqlparser.SelectStatement) (logicalPlan, *semantics.SemTable, []string, error) {
 		return newBuildSelectPlan(selStatement, reservedVars, vschema, plannerVersion)
@@ -143,6 +148,50 @@ func gen4planSQLCalcFoundRows(vschema plancontext.VSchema, sel *sqlparser.Select
 	return newPlanResult(plan.Primitive(), tablesUsed...), nil
 }
 
+func gen4planSQLCalcFoundRowsOnUnion(
+	plannerVersion querypb.ExecuteOptions_PlannerVersion,
+	union *sqlparser.Union,
+	query string,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*planResult, error) {
+	limitPlan, _, _, err := newBuildSelectPlan(union, reservedVars, vschema, plannerVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	statement2, reserved2, err := sqlparser.Parse2(query)
+	if err != nil {
+		return nil, err
+	}
+	union2, ok := statement2.(*sqlparser.Union)
+	if !ok {
+		return nil, vterrors.VT13001(fmt.Sprintf("expected a UNION, got: %T", statement2))
+	}
+	sqlparser.GetFirstSelect(union2).SQLCalcFoundRows = false
+	union2.OrderBy = nil
+	union2.Limit = nil
+
+	// the rows of the UNION are counted by moving it into a derived table
+	//     select id from user union select id from music limit 10 =>
+	//     select count(*) from (select id from user union select id from music) t
+	countSel := &sqlparser.Select{
+		SelectExprs: []sqlparser.SelectExpr{&sqlparser.AliasedExpr{Expr: &sqlparser.CountStar{}}},
+		From: []sqlparser.TableExpr{
+			&sqlparser.AliasedTableExpr{
+				Expr: &sqlparser.DerivedTable{Select: union2},
+				As:   sqlparser.NewIdentifierCS("t"),
+			},
+		},
+	}
+	countPlan, _, tablesUsed, err := newBuildSelectPlan(countSel, sqlparser.NewReservedVars("vtg", reserved2), vschema, plannerVersion)
+	if err != nil {
+		return nil, err
+	}
+	plan := &sqlCalcFoundRows{LimitQuery: limitPlan, CountQuery: countPlan}
+	return newPlanResult(plan.Primitive(), tablesUsed...), nil
+}
+
 func planSelectGen4(reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, sel *sqlparser.Select) (*jointab, logicalPlan, []string, error) {
 	plan, _, tablesUsed, err := newBuildSelectPlan(sel, reservedVars, vschema, 0)
 	if err != nil {
@@ -183,6 +232,7 @@ func newBuildSelectPlan(
 	vschema.PlannerWarning(semTable.Warning)
 
 	ctx := plancontext.NewPlanningContext(reservedVars, semTable, vschema, version)
+	ctx.AllowHashJoins = selStmt.GetParsedComments().Directives().IsSet(sqlparser.DirectiveAllowHashJoin)
 
 	if ks, _ := semTable.SingleUnshardedKeyspace(); ks != nil {
 		plan, tablesUsed, err = unshardedShortcut(ctx, selStmt, ks)
@@ -265,8 +315,8 @@ func gen4UpdateStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
-	if updStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in UPDATE statement")
+	if updStmt.With != nil && updStmt.With.Recursive {
+		return nil, vterrors.VT12001("WITH RECURSIVE in UPDATE statement")
 	}
 
 	ksName := ""
@@ -285,6 +335,11 @@ func gen4UpdateStmtPlanner(
 		return nil, err
 	}
 
+	fkPlan, err := planUpdateForeignKeys(version, updStmt, semTable, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+
 	if ks, tables := semTable.SingleUnshardedKeyspace(); ks != nil {
 		edml := engine.NewDML()
 		edml.Keyspace = ks
@@ -292,7 +347,7 @@ func gen4UpdateStmtPlanner(
 		edml.Opcode = engine.Unsharded
 		edml.Query = generateQuery(updStmt)
 		upd := &engine.Update{DML: edml}
-		return newPlanResult(upd, operators.QualifiedTables(ks, tables)...), nil
+		return fkPlan.wrap(newPlanResult(upd, operators.QualifiedTables(ks, tables)...)), nil
 	}
 
 	if semTable.NotUnshardedErr != nil {
@@ -327,7 +382,7 @@ func gen4UpdateStmtPlanner(
 		return nil, err
 	}
 
-	return newPlanResult(plan.Primitive(), operators.TablesUsed(op)...), nil
+	return fkPlan.wrap(newPlanResult(plan.Primitive(), operators.TablesUsed(op)...)), nil
 }
 
 func gen4DeleteStmtPlanner(
@@ -336,8 +391,8 @@ func gen4DeleteStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
-	if deleteStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in DELETE statement")
+	if deleteStmt.With != nil && deleteStmt.With.Recursive {
+		return nil, vterrors.VT12001("WITH RECURSIVE in DELETE statement")
 	}
 
 	var err error
@@ -364,6 +419,11 @@ func gen4DeleteStmtPlanner(
 		return nil, err
 	}
 
+	fkPlan, err := planDeleteForeignKeys(version, deleteStmt, semTable, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+
 	if ks, tables := semTable.SingleUnshardedKeyspace(); ks != nil {
 		edml := engine.NewDML()
 		edml.Keyspace = ks
@@ -371,7 +431,7 @@ func gen4DeleteStmtPlanner(
 		edml.Opcode = engine.Unsharded
 		edml.Query = generateQuery(deleteStmt)
 		del := &engine.Delete{DML: edml}
-		return newPlanResult(del, operators.QualifiedTables(ks, tables)...), nil
+		return fkPlan.wrap(newPlanResult(del, operators.QualifiedTables(ks, tables)...)), nil
 	}
 
 	if err := checkIfDeleteSupported(deleteStmt, semTable); err != nil {
@@ -405,7 +465,7 @@ func gen4DeleteStmtPlanner(
 		return nil, err
 	}
 
-	return newPlanResult(plan.Primitive(), operators.TablesUsed(op)...), nil
+	return fkPlan.wrap(newPlanResult(plan.Primitive(), operators.TablesUsed(op)...)), nil
 }
 
 func rewriteRoutedTables(stmt sqlparser.Statement, vschema plancontext.VSchema) error {
@@ -519,16 +579,151 @@ func planOrderByOnUnion(ctx *plancontext.PlanningContext, plan logicalPlan, unio
 	if err != nil {
 		return nil, err
 	}
+	if len(qp.OrderExprs) == 0 {
+		return plan, nil
+	}
+
+	// the ORDER BY of a UNION can only reference the columns produced by the UNION,
+	// so we resolve every ordering expression to a column offset of the first SELECT
+	sel := sqlparser.GetFirstSelect(union)
+	colls := getCollationsForSelect(ctx, sel)
+	orderBy := make([]engine.OrderByParams, 0, len(qp.OrderExprs))
+	for _, order := range qp.OrderExprs {
+		offset, err := findUnionColumnOffset(ctx, sel, order)
+		if err != nil {
+			return nil, err
+		}
+		coll := collations.Unknown
+		if offset < len(colls) {
+			coll = colls[offset]
+		}
+		orderBy = append(orderBy, engine.OrderByParams{
+			Col:               offset,
+			WeightStringCol:   -1,
+			Desc:              order.Inner.Direction == sqlparser.DescOrder,
+			StarColFixedIndex: offset,
+			CollationID:       coll,
+		})
+	}
+	columns := len(sel.SelectExprs)
+
+	switch node := plan.(type) {
+	case *routeGen4:
+		// a scatter route merge-sorts the ordered results coming from the shards
+		needsTruncation, err := addWeightStringsForUnionOrder(ctx, node, orderBy, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range union.OrderBy {
+			node.Select.AddOrder(order)
+		}
+		node.eroute.OrderBy = append(node.eroute.OrderBy, orderBy...)
+		if needsTruncation {
+			node.eroute.TruncateColumnCount = columns
+		}
+		return node, nil
+	case *distinct:
+		if _, err := addWeightStringsForUnionOrder(ctx, node.input, orderBy, node.checkCols); err != nil {
+			return nil, err
+		}
+		if rb, isRoute := node.input.(*routeGen4); isRoute {
+			// distinct keeps the order of its input, so the route can do the sorting
+			for _, order := range union.OrderBy {
+				rb.Select.AddOrder(order)
+			}
+			rb.eroute.OrderBy = append(rb.eroute.OrderBy, orderBy...)
+			return node, nil
+		}
+		// the weight_string columns are needed by the sort, so we let the sort truncate them
+		needsTruncation := node.needToTruncate && hasWeightStringCheckCol(node.checkCols)
+		node.needToTruncate = false
+		return createMemorySortOnUnion(node, orderBy, needsTruncation, columns), nil
+	case *concatenateGen4:
+		needsTruncation, err := addWeightStringsForUnionOrder(ctx, node, orderBy, nil)
+		if err != nil {
+			return nil, err
+		}
+		return createMemorySortOnUnion(node, orderBy, needsTruncation, columns), nil
+	}
+
 	hp := horizonPlanning{
 		qp: qp,
 	}
-	if len(qp.OrderExprs) > 0 {
-		plan, err = hp.planOrderBy(ctx, qp.OrderExprs, plan)
+	return hp.planOrderBy(ctx, qp.OrderExprs, plan)
+}
+
+// findUnionColumnOffset returns the offset of the UNION column that the ORDER BY expression is referring to
+func findUnionColumnOffset(ctx *plancontext.PlanningContext, sel *sqlparser.Select, order operators.OrderBy) (int, error) {
+	col, isCol := order.WeightStrExpr.(*sqlparser.ColName)
+	for i, selectExpr := range sel.SelectExprs {
+		ae, ok := selectExpr.(*sqlparser.AliasedExpr)
+		if !ok {
+			break
+		}
+		if ctx.SemTable.EqualsExpr(ae.Expr, order.WeightStrExpr) {
+			return i, nil
+		}
+		if !isCol || !col.Qualifier.IsEmpty() {
+			continue
+		}
+		if ae.As.Equal(col.Name) {
+			return i, nil
+		}
+		if selCol, ok := ae.Expr.(*sqlparser.ColName); ok && ae.As.IsEmpty() && selCol.Name.Equal(col.Name) {
+			return i, nil
+		}
+	}
+	return 0, vterrors.VT12001(fmt.Sprintf("ORDER BY on top of UNION using an expression that is not a column of the UNION: %s", sqlparser.String(order.Inner)))
+}
+
+// addWeightStringsForUnionOrder makes sure that ordering on columns with unknown collations
+// can be done using weight_string values. Weight strings already fetched for DISTINCT are reused.
+// It returns true if new columns had to be added to the output of the plan.
+func addWeightStringsForUnionOrder(ctx *plancontext.PlanningContext, plan logicalPlan, orderBy []engine.OrderByParams, checkCols []engine.CheckCol) (bool, error) {
+	added := false
+outer:
+	for i, order := range orderBy {
+		if order.CollationID != collations.Unknown {
+			continue
+		}
+		for _, checkCol := range checkCols {
+			if checkCol.Col == order.Col && checkCol.WsCol != nil {
+				orderBy[i].WeightStringCol = *checkCol.WsCol
+				continue outer
+			}
+		}
+		offset, err := pushWeightStringForDistinct(ctx, plan, order.Col)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+		orderBy[i].WeightStringCol = offset
+		added = true
+	}
+	return added, nil
+}
+
+func hasWeightStringCheckCol(checkCols []engine.CheckCol) bool {
+	for _, col := range checkCols {
+		if col.WsCol != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func createMemorySortOnUnion(plan logicalPlan, orderBy []engine.OrderByParams, needsTruncation bool, columns int) logicalPlan {
+	primitive := &engine.MemorySort{OrderBy: orderBy}
+	if needsTruncation {
+		primitive.TruncateColumnCount = columns
+	}
+	return &memorySort{
+		resultsBuilder: resultsBuilder{
+			logicalPlanCommon: newBuilderCommon(plan),
+			weightStrings:     make(map[*resultColumn]int),
+			truncater:         primitive,
+		},
+		eMemorySort: primitive,
 	}
-	return plan, nil
 }
 
 func pushCommentDirectivesOnPlan(plan logicalPlan, stmt sqlparser.Statement) (logicalPlan, error) {
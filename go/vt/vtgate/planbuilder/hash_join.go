@@ -20,10 +20,14 @@ import (
 	"fmt"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 )
@@ -39,8 +43,15 @@ type hashJoin struct {
 
 	Opcode engine.JoinOpcode
 
+	// Cols works like the Cols of the HashJoin primitive, a zero stands
+	// for the next expression of Exprs.
 	Cols []int
 
+	// Exprs are the projections that need columns from both sides of the join,
+	// and Columns are the output columns they were planned for.
+	Exprs   []evalengine.Expr
+	Columns []*sqlparser.AliasedExpr
+
 	// The keys correspond to the column offset in the inputs where
 	// the join columns can be found
 	LHSKey, RHSKey int
@@ -48,6 +59,9 @@ type hashJoin struct {
 	ComparisonType querypb.Type
 
 	Collation collations.ID
+
+	// Predicate is the join condition.
+	Predicate sqlparser.Expr
 }
 
 // WireupGen4 implements the logicalPlan interface
@@ -65,14 +79,28 @@ func (hj *hashJoin) Primitive() engine.Primitive {
 		Left:           hj.Left.Primitive(),
 		Right:          hj.Right.Primitive(),
 		Cols:           hj.Cols,
+		Exprs:          hj.Exprs,
+		ExprNames:      exprNames(hj.Columns),
 		Opcode:         hj.Opcode,
 		LHSKey:         hj.LHSKey,
 		RHSKey:         hj.RHSKey,
+		ASTPred:        hj.Predicate,
 		ComparisonType: hj.ComparisonType,
 		Collation:      hj.Collation,
 	}
 }
 
+func exprNames(columns []*sqlparser.AliasedExpr) []string {
+	if len(columns) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.ColumnName())
+	}
+	return names
+}
+
 // Inputs implements the logicalPlan interface
 func (hj *hashJoin) Inputs() []logicalPlan {
 	return []logicalPlan{hj.Left, hj.Right}
@@ -95,7 +123,22 @@ func (hj *hashJoin) ContainsTables() semantics.TableSet {
 
 // OutputColumns implements the logicalPlan interface
 func (hj *hashJoin) OutputColumns() []sqlparser.SelectExpr {
-	return getOutputColumnsFromJoin(hj.Cols, hj.Left.OutputColumns(), hj.Right.OutputColumns())
+	lhs := hj.Left.OutputColumns()
+	rhs := hj.Right.OutputColumns()
+	cols := make([]sqlparser.SelectExpr, 0, len(hj.Cols))
+	next := 0
+	for _, col := range hj.Cols {
+		switch {
+		case col < 0:
+			cols = append(cols, lhs[-col-1])
+		case col > 0:
+			cols = append(cols, rhs[col-1])
+		default:
+			cols = append(cols, hj.Columns[next])
+			next++
+		}
+	}
+	return cols
 }
 
 func getOutputColumnsFromJoin(ints []int, lhs []sqlparser.SelectExpr, rhs []sqlparser.SelectExpr) (cols []sqlparser.SelectExpr) {
@@ -109,3 +152,208 @@ func getOutputColumnsFromJoin(ints []int, lhs []sqlparser.SelectExpr, rhs []sqlp
 	}
 	return
 }
+
+// hashJoinRowsPerCost is the number of rows that a hash join can fetch and hash
+// for the cost of sending a query to one shard.
+const hashJoinRowsPerCost = 5
+
+// planHashJoin returns a hash join plan for the join, or nil if it cannot or should not be a hash join.
+//
+// A join can be a hash join when it is an equality between a column of the LHS and an expression of
+// the RHS, and the RHS is a route whose routing does not depend on the value of the LHS. The RHS is
+// then sent once without the join predicate, instead of once per row of the LHS. Such a join is
+// planned as a hash join when it is estimated to be cheaper than a nested loop join, or when the
+// ALLOW_HASH_JOIN directive is set.
+func planHashJoin(ctx *plancontext.PlanningContext, n *operators.ApplyJoin) (logicalPlan, error) {
+	if ctx.NoHashJoins || n.Predicate == nil {
+		return nil, nil
+	}
+	rhsRoute, ok := n.RHS.(*operators.Route)
+	if !ok {
+		return nil, nil
+	}
+	cmp, ok := n.Predicate.(*sqlparser.ComparisonExpr)
+	if !ok || cmp.Operator != sqlparser.EqualOp {
+		return nil, nil
+	}
+	lhsTables := operators.TableID(n.LHS)
+	rhsTables := operators.TableID(n.RHS)
+	lhsExpr, rhsExpr := cmp.Left, cmp.Right
+	if !ctx.SemTable.RecursiveDeps(lhsExpr).IsSolvedBy(lhsTables) {
+		lhsExpr, rhsExpr = rhsExpr, lhsExpr
+	}
+	lhsCol, ok := lhsExpr.(*sqlparser.ColName)
+	if !ok || !ctx.SemTable.RecursiveDeps(lhsExpr).IsSolvedBy(lhsTables) || !ctx.SemTable.RecursiveDeps(rhsExpr).IsSolvedBy(rhsTables) {
+		return nil, nil
+	}
+	// the join column must be the only value that the RHS needs from the LHS
+	lhsKey, ok := n.Vars[lhsCol.CompliantName()]
+	if !ok || len(n.Vars) != 1 {
+		return nil, nil
+	}
+
+	comparisonType, collation, ok := hashJoinComparison(ctx, lhsExpr, rhsExpr)
+	if !ok {
+		return nil, nil
+	}
+
+	predicates := joinPredicatesFor(ctx, n.Predicate)
+	if routing, ok := rhsRoute.Routing.(*operators.ShardedRouting); ok && routing.Selected != nil {
+		for _, pred := range routing.Selected.Predicates {
+			for _, joinPred := range predicates {
+				if ctx.SemTable.EqualsExpr(pred, joinPred) {
+					// the RHS is routed using the value of the LHS, so it has to be a nested loop join
+					return nil, nil
+				}
+			}
+		}
+	}
+
+	if !ctx.AllowHashJoins && !hashJoinIsCheaper(n) {
+		return nil, nil
+	}
+
+	var rhsOp ops.Operator
+	for _, pred := range predicates {
+		op, err := operators.RemovePredicate(ctx, pred, rhsRoute)
+		if err == nil {
+			rhsOp = op
+			break
+		}
+	}
+	if rhsOp == nil {
+		return nil, nil
+	}
+
+	lhs, err := transformToLogicalPlan(ctx, n.LHS, false)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := transformToLogicalPlan(ctx, rhsOp, false)
+	if err != nil {
+		return nil, err
+	}
+	rhsKey, _, err := pushProjection(ctx, &sqlparser.AliasedExpr{Expr: rhsExpr}, rhs, true, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	opCode := engine.InnerJoin
+	if n.LeftJoin {
+		opCode = engine.LeftJoin
+	}
+	return &hashJoin{
+		Left:           lhs,
+		Right:          rhs,
+		Opcode:         opCode,
+		Cols:           n.Columns,
+		LHSKey:         lhsKey,
+		RHSKey:         rhsKey,
+		ComparisonType: comparisonType,
+		Collation:      collation,
+		Predicate:      n.Predicate,
+	}, nil
+}
+
+// joinPredicatesFor returns the predicates that were pushed to the RHS for the join predicate.
+// Joins are cloned while planning, so the predicate might be a copy of the one they were recorded for.
+func joinPredicatesFor(ctx *plancontext.PlanningContext, predicate sqlparser.Expr) []sqlparser.Expr {
+	if predicates, ok := ctx.JoinPredicates[predicate]; ok {
+		return predicates
+	}
+	for expr, predicates := range ctx.JoinPredicates {
+		if ctx.SemTable.EqualsExpr(expr, predicate) {
+			return predicates
+		}
+	}
+	return nil
+}
+
+// hashJoinComparison returns the type and collation that the values of both sides of the join are hashed with.
+// They are only known when both sides have the same type, and the same collation for text types.
+func hashJoinComparison(ctx *plancontext.PlanningContext, lhsExpr, rhsExpr sqlparser.Expr) (querypb.Type, collations.ID, bool) {
+	lhsType := ctx.SemTable.TypeFor(lhsExpr)
+	rhsType := ctx.SemTable.TypeFor(rhsExpr)
+	if lhsType == nil || rhsType == nil || *lhsType != *rhsType {
+		return 0, 0, false
+	}
+	collation := ctx.SemTable.CollationForExpr(lhsExpr)
+	if collation != ctx.SemTable.CollationForExpr(rhsExpr) {
+		return 0, 0, false
+	}
+	if collation == collations.Unknown && sqltypes.IsText(*lhsType) {
+		collation = ctx.SemTable.Collation
+	}
+	return *lhsType, collation, true
+}
+
+// hashJoinIsCheaper compares the estimated cost of a nested loop join, that sends the RHS once per
+// row of the LHS, to the one of a hash join, that sends it once and fetches the rows of both sides.
+func hashJoinIsCheaper(n *operators.ApplyJoin) bool {
+	lhsRows := estimatedRows(n.LHS)
+	rhsRows := estimatedRows(n.RHS)
+	rhsCost := operators.CostOf(n.RHS)
+	if rhsCost < 1 {
+		rhsCost = 1
+	}
+	nestedLoopCost := lhsRows * rhsCost
+	hashJoinCost := rhsCost + (lhsRows+rhsRows)/hashJoinRowsPerCost
+	return hashJoinCost < nestedLoopCost
+}
+
+// estimatedRows is a rough estimate of the number of rows an operator returns, based on
+// how its routes are routed. It is only good to compare join strategies with each other.
+func estimatedRows(op ops.Operator) int {
+	switch op := op.(type) {
+	case *operators.Route:
+		switch op.Routing.OpCode() {
+		case engine.EqualUnique, engine.Next, engine.None:
+			return 1
+		case engine.Equal, engine.IN, engine.MultiEqual:
+			return 10
+		default:
+			return 1000
+		}
+	default:
+		rows := 1
+		for _, input := range op.Inputs() {
+			if inputRows := estimatedRows(input); inputRows > rows {
+				rows = inputRows
+			}
+		}
+		return rows
+	}
+}
+
+// hashJoinsSupported returns false when the horizon of the statement needs something that
+// hash joins cannot do yet, in which case its joins are planned as nested loop joins.
+func hashJoinsSupported(ctx *plancontext.PlanningContext, stmt sqlparser.SelectStatement) bool {
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || sel.Distinct || sel.GroupBy != nil || sel.Having != nil || sqlparser.ContainsAggregation(sel.SelectExprs) {
+		return false
+	}
+	// projections from both sides of the join are evaluated by vtgate
+	exprs := make([]sqlparser.Expr, 0, len(sel.SelectExprs)+len(sel.OrderBy))
+	for _, expr := range sel.SelectExprs {
+		ae, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return false
+		}
+		exprs = append(exprs, ae.Expr)
+	}
+	for _, order := range sel.OrderBy {
+		exprs = append(exprs, order.Expr)
+	}
+	for _, expr := range exprs {
+		expr = sqlparser.Rewrite(sqlparser.CloneExpr(expr), nil, func(cursor *sqlparser.Cursor) bool {
+			if _, ok := cursor.Node().(*sqlparser.ColName); ok {
+				cursor.Replace(sqlparser.NewOffset(0, nil))
+			}
+			return true
+		}).(sqlparser.Expr)
+		if _, err := evalengine.Translate(expr, ctx.SemTable); err != nil {
+			return false
+		}
+	}
+	return true
+}
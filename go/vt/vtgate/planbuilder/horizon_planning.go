@@ -80,6 +80,11 @@ func (hp *horizonPlanning) planHorizon(ctx *plancontext.PlanningContext, plan lo
 	// If we still have a HAVING clause, it's because it could not be pushed to the WHERE,
 	// so it probably has aggregations
 	switch {
+	case hp.qp.HasWindowFunc && !hp.canPushDownWindows(ctx, plan):
+		plan, err = hp.planWindows(ctx, plan)
+		if err != nil {
+			return nil, err
+		}
 	case hp.qp.NeedsAggregation() || hp.sel.Having != nil:
 		plan, err = hp.planAggregations(ctx, plan)
 		if err != nil {
@@ -144,12 +149,31 @@ func (hp *horizonPlanning) truncateColumnsIfNeeded(ctx *plancontext.PlanningCont
 	switch p := plan.(type) {
 	case *routeGen4:
 		p.eroute.SetTruncateColumnCount(hp.qp.GetColumnCount())
-	case *joinGen4, *semiJoin, *hashJoin:
+	case *joinGen4, *semiJoin:
 		// since this is a join, we can safely add extra columns and not need to truncate them
 	case *orderedAggregate:
 		p.truncateColumnCount = hp.qp.GetColumnCount()
+	case *window:
+		p.SetTruncateColumnCount(hp.qp.GetColumnCount())
 	case *memorySort:
 		p.truncater.SetTruncateColumnCount(hp.qp.GetColumnCount())
+	case *projection:
+		p.columns = p.columns[:hp.qp.GetColumnCount()]
+		p.columnNames = p.columnNames[:hp.qp.GetColumnCount()]
+	case *filter:
+		if !hp.qp.NeedsAggregation() && hp.sel.Having == nil {
+			return hp.truncateWithSimpleProjection(ctx, plan)
+		}
+		// this is the HAVING filter on top of the aggregation. its predicate might use columns that
+		// are not part of the output, so we truncate on top of the filter without pushing anything below it
+		proj := &simpleProjection{
+			logicalPlanCommon: newBuilderCommon(plan),
+			eSimpleProj:       &engine.SimpleProjection{},
+		}
+		for idx := 0; idx < hp.qp.GetColumnCount(); idx++ {
+			proj.eSimpleProj.Cols = append(proj.eSimpleProj.Cols, idx)
+		}
+		plan = proj
 	case *pulloutSubquery:
 		newUnderlyingPlan, err := hp.truncateColumnsIfNeeded(ctx, p.underlying)
 		if err != nil {
@@ -157,16 +181,21 @@ func (hp *horizonPlanning) truncateColumnsIfNeeded(ctx *plancontext.PlanningCont
 		}
 		p.underlying = newUnderlyingPlan
 	default:
-		plan = &simpleProjection{
-			logicalPlanCommon: newBuilderCommon(plan),
-			eSimpleProj:       &engine.SimpleProjection{},
-		}
+		return hp.truncateWithSimpleProjection(ctx, plan)
+	}
+	return plan, nil
+}
 
-		exprs := hp.qp.SelectExprs[0:hp.qp.GetColumnCount()]
-		err := pushProjections(ctx, plan, exprs)
-		if err != nil {
-			return nil, err
-		}
+func (hp *horizonPlanning) truncateWithSimpleProjection(ctx *plancontext.PlanningContext, plan logicalPlan) (logicalPlan, error) {
+	plan = &simpleProjection{
+		logicalPlanCommon: newBuilderCommon(plan),
+		eSimpleProj:       &engine.SimpleProjection{},
+	}
+
+	exprs := hp.qp.SelectExprs[0:hp.qp.GetColumnCount()]
+	err := pushProjections(ctx, plan, exprs)
+	if err != nil {
+		return nil, err
 	}
 	return plan, nil
 }
@@ -208,6 +237,16 @@ func checkIfAlreadyExists(expr *sqlparser.AliasedExpr, node sqlparser.SelectStat
 }
 
 func (hp *horizonPlanning) planAggregations(ctx *plancontext.PlanningContext, plan logicalPlan) (logicalPlan, error) {
+	if ps, ok := plan.(*pulloutSubquery); ok && isJoin(ps.underlying) {
+		// the aggregation has to happen under the pullout subquery,
+		// so that the subquery's result can be used by the HAVING clause and the select expressions
+		newUnderlying, err := hp.planAggregations(ctx, ps.underlying)
+		if err != nil {
+			return nil, err
+		}
+		ps.underlying = newUnderlying
+		return ps, nil
+	}
 	isPushable := !isJoin(plan)
 	grouping := hp.qp.GetGrouping()
 	vindexOverlapWithGrouping := hasUniqueVindex(ctx.SemTable, grouping)
@@ -285,11 +324,31 @@ func (hp *horizonPlanning) planAggrUsingOA(
 		return nil, err
 	}
 
+	groupConcatOrder, err := hp.groupConcatOrder(ctx, aggregationExprs)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(distinctGroupBy) > 0 {
 		grouping = append(grouping, distinctGroupBy...)
-		// all the distinct grouping aggregates use the same expression, so it should be OK to just add it once
-		order = append(order, distinctGroupBy[0].AsOrderBy())
+		// all the distinct grouping aggregates use the same expressions, so it should be OK to just add them once
+		distinctOrder, err := distinctAggrOrder(ctx, aggregationExprs[distinctOffsets[0]], distinctGroupBy, groupConcatOrder)
+		if err != nil {
+			return nil, err
+		}
+		order = append(order, distinctOrder...)
 		oa.preProcess = true
+	} else if len(groupConcatOrder) > 0 {
+		// Sorting the rows of each group on the GROUP_CONCAT ORDER BY expressions allows us to concatenate
+		// the values in the right order. We also group on these expressions, so the partial results we get
+		// from the shards can be concatenated the same way.
+		for _, orderBy := range groupConcatOrder {
+			grouping = append(grouping, operators.GroupBy{
+				Inner:         orderBy.Inner.Expr,
+				WeightStrExpr: orderBy.WeightStrExpr,
+			})
+		}
+		order = append(order, groupConcatOrder...)
 	}
 
 	newPlan, groupingOffsets, aggrParamOffsets, pushed, err := hp.pushAggregation(ctx, plan, grouping, aggrs, false)
@@ -342,7 +401,40 @@ func (hp *horizonPlanning) planAggrUsingOA(
 		weightStrings:     make(map[*resultColumn]int),
 	}
 
-	return hp.planHaving(ctx, oa)
+	havingPlan, err := hp.planHaving(ctx, oa)
+	if err != nil {
+		return nil, err
+	}
+	return hp.planComplexAggrExprs(havingPlan)
+}
+
+// planComplexAggrExprs adds a projection on top of the aggregation, evaluating the expressions
+// that combine aggregations with other expressions, e.g. `sum(a) / count(*)`.
+// All other columns are passed through as they are.
+func (hp *horizonPlanning) planComplexAggrExprs(plan logicalPlan) (logicalPlan, error) {
+	if len(hp.qp.ComplexAggrExprs) == 0 {
+		return plan, nil
+	}
+	proj := &projection{
+		source:      plan,
+		columns:     make([]sqlparser.Expr, len(hp.qp.SelectExprs)),
+		columnNames: make([]string, len(hp.qp.SelectExprs)),
+	}
+	for idx, selectExpr := range hp.qp.SelectExprs {
+		ae, err := selectExpr.GetAliasedExpr()
+		if err != nil {
+			return nil, err
+		}
+		expr, isComplex := hp.qp.ComplexAggrExprs[idx]
+		if !isComplex {
+			expr = sqlparser.NewOffset(idx, ae.Expr)
+		}
+		idx := idx
+		if _, err := proj.addColumn(&idx, expr, ae.ColumnName()); err != nil {
+			return nil, err
+		}
+	}
+	return proj, nil
 }
 
 func passGroupingColumns(proj *projection, groupings []offsets, grouping []operators.GroupBy) (projGrpOffsets []offsets, err error) {
@@ -403,12 +495,20 @@ func generateAggregateParams(aggrs []operators.Aggr, aggrParamOffsets [][]offset
 
 		opcode := engine.AggregateSum
 		switch aggr.OpCode {
-		case engine.AggregateMin, engine.AggregateMax, engine.AggregateRandom:
+		case engine.AggregateMin, engine.AggregateMax, engine.AggregateRandom,
+			engine.AggregateGroupConcat, engine.AggregateBitAnd, engine.AggregateBitOr, engine.AggregateBitXor,
+			engine.AggregateJSONArrayAgg, engine.AggregateJSONObjectAgg:
 			opcode = aggr.OpCode
 		case engine.AggregateCount, engine.AggregateCountStar, engine.AggregateCountDistinct, engine.AggregateSumDistinct:
 			if !pushed {
 				opcode = aggr.OpCode
 			}
+		case engine.AggregateGroupConcatDistinct:
+			// the partial results of a distinct aggregation on a unique vindex are simply concatenated
+			opcode = engine.AggregateGroupConcat
+			if !pushed {
+				opcode = aggr.OpCode
+			}
 		}
 
 		aggrParams[idx] = &engine.AggregateParams{
@@ -418,6 +518,7 @@ func generateAggregateParams(aggrs []operators.Aggr, aggrParamOffsets [][]offset
 			Expr:       aggr.Original.Expr,
 			Original:   aggr.Original,
 			OrigOpcode: aggr.OpCode,
+			Separator:  groupConcatSeparator(aggr.Func),
 		}
 	}
 	return aggrParams, nil
@@ -442,12 +543,23 @@ func addColumnsToOA(
 		// no distinct aggregations
 		oa.aggregates = aggrParams
 	} else {
-		count := len(groupings) - len(distinctOffsets)
+		count := len(groupings) - len(distinctGroupBy)
 		addDistinctAggr := func(offset int) {
-			// the last grouping we pushed is the one we added for the distinct aggregation
-			o := groupings[count]
-			count++
+			// the last groupings we pushed are the ones we added for the distinct aggregations,
+			// one per argument of the aggregation
 			a := aggregationExprs[offset]
+			args := a.Func.GetArgs()
+			o := groupings[count]
+			var extraKeys []*engine.GroupByParams
+			for i, arg := range args[1:] {
+				extra := groupings[count+1+i]
+				extraKeys = append(extraKeys, &engine.GroupByParams{
+					KeyCol:          extra.col,
+					WeightStringCol: extra.wsCol,
+					CollationID:     ctx.SemTable.CollationForExpr(arg),
+				})
+			}
+			count += len(args)
 			collID := ctx.SemTable.CollationForExpr(a.Func.GetArg())
 			oa.aggregates = append(oa.aggregates, &engine.AggregateParams{
 				Opcode:      a.OpCode,
@@ -455,9 +567,11 @@ func addColumnsToOA(
 				KeyCol:      o.col,
 				WAssigned:   o.wsCol >= 0,
 				WCol:        o.wsCol,
+				ExtraKeys:   extraKeys,
 				Alias:       a.Alias,
 				Original:    a.Original,
 				CollationID: collID,
+				Separator:   groupConcatSeparator(a.Func),
 			})
 		}
 		lastOffset := distinctOffsets[len(distinctOffsets)-1]
@@ -474,13 +588,87 @@ func addColumnsToOA(
 		}
 
 		// we have to remove the tail of the grouping offsets, so we only have the offsets for the GROUP BY in the query
-		groupings = groupings[:len(groupings)-len(distinctOffsets)]
+		groupings = groupings[:len(groupings)-len(distinctGroupBy)]
+	}
+
+	// groupings added for the GROUP_CONCAT ordering are only used to sort the rows, not to group them
+	for i, groupBy := range oa.groupByKeys {
+		groupBy.KeyCol = groupings[i].col
+		groupBy.WeightStringCol = groupings[i].wsCol
 	}
+}
 
-	for i, grouping := range groupings {
-		oa.groupByKeys[i].KeyCol = grouping.col
-		oa.groupByKeys[i].WeightStringCol = grouping.wsCol
+// groupConcatSeparator returns the separator to use when merging GROUP_CONCAT results,
+// or an empty string for other aggregations
+func groupConcatSeparator(fnc sqlparser.AggrFunc) string {
+	gc, isGroupConcat := fnc.(*sqlparser.GroupConcatExpr)
+	if !isGroupConcat {
+		return ""
 	}
+	return gc.GetSeparator()
+}
+
+// groupConcatOrder returns the ordering the GROUP_CONCAT aggregations ask for in their ORDER BY clause.
+// The rows are sorted only once, so all the GROUP_CONCAT aggregations with an ORDER BY must agree on it.
+func (hp *horizonPlanning) groupConcatOrder(ctx *plancontext.PlanningContext, aggrs []operators.Aggr) ([]operators.OrderBy, error) {
+	var orderBy sqlparser.OrderBy
+	for _, aggr := range aggrs {
+		gc, isGroupConcat := aggr.Func.(*sqlparser.GroupConcatExpr)
+		if !isGroupConcat || len(gc.OrderBy) == 0 {
+			continue
+		}
+		if orderBy == nil {
+			orderBy = gc.OrderBy
+			continue
+		}
+		if !ctx.SemTable.ASTEquals().OrderBy(orderBy, gc.OrderBy) {
+			return nil, vterrors.VT12001("in scatter query: GROUP_CONCAT aggregations with different ORDER BY clauses")
+		}
+	}
+
+	var order []operators.OrderBy
+	for _, o := range orderBy {
+		expr, weightStrExpr, err := hp.qp.GetSimplifiedExpr(o.Expr)
+		if err != nil {
+			return nil, err
+		}
+		order = append(order, operators.OrderBy{
+			Inner: &sqlparser.Order{
+				Expr:      expr,
+				Direction: o.Direction,
+			},
+			WeightStrExpr: weightStrExpr,
+		})
+	}
+	return order, nil
+}
+
+// distinctAggrOrder returns the ordering needed by the distinct aggregations: the rows are sorted on the
+// arguments of the aggregation. A GROUP_CONCAT(DISTINCT) can ask for a specific ordering of its argument,
+// but we can't sort the rows on anything else.
+func distinctAggrOrder(
+	ctx *plancontext.PlanningContext,
+	aggr operators.Aggr,
+	distinctGroupBy []operators.GroupBy,
+	groupConcatOrder []operators.OrderBy,
+) ([]operators.OrderBy, error) {
+	args := distinctGroupBy[:len(aggr.Func.GetArgs())]
+	if len(groupConcatOrder) == 0 {
+		var order []operators.OrderBy
+		for _, groupBy := range args {
+			order = append(order, groupBy.AsOrderBy())
+		}
+		return order, nil
+	}
+	if len(groupConcatOrder) != len(args) {
+		return nil, vterrors.VT12001("in scatter query: GROUP_CONCAT with ORDER BY together with DISTINCT aggregations on other expressions")
+	}
+	for i, groupBy := range args {
+		if !ctx.SemTable.EqualsExpr(groupBy.WeightStrExpr, groupConcatOrder[i].WeightStrExpr) {
+			return nil, vterrors.VT12001("in scatter query: GROUP_CONCAT with ORDER BY together with DISTINCT aggregations on other expressions")
+		}
+	}
+	return groupConcatOrder, nil
 }
 
 // handleDistinctAggr takes in a slice of aggregations and returns GroupBy elements that replace
@@ -488,34 +676,46 @@ func addColumnsToOA(
 // so we can later reify the original aggregations
 func (hp *horizonPlanning) handleDistinctAggr(ctx *plancontext.PlanningContext, exprs []operators.Aggr) (
 	distincts []operators.GroupBy, offsets []int, aggrs []operators.Aggr, err error) {
-	var distinctExpr sqlparser.Expr
+	var distinctExprs sqlparser.Exprs
 	for i, expr := range exprs {
 		if !expr.Distinct {
 			aggrs = append(aggrs, expr)
 			continue
 		}
 
-		inner, innerWS, err := hp.qp.GetSimplifiedExpr(expr.Func.GetArg())
-		if err != nil {
-			return nil, nil, nil, err
+		var groupings []operators.GroupBy
+		var innerWSs sqlparser.Exprs
+		hasVindex := false
+		for _, arg := range expr.Func.GetArgs() {
+			inner, innerWS, err := hp.qp.GetSimplifiedExpr(arg)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			// only the first argument takes the place of the aggregation in the output
+			var innerIndex *int
+			if len(groupings) == 0 {
+				innerIndex = expr.Index
+			}
+			groupings = append(groupings, operators.GroupBy{
+				Inner:         inner,
+				WeightStrExpr: innerWS,
+				InnerIndex:    innerIndex,
+			})
+			innerWSs = append(innerWSs, innerWS)
+			hasVindex = hasVindex || exprHasVindex(ctx.SemTable, innerWS, false)
 		}
-		if exprHasVindex(ctx.SemTable, innerWS, false) {
+		if hasVindex {
+			// the values are already distinct across shards, so the aggregation can be pushed down
 			aggrs = append(aggrs, expr)
 			continue
 		}
-		if distinctExpr == nil {
-			distinctExpr = innerWS
-		} else {
-			if !ctx.SemTable.EqualsExpr(distinctExpr, innerWS) {
-				err = vterrors.VT12001(fmt.Sprintf("only one DISTINCT aggregation is allowed in a SELECT: %s", sqlparser.String(expr.Original)))
-				return nil, nil, nil, err
-			}
+		if distinctExprs == nil {
+			distinctExprs = innerWSs
+		} else if !ctx.SemTable.ASTEquals().Exprs(distinctExprs, innerWSs) {
+			err = vterrors.VT12001(fmt.Sprintf("only one DISTINCT aggregation is allowed in a SELECT: %s", sqlparser.String(expr.Original)))
+			return nil, nil, nil, err
 		}
-		distincts = append(distincts, operators.GroupBy{
-			Inner:         inner,
-			WeightStrExpr: innerWS,
-			InnerIndex:    expr.Index,
-		})
+		distincts = append(distincts, groupings...)
 		offsets = append(offsets, i)
 	}
 	return
@@ -567,6 +767,22 @@ func (hp *horizonPlanning) createGroupingsForColumns(columns []*sqlparser.ColNam
 	return lhsGrouping, nil
 }
 
+// canPushDownWindows returns true if the window functions of the query can be evaluated by MySQL.
+// This is the case when the plan is a single route, and every window partition is contained within one shard.
+func (hp *horizonPlanning) canPushDownWindows(ctx *plancontext.PlanningContext, plan logicalPlan) bool {
+	if _, isRoute := plan.(*routeGen4); !isRoute {
+		return false
+	}
+	if hp.qp.NeedsAggregation() && !hasUniqueVindex(ctx.SemTable, hp.qp.GetGrouping()) {
+		// the window functions are evaluated over the aggregated rows,
+		// so the grouping must also be done on the shards
+		return false
+	}
+	return hp.qp.PartitionedByUniqueVindex(hp.sel, func(expr sqlparser.Expr) bool {
+		return exprHasUniqueVindex(ctx.SemTable, expr)
+	})
+}
+
 func hasUniqueVindex(semTable *semantics.SemTable, groupByExprs []operators.GroupBy) bool {
 	for _, groupByExpr := range groupByExprs {
 		if exprHasUniqueVindex(semTable, groupByExpr.WeightStrExpr) {
@@ -626,12 +842,52 @@ func (hp *horizonPlanning) planOrderBy(ctx *plancontext.PlanningContext, orderEx
 		return plan, nil
 	case *memorySort:
 		return plan, nil
+	case *window:
+		return hp.planOrderByForWindow(ctx, orderExprs, plan)
+	case *distinct, *concatenateGen4:
+		if len(orderExprs) == 0 {
+			return plan, nil
+		}
+		return hp.createMemorySortPlan(ctx, plan, orderExprs, true)
 	case *simpleProjection:
 		return hp.createMemorySortPlan(ctx, plan, orderExprs, true)
 	case *vindexFunc:
 		// This is evaluated at VTGate only, so weight_string function cannot be used.
 		return hp.createMemorySortPlan(ctx, plan, orderExprs /* useWeightStr */, false)
-	case *limit, *semiJoin, *filter, *pulloutSubquery, *projection:
+	case *projection:
+		for _, order := range orderExprs {
+			if sqlparser.ContainsAggregation(order.WeightStrExpr) {
+				return hp.createMemorySortPlanOnProjection(ctx, plan, orderExprs)
+			}
+		}
+		newInput, err := hp.planOrderBy(ctx, orderExprs, plan.source)
+		if err != nil {
+			return nil, err
+		}
+		plan.source = newInput
+		return plan, nil
+	case *correlatedSubquery:
+		// the result of the subquery is only known at the vtgate level, so ordering on it needs a memory sort.
+		// aliases of the subquery are replaced by the subquery itself, so they are evaluated by this plan
+		var usesSubquery bool
+		sortExprs := make([]operators.OrderBy, 0, len(orderExprs))
+		for _, order := range orderExprs {
+			if plan.usesSubquery(order.WeightStrExpr) {
+				usesSubquery = true
+				order.Inner = &sqlparser.Order{Expr: order.WeightStrExpr, Direction: order.Inner.Direction}
+			}
+			sortExprs = append(sortExprs, order)
+		}
+		if usesSubquery {
+			return hp.createMemorySortPlan(ctx, plan, sortExprs, true)
+		}
+		newOuter, err := hp.planOrderBy(ctx, orderExprs, plan.outer)
+		if err != nil {
+			return nil, err
+		}
+		plan.outer = newOuter
+		return plan, nil
+	case *limit, *semiJoin, *filter, *pulloutSubquery:
 		inputs := plan.Inputs()
 		if len(inputs) == 0 {
 			break
@@ -673,7 +929,7 @@ func planOrderByForRoute(ctx *plancontext.PlanningContext, orderExprs []operator
 			wsExpr = order.WeightStrExpr
 		}
 
-		offset, weightStringOffset, err := wrapAndPushExpr(ctx, order.Inner.Expr, wsExpr, plan)
+		offset, weightStringOffset, err := wrapAndPushExpr(ctx, order.SimplifiedExpr(), wsExpr, plan)
 		if err != nil {
 			return nil, err
 		}
@@ -718,17 +974,6 @@ func wrapAndPushExpr(ctx *plancontext.PlanningContext, expr sqlparser.Expr, weig
 	if weightStrExpr == nil {
 		return offset, -1, nil
 	}
-	if !sqlparser.IsColName(expr) {
-		switch unary := expr.(type) {
-		case *sqlparser.CastExpr:
-			expr = unary.Expr
-		case *sqlparser.ConvertExpr:
-			expr = unary.Expr
-		}
-		if !sqlparser.IsColName(expr) {
-			return 0, 0, vterrors.VT13001(fmt.Sprintf("in scatter query: complex ORDER BY expression: %s", sqlparser.String(expr)))
-		}
-	}
 	qt := ctx.SemTable.TypeFor(expr)
 	wsNeeded := true
 	if qt != nil && sqltypes.IsNumber(*qt) {
@@ -759,14 +1004,8 @@ func (hp *horizonPlanning) planOrderByForHashJoin(ctx *plancontext.PlanningConte
 		plan.Right = rhs
 		return plan, nil
 	}
-	if orderExprsDependsOnTableSet(orderExprs, ctx.SemTable, plan.Right.ContainsTables()) {
-		newRight, err := hp.planOrderBy(ctx, orderExprs, plan.Right)
-		if err != nil {
-			return nil, err
-		}
-		plan.Right = newRight
-		return plan, nil
-	}
+	// the rows of a hash join do not keep the order of either side when it spills to disk,
+	// and the unmatched rows of a left join come last, so we always sort them
 	sortPlan, err := hp.createMemorySortPlan(ctx, plan, orderExprs, true)
 	if err != nil {
 		return nil, err
@@ -834,6 +1073,35 @@ func createMemorySortPlanOnAggregation(ctx *plancontext.PlanningContext, plan *o
 	return ms, nil
 }
 
+// createMemorySortPlanOnProjection sorts the output of the projection evaluating complex aggregate expressions.
+// All ORDER BY expressions are part of the select expressions by now, so we can sort on the projected columns.
+func (hp *horizonPlanning) createMemorySortPlanOnProjection(ctx *plancontext.PlanningContext, plan *projection, orderExprs []operators.OrderBy) (logicalPlan, error) {
+	primitive := &engine.MemorySort{}
+	ms := &memorySort{
+		resultsBuilder: resultsBuilder{
+			logicalPlanCommon: newBuilderCommon(plan),
+			weightStrings:     make(map[*resultColumn]int),
+			truncater:         primitive,
+		},
+		eMemorySort: primitive,
+	}
+
+	for _, order := range orderExprs {
+		idx, _ := hp.qp.FindSelectExprIndexForExpr(ctx, order.WeightStrExpr)
+		if idx == nil {
+			return nil, vterrors.VT13001(fmt.Sprintf("expected to find ORDER BY expression (%s) in the projection", sqlparser.String(order.Inner)))
+		}
+		ms.eMemorySort.OrderBy = append(ms.eMemorySort.OrderBy, engine.OrderByParams{
+			Col:               *idx,
+			WeightStringCol:   -1,
+			Desc:              order.Inner.Direction == sqlparser.DescOrder,
+			StarColFixedIndex: *idx,
+			CollationID:       ctx.SemTable.CollationForExpr(order.WeightStrExpr),
+		})
+	}
+	return ms, nil
+}
+
 func findExprInOrderedAggr(ctx *plancontext.PlanningContext, plan *orderedAggregate, order operators.OrderBy) (keyCol int, weightStringCol int, found bool) {
 	for _, key := range plan.groupByKeys {
 		if ctx.SemTable.EqualsExpr(order.WeightStrExpr, key.Expr) ||
@@ -866,7 +1134,7 @@ func (hp *horizonPlanning) createMemorySortPlan(ctx *plancontext.PlanningContext
 		if !useWeightStr {
 			wsExpr = nil
 		}
-		offset, weightStringOffset, err := wrapAndPushExpr(ctx, order.Inner.Expr, wsExpr, plan)
+		offset, weightStringOffset, err := wrapAndPushExpr(ctx, order.SimplifiedExpr(), wsExpr, plan)
 		if err != nil {
 			return nil, err
 		}
@@ -895,6 +1163,9 @@ func (hp *horizonPlanning) planDistinct(ctx *plancontext.PlanningContext, plan l
 	if !hp.qp.NeedsDistinct() {
 		return plan, nil
 	}
+	if len(hp.qp.ComplexAggrExprs) > 0 && len(hp.sel.OrderBy) > 0 {
+		return nil, vterrors.VT12001("DISTINCT and ORDER BY on complex aggregate expressions")
+	}
 	switch p := plan.(type) {
 	case *routeGen4:
 		// we always make the underlying query distinct,
@@ -909,6 +1180,8 @@ func (hp *horizonPlanning) planDistinct(ctx *plancontext.PlanningContext, plan l
 		return hp.addDistinct(ctx, plan)
 	case *orderedAggregate:
 		return hp.planDistinctOA(ctx.SemTable, p)
+	case *projection:
+		return hp.addDistinctOnComplexAggr(ctx, p)
 	default:
 		return nil, vterrors.VT13001(fmt.Sprintf("unknown plan type for DISTINCT %T", plan))
 	}
@@ -951,6 +1224,48 @@ func (hp *horizonPlanning) planDistinctOA(semTable *semantics.SemTable, currPlan
 	return oa, nil
 }
 
+// addDistinctOnComplexAggr makes the output of the projection evaluating complex aggregate expressions distinct.
+// The projected values are only available at the vtgate level, so we sort and group them there.
+func (hp *horizonPlanning) addDistinctOnComplexAggr(ctx *plancontext.PlanningContext, plan *projection) (logicalPlan, error) {
+	primitive := &engine.MemorySort{}
+	ms := &memorySort{
+		resultsBuilder: resultsBuilder{
+			logicalPlanCommon: newBuilderCommon(plan),
+			weightStrings:     make(map[*resultColumn]int),
+			truncater:         primitive,
+		},
+		eMemorySort: primitive,
+	}
+	var groupByKeys []*engine.GroupByParams
+	for index := 0; index < hp.qp.GetColumnCount(); index++ {
+		aliasExpr, err := hp.qp.SelectExprs[index].GetAliasedExpr()
+		if err != nil {
+			return nil, err
+		}
+		collationID := ctx.SemTable.CollationForExpr(aliasExpr.Expr)
+		ms.eMemorySort.OrderBy = append(ms.eMemorySort.OrderBy, engine.OrderByParams{
+			Col:               index,
+			WeightStringCol:   -1,
+			StarColFixedIndex: index,
+			CollationID:       collationID,
+		})
+		groupByKeys = append(groupByKeys, &engine.GroupByParams{
+			KeyCol:          index,
+			WeightStringCol: -1,
+			CollationID:     collationID,
+			Expr:            aliasExpr.Expr,
+		})
+	}
+	oa := &orderedAggregate{
+		resultsBuilder: resultsBuilder{
+			logicalPlanCommon: newBuilderCommon(ms),
+			weightStrings:     make(map[*resultColumn]int),
+		},
+		groupByKeys: groupByKeys,
+	}
+	return oa, nil
+}
+
 func (hp *horizonPlanning) addDistinct(ctx *plancontext.PlanningContext, plan logicalPlan) (logicalPlan, error) {
 	var orderExprs []operators.OrderBy
 	var groupByKeys []*engine.GroupByParams
@@ -1080,6 +1395,13 @@ func exprHasVindex(semTable *semantics.SemTable, expr sqlparser.Expr, hasToBeUni
 	if !isCol {
 		return false
 	}
+	if tableInfo, err := semTable.TableInfoForExpr(expr); err == nil {
+		if dt, ok := tableInfo.(*semantics.DerivedTable); ok && isUnionDerivedTable(dt) {
+			// the columns of a UNION come from different tables,
+			// so the vindex of the first SELECT does not say anything about the rows of the others
+			return false
+		}
+	}
 	ts := semTable.RecursiveDeps(expr)
 	tableInfo, err := semTable.TableInfoFor(ts)
 	if err != nil {
@@ -1097,6 +1419,18 @@ func exprHasVindex(semTable *semantics.SemTable, expr sqlparser.Expr, hasToBeUni
 	return false
 }
 
+func isUnionDerivedTable(dt *semantics.DerivedTable) bool {
+	if dt.ASTNode == nil {
+		return false
+	}
+	derived, ok := dt.ASTNode.Expr.(*sqlparser.DerivedTable)
+	if !ok {
+		return false
+	}
+	_, isUnion := derived.Select.(*sqlparser.Union)
+	return isUnion
+}
+
 func planSingleShardRoutePlan(sel sqlparser.SelectStatement, rb *routeGen4) error {
 	err := stripDownQuery(sel, rb.Select)
 	if err != nil {
@@ -1172,7 +1506,7 @@ func planGroupByGen4(ctx *plancontext.PlanningContext, groupExpr operators.Group
 		return nil
 	case *pulloutSubquery:
 		return planGroupByGen4(ctx, groupExpr, node.underlying, wsAdded)
-	case *semiJoin:
+	case *semiJoin, *correlatedSubquery:
 		return vterrors.VT13001("GROUP BY in a query having a correlated subquery")
 	default:
 		return vterrors.VT13001(fmt.Sprintf("GROUP BY on: %T", plan))
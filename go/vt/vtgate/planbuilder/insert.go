@@ -20,6 +20,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
 
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 
@@ -56,6 +59,12 @@ func buildInsertPlan(stmt sqlparser.Statement, reservedVars *sqlparser.ReservedV
 		// There is only one table.
 		vschemaTable = tval.vschemaTable
 	}
+	if err := checkInsertForeignKeys(ins, vschemaTable, vschema); err != nil {
+		return nil, err
+	}
+	if err := applyMessageDelivery(ins, vschemaTable); err != nil {
+		return nil, err
+	}
 	if !rb.eroute.Keyspace.Sharded {
 		return buildInsertUnshardedPlan(ins, vschemaTable, reservedVars, vschema)
 	}
@@ -309,6 +318,74 @@ func applyCommentDirectives(ins *sqlparser.Insert, eins *engine.Insert) {
 	eins.QueryTimeout = queryTimeout(directives)
 }
 
+// messageTableColumns are the columns that the tablets require a message table to have.
+var messageTableColumns = []string{"id", "time_next", "epoch", "time_acked"}
+
+// applyMessageDelivery sets time_next, the time at which a message is first sent, on the
+// rows inserted into a message table by an INSERT with a DELIVER_AT or DELIVER_AFTER directive.
+// DELIVER_AT is an RFC 3339 timestamp. DELIVER_AFTER is a duration that is added to the time
+// at which MySQL runs the INSERT, so that the plan stays valid in the plan cache.
+// The table has to be known to be a message table, which takes an authoritative column list,
+// either from the vschema or from the schema tracking, with the columns of a message table.
+func applyMessageDelivery(ins *sqlparser.Insert, table *vindexes.Table) error {
+	directives := ins.Comments.Directives()
+	deliverAt, isAtSet := directives.GetString(sqlparser.DirectiveDeliverAt, "")
+	deliverAfter, isAfterSet := directives.GetString(sqlparser.DirectiveDeliverAfter, "")
+	if !isAtSet && !isAfterSet {
+		return nil
+	}
+	if !table.ColumnListAuthoritative {
+		return vterrors.VT03024(fmt.Sprintf("%s is not known to be a message table, its column list is not authoritative", table.Name.String()))
+	}
+	for _, name := range messageTableColumns {
+		col := sqlparser.NewIdentifierCI(name)
+		if !slices.ContainsFunc(table.Columns, func(c vindexes.Column) bool { return c.Name.Equal(col) }) {
+			return vterrors.VT03024(fmt.Sprintf("%s is not a message table", table.Name.String()))
+		}
+	}
+	if isAtSet && isAfterSet {
+		return vterrors.VT03024("DELIVER_AT and DELIVER_AFTER cannot be used together")
+	}
+
+	var timeNext sqlparser.Expr
+	if isAtSet {
+		at, err := time.Parse(time.RFC3339Nano, deliverAt)
+		if err != nil {
+			return vterrors.VT03024(fmt.Sprintf("DELIVER_AT %s is not an RFC 3339 timestamp", deliverAt))
+		}
+		timeNext = sqlparser.NewIntLiteral(strconv.FormatInt(at.UnixNano(), 10))
+	} else {
+		after, err := time.ParseDuration(deliverAfter)
+		if err != nil {
+			return vterrors.VT03024(fmt.Sprintf("DELIVER_AFTER %s is not a duration", deliverAfter))
+		}
+		if after < 0 {
+			return vterrors.VT03024(fmt.Sprintf("DELIVER_AFTER %s is negative", deliverAfter))
+		}
+		timeNext, err = sqlparser.ParseExpr(fmt.Sprintf("cast(unix_timestamp(now(6)) * 1000000000 as signed) + %d", after.Nanoseconds()))
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return vterrors.VT12001("DELIVER_AT or DELIVER_AFTER with INSERT ... SELECT")
+	}
+	timeNextCol := sqlparser.NewIdentifierCI("time_next")
+	if ins.Columns == nil {
+		populateInsertColumnlist(ins, table)
+	}
+	if findColumn(ins, timeNextCol) != -1 {
+		return vterrors.VT03024("time_next cannot be set by the INSERT as well")
+	}
+	ins.Columns = append(ins.Columns, timeNextCol)
+	for i := range rows {
+		rows[i] = append(rows[i], sqlparser.CloneExpr(timeNext))
+	}
+	return nil
+}
+
 func getColVindexes(allColVindexes []*vindexes.ColumnVindex) (colVindexes []*vindexes.ColumnVindex) {
 	for _, colVindex := range allColVindexes {
 		if colVindex.IsPartialVindex() {
@@ -89,8 +89,20 @@ func transformToLogicalPlan(ctx *plancontext.PlanningContext, op ops.Operator, i
 	return nil, vterrors.VT13001(fmt.Sprintf("unknown type encountered: %T (transformToLogicalPlan)", op))
 }
 
+// transformHorizonSource transforms the source of the horizon of the statement into a logical plan.
+func transformHorizonSource(ctx *plancontext.PlanningContext, stmt sqlparser.SelectStatement, source ops.Operator, isRoot bool) (logicalPlan, error) {
+	noHashJoins := ctx.NoHashJoins
+	defer func() {
+		ctx.NoHashJoins = noHashJoins
+	}()
+	if !hashJoinsSupported(ctx, stmt) {
+		ctx.NoHashJoins = true
+	}
+	return transformToLogicalPlan(ctx, source, isRoot)
+}
+
 func transformHorizon(ctx *plancontext.PlanningContext, op *operators.Horizon, isRoot bool) (logicalPlan, error) {
-	source, err := transformToLogicalPlan(ctx, op.Source, isRoot)
+	source, err := transformHorizonSource(ctx, op.Select, op.Source, isRoot)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +141,11 @@ func transformHorizon(ctx *plancontext.PlanningContext, op *operators.Horizon, i
 }
 
 func transformApplyJoinPlan(ctx *plancontext.PlanningContext, n *operators.ApplyJoin) (logicalPlan, error) {
+	hj, err := planHashJoin(ctx, n)
+	if err != nil || hj != nil {
+		return hj, err
+	}
+
 	lhs, err := transformToLogicalPlan(ctx, n.LHS, false)
 	if err != nil {
 		return nil, err
@@ -233,6 +250,15 @@ func transformUpdatePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 		DML:                 edml,
 	}
 
+	if upd.RowsToChange != nil {
+		dwl, err := newDMLWithLimit(ctx, upd.VTable, upd.RowsToChange)
+		if err != nil {
+			return nil, err
+		}
+		dwl.DML = e
+		return &primitiveWrapper{prim: dwl}, nil
+	}
+
 	return &primitiveWrapper{prim: e}, nil
 }
 
@@ -259,9 +285,44 @@ func transformDeletePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 		DML: edml,
 	}
 
+	if del.RowsToChange != nil {
+		dwl, err := newDMLWithLimit(ctx, del.VTable, del.RowsToChange)
+		if err != nil {
+			return nil, err
+		}
+		dwl.DML = e
+		return &primitiveWrapper{prim: dwl}, nil
+	}
+
 	return &primitiveWrapper{prim: e}, nil
 }
 
+// newDMLWithLimit plans the input of a DML with a LIMIT that changes rows on several shards:
+// it selects and locks the rows to change, so that each shard can be sent the primary keys of
+// the rows it has to change.
+func newDMLWithLimit(ctx *plancontext.PlanningContext, vtable *vindexes.Table, rowsToChange *sqlparser.Select) (*engine.DMLWithLimit, error) {
+	primary := vtable.ColumnVindexes[0]
+	ksidOffsets := make([]int, 0, len(primary.Columns))
+	for _, col := range primary.Columns {
+		for i, expr := range rowsToChange.SelectExprs {
+			if ae, ok := expr.(*sqlparser.AliasedExpr); ok && col.EqualString(ae.ColumnName()) {
+				ksidOffsets = append(ksidOffsets, i)
+				break
+			}
+		}
+	}
+
+	input, err := gen4SelectStmtPlanner("", ctx.PlannerVersion, rowsToChange, ctx.ReservedVars, ctx.VSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.DMLWithLimit{
+		Input:       input.primitive,
+		KsidVindex:  primary.Vindex,
+		KsidOffsets: ksidOffsets,
+	}, nil
+}
+
 func transformDMLPlan(stmt sqlparser.Commented, vtable *vindexes.Table, edml *engine.DML, routing operators.Routing, setVindex bool) {
 	directives := stmt.GetParsedComments().Directives()
 	if directives.IsSet(sqlparser.DirectiveMultiShardAutocommit) {
@@ -383,9 +444,6 @@ func transformUnionPlan(ctx *plancontext.PlanningContext, op *operators.Union, i
 		}
 		result = src
 	} else {
-		if len(op.Ordering) > 0 {
-			return nil, vterrors.VT12001("ORDER BY on top of UNION")
-		}
 		result = &concatenateGen4{sources: sources}
 	}
 	if op.Distinct {
@@ -554,13 +612,18 @@ func transformAndMergeInOrder(ctx *plancontext.PlanningContext, op *operators.Un
 }
 
 func getCollationsFor(ctx *plancontext.PlanningContext, n *operators.Union) []collations.ID {
-	// TODO: coerce selects' select expressions' collations
-	var colls []collations.ID
-
 	sel, err := n.GetSelectFor(0)
 	if err != nil {
 		return nil
 	}
+	return getCollationsForSelect(ctx, sel)
+}
+
+// getCollationsForSelect returns the collations of the columns produced by a UNION,
+// based on the select expressions of its first SELECT
+func getCollationsForSelect(ctx *plancontext.PlanningContext, sel *sqlparser.Select) []collations.ID {
+	// TODO: coerce selects' select expressions' collations
+	var colls []collations.ID
 	for _, expr := range sel.SelectExprs {
 		aliasedE, ok := expr.(*sqlparser.AliasedExpr)
 		if !ok {
@@ -584,7 +647,7 @@ func transformDerivedPlan(ctx *plancontext.PlanningContext, op *operators.Derive
 	// expression containing our derived table's inner select and the derived
 	// table's alias.
 
-	plan, err := transformToLogicalPlan(ctx, op.Source, false)
+	plan, err := transformHorizonSource(ctx, op.Query, op.Source, false)
 	if err != nil {
 		return nil, err
 	}
@@ -117,18 +117,10 @@ func (a *ApplyJoin) IsInner() bool {
 }
 
 func (a *ApplyJoin) AddJoinPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) error {
-	bvName, cols, predicate, err := BreakExpressionInLHSandRHS(ctx, expr, TableID(a.LHS))
+	predicate, err := a.breakJoinPredicate(ctx, expr)
 	if err != nil {
 		return err
 	}
-	for i, col := range cols {
-		offset, err := a.LHS.AddColumn(ctx, col)
-		if err != nil {
-			return err
-		}
-		a.Vars[bvName[i]] = offset
-	}
-	a.LHSColumns = append(a.LHSColumns, cols...)
 
 	rhs, err := a.RHS.AddPredicate(ctx, predicate)
 	if err != nil {
@@ -140,6 +132,44 @@ func (a *ApplyJoin) AddJoinPredicate(ctx *plancontext.PlanningContext, expr sqlp
 	return nil
 }
 
+// addJoinPredicateOnTop adds a join predicate like AddJoinPredicate does, except that the RHS part
+// of the predicate is evaluated at the vtgate level, on top of the RHS, instead of being pushed into it.
+// This is used when pushing the predicate down would change the result of the RHS, such as for a derived
+// table with a LIMIT on the outer side of a LEFT JOIN
+func (a *ApplyJoin) addJoinPredicateOnTop(ctx *plancontext.PlanningContext, expr sqlparser.Expr) error {
+	predicate, err := a.breakJoinPredicate(ctx, expr)
+	if err != nil {
+		return err
+	}
+
+	if filter, ok := a.RHS.(*Filter); ok {
+		filter.Predicates = append(filter.Predicates, predicate)
+	} else {
+		a.RHS = newFilter(a.RHS, predicate)
+	}
+
+	a.Predicate = ctx.SemTable.AndExpressions(expr, a.Predicate)
+	return nil
+}
+
+// breakJoinPredicate adds the LHS columns used by the predicate to the join variables,
+// and returns the predicate that has to be evaluated on the RHS
+func (a *ApplyJoin) breakJoinPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (sqlparser.Expr, error) {
+	bvName, cols, predicate, err := BreakExpressionInLHSandRHS(ctx, expr, TableID(a.LHS))
+	if err != nil {
+		return nil, err
+	}
+	for i, col := range cols {
+		offset, err := a.LHS.AddColumn(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		a.Vars[bvName[i]] = offset
+	}
+	a.LHSColumns = append(a.LHSColumns, cols...)
+	return predicate, nil
+}
+
 func (a *ApplyJoin) AddColumn(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (int, error) {
 	// first check if we already are passing through this expression
 	for i, existing := range a.ColumnsAST {
@@ -33,6 +33,15 @@ type (
 		// arguments that need to be copied from the outer to inner
 		Vars map[string]int
 
+		// AntiJoin is set for NOT EXISTS subqueries, that only keep the outer rows without a match
+		AntiJoin bool
+
+		// Pullout is set when the result of the subquery is used by the outer query, and not only
+		// checked for existence. The subquery is then evaluated for every row of the outer query,
+		// and Predicate, if any, is the predicate of the outer query that uses its result.
+		Pullout   bool
+		Predicate sqlparser.Expr
+
 		noColumns
 		noPredicates
 	}
@@ -85,6 +94,9 @@ func (c *CorrelatedSubQueryOp) Clone(inputs []ops.Operator) ops.Operator {
 		Extracted:  c.Extracted,
 		LHSColumns: columns,
 		Vars:       vars,
+		AntiJoin:   c.AntiJoin,
+		Pullout:    c.Pullout,
+		Predicate:  c.Predicate,
 	}
 	return result
 }
@@ -29,6 +29,10 @@ type Delete struct {
 	OwnedVindexQuery string
 	AST              *sqlparser.Delete
 
+	// RowsToChange selects the rows a DML with a LIMIT changes when it targets several shards.
+	// The AST then only changes the rows whose primary keys are bound on each shard.
+	RowsToChange *sqlparser.Select
+
 	noInputs
 	noColumns
 	noPredicates
@@ -51,6 +55,7 @@ func (d *Delete) Clone(inputs []ops.Operator) ops.Operator {
 		VTable:           d.VTable,
 		OwnedVindexQuery: d.OwnedVindexQuery,
 		AST:              d.AST,
+		RowsToChange:     d.RowsToChange,
 	}
 }
 
@@ -166,3 +166,56 @@ func extractValueFromUpdate(upd *sqlparser.UpdateExpr) (evalengine.Expr, error)
 func invalidUpdateExpr(upd *sqlparser.UpdateExpr, expr sqlparser.Expr) error {
 	return vterrors.VT12001(fmt.Sprintf("only values are supported; invalid update on column: `%s` with expr: [%s]", upd.Name.Name.String(), sqlparser.String(expr)))
 }
+
+// isMultiShardDMLWithLimit returns true if the DML has a LIMIT and can change rows on several shards.
+// The rows to change are then selected first, and each shard is sent the primary keys of the rows it has to change.
+// A DML that targets a destination explicitly is sent as is to each shard of the destination.
+func isMultiShardDMLWithLimit(routing Routing, limit *sqlparser.Limit) bool {
+	if limit == nil {
+		return false
+	}
+	opCode := routing.OpCode()
+	return !opCode.IsSingleShard() && opCode != engine.None && opCode != engine.ByDestination
+}
+
+// rowsToChange returns the query that selects and locks the rows a multi-shard DML with a LIMIT changes,
+// in the order of the DML and within its LIMIT. Its first column is the primary key of the table, and the
+// columns of the primary vindex that are not the primary key follow it.
+func rowsToChange(
+	vTbl *vindexes.Table,
+	tableExprs sqlparser.TableExprs,
+	where *sqlparser.Where,
+	orderBy sqlparser.OrderBy,
+	limit *sqlparser.Limit,
+	dmlType string,
+) (*sqlparser.Select, error) {
+	if len(vTbl.PrimaryKey) != 1 {
+		return nil, vterrors.VT12001(fmt.Sprintf("multi shard %s with LIMIT on a table without a known single column primary key", dmlType))
+	}
+	pk := vTbl.PrimaryKey[0]
+	sel := &sqlparser.Select{
+		SelectExprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: sqlparser.NewColName(pk.String())}},
+		From:        sqlparser.CloneTableExprs(tableExprs),
+		Where:       sqlparser.CloneRefOfWhere(where),
+		OrderBy:     sqlparser.CloneOrderBy(orderBy),
+		Limit:       limit,
+		Lock:        sqlparser.ForUpdateLock,
+	}
+	for _, col := range vTbl.ColumnVindexes[0].Columns {
+		if col.Equal(pk) {
+			continue
+		}
+		sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(col.String())})
+	}
+	return sel, nil
+}
+
+// keyedByPrimaryKey returns the WHERE clause of a multi-shard DML with a LIMIT, which matches
+// the primary keys of the rows to change that are bound on each shard.
+func keyedByPrimaryKey(vTbl *vindexes.Table) *sqlparser.Where {
+	return sqlparser.NewWhere(sqlparser.WhereClause, &sqlparser.ComparisonExpr{
+		Operator: sqlparser.InOp,
+		Left:     sqlparser.NewColName(vTbl.PrimaryKey[0].String()),
+		Right:    sqlparser.NewListArg(engine.DMLValsVarName),
+	})
+}
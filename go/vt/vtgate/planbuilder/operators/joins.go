@@ -37,6 +37,14 @@ type JoinOp interface {
 func AddPredicate(join JoinOp, ctx *plancontext.PlanningContext, expr sqlparser.Expr, joinPredicates bool, newFilter func(ops.Operator, sqlparser.Expr) ops.Operator) (ops.Operator, error) {
 	deps := ctx.SemTable.RecursiveDeps(expr)
 	switch {
+	case joinPredicates && !join.IsInner() && !deps.IsSolvedBy(TableID(join.GetRHS())):
+		// the ON condition of an outer join does not filter the rows of the left-hand side,
+		// so predicates using the lhs are evaluated as join predicates, on the rhs
+		err := join.AddJoinPredicate(ctx, expr)
+		if err != nil {
+			return nil, err
+		}
+		return join, nil
 	case deps.IsSolvedBy(TableID(join.GetLHS())):
 		// predicates can always safely be pushed down to the lhs if that is all they depend on
 		lhs, err := join.GetLHS().AddPredicate(ctx, expr)
@@ -48,7 +56,7 @@ func AddPredicate(join JoinOp, ctx *plancontext.PlanningContext, expr sqlparser.
 	case deps.IsSolvedBy(TableID(join.GetRHS())):
 		// if we are dealing with an outer join, always start by checking if this predicate can turn
 		// the join into an inner join
-		if !join.IsInner() && canConvertToInner(ctx, expr, TableID(join.GetRHS())) {
+		if !joinPredicates && !join.IsInner() && canConvertToInner(ctx, expr, TableID(join.GetRHS())) {
 			join.MakeInner()
 		}
 
@@ -58,7 +66,7 @@ func AddPredicate(join JoinOp, ctx *plancontext.PlanningContext, expr sqlparser.
 			return newFilter(join, expr), nil
 		}
 
-		// For inner joins, we can just push the filtering on the RHS
+		// For inner joins and the ON condition of outer joins, we can just push the filtering on the RHS
 		rhs, err := join.GetRHS().AddPredicate(ctx, expr)
 		if err != nil {
 			return nil, err
@@ -21,7 +21,6 @@ import (
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
-	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
@@ -139,9 +138,20 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && updStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard UPDATE with LIMIT")
+	var rows *sqlparser.Select
+	if isMultiShardDMLWithLimit(routing, updStmt.Limit) {
+		rows, err = rowsToChange(vindexTable, updStmt.TableExprs, updStmt.Where, updStmt.OrderBy, updStmt.Limit, "UPDATE")
+		if err != nil {
+			return nil, err
+		}
+		updStmt.Where, updStmt.OrderBy, updStmt.Limit = keyedByPrimaryKey(vindexTable), nil, nil
+		if len(cvv) > 0 {
+			// the owned vindex query must only select the rows to change on the shard as well
+			_, _, ovq, err = getUpdateVindexInformation(updStmt, vindexTable, qt.ID, qt.Predicates)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	r := &Route{
@@ -152,6 +162,7 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 			ChangedVindexValues: cvv,
 			OwnedVindexQuery:    ovq,
 			AST:                 updStmt,
+			RowsToChange:        rows,
 		},
 		Routing: routing,
 	}
@@ -202,14 +213,6 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 		tr.VindexPreds = vindexAndPredicates
 	}
 
-	var ovq string
-	if len(vindexTable.Owned) > 0 {
-		tblExpr := &sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: vindexTable.Name}, As: qt.Alias.As}
-		ovq = generateOwnedVindexQuery(tblExpr, deleteStmt, vindexTable, primaryVindex.Columns)
-	}
-
-	del.OwnedVindexQuery = ovq
-
 	for _, predicate := range qt.Predicates {
 		var err error
 		route.Routing, err = UpdateRoutingLogic(ctx, predicate, route.Routing)
@@ -218,9 +221,17 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && deleteStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard DELETE with LIMIT")
+	if isMultiShardDMLWithLimit(route.Routing, deleteStmt.Limit) {
+		del.RowsToChange, err = rowsToChange(vindexTable, deleteStmt.TableExprs, deleteStmt.Where, deleteStmt.OrderBy, deleteStmt.Limit, "DELETE")
+		if err != nil {
+			return nil, err
+		}
+		deleteStmt.Where, deleteStmt.OrderBy, deleteStmt.Limit = keyedByPrimaryKey(vindexTable), nil, nil
+	}
+
+	if len(vindexTable.Owned) > 0 {
+		tblExpr := &sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: vindexTable.Name}, As: qt.Alias.As}
+		del.OwnedVindexQuery = generateOwnedVindexQuery(tblExpr, deleteStmt, vindexTable, primaryVindex.Columns)
 	}
 
 	subq, err := createSubqueryFromStatement(ctx, deleteStmt)
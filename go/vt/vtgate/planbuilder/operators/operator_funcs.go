@@ -96,6 +96,20 @@ func RemovePredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr, op o
 		// remove the predicate from this filter
 		op.Predicates = append(op.Predicates[:idx], op.Predicates[idx+1:]...)
 		return op, nil
+	case *Table:
+		var keep []sqlparser.Expr
+		for _, predicate := range op.QTable.Predicates {
+			if !ctx.SemTable.EqualsExpr(predicate, expr) {
+				keep = append(keep, predicate)
+			}
+		}
+		if len(keep) == len(op.QTable.Predicates) {
+			return nil, vterrors.VT13001(fmt.Sprintf("predicate '%s' not found on table %s", sqlparser.String(expr), sqlparser.String(op.QTable.Table)))
+		}
+		// the query table can be shared with alternate routes, so we don't modify it in place
+		op.QTable = op.QTable.Clone()
+		op.QTable.Predicates = keep
+		return op, nil
 
 	default:
 		return nil, vterrors.VT13001("this should not happen - tried to remove predicate from the operator table")
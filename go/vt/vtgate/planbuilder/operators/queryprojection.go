@@ -48,9 +48,15 @@ type (
 		OrderExprs         []OrderBy
 		CanPushDownSorting bool
 		HasStar            bool
+		HasWindowFunc      bool
 
 		// AddedColumn keeps a counter for expressions added to solve HAVING expressions the user is not selecting
 		AddedColumn int
+
+		// ComplexAggrExprs holds, per select expression index, the expressions that combine aggregations with
+		// other expressions, e.g. `sum(a) / count(*)`. The aggregations in these expressions are replaced by
+		// offsets into the select expressions. They are evaluated at the vtgate level, after aggregation.
+		ComplexAggrExprs map[int]sqlparser.Expr
 	}
 
 	// OrderBy contains the expression to used in order by and also if ordering is needed at VTGate level then what the weight_string function expression to be sent down for evaluation.
@@ -99,6 +105,15 @@ func (b GroupBy) AsOrderBy() OrderBy {
 	}
 }
 
+// SimplifiedExpr returns the expression that must be selected to sort on this ORDER BY expression
+func (b OrderBy) SimplifiedExpr() sqlparser.Expr {
+	if sqlparser.IsColName(b.Inner.Expr) {
+		// column aliases are resolved when the expression is pushed
+		return b.Inner.Expr
+	}
+	return b.WeightStrExpr
+}
+
 func (b GroupBy) AsAliasedExpr() *sqlparser.AliasedExpr {
 	if b.aliasedExpr != nil {
 		return b.aliasedExpr
@@ -110,10 +125,6 @@ func (b GroupBy) AsAliasedExpr() *sqlparser.AliasedExpr {
 			As:   col.Name,
 		}
 	}
-	if !isColName && b.WeightStrExpr != b.Inner {
-		panic("this should not happen - different inner and weighStringExpr and not a column alias")
-	}
-
 	return &sqlparser.AliasedExpr{
 		Expr: b.WeightStrExpr,
 	}
@@ -191,8 +202,12 @@ func (ar *AggrRewriter) RewriteDown() func(sqlparser.SQLNode, sqlparser.SQLNode)
 		if ar.Err != nil {
 			return true
 		}
-		_, ok := node.(sqlparser.AggrFunc)
-		return !ok
+		switch node.(type) {
+		case sqlparser.AggrFunc, *sqlparser.Subquery:
+			// aggregations inside subqueries belong to the subquery
+			return false
+		}
+		return true
 	}
 }
 
@@ -207,30 +222,46 @@ func (ar *AggrRewriter) RewriteUp() func(*sqlparser.Cursor) bool {
 		if !ok {
 			return true
 		}
-		for offset, expr := range ar.qp.SelectExprs {
-			ae, err := expr.GetAliasedExpr()
-			if err != nil {
-				ar.Err = err
-				return false
-			}
-			if ar.st.EqualsExpr(ae.Expr, fExp) {
-				cursor.Replace(sqlparser.NewOffset(offset, fExp))
-				return true
-			}
+		expanded, isStatistical := expandStatisticalAggrs(fExp)
+		if !isStatistical {
+			cursor.Replace(ar.offsetFor(fExp))
+			return ar.Err == nil
 		}
+		// statistical aggregations are computed from simpler aggregations,
+		// each of them needs its own column
+		rewritten := sqlparser.CopyOnRewrite(expanded, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+			if inner, isAggr := cursor.Node().(sqlparser.AggrFunc); isAggr {
+				cursor.Replace(ar.offsetFor(inner))
+			}
+		}, nil)
+		cursor.Replace(rewritten)
+		return ar.Err == nil
+	}
+}
 
-		col := SelectExpr{
-			Aggr: true,
-			Col:  &sqlparser.AliasedExpr{Expr: fExp},
+// offsetFor returns an offset to the select expression producing the aggregation, adding it if needed
+func (ar *AggrRewriter) offsetFor(fExp sqlparser.AggrFunc) sqlparser.Expr {
+	for offset, expr := range ar.qp.SelectExprs {
+		ae, err := expr.GetAliasedExpr()
+		if err != nil {
+			ar.Err = err
+			return fExp
 		}
-		ar.qp.HasAggr = true
-
-		cursor.Replace(sqlparser.NewOffset(len(ar.qp.SelectExprs), fExp))
-		ar.qp.SelectExprs = append(ar.qp.SelectExprs, col)
-		ar.qp.AddedColumn++
+		if ar.st.EqualsExpr(ae.Expr, fExp) {
+			return sqlparser.NewOffset(offset, fExp)
+		}
+	}
 
-		return true
+	col := SelectExpr{
+		Aggr: true,
+		Col:  &sqlparser.AliasedExpr{Expr: fExp},
 	}
+	ar.qp.HasAggr = true
+
+	offset := sqlparser.NewOffset(len(ar.qp.SelectExprs), fExp)
+	ar.qp.SelectExprs = append(ar.qp.SelectExprs, col)
+	ar.qp.AddedColumn++
+	return offset
 }
 
 // AggrRewriter extracts
@@ -256,6 +287,9 @@ func (qp *QueryProjection) addSelectExpressions(sel *sqlparser.Select) error {
 				col.Aggr = true
 				qp.HasAggr = true
 			}
+			if sqlparser.ContainsWindowFunc(selExp.Expr) {
+				qp.HasWindowFunc = true
+			}
 
 			qp.SelectExprs = append(qp.SelectExprs, col)
 		case *sqlparser.StarExpr:
@@ -308,6 +342,9 @@ func (qp *QueryProjection) addOrderBy(orderBy sqlparser.OrderBy) error {
 			WeightStrExpr: weightStrExpr,
 		})
 		canPushDownSorting = canPushDownSorting && !sqlparser.ContainsAggregation(weightStrExpr)
+		if sqlparser.ContainsWindowFunc(weightStrExpr) {
+			qp.HasWindowFunc = true
+		}
 	}
 	qp.CanPushDownSorting = canPushDownSorting
 	return nil
@@ -324,7 +361,8 @@ func checkForInvalidAggregations(exp *sqlparser.AliasedExpr) error {
 	return sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		if aggrFunc, isAggregate := node.(sqlparser.AggrFunc); isAggregate {
 			if aggrFunc.GetArgs() != nil &&
-				len(aggrFunc.GetArgs()) != 1 {
+				len(aggrFunc.GetArgs()) != 1 &&
+				!takesMultipleArgs(aggrFunc) {
 				return false, vterrors.VT03001(sqlparser.String(node))
 			}
 			return true, nil
@@ -334,6 +372,18 @@ func checkForInvalidAggregations(exp *sqlparser.AliasedExpr) error {
 	}, exp.Expr)
 }
 
+// takesMultipleArgs returns true for the aggregations that accept more than one argument:
+// GROUP_CONCAT, JSON_OBJECTAGG and COUNT(DISTINCT ...)
+func takesMultipleArgs(aggrFunc sqlparser.AggrFunc) bool {
+	switch aggrFunc := aggrFunc.(type) {
+	case *sqlparser.GroupConcatExpr, *sqlparser.JSONObjectAgg:
+		return true
+	case *sqlparser.Count:
+		return aggrFunc.Distinct
+	}
+	return false
+}
+
 func (qp *QueryProjection) isExprInGroupByExprs(ctx *plancontext.PlanningContext, expr SelectExpr) bool {
 	for _, groupByExpr := range qp.groupByExprs {
 		exp, err := expr.GetExpr()
@@ -355,7 +405,7 @@ func (qp *QueryProjection) GetSimplifiedExpr(e sqlparser.Expr) (expr sqlparser.E
 
 	colExpr, isColName := e.(*sqlparser.ColName)
 	if !isColName {
-		return e, e, nil
+		return e, qp.replaceColumnAliases(e), nil
 	}
 
 	if sqlparser.IsNull(e) {
@@ -378,6 +428,39 @@ func (qp *QueryProjection) GetSimplifiedExpr(e sqlparser.Expr) (expr sqlparser.E
 	return e, e, nil
 }
 
+// replaceColumnAliases returns the expression with the column aliases it uses replaced by
+// the expressions behind them, so that it can be added to the SELECT list of a query.
+// Eg - select music.foo as bar from music order by bar + 1 needs music.foo + 1 to be selected
+func (qp *QueryProjection) replaceColumnAliases(e sqlparser.Expr) sqlparser.Expr {
+	aliases := map[string]sqlparser.Expr{}
+	for _, selectExpr := range qp.SelectExprs {
+		aliasedExpr, isAliasedExpr := selectExpr.Col.(*sqlparser.AliasedExpr)
+		if !isAliasedExpr || aliasedExpr.As.IsEmpty() {
+			continue
+		}
+		if _, found := aliases[aliasedExpr.As.Lowered()]; !found {
+			aliases[aliasedExpr.As.Lowered()] = aliasedExpr.Expr
+		}
+	}
+	if len(aliases) == 0 {
+		return e
+	}
+
+	result := sqlparser.CopyOnRewrite(e, func(node, _ sqlparser.SQLNode) bool {
+		_, isSubQ := node.(*sqlparser.Subquery)
+		return !isSubQ
+	}, func(cursor *sqlparser.CopyOnWriteCursor) {
+		col, isCol := cursor.Node().(*sqlparser.ColName)
+		if !isCol || !col.Qualifier.IsEmpty() {
+			return
+		}
+		if expr, found := aliases[col.Name.Lowered()]; found {
+			cursor.Replace(expr)
+		}
+	}, nil)
+	return result.(sqlparser.Expr)
+}
+
 // toString should only be used for tests
 func (qp *QueryProjection) toString() string {
 	type output struct {
@@ -461,7 +544,10 @@ orderBy:
 		qp.AddedColumn++
 	}
 
-	for idx, expr := range qp.SelectExprs {
+	// complex aggregate expressions can add aggregations to the select expressions,
+	// so we can't range over them here
+	for idx := 0; idx < len(qp.SelectExprs); idx++ {
+		expr := qp.SelectExprs[idx]
 		aliasedExpr, err := expr.GetAliasedExpr()
 		if err != nil {
 			return nil, err
@@ -481,42 +567,196 @@ orderBy:
 			continue
 		}
 		fnc, isAggregate := aliasedExpr.Expr.(sqlparser.AggrFunc)
-		if !isAggregate {
-			return nil, vterrors.VT12001("in scatter query: complex aggregate expression")
+		expanded, isStatistical := expandStatisticalAggrs(aliasedExpr.Expr)
+		if !isAggregate || isStatistical {
+			fnc, err = qp.splitComplexAggrExpr(ctx, idx, expanded)
+			if err != nil {
+				return nil, err
+			}
+			aliasedExpr = &sqlparser.AliasedExpr{Expr: fnc}
 		}
 
-		opcode, found := engine.SupportedAggregates[strings.ToLower(fnc.AggrName())]
-		if !found {
-			return nil, vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", fnc.AggrName()))
+		aggr, err := createAggr(aliasedExpr, fnc, &idxCopy)
+		if err != nil {
+			return nil, err
 		}
+		out = append(out, aggr)
+	}
+	return
+}
 
-		if opcode == engine.AggregateCount {
-			if _, isStar := fnc.(*sqlparser.CountStar); isStar {
-				opcode = engine.AggregateCountStar
-			}
+func createAggr(aliasedExpr *sqlparser.AliasedExpr, fnc sqlparser.AggrFunc, idx *int) (Aggr, error) {
+	opcode, found := engine.SupportedAggregates[strings.ToLower(fnc.AggrName())]
+	if !found {
+		return Aggr{}, vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", fnc.AggrName()))
+	}
+
+	if opcode == engine.AggregateCount {
+		if _, isStar := fnc.(*sqlparser.CountStar); isStar {
+			opcode = engine.AggregateCountStar
 		}
+	}
 
-		aggr, _ := aliasedExpr.Expr.(sqlparser.AggrFunc)
+	if gc, isGroupConcat := fnc.(*sqlparser.GroupConcatExpr); isGroupConcat {
+		if gc.Limit != nil {
+			return Aggr{}, vterrors.VT12001("in scatter query: GROUP_CONCAT with LIMIT")
+		}
+		if gc.Distinct && len(gc.Exprs) > 1 {
+			return Aggr{}, vterrors.VT12001(fmt.Sprintf("in scatter query: GROUP_CONCAT(DISTINCT) with more than one expression: %s", sqlparser.String(gc)))
+		}
+	}
 
-		if aggr.IsDistinct() {
-			switch opcode {
-			case engine.AggregateCount:
-				opcode = engine.AggregateCountDistinct
-			case engine.AggregateSum:
-				opcode = engine.AggregateSumDistinct
-			}
+	if fnc.IsDistinct() {
+		switch opcode {
+		case engine.AggregateCount:
+			opcode = engine.AggregateCountDistinct
+		case engine.AggregateSum:
+			opcode = engine.AggregateSumDistinct
+		case engine.AggregateGroupConcat:
+			opcode = engine.AggregateGroupConcatDistinct
 		}
+	}
+
+	return Aggr{
+		Original: aliasedExpr,
+		Func:     fnc,
+		OpCode:   opcode,
+		Alias:    aliasedExpr.ColumnName(),
+		Index:    idx,
+		Distinct: fnc.IsDistinct(),
+	}, nil
+}
 
-		out = append(out, Aggr{
-			Original: aliasedExpr,
-			Func:     aggr,
-			OpCode:   opcode,
-			Alias:    aliasedExpr.ColumnName(),
-			Index:    &idxCopy,
-			Distinct: aggr.IsDistinct(),
+// splitComplexAggrExpr breaks up an expression that combines aggregations with other expressions,
+// such as `sum(a) / count(*)`, so the aggregations can be planned on their own and the expression
+// evaluated at the vtgate level on top of the aggregated results.
+// The first aggregation found takes the place of the expression in the select expressions; the others
+// are added as extra columns, unless they are already selected. The returned aggregation is the one
+// taking the place of the expression.
+func (qp *QueryProjection) splitComplexAggrExpr(ctx *plancontext.PlanningContext, idx int, expr sqlparser.Expr) (sqlparser.AggrFunc, error) {
+	var first sqlparser.AggrFunc
+	rewritten := sqlparser.CopyOnRewrite(expr, func(node, _ sqlparser.SQLNode) bool {
+		_, isAggr := node.(sqlparser.AggrFunc)
+		_, isSubq := node.(*sqlparser.Subquery)
+		return !isAggr && !isSubq
+	}, func(cursor *sqlparser.CopyOnWriteCursor) {
+		fnc, isAggr := cursor.Node().(sqlparser.AggrFunc)
+		if !isAggr {
+			return
+		}
+		if first == nil || ctx.SemTable.EqualsExpr(first, fnc) {
+			if first == nil {
+				first = fnc
+			}
+			cursor.Replace(sqlparser.NewOffset(idx, fnc))
+			return
+		}
+		for offset, selectExpr := range qp.SelectExprs {
+			ae, isAliased := selectExpr.Col.(*sqlparser.AliasedExpr)
+			if isAliased && ctx.SemTable.EqualsExpr(ae.Expr, fnc) {
+				cursor.Replace(sqlparser.NewOffset(offset, fnc))
+				return
+			}
+		}
+		cursor.Replace(sqlparser.NewOffset(len(qp.SelectExprs), fnc))
+		qp.SelectExprs = append(qp.SelectExprs, SelectExpr{
+			Col:  &sqlparser.AliasedExpr{Expr: fnc},
+			Aggr: true,
 		})
+		qp.AddedColumn++
+	}, nil)
+	if first == nil {
+		return nil, vterrors.VT12001("in scatter query: complex aggregate expression")
 	}
-	return
+	if qp.ComplexAggrExprs == nil {
+		qp.ComplexAggrExprs = map[int]sqlparser.Expr{}
+	}
+	qp.ComplexAggrExprs[idx] = rewritten.(sqlparser.Expr)
+	return first, nil
+}
+
+// expandStatisticalAggrs rewrites the statistical aggregations (STD, STDDEV, VARIANCE and friends)
+// in the expression into expressions over SUM(x), SUM(x*x) and COUNT(x). Unlike the statistical
+// aggregations, these can be computed from the partial aggregations of each shard.
+func expandStatisticalAggrs(expr sqlparser.Expr) (sqlparser.Expr, bool) {
+	expanded := false
+	result := sqlparser.CopyOnRewrite(expr, func(node, _ sqlparser.SQLNode) bool {
+		_, isSubq := node.(*sqlparser.Subquery)
+		return !isSubq
+	}, func(cursor *sqlparser.CopyOnWriteCursor) {
+		var arg sqlparser.Expr
+		var sample, stddev bool
+		switch node := cursor.Node().(type) {
+		case *sqlparser.Std:
+			arg, stddev = node.Arg, true
+		case *sqlparser.StdDev:
+			arg, stddev = node.Arg, true
+		case *sqlparser.StdPop:
+			arg, stddev = node.Arg, true
+		case *sqlparser.StdSamp:
+			arg, stddev, sample = node.Arg, true, true
+		case *sqlparser.VarPop:
+			arg = node.Arg
+		case *sqlparser.Variance:
+			arg = node.Arg
+		case *sqlparser.VarSamp:
+			arg, sample = node.Arg, true
+		default:
+			return
+		}
+		cursor.Replace(statisticalExpr(arg, sample, stddev))
+		expanded = true
+	}, nil)
+	return result.(sqlparser.Expr), expanded
+}
+
+// statisticalExpr returns the expression computing the variance, or standard deviation, of arg:
+//
+//	greatest(sum(x*x) - sum(x)*sum(x)/count(x), 0) / count(x)
+//
+// The sample variants divide by count(x)-1 instead. Like MySQL, the computation is done using doubles.
+func statisticalExpr(arg sqlparser.Expr, sample, stddev bool) sqlparser.Expr {
+	asDouble := func(expr sqlparser.Expr) sqlparser.Expr {
+		return &sqlparser.CastExpr{Expr: expr, Type: &sqlparser.ConvertType{Type: "double"}}
+	}
+	sum := func() sqlparser.Expr {
+		return asDouble(&sqlparser.Sum{Arg: sqlparser.CloneExpr(arg)})
+	}
+	count := func() sqlparser.Expr {
+		return &sqlparser.Count{Args: sqlparser.Exprs{sqlparser.CloneExpr(arg)}}
+	}
+	sumOfSquares := asDouble(&sqlparser.Sum{Arg: &sqlparser.BinaryExpr{
+		Operator: sqlparser.MultOp,
+		Left:     sqlparser.CloneExpr(arg),
+		Right:    sqlparser.CloneExpr(arg),
+	}})
+	deviations := &sqlparser.FuncExpr{
+		Name: sqlparser.NewIdentifierCI("greatest"),
+		Exprs: sqlparser.SelectExprs{
+			&sqlparser.AliasedExpr{Expr: &sqlparser.BinaryExpr{
+				Operator: sqlparser.MinusOp,
+				Left:     sumOfSquares,
+				Right: &sqlparser.BinaryExpr{
+					Operator: sqlparser.DivOp,
+					Left:     &sqlparser.BinaryExpr{Operator: sqlparser.MultOp, Left: sum(), Right: sum()},
+					Right:    count(),
+				},
+			}},
+			&sqlparser.AliasedExpr{Expr: sqlparser.NewFloatLiteral("0e0")},
+		},
+	}
+	divisor := count()
+	if sample {
+		divisor = &sqlparser.BinaryExpr{Operator: sqlparser.MinusOp, Left: divisor, Right: sqlparser.NewIntLiteral("1")}
+	}
+	var result sqlparser.Expr = &sqlparser.BinaryExpr{Operator: sqlparser.DivOp, Left: deviations, Right: divisor}
+	if stddev {
+		result = &sqlparser.FuncExpr{
+			Name:  sqlparser.NewIdentifierCI("sqrt"),
+			Exprs: sqlparser.SelectExprs{&sqlparser.AliasedExpr{Expr: result}},
+		}
+	}
+	return result
 }
 
 // FindSelectExprIndexForExpr returns the index of the given expression in the select expressions, if it is part of it
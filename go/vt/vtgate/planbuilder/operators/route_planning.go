@@ -434,16 +434,15 @@ func mergeOrJoin(ctx *plancontext.PlanningContext, lhs, rhs ops.Operator, joinPr
 	}
 
 	if len(joinPredicates) > 0 && requiresSwitchingSides(ctx, rhs) {
-		if !inner {
-			return nil, vterrors.VT12001("LEFT JOIN with derived tables")
+		if inner && !requiresSwitchingSides(ctx, lhs) {
+			join := NewApplyJoin(Clone(rhs), Clone(lhs), nil, false)
+			return pushJoinPredicates(ctx, joinPredicates, join)
 		}
 
-		if requiresSwitchingSides(ctx, lhs) {
-			return nil, vterrors.VT12001("JOIN between derived tables")
-		}
-
-		join := NewApplyJoin(Clone(rhs), Clone(lhs), nil, !inner)
-		return pushJoinPredicates(ctx, joinPredicates, join)
+		// the sides of an outer join can't be switched, and a join between derived tables
+		// has no side that could take the predicates, so they are evaluated on top of the rhs
+		join := NewApplyJoin(Clone(lhs), Clone(rhs), nil, !inner)
+		return pushJoinPredicatesOnTop(ctx, joinPredicates, join)
 	}
 
 	join := NewApplyJoin(Clone(lhs), Clone(rhs), nil, !inner)
@@ -683,3 +682,14 @@ func pushJoinPredicates(ctx *plancontext.PlanningContext, exprs []sqlparser.Expr
 
 	return op, nil
 }
+
+func pushJoinPredicatesOnTop(ctx *plancontext.PlanningContext, exprs []sqlparser.Expr, op *ApplyJoin) (ops.Operator, error) {
+	for _, expr := range exprs {
+		err := op.addJoinPredicateOnTop(ctx, expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return op, nil
+}
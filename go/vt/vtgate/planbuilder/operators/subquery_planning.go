@@ -17,6 +17,8 @@ limitations under the License.
 package operators
 
 import (
+	"golang.org/x/exp/slices"
+
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
@@ -61,16 +63,11 @@ func optimizeSubQuery(ctx *plancontext.PlanningContext, op *SubQuery, ts semanti
 			continue
 		}
 
-		if inner.ExtractedSubquery.OpCode == int(engine.PulloutExists) {
-			correlatedTree, err := createCorrelatedSubqueryOp(ctx, innerOp, outer, preds, inner.ExtractedSubquery)
-			if err != nil {
-				return nil, rewrite.SameTree, err
-			}
-			outer = correlatedTree
-			continue
+		correlatedTree, err := createCorrelatedSubqueryOp(ctx, innerOp, outer, preds, inner.ExtractedSubquery)
+		if err != nil {
+			return nil, rewrite.SameTree, err
 		}
-
-		return nil, rewrite.SameTree, vterrors.VT12001("cross-shard correlated subquery")
+		outer = correlatedTree
 	}
 
 	for _, tree := range unmerged {
@@ -384,9 +381,43 @@ func createCorrelatedSubqueryOp(
 	preds []sqlparser.Expr,
 	extractedSubquery *sqlparser.ExtractedSubquery,
 ) (*CorrelatedSubQueryOp, error) {
-	newOuter, err := RemovePredicate(ctx, extractedSubquery, outerOp)
-	if err != nil {
-		return nil, vterrors.VT12001("EXISTS sub-queries are only supported with AND clause")
+	result := &CorrelatedSubQueryOp{Extracted: extractedSubquery}
+	predicate := findSubqueryPredicate(outerOp, extractedSubquery)
+	switch {
+	case predicate == nil:
+		// the subquery is not used to filter the rows of the outer query,
+		// and has to be evaluated for every one of them
+		if !isInSelectExprs(ctx, extractedSubquery) {
+			return nil, vterrors.VT12001("cross-shard correlated subquery outside of the WHERE clause and the SELECT list")
+		}
+		result.Pullout = true
+	case predicate == sqlparser.Expr(extractedSubquery) && extractedSubquery.OpCode == int(engine.PulloutExists):
+		// EXISTS is planned as a semi-join
+	case isNotExists(predicate, extractedSubquery):
+		// and NOT EXISTS as an anti-join
+		result.AntiJoin = true
+	default:
+		result.Pullout = true
+		result.Predicate = predicate
+	}
+
+	newOuter := outerOp
+	if predicate != nil {
+		var err error
+		newOuter, err = RemovePredicate(ctx, predicate, outerOp)
+		if err != nil {
+			return nil, err
+		}
+		if err := forgetRoutingPredicate(ctx, newOuter, predicate); err != nil {
+			return nil, err
+		}
+	}
+
+	switch innerOp.(type) {
+	case *SubQueryOp, *CorrelatedSubQueryOp:
+		if len(preds) > 0 {
+			return nil, vterrors.VT12001("nested cross-shard correlated subqueries")
+		}
 	}
 
 	resultOuterOp := newOuter
@@ -435,19 +466,105 @@ func createCorrelatedSubqueryOp(
 		if rewriteError != nil {
 			return nil, rewriteError
 		}
+		if semantics.ValidAsMapKey(pred) {
+			// the columns of the outer query are now arguments, so the dependencies that
+			// might have been cached for the predicate have to be updated
+			outerID := TableID(resultOuterOp)
+			ctx.SemTable.Direct[pred] = ctx.SemTable.DirectDeps(pred).Remove(outerID)
+			ctx.SemTable.Recursive[pred] = ctx.SemTable.RecursiveDeps(pred).Remove(outerID)
+		}
 		var err error
 		innerOp, err = innerOp.AddPredicate(ctx, pred)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return &CorrelatedSubQueryOp{
-		Outer:      resultOuterOp,
-		Inner:      innerOp,
-		Extracted:  extractedSubquery,
-		Vars:       vars,
-		LHSColumns: lhsCols,
-	}, nil
+	result.Outer = resultOuterOp
+	result.Inner = innerOp
+	result.Vars = vars
+	result.LHSColumns = lhsCols
+	return result, nil
+}
+
+// findSubqueryPredicate returns the predicate of the operator that uses the given subquery, if any
+func findSubqueryPredicate(op ops.Operator, subq *sqlparser.ExtractedSubquery) sqlparser.Expr {
+	var predicate sqlparser.Expr
+	_ = rewrite.Visit(op, func(op ops.Operator) error {
+		var exprs []sqlparser.Expr
+		switch op := op.(type) {
+		case *Filter:
+			exprs = op.Predicates
+		case *ApplyJoin:
+			if !op.LeftJoin {
+				// the ON condition of an outer join does not filter the rows of the outer query
+				exprs = sqlparser.SplitAndExpression(nil, op.Predicate)
+			}
+		case *Table:
+			exprs = op.QTable.Predicates
+		}
+		for _, expr := range exprs {
+			if predicate == nil && containsSubquery(expr, subq) {
+				predicate = expr
+			}
+		}
+		return nil
+	})
+	return predicate
+}
+
+// isInSelectExprs returns true if the subquery is used in the SELECT list of its outer query
+func isInSelectExprs(ctx *plancontext.PlanningContext, subq *sqlparser.ExtractedSubquery) bool {
+	for stmt, subqueries := range ctx.SemTable.SubqueryMap {
+		sel, ok := stmt.(*sqlparser.Select)
+		if !ok || !slices.Contains(subqueries, subq) {
+			continue
+		}
+		for _, selectExpr := range sel.SelectExprs {
+			ae, ok := selectExpr.(*sqlparser.AliasedExpr)
+			if ok && containsSubquery(ae.Expr, subq) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsSubquery(node sqlparser.SQLNode, subq *sqlparser.ExtractedSubquery) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if node == sqlparser.SQLNode(subq) {
+			found = true
+		}
+		return !found, nil
+	}, node)
+	return found
+}
+
+func isNotExists(predicate sqlparser.Expr, subq *sqlparser.ExtractedSubquery) bool {
+	not, ok := predicate.(*sqlparser.NotExpr)
+	return ok && not.Expr == sqlparser.Expr(subq) && subq.OpCode == int(engine.PulloutExists)
+}
+
+// forgetRoutingPredicate removes a predicate that was removed from the operator from the
+// predicates that the sharded routes below it used to decide their routing.
+func forgetRoutingPredicate(ctx *plancontext.PlanningContext, op ops.Operator, predicate sqlparser.Expr) error {
+	return rewrite.Visit(op, func(op ops.Operator) error {
+		route, ok := op.(*Route)
+		if !ok {
+			return nil
+		}
+		routing, ok := route.Routing.(*ShardedRouting)
+		if !ok {
+			return nil
+		}
+		for i, seen := range routing.SeenPredicates {
+			if ctx.SemTable.EqualsExpr(seen, predicate) {
+				routing.SeenPredicates = append(routing.SeenPredicates[:i], routing.SeenPredicates[i+1:]...)
+				return routing.resetRoutingSelections(ctx)
+			}
+		}
+		return nil
+	})
 }
 
 // canMergeSubqueryOnColumnSelection will return true if the predicate used allows us to merge the two subqueries
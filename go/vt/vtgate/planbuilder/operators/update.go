@@ -32,6 +32,10 @@ type Update struct {
 	OwnedVindexQuery    string
 	AST                 *sqlparser.Update
 
+	// RowsToChange selects the rows a DML with a LIMIT changes when it targets several shards.
+	// The AST then only changes the rows whose primary keys are bound on each shard.
+	RowsToChange *sqlparser.Select
+
 	noInputs
 	noColumns
 	noPredicates
@@ -56,6 +60,7 @@ func (u *Update) Clone(inputs []ops.Operator) ops.Operator {
 		ChangedVindexValues: u.ChangedVindexValues,
 		OwnedVindexQuery:    u.OwnedVindexQuery,
 		AST:                 u.AST,
+		RowsToChange:        u.RowsToChange,
 	}
 }
 
@@ -0,0 +1,197 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// WindowFunc encodes all information needed to evaluate a window function at the vtgate level
+type WindowFunc struct {
+	Original *sqlparser.AliasedExpr
+	Func     sqlparser.WindowFunc
+	OpCode   engine.WindowOpcode
+
+	// Arg is the expression the window function is evaluated over. It is nil for ranking functions.
+	Arg sqlparser.Expr
+
+	// Offset and Default are the N and default arguments of LAG and LEAD
+	Offset  int
+	Default sqlparser.Expr
+
+	// Index is the position of the window function in the select list
+	Index int
+}
+
+// PartitionedByUniqueVindex returns true when the PARTITION BY clause of every window function
+// in the query contains an expression that satisfies isUniqueVindex.
+// When this is the case, every partition lives on a single shard and the window
+// functions can be evaluated by MySQL on each shard.
+func (qp *QueryProjection) PartitionedByUniqueVindex(sel *sqlparser.Select, isUniqueVindex func(sqlparser.Expr) bool) bool {
+	if !qp.HasWindowFunc {
+		return true
+	}
+	allPartitioned := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
+		if _, isSubq := node.(*sqlparser.Subquery); isSubq {
+			return false, nil
+		}
+		if !sqlparser.IsWindowFunc(node) {
+			return true, nil
+		}
+		spec := windowSpecFor(sel, node.(sqlparser.WindowFunc).GetOverClause())
+		if spec == nil || !anyExpr(spec.PartitionClause, isUniqueVindex) {
+			allPartitioned = false
+			return false, nil
+		}
+		return true, nil
+	}, sel.SelectExprs, sel.OrderBy)
+	return allPartitioned
+}
+
+// WindowFunctions returns the window functions of the select list, and the window
+// specification they share. It fails when the window functions cannot be evaluated at the vtgate level.
+func (qp *QueryProjection) WindowFunctions(ctx *plancontext.PlanningContext, sel *sqlparser.Select) ([]WindowFunc, *sqlparser.WindowSpecification, error) {
+	var out []WindowFunc
+	var spec *sqlparser.WindowSpecification
+	for idx, expr := range qp.SelectExprs {
+		aliasedExpr, err := expr.GetAliasedExpr()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !sqlparser.ContainsWindowFunc(aliasedExpr.Expr) {
+			continue
+		}
+		fnc, isWindowFunc := aliasedExpr.Expr.(sqlparser.WindowFunc)
+		if !isWindowFunc || !sqlparser.IsWindowFunc(fnc) {
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("in scatter query: complex window function expression: %s", sqlparser.String(aliasedExpr.Expr)))
+		}
+
+		fspec := windowSpecFor(sel, fnc.GetOverClause())
+		switch {
+		case fspec == nil:
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("in scatter query: window specification in: %s", sqlparser.String(fnc)))
+		case fspec.FrameClause != nil:
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("in scatter query: window frame clause in: %s", sqlparser.String(fnc)))
+		case spec == nil:
+			spec = fspec
+		case !ctx.SemTable.ASTEquals().Exprs(spec.PartitionClause, fspec.PartitionClause) ||
+			!ctx.SemTable.ASTEquals().OrderBy(spec.OrderClause, fspec.OrderClause):
+			return nil, nil, vterrors.VT12001("in scatter query: window functions using different windows")
+		}
+
+		wf, err := createWindowFunc(fnc)
+		if err != nil {
+			return nil, nil, err
+		}
+		wf.Original = aliasedExpr
+		wf.Index = idx
+		out = append(out, wf)
+	}
+	return out, spec, nil
+}
+
+func createWindowFunc(fnc sqlparser.WindowFunc) (WindowFunc, error) {
+	unsupported := func() (WindowFunc, error) {
+		return WindowFunc{}, vterrors.VT12001(fmt.Sprintf("in scatter query: window function '%s'", sqlparser.String(fnc)))
+	}
+	wf := WindowFunc{Func: fnc}
+	switch fnc := fnc.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		switch fnc.Type {
+		case sqlparser.RowNumberExprType:
+			wf.OpCode = engine.WindowRowNumber
+		case sqlparser.RankExprType:
+			wf.OpCode = engine.WindowRank
+		case sqlparser.DenseRankExprType:
+			wf.OpCode = engine.WindowDenseRank
+		default:
+			return unsupported()
+		}
+	case *sqlparser.LagLeadExpr:
+		wf.OpCode = engine.WindowLag
+		if fnc.Type == sqlparser.LeadExprType {
+			wf.OpCode = engine.WindowLead
+		}
+		wf.Arg = fnc.Expr
+		wf.Offset = 1
+		if fnc.N != nil {
+			lit, ok := fnc.N.(*sqlparser.Literal)
+			if !ok || lit.Type != sqlparser.IntVal {
+				return unsupported()
+			}
+			n, err := strconv.Atoi(lit.Val)
+			if err != nil {
+				return unsupported()
+			}
+			wf.Offset = n
+		}
+		wf.Default = fnc.Default
+	case *sqlparser.Sum:
+		if fnc.Distinct {
+			return unsupported()
+		}
+		wf.OpCode = engine.WindowSum
+		wf.Arg = fnc.Arg
+	case *sqlparser.Count:
+		if fnc.Distinct || len(fnc.Args) != 1 {
+			return unsupported()
+		}
+		wf.OpCode = engine.WindowCount
+		wf.Arg = fnc.Args[0]
+	case *sqlparser.CountStar:
+		wf.OpCode = engine.WindowCountStar
+	default:
+		return unsupported()
+	}
+	return wf, nil
+}
+
+// windowSpecFor returns the window specification used by an OVER clause,
+// resolving named windows from the WINDOW clause of the query.
+func windowSpecFor(sel *sqlparser.Select, over *sqlparser.OverClause) *sqlparser.WindowSpecification {
+	if over.WindowSpec != nil {
+		if over.WindowSpec.Name.IsEmpty() {
+			return over.WindowSpec
+		}
+		// windows that extend a named window are not resolved
+		return nil
+	}
+	for _, named := range sel.Windows {
+		for _, def := range named.Windows {
+			if def.Name.Equal(over.WindowName) && def.WindowSpec.Name.IsEmpty() {
+				return def.WindowSpec
+			}
+		}
+	}
+	return nil
+}
+
+func anyExpr(exprs sqlparser.Exprs, f func(sqlparser.Expr) bool) bool {
+	for _, expr := range exprs {
+		if f(expr) {
+			return true
+		}
+	}
+	return false
+}
@@ -233,6 +233,11 @@ func (oa *orderedAggregate) Primitive() engine.Primitive {
 		if key.CollationID != collations.Unknown {
 			colls[key.KeyCol] = key.CollationID
 		}
+		for _, extra := range key.ExtraKeys {
+			if extra.CollationID != collations.Unknown {
+				colls[extra.KeyCol] = extra.CollationID
+			}
+		}
 	}
 	for _, key := range oa.groupByKeys {
 		if key.CollationID != collations.Unknown {
@@ -267,6 +272,9 @@ func (oa *orderedAggregate) pushAggr(pb *primitiveBuilder, expr *sqlparser.Alias
 	aggrFunc, _ := expr.Expr.(sqlparser.AggrFunc)
 	origOpcode := engine.SupportedAggregates[strings.ToLower(aggrFunc.AggrName())]
 	opcode := origOpcode
+	if origOpcode == engine.AggregateGroupConcat {
+		return nil, 0, vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", aggrFunc.AggrName()))
+	}
 	if aggrFunc.GetArgs() != nil &&
 		len(aggrFunc.GetArgs()) != 1 {
 		return nil, 0, vterrors.VT12001(fmt.Sprintf("only one expression is allowed inside aggregates: %s", sqlparser.String(expr)))
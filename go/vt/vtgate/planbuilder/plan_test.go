@@ -254,6 +254,9 @@ func TestPlan(t *testing.T) {
 	testFile(t, "info_schema80_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "reference_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "vexplain_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "window_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "cte_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "hash_join_cases.json", testOutputTempDir, vschemaWrapper, false)
 }
 
 func TestSystemTables57(t *testing.T) {
@@ -283,6 +286,51 @@ func TestViews(t *testing.T) {
 	testFile(t, "view_cases.json", makeTestOutput(t), vschemaWrapper, false)
 }
 
+func TestForeignKeyPlanning(t *testing.T) {
+	vschema := loadSchema(t, "vschemas/schema.json", true)
+	addTestForeignKeys(t, vschema)
+	vschemaWrapper := &vschemaWrapper{
+		v:      vschema,
+		fkMode: "managed",
+	}
+
+	testFile(t, "foreignkey_cases.json", makeTestOutput(t), vschemaWrapper, false)
+}
+
+// addTestForeignKeys adds foreign keys like the schema tracker would find them.
+func addTestForeignKeys(t testing.TB, vschema *vindexes.VSchema) {
+	fks := []struct {
+		ks, child, childCol, parent, parentCol string
+		onDelete, onUpdate                     sqlparser.ReferenceAction
+	}{
+		// shard scoped
+		{"user", "user_extra", "user_id", "user", "id", sqlparser.Cascade, sqlparser.Cascade},
+		// cross shard
+		{"user", "music_extra", "music_id", "music", "id", sqlparser.Cascade, sqlparser.Cascade},
+		// sharded parent, unsharded children
+		{"main", "unsharded_a", "col", "user", "col", sqlparser.Cascade, sqlparser.Cascade},
+		{"main", "unsharded_b", "col", "user", "col", sqlparser.Restrict, sqlparser.SetNull},
+		// unsharded parent, sharded child
+		{"user", "user_metadata", "user_id", "unsharded", "id", sqlparser.Cascade, sqlparser.NoAction},
+		// MySQL cascades into a table with a child in another keyspace
+		{"main", "unsharded_authoritative", "col1", "unsharded_auto", "id", sqlparser.Cascade, sqlparser.Cascade},
+		// cycle across keyspaces
+		{"main_2", "unsharded_tab", "col", "unsharded_authoritative", "col1", sqlparser.Cascade, sqlparser.Cascade},
+		{"main", "unsharded_authoritative", "col2", "unsharded_tab", "col", sqlparser.Cascade, sqlparser.Cascade},
+	}
+	for _, fk := range fks {
+		err := vschema.AddForeignKey(fk.ks, fk.child, vindexes.ForeignKey{
+			Name:          fmt.Sprintf("fk_%s_%s", fk.child, fk.parent),
+			ChildColumns:  sqlparser.Columns{sqlparser.NewIdentifierCI(fk.childCol)},
+			ParentTable:   fk.parent,
+			ParentColumns: sqlparser.Columns{sqlparser.NewIdentifierCI(fk.parentCol)},
+			OnDelete:      fk.onDelete,
+			OnUpdate:      fk.onUpdate,
+		})
+		require.NoError(t, err)
+	}
+}
+
 func TestOne(t *testing.T) {
 	vschema := &vschemaWrapper{
 		v: loadSchema(t, "vschemas/schema.json", true),
@@ -534,6 +582,13 @@ func loadSchema(t testing.TB, filename string, setCollation bool) *vindexes.VSch
 				"select user.id, user_extra.col from user join user_extra on user.id = user_extra.user_id"); err != nil {
 				t.Fatal(err)
 			}
+
+			// setting the primary keys that the schema tracking would find for these tables
+			for tblName, pk := range map[string]string{"user": "id", "user_extra": "extra_id"} {
+				if tbl := ks.Tables[tblName]; tbl != nil {
+					tbl.PrimaryKey = sqlparser.Columns{sqlparser.NewIdentifierCI(pk)}
+				}
+			}
 		}
 
 		// setting a default value to all the text columns in the tables of this keyspace
@@ -562,6 +617,7 @@ type vschemaWrapper struct {
 	sysVarEnabled bool
 	version       plancontext.PlannerVersion
 	enableViews   bool
+	fkMode        string
 }
 
 func (vw *vschemaWrapper) IsShardRoutingEnabled() bool {
@@ -594,7 +650,10 @@ func (vw *vschemaWrapper) PlannerWarning(_ string) {
 }
 
 func (vw *vschemaWrapper) ForeignKeyMode() string {
-	return "allow"
+	if vw.fkMode == "" {
+		return "allow"
+	}
+	return vw.fkMode
 }
 
 func (vw *vschemaWrapper) AllKeyspace() ([]*vindexes.Keyspace, error) {
@@ -35,6 +35,12 @@ type PlanningContext struct {
 	SkipPredicates     map[sqlparser.Expr]any
 	PlannerVersion     querypb.ExecuteOptions_PlannerVersion
 	RewriteDerivedExpr bool
+
+	// AllowHashJoins is set by the ALLOW_HASH_JOIN directive, and plans every join that can be a hash join
+	// as one, even when a nested loop join is estimated to be cheaper.
+	AllowHashJoins bool
+	// NoHashJoins is set while planning joins that are under a horizon that hash joins do not support.
+	NoHashJoins bool
 }
 
 func NewPlanningContext(reservedVars *sqlparser.ReservedVars, semTable *semantics.SemTable, vschema VSchema, version querypb.ExecuteOptions_PlannerVersion) *PlanningContext {
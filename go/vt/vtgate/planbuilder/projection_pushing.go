@@ -22,6 +22,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
@@ -35,7 +36,21 @@ func pushProjection(
 	inner, reuseCol, hasAggregation bool,
 ) (offset int, added bool, err error) {
 	switch node := plan.(type) {
-	case *limit, *projection, *pulloutSubquery, *distinct, *filter:
+	case *distinct:
+		if ws, isWS := expr.Expr.(*sqlparser.WeightStringFuncExpr); isWS {
+			// the weight strings used to dedup the rows can be reused
+			offset, added, err := pushProjection(ctx, &sqlparser.AliasedExpr{Expr: ws.Expr}, node.input, inner, true, hasAggregation)
+			if err != nil {
+				return 0, false, err
+			}
+			for _, col := range node.checkCols {
+				if !added && col.Col == offset && col.WsCol != nil {
+					return *col.WsCol, false, nil
+				}
+			}
+		}
+		return pushProjection(ctx, expr, node.input, inner, reuseCol, hasAggregation)
+	case *limit, *projection, *pulloutSubquery, *filter:
 		// All of these either push to the single source, or push to the LHS
 		src := node.Inputs()[0]
 		return pushProjection(ctx, expr, src, inner, reuseCol, hasAggregation)
@@ -49,10 +64,14 @@ func pushProjection(
 		return pushProjectionIntoSimpleProj(ctx, expr, node, inner, hasAggregation, reuseCol)
 	case *orderedAggregate:
 		return pushProjectionIntoOA(ctx, expr, node, inner, hasAggregation)
+	case *window:
+		return pushProjectionIntoWindow(ctx, expr, node, inner, hasAggregation)
 	case *vindexFunc:
 		return pushProjectionIntoVindexFunc(node, expr, reuseCol)
 	case *semiJoin:
 		return pushProjectionIntoSemiJoin(ctx, expr, reuseCol, node, inner, hasAggregation)
+	case *correlatedSubquery:
+		return pushProjectionIntoCorrelatedSubquery(ctx, expr, reuseCol, node, inner, hasAggregation)
 	case *concatenateGen4:
 		return pushProjectionIntoConcatenate(ctx, expr, hasAggregation, node, inner, reuseCol)
 	default:
@@ -73,6 +92,18 @@ func pushProjectionIntoConcatenate(ctx *plancontext.PlanningContext, expr *sqlpa
 	if hasAggregation {
 		return 0, false, vterrors.VT12001("aggregation on UNIONs")
 	}
+	if ws, isWS := expr.Expr.(*sqlparser.WeightStringFuncExpr); isWS {
+		// weight strings of the columns of the UNION have to be fetched from all the sources
+		offset, added, err := pushProjection(ctx, &sqlparser.AliasedExpr{Expr: ws.Expr}, node.sources[0], inner, true, hasAggregation)
+		if err != nil {
+			return 0, false, err
+		}
+		if !added {
+			wsOffset, err := pushWeightStringForDistinct(ctx, node, offset)
+			return wsOffset, true, err
+		}
+		return 0, false, vterrors.VT13001(fmt.Sprintf("pushing projection %v on concatenate should reference an existing column", sqlparser.String(expr)))
+	}
 	offset, added, err := pushProjection(ctx, expr, node.sources[0], inner, reuseCol, hasAggregation)
 	if err != nil {
 		return 0, false, err
@@ -118,6 +149,61 @@ func pushProjectionIntoSemiJoin(
 	return len(node.cols) - 1, true, nil
 }
 
+func pushProjectionIntoCorrelatedSubquery(
+	ctx *plancontext.PlanningContext,
+	expr *sqlparser.AliasedExpr,
+	reuseCol bool,
+	node *correlatedSubquery,
+	inner, hasAggregation bool,
+) (int, bool, error) {
+	if !node.usesSubquery(expr.Expr) {
+		passDownReuseCol := reuseCol
+		if !reuseCol {
+			passDownReuseCol = expr.As.IsEmpty()
+		}
+		offset, added, err := pushProjection(ctx, expr, node.outer, inner, passDownReuseCol, hasAggregation)
+		if err != nil {
+			return 0, false, err
+		}
+		column := -(offset + 1)
+		if reuseCol && !added {
+			for idx, col := range node.cols {
+				if column == col {
+					return idx, false, nil
+				}
+			}
+		}
+		node.cols = append(node.cols, column)
+		return len(node.cols) - 1, true, nil
+	}
+
+	if hasAggregation {
+		return 0, false, vterrors.VT12001("aggregation on the result of a cross-shard correlated subquery")
+	}
+	if reuseCol {
+		next := 0
+		for idx, col := range node.cols {
+			if col != 0 {
+				continue
+			}
+			if ctx.SemTable.EqualsExpr(node.columns[next].Expr, expr.Expr) {
+				return idx, false, nil
+			}
+			next++
+		}
+	}
+	// the expression is evaluated by vtgate on the rows of the outer query,
+	// once the result of the subquery for the row is known
+	evalExpr, err := evalengine.Translate(replaceExtractedSubqueries(expr.Expr), node.outerLookup(ctx))
+	if err != nil {
+		return 0, false, err
+	}
+	node.cols = append(node.cols, 0)
+	node.columns = append(node.columns, expr)
+	node.exprs = append(node.exprs, evalExpr)
+	return len(node.cols) - 1, true, nil
+}
+
 func pushProjectionIntoOA(ctx *plancontext.PlanningContext, expr *sqlparser.AliasedExpr, node *orderedAggregate, inner, hasAggregation bool) (int, bool, error) {
 	colName, isColName := expr.Expr.(*sqlparser.ColName)
 	for _, aggregate := range node.aggregates {
@@ -279,7 +365,29 @@ func pushProjectionIntoHashJoin(
 		if hasAggregation {
 			return 0, false, vterrors.VT12001("cross-shard query with aggregates")
 		}
-		return 0, false, vterrors.VT12001("hash join with projection from both sides of the join")
+		if reuseCol {
+			for idx, col := range node.Columns {
+				if ctx.SemTable.EqualsExpr(col.Expr, expr.Expr) {
+					return outputOffsetOfHashJoinExpr(node, idx), false, nil
+				}
+			}
+		}
+		// the expression is evaluated by vtgate, on the columns it needs from both sides,
+		// which are added to the output of the join after the expression itself
+		offset := len(node.Cols)
+		node.Cols = append(node.Cols, 0)
+		node.Columns = append(node.Columns, expr)
+		scl := &simpleConverterLookup{
+			canPushProjection: true,
+			ctx:               ctx,
+			plan:              node,
+		}
+		evalExpr, err := evalengine.Translate(expr.Expr, scl)
+		if err != nil {
+			return 0, false, err
+		}
+		node.Exprs = append(node.Exprs, evalExpr)
+		return offset, true, nil
 	}
 	if reuseCol && !appended {
 		for idx, col := range node.Cols {
@@ -294,6 +402,20 @@ func pushProjectionIntoHashJoin(
 	return len(node.Cols) - 1, true, nil
 }
 
+// outputOffsetOfHashJoinExpr returns the offset of the output column of the expression at index idx of the hash join.
+func outputOffsetOfHashJoinExpr(node *hashJoin, idx int) int {
+	for offset, col := range node.Cols {
+		if col != 0 {
+			continue
+		}
+		if idx == 0 {
+			return offset
+		}
+		idx--
+	}
+	return -1
+}
+
 func addExpressionToRoute(ctx *plancontext.PlanningContext, rb *routeGen4, expr *sqlparser.AliasedExpr, reuseCol bool) (int, bool, error) {
 	if reuseCol {
 		if i := checkIfAlreadyExists(expr, rb.Select, ctx.SemTable); i != -1 {
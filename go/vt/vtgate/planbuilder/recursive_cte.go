@@ -0,0 +1,607 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"vitess.io/vitess/go/mysql/collations"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// findRecursiveCTE returns the index of the common table expression that references itself,
+// or -1 if the statement has no recursive CTE
+func findRecursiveCTE(stmt sqlparser.SelectStatement) int {
+	var with *sqlparser.With
+	switch node := stmt.(type) {
+	case *sqlparser.Select:
+		with = node.With
+	case *sqlparser.Union:
+		with = node.With
+	}
+	if with == nil || !with.Recursive {
+		return -1
+	}
+	for i, cte := range with.CTEs {
+		if cteReferencesTable(cte.Subquery, cte.ID.String()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// gen4RecursiveCTEPlanner plans a query using a recursive common table expression.
+// When everything lives in a single unsharded keyspace, the whole query is sent to it.
+// Otherwise, the seed and the recursive term of the CTE are planned as two separate queries
+// and the recursion is done by the RecurseCTE primitive: the term is executed once per row of
+// the previous iteration, with the columns of the CTE replaced by bind variables.
+// The query using the CTE is then evaluated at the vtgate level on top of it.
+func gen4RecursiveCTEPlanner(
+	plannerVersion querypb.ExecuteOptions_PlannerVersion,
+	stmt sqlparser.SelectStatement,
+	idx int,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*planResult, error) {
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, vterrors.VT12001("WITH RECURSIVE in UNION statement")
+	}
+	with := sel.With
+	cte := with.CTEs[idx]
+	name := cte.ID.String()
+	for _, other := range with.CTEs[idx+1:] {
+		if cteReferencesTable(other.Subquery, other.ID.String()) {
+			return nil, vterrors.VT12001("more than one recursive common table expression")
+		}
+		if cteReferencesTable(other.Subquery, name) {
+			return nil, vterrors.VT12001(fmt.Sprintf("reference to the recursive common table expression %s from another common table expression", name))
+		}
+	}
+
+	if plan := unshardedRecursiveCTE(sel, vschema); plan != nil {
+		return plan, nil
+	}
+
+	body, ok := cte.Subquery.Select.(*sqlparser.Union)
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Recursive Common Table Expression '%s' should contain a UNION", name)
+	}
+	if len(body.OrderBy) > 0 || body.Limit != nil {
+		return nil, vterrors.VT12001("ORDER BY or LIMIT in a recursive common table expression")
+	}
+	term, ok := body.Right.(*sqlparser.Select)
+	if !ok || cteReferencesTable(body.Left, name) {
+		return nil, vterrors.VT12001("recursive common table expression with more than one recursive query block")
+	}
+
+	columns, err := recursiveCTEColumns(cte, body.Left)
+	if err != nil {
+		return nil, err
+	}
+	if !containsStarExpr(term.SelectExprs) && len(term.SelectExprs) != len(columns) {
+		return nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.WrongNumberOfColumnsInSelect, "The used SELECT statements have a different number of columns")
+	}
+
+	termStmt, vars, err := rewriteRecursiveTerm(term, name, columns, reservedVars)
+	if err != nil {
+		return nil, err
+	}
+	seedStmt := sqlparser.CloneSelectStatement(body.Left)
+	if idx > 0 {
+		// the seed and the term can use the CTEs defined before the recursive one
+		seedStmt.SetWith(&sqlparser.With{CTEs: sqlparser.CloneSliceOfRefOfCommonTableExpr(with.CTEs[:idx])})
+		termStmt.SetWith(&sqlparser.With{CTEs: sqlparser.CloneSliceOfRefOfCommonTableExpr(with.CTEs[:idx])})
+	}
+
+	seed, err := gen4SelectStmtPlanner("", plannerVersion, seedStmt, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+	recurse, err := gen4SelectStmtPlanner("", plannerVersion, termStmt, reservedVars, vschema)
+	if err != nil {
+		return nil, err
+	}
+
+	prim := &engine.RecurseCTE{
+		Seed: seed.primitive,
+		Term: recurse.primitive,
+		Vars: vars,
+	}
+	if body.Distinct {
+		for i := range columns {
+			prim.CheckCols = append(prim.CheckCols, engine.CheckCol{Col: i, Collation: vschema.ConnCollation()})
+		}
+	}
+
+	primitive, err := planRecursiveCTEOuterQuery(sel, name, columns, prim, vschema.ConnCollation())
+	if err != nil {
+		return nil, err
+	}
+	tablesUsed := seed.tables
+	for _, tbl := range recurse.tables {
+		if !slices.Contains(tablesUsed, tbl) {
+			tablesUsed = append(tablesUsed, tbl)
+		}
+	}
+	return newPlanResult(primitive, tablesUsed...), nil
+}
+
+// unshardedRecursiveCTE returns a plan sending the whole query to a single unsharded keyspace,
+// if all the tables it uses are in that keyspace
+func unshardedRecursiveCTE(sel *sqlparser.Select, vschema plancontext.VSchema) *planResult {
+	ctes := map[string]any{}
+	for _, cte := range sel.With.CTEs {
+		ctes[cte.ID.String()] = nil
+	}
+
+	var (
+		ks     *vindexes.Keyspace
+		tables []string
+	)
+	ok := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			return false, nil
+		case sqlparser.TableName:
+			if _, isCTE := ctes[node.Name.String()]; isCTE && node.Qualifier.IsEmpty() {
+				return false, nil
+			}
+			vtbl, _, _, _, _, err := vschema.FindTableOrVindex(node)
+			if err != nil || vtbl == nil || vtbl.Keyspace == nil || vtbl.Keyspace.Sharded ||
+				vtbl.Name.String() != node.Name.String() || (ks != nil && ks.Name != vtbl.Keyspace.Name) {
+				ok = false
+				return false, nil
+			}
+			ks = vtbl.Keyspace
+			tables = append(tables, vtbl.Name.String())
+			return false, nil
+		}
+		return ok, nil
+	}, sel)
+	if !ok || ks == nil {
+		return nil
+	}
+
+	stmt := sqlparser.CloneRefOfSelect(sel)
+	sqlparser.SafeRewrite(stmt, nil, func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case sqlparser.SelectExpr:
+			removeKeyspaceFromSelectExpr(node)
+		case sqlparser.TableName:
+			cursor.Replace(sqlparser.TableName{
+				Name: node.Name,
+			})
+		}
+		return true
+	})
+
+	buf := sqlparser.NewTrackedBuffer(sqlparser.FormatImpossibleQuery)
+	buf.Myprintf("%v", stmt)
+	route := engine.NewRoute(engine.Unsharded, ks, sqlparser.String(stmt), buf.String())
+
+	sort.Strings(tables)
+	var (
+		tableNames []string
+		used       []string
+	)
+	for i, tbl := range tables {
+		if i > 0 && tables[i-1] == tbl {
+			continue
+		}
+		tableNames = append(tableNames, tbl)
+		used = append(used, ks.Name+"."+tbl)
+	}
+	route.TableName = strings.Join(tableNames, ", ")
+	return newPlanResult(route, used...)
+}
+
+// recursiveCTEColumns returns the column names of the CTE, which come from its
+// column list or from the select expressions of the seed
+func recursiveCTEColumns(cte *sqlparser.CommonTableExpr, seed sqlparser.SelectStatement) ([]string, error) {
+	exprs := sqlparser.GetFirstSelect(seed).SelectExprs
+	if len(cte.Columns) > 0 {
+		if !containsStarExpr(exprs) && len(exprs) != len(cte.Columns) {
+			return nil, semantics.NewError(semantics.CTEColumnCountMismatch)
+		}
+		columns := make([]string, 0, len(cte.Columns))
+		for _, col := range cte.Columns {
+			columns = append(columns, col.String())
+		}
+		return columns, nil
+	}
+
+	columns := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		ae, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, vterrors.VT12001("'*' expression in a recursive common table expression without a column list")
+		}
+		columns = append(columns, ae.ColumnName())
+	}
+	return columns, nil
+}
+
+// rewriteRecursiveTerm removes the reference to the CTE from the recursive term,
+// and replaces the columns of the CTE with bind variables that will be filled in
+// by the RecurseCTE primitive with the rows of the previous iteration
+func rewriteRecursiveTerm(
+	term *sqlparser.Select,
+	name string,
+	columns []string,
+	reservedVars *sqlparser.ReservedVars,
+) (*sqlparser.Select, map[string]int, error) {
+	term = sqlparser.CloneRefOfSelect(term)
+	from, alias, on, err := removeCTEFromTableExprs(term.From, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if alias.IsEmpty() {
+		return nil, nil, vterrors.VT12001(fmt.Sprintf("recursive reference to %s in a subquery or nested join", name))
+	}
+	// unqualified columns can only be resolved to the CTE if there are no other tables
+	onlyCTE := len(from) == 0
+	if onlyCTE {
+		from = sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewIdentifierCS("dual")}}}
+	}
+	term.From = from
+	term.AddWhere(on)
+	if cteReferencesTable(term, name) {
+		return nil, nil, vterrors.VT12001(fmt.Sprintf("recursive reference to %s in a subquery or nested join", name))
+	}
+
+	vars := map[string]int{}
+	argNames := map[int]string{}
+	offsetOf := func(col *sqlparser.ColName) int {
+		for i, column := range columns {
+			if col.Name.EqualString(column) {
+				return i
+			}
+		}
+		return -1
+	}
+	var rewriteErr error
+	sqlparser.SafeRewrite(term, func(node, _ sqlparser.SQLNode) bool {
+		// inside subqueries, only qualified references to the CTE are rewritten
+		_, isSubq := node.(*sqlparser.Subquery)
+		return !isSubq || !onlyCTE
+	}, func(cursor *sqlparser.Cursor) bool {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok {
+			return true
+		}
+		switch {
+		case col.Qualifier.IsEmpty() && onlyCTE:
+		case col.Qualifier.Qualifier.IsEmpty() && col.Qualifier.Name.String() == alias.String():
+		default:
+			return true
+		}
+		offset := offsetOf(col)
+		if offset < 0 {
+			rewriteErr = vterrors.VT03019(sqlparser.String(col))
+			return false
+		}
+		argName, found := argNames[offset]
+		if !found {
+			argName = reservedVars.ReserveColName(col)
+			argNames[offset] = argName
+			vars[argName] = offset
+		}
+		cursor.Replace(sqlparser.NewArgument(argName))
+		return true
+	})
+	if rewriteErr != nil {
+		return nil, nil, rewriteErr
+	}
+	return term, vars, nil
+}
+
+// removeCTEFromTableExprs removes the reference to the CTE from a FROM clause.
+// If the CTE was joined, the join condition is returned so it can be added to the WHERE clause
+func removeCTEFromTableExprs(exprs sqlparser.TableExprs, name string) (sqlparser.TableExprs, sqlparser.IdentifierCS, sqlparser.Expr, error) {
+	var (
+		result sqlparser.TableExprs
+		alias  sqlparser.IdentifierCS
+		on     sqlparser.Expr
+	)
+	found := func(a sqlparser.IdentifierCS) error {
+		if !alias.IsEmpty() {
+			return vterrors.VT12001(fmt.Sprintf("more than one recursive reference to %s", name))
+		}
+		alias = a
+		return nil
+	}
+	for _, expr := range exprs {
+		if a, ok := cteAlias(expr, name); ok {
+			if err := found(a); err != nil {
+				return nil, alias, nil, err
+			}
+			continue
+		}
+		join, ok := expr.(*sqlparser.JoinTableExpr)
+		if !ok {
+			result = append(result, expr)
+			continue
+		}
+		var other sqlparser.TableExpr
+		a, isLeft := cteAlias(join.LeftExpr, name)
+		if isLeft {
+			other = join.RightExpr
+		} else if a, ok = cteAlias(join.RightExpr, name); ok {
+			other = join.LeftExpr
+		} else {
+			result = append(result, expr)
+			continue
+		}
+		if join.Join != sqlparser.NormalJoinType && join.Join != sqlparser.StraightJoinType {
+			return nil, alias, nil, vterrors.VT12001(fmt.Sprintf("%s with the recursive reference to %s", join.Join.ToString(), name))
+		}
+		if join.Condition != nil && len(join.Condition.Using) > 0 {
+			return nil, alias, nil, vterrors.VT12001(fmt.Sprintf("JOIN with USING on the recursive reference to %s", name))
+		}
+		if err := found(a); err != nil {
+			return nil, alias, nil, err
+		}
+		if join.Condition != nil {
+			on = join.Condition.On
+		}
+		result = append(result, other)
+	}
+	return result, alias, on, nil
+}
+
+func cteAlias(expr sqlparser.TableExpr, name string) (sqlparser.IdentifierCS, bool) {
+	ate, ok := expr.(*sqlparser.AliasedTableExpr)
+	if !ok {
+		return sqlparser.IdentifierCS{}, false
+	}
+	tbl, ok := ate.Expr.(sqlparser.TableName)
+	if !ok || !tbl.Qualifier.IsEmpty() || tbl.Name.String() != name {
+		return sqlparser.IdentifierCS{}, false
+	}
+	if !ate.As.IsEmpty() {
+		return ate.As, true
+	}
+	return tbl.Name, true
+}
+
+// planRecursiveCTEOuterQuery plans the query reading from the recursive CTE on top of
+// the RecurseCTE primitive. Since the rows of the CTE only exist at the vtgate level,
+// the query can only read from the CTE, and filtering, ordering, projection and limits
+// are all evaluated by vtgate.
+func planRecursiveCTEOuterQuery(
+	sel *sqlparser.Select,
+	name string,
+	columns []string,
+	input engine.Primitive,
+	collation collations.ID,
+) (engine.Primitive, error) {
+	var alias sqlparser.IdentifierCS
+	ok := len(sel.From) == 1
+	if ok {
+		alias, ok = cteAlias(sel.From[0], name)
+	}
+	if !ok {
+		return nil, vterrors.VT12001(fmt.Sprintf("joining the recursive common table expression %s with other tables", name))
+	}
+	if cols := sel.From[0].(*sqlparser.AliasedTableExpr).Columns; len(cols) > 0 {
+		if len(cols) != len(columns) {
+			return nil, semantics.NewError(semantics.CTEColumnCountMismatch)
+		}
+		columns = make([]string, 0, len(cols))
+		for _, col := range cols {
+			columns = append(columns, col.String())
+		}
+	}
+	switch {
+	case sel.Distinct:
+		return nil, vterrors.VT12001("DISTINCT on a recursive common table expression")
+	case len(sel.GroupBy) > 0 || sel.Having != nil || sqlparser.ContainsAggregation(sel.SelectExprs):
+		return nil, vterrors.VT12001("aggregation on a recursive common table expression")
+	case containsWindowFunction(sel.SelectExprs):
+		return nil, vterrors.VT12001("window function on a recursive common table expression")
+	case sel.Into != nil || sel.Lock != sqlparser.NoLock:
+		return nil, vterrors.VT12001("INTO or locking clause on a recursive common table expression")
+	}
+
+	lookup := &cteColumnLookup{alias: alias, columns: columns, collation: collation}
+	plan := input
+	if sel.Where != nil {
+		predicate, err := evalengine.Translate(sel.Where.Expr, lookup)
+		if err != nil {
+			return nil, err
+		}
+		plan = &engine.Filter{
+			Predicate:    predicate,
+			ASTPredicate: sel.Where.Expr,
+			Input:        plan,
+		}
+	}
+
+	var (
+		cols  []string
+		exprs []sqlparser.Expr
+	)
+	for _, expr := range sel.SelectExprs {
+		switch expr := expr.(type) {
+		case *sqlparser.StarExpr:
+			for _, column := range columns {
+				cols = append(cols, column)
+				exprs = append(exprs, sqlparser.NewColName(column))
+			}
+		case *sqlparser.AliasedExpr:
+			cols = append(cols, expr.ColumnName())
+			exprs = append(exprs, expr.Expr)
+		default:
+			return nil, vterrors.VT13001(fmt.Sprintf("unexpected select expression: %s", sqlparser.String(expr)))
+		}
+	}
+
+	if len(sel.OrderBy) > 0 {
+		ms := &engine.MemorySort{Input: plan}
+		for _, order := range sel.OrderBy {
+			offset, err := recursiveCTEOrderOffset(order.Expr, sel.SelectExprs, cols, exprs, lookup)
+			if err != nil {
+				return nil, err
+			}
+			ms.OrderBy = append(ms.OrderBy, engine.OrderByParams{
+				Col:             offset,
+				WeightStringCol: -1,
+				Desc:            order.Direction == sqlparser.DescOrder,
+				CollationID:     collation,
+			})
+		}
+		plan = ms
+	}
+
+	proj := &engine.Projection{Cols: cols, Input: plan}
+	for _, expr := range exprs {
+		eexpr, err := evalengine.Translate(expr, lookup)
+		if err != nil {
+			return nil, err
+		}
+		proj.Exprs = append(proj.Exprs, eexpr)
+	}
+	plan = proj
+
+	if sel.Limit != nil {
+		limit := &engine.Limit{Input: plan}
+		emptySemTable := semantics.EmptySemTable()
+		count, err := evalengine.Translate(sel.Limit.Rowcount, emptySemTable)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "unexpected expression in LIMIT")
+		}
+		limit.Count = count
+		if sel.Limit.Offset != nil {
+			offset, err := evalengine.Translate(sel.Limit.Offset, emptySemTable)
+			if err != nil {
+				return nil, vterrors.Wrap(err, "unexpected expression in OFFSET")
+			}
+			limit.Offset = offset
+		}
+		plan = limit
+	}
+	return plan, nil
+}
+
+// recursiveCTEOrderOffset returns the offset of the CTE column to order by.
+// Ordering is done before the projection, so it has to be on a column of the CTE,
+// either directly, through the alias of a projected column, or through its position
+func recursiveCTEOrderOffset(
+	expr sqlparser.Expr,
+	selectExprs sqlparser.SelectExprs,
+	cols []string,
+	exprs []sqlparser.Expr,
+	lookup *cteColumnLookup,
+) (int, error) {
+	if lit, ok := expr.(*sqlparser.Literal); ok && lit.Type == sqlparser.IntVal {
+		num, err := strconv.Atoi(lit.Val)
+		if err != nil || num < 1 || num > len(exprs) {
+			return 0, vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.BadFieldError, "Unknown column '%s' in 'order clause'", lit.Val)
+		}
+		expr = exprs[num-1]
+	} else if col, ok := expr.(*sqlparser.ColName); ok && col.Qualifier.IsEmpty() {
+		for i, ae := range selectExprs {
+			if ae, ok := ae.(*sqlparser.AliasedExpr); ok && !ae.As.IsEmpty() && ae.As.Equal(col.Name) {
+				expr = exprs[i]
+				break
+			}
+		}
+	}
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return 0, vterrors.VT12001(fmt.Sprintf("ORDER BY on the expression %s over a recursive common table expression", sqlparser.String(expr)))
+	}
+	return lookup.ColumnLookup(col)
+}
+
+func containsWindowFunction(exprs sqlparser.SelectExprs) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.OverClause); ok {
+			found = true
+		}
+		return !found, nil
+	}, exprs)
+	return found
+}
+
+func containsStarExpr(exprs sqlparser.SelectExprs) bool {
+	for _, expr := range exprs {
+		if _, ok := expr.(*sqlparser.StarExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cteReferencesTable returns true if the node uses the given name as an unqualified table
+func cteReferencesTable(node sqlparser.SQLNode, name string) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			return false, nil
+		case sqlparser.TableName:
+			found = node.Qualifier.IsEmpty() && node.Name.String() == name
+		}
+		return !found, nil
+	}, node)
+	return found
+}
+
+// cteColumnLookup resolves the columns of a recursive CTE to their offsets in the rows of the RecurseCTE primitive
+type cteColumnLookup struct {
+	alias     sqlparser.IdentifierCS
+	columns   []string
+	collation collations.ID
+}
+
+var _ evalengine.TranslationLookup = (*cteColumnLookup)(nil)
+
+func (l *cteColumnLookup) ColumnLookup(col *sqlparser.ColName) (int, error) {
+	if col.Qualifier.IsEmpty() || (col.Qualifier.Qualifier.IsEmpty() && col.Qualifier.Name.String() == l.alias.String()) {
+		for i, column := range l.columns {
+			if col.Name.EqualString(column) {
+				return i, nil
+			}
+		}
+	}
+	return 0, vterrors.VT03019(sqlparser.String(col))
+}
+
+func (l *cteColumnLookup) CollationForExpr(sqlparser.Expr) collations.ID {
+	return l.collation
+}
+
+func (l *cteColumnLookup) DefaultCollation() collations.ID {
+	return l.collation
+}
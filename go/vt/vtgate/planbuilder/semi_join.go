@@ -40,25 +40,30 @@ type semiJoin struct {
 	// LHSColumns are the columns from the LHS used for the join.
 	// These are the same columns pushed on the LHS that are now used in the vars field
 	LHSColumns []*sqlparser.ColName
+
+	// antiJoin is set for NOT EXISTS subqueries
+	antiJoin bool
 }
 
 // newSemiJoin builds a new semiJoin.
-func newSemiJoin(lhs, rhs logicalPlan, vars map[string]int, lhsCols []*sqlparser.ColName) *semiJoin {
+func newSemiJoin(lhs, rhs logicalPlan, vars map[string]int, lhsCols []*sqlparser.ColName, antiJoin bool) *semiJoin {
 	return &semiJoin{
 		rhs:        rhs,
 		lhs:        lhs,
 		vars:       vars,
 		LHSColumns: lhsCols,
+		antiJoin:   antiJoin,
 	}
 }
 
 // Primitive implements the logicalPlan interface
 func (ps *semiJoin) Primitive() engine.Primitive {
 	return &engine.SemiJoin{
-		Left:  ps.lhs.Primitive(),
-		Right: ps.rhs.Primitive(),
-		Vars:  ps.vars,
-		Cols:  ps.cols,
+		Left:     ps.lhs.Primitive(),
+		Right:    ps.rhs.Primitive(),
+		Vars:     ps.vars,
+		Cols:     ps.cols,
+		AntiJoin: ps.antiJoin,
 	}
 }
 
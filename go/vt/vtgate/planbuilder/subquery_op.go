@@ -58,7 +58,14 @@ func transformCorrelatedSubQueryPlan(ctx *plancontext.PlanningContext, op *opera
 	if err != nil {
 		return nil, err
 	}
-	return newSemiJoin(outer, inner, op.Vars, op.LHSColumns), nil
+	if !op.Pullout {
+		return newSemiJoin(outer, inner, op.Vars, op.LHSColumns, op.AntiJoin), nil
+	}
+	inner, err = planHorizon(ctx, inner, op.Extracted.Subquery.Select, true)
+	if err != nil {
+		return nil, err
+	}
+	return newCorrelatedSubquery(ctx, outer, inner, op.Extracted, op.Vars, op.Predicate)
 }
 
 func mergeSubQueryOpPlan(ctx *plancontext.PlanningContext, inner, outer logicalPlan, n *operators.SubQueryOp) logicalPlan {
@@ -40,6 +40,20 @@ func buildUpdatePlan(string) stmtPlanner {
 		if err != nil {
 			return nil, err
 		}
+		isUpdated := func(fk vindexes.ChildFKInfo) bool { return updatesAnyColumn(upd.Exprs, fk.ParentColumns) }
+		if err := checkV3ForeignKeys(vschema, dml.Table, isUpdated); err != nil {
+			return nil, err
+		}
+		if isFkManaged(vschema) {
+			for _, vTbl := range dml.Table {
+				if vTbl == nil {
+					continue
+				}
+				if err := checkUpdatedParents(vTbl, upd.Exprs); err != nil {
+					return nil, err
+				}
+			}
+		}
 		eupd := &engine.Update{DML: dml}
 
 		if dml.Opcode == engine.Unsharded {
@@ -0,0 +1,244 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+var _ logicalPlan = (*window)(nil)
+
+// window is the logicalPlan for engine.Window.
+// This gets built if window functions can't be pushed down to the tablets.
+type window struct {
+	logicalPlanCommon
+	eWindow *engine.Window
+}
+
+// Primitive implements the logicalPlan interface
+func (w *window) Primitive() engine.Primitive {
+	w.eWindow.Input = w.input.Primitive()
+	return w.eWindow
+}
+
+// OutputColumns implements the logicalPlan interface
+func (w *window) OutputColumns() []sqlparser.SelectExpr {
+	outputCols := sqlparser.CloneSelectExprs(w.input.OutputColumns())
+	for _, wf := range w.eWindow.Functions {
+		outputCols[wf.Col] = &sqlparser.AliasedExpr{Expr: wf.Expr, As: sqlparser.NewIdentifierCI(wf.Alias)}
+	}
+	if w.eWindow.TruncateColumnCount > 0 {
+		return outputCols[:w.eWindow.TruncateColumnCount]
+	}
+	return outputCols
+}
+
+// SetTruncateColumnCount sets the truncate column count.
+func (w *window) SetTruncateColumnCount(count int) {
+	w.eWindow.TruncateColumnCount = count
+}
+
+// isWindowCol returns true if the column at offset is overwritten by a window function
+func (w *window) isWindowCol(offset int) bool {
+	for _, wf := range w.eWindow.Functions {
+		if wf.Col == offset {
+			return true
+		}
+	}
+	return false
+}
+
+func pushProjectionIntoWindow(ctx *plancontext.PlanningContext, expr *sqlparser.AliasedExpr, node *window, inner, hasAggregation bool) (int, bool, error) {
+	colName, isColName := expr.Expr.(*sqlparser.ColName)
+	for _, wf := range node.eWindow.Functions {
+		if ctx.SemTable.EqualsExpr(wf.Expr, expr.Expr) {
+			return wf.Col, false, nil
+		}
+		if isColName && colName.Qualifier.IsEmpty() && colName.Name.EqualString(wf.Alias) {
+			return wf.Col, false, nil
+		}
+	}
+	if sqlparser.ContainsWindowFunc(expr.Expr) {
+		return 0, false, vterrors.VT12001(fmt.Sprintf("in scatter query: window function not in the SELECT list: %s", sqlparser.String(expr.Expr)))
+	}
+
+	// all input columns are passed through, except the ones holding window function results
+	offset, added, err := pushProjection(ctx, expr, node.input, inner, true, hasAggregation)
+	if err != nil {
+		return 0, false, err
+	}
+	if node.isWindowCol(offset) {
+		return pushProjection(ctx, expr, node.input, inner, false, hasAggregation)
+	}
+	return offset, added, nil
+}
+
+// planWindows plans the evaluation of window functions on the vtgate.
+// The input is sorted on the PARTITION BY and ORDER BY expressions of the window,
+// and the window functions overwrite the columns that hold their arguments.
+func (hp *horizonPlanning) planWindows(ctx *plancontext.PlanningContext, plan logicalPlan) (logicalPlan, error) {
+	if hp.qp.NeedsAggregation() || hp.sel.Having != nil {
+		return nil, vterrors.VT12001("in scatter query: window functions combined with aggregation")
+	}
+	if hp.qp.NeedsDistinct() {
+		return nil, vterrors.VT12001("in scatter query: window functions combined with DISTINCT")
+	}
+
+	funcs, spec, err := hp.qp.WindowFunctions(ctx, hp.sel)
+	if err != nil {
+		return nil, err
+	}
+
+	eWindow := &engine.Window{}
+	pos := 0
+	for idx, selExpr := range hp.qp.SelectExprs {
+		aliasedExpr, err := selExpr.GetAliasedExpr()
+		if err != nil {
+			return nil, err
+		}
+		if pos < len(funcs) && funcs[pos].Index == idx {
+			wf := funcs[pos]
+			pos++
+			// the argument of the function is fetched in the column the result will be written to
+			arg := wf.Arg
+			if arg == nil {
+				arg = &sqlparser.NullVal{}
+			}
+			aliasedExpr = &sqlparser.AliasedExpr{Expr: arg}
+			params := &engine.WindowFuncParams{
+				Opcode:     wf.OpCode,
+				Col:        idx,
+				Offset:     wf.Offset,
+				DefaultCol: -1,
+				Alias:      wf.Original.ColumnName(),
+				Expr:       wf.Original.Expr,
+			}
+			eWindow.Functions = append(eWindow.Functions, params)
+		}
+		offset, _, err := pushProjection(ctx, aliasedExpr, plan, true, false, false)
+		if err != nil {
+			return nil, err
+		}
+		if offset != idx {
+			return nil, vterrors.VT13001(fmt.Sprintf("expected projection at offset %d, got %d", idx, offset))
+		}
+	}
+
+	for i, wf := range funcs {
+		if wf.Default == nil {
+			continue
+		}
+		offset, _, err := pushProjection(ctx, &sqlparser.AliasedExpr{Expr: wf.Default}, plan, true, true, false)
+		if err != nil {
+			return nil, err
+		}
+		eWindow.Functions[i].DefaultCol = offset
+	}
+
+	var orderExprs []operators.OrderBy
+	for _, expr := range spec.PartitionClause {
+		checkCol, err := pushWindowKey(ctx, expr, plan)
+		if err != nil {
+			return nil, err
+		}
+		eWindow.PartitionBy = append(eWindow.PartitionBy, checkCol)
+		orderExprs = append(orderExprs, operators.OrderBy{
+			Inner:         &sqlparser.Order{Expr: expr, Direction: sqlparser.AscOrder},
+			WeightStrExpr: expr,
+		})
+	}
+	for _, order := range spec.OrderClause {
+		checkCol, err := pushWindowKey(ctx, order.Expr, plan)
+		if err != nil {
+			return nil, err
+		}
+		eWindow.OrderBy = append(eWindow.OrderBy, checkCol)
+		orderExprs = append(orderExprs, operators.OrderBy{
+			Inner:         order,
+			WeightStrExpr: order.Expr,
+		})
+	}
+
+	if len(orderExprs) > 0 {
+		plan, err = hp.planOrderBy(ctx, orderExprs, plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &window{
+		logicalPlanCommon: newBuilderCommon(plan),
+		eWindow:           eWindow,
+	}, nil
+}
+
+func pushWindowKey(ctx *plancontext.PlanningContext, expr sqlparser.Expr, plan logicalPlan) (engine.CheckCol, error) {
+	var wsExpr sqlparser.Expr
+	if ctx.SemTable.NeedsWeightString(expr) {
+		wsExpr = expr
+	}
+	offset, wsOffset, err := wrapAndPushExpr(ctx, expr, wsExpr, plan)
+	if err != nil {
+		return engine.CheckCol{}, err
+	}
+	checkCol := engine.CheckCol{
+		Col:       offset,
+		Collation: ctx.SemTable.CollationForExpr(expr),
+	}
+	if wsOffset != -1 {
+		checkCol.WsCol = &wsOffset
+	}
+	return checkCol, nil
+}
+
+// planOrderByForWindow sorts the output of the window functions in memory
+func (hp *horizonPlanning) planOrderByForWindow(ctx *plancontext.PlanningContext, orderExprs []operators.OrderBy, plan *window) (logicalPlan, error) {
+	primitive := &engine.MemorySort{}
+	ms := &memorySort{
+		resultsBuilder: resultsBuilder{
+			logicalPlanCommon: newBuilderCommon(plan),
+			weightStrings:     make(map[*resultColumn]int),
+			truncater:         primitive,
+		},
+		eMemorySort: primitive,
+	}
+
+	for _, order := range orderExprs {
+		var wsExpr sqlparser.Expr
+		if !sqlparser.ContainsWindowFunc(order.WeightStrExpr) && ctx.SemTable.NeedsWeightString(order.Inner.Expr) {
+			wsExpr = order.WeightStrExpr
+		}
+		offset, weightStringOffset, err := wrapAndPushExpr(ctx, order.SimplifiedExpr(), wsExpr, plan)
+		if err != nil {
+			return nil, err
+		}
+		ms.eMemorySort.OrderBy = append(ms.eMemorySort.OrderBy, engine.OrderByParams{
+			Col:               offset,
+			WeightStringCol:   weightStringOffset,
+			Desc:              order.Inner.Direction == sqlparser.DescOrder,
+			StarColFixedIndex: offset,
+			CollationID:       ctx.SemTable.CollationForExpr(order.Inner.Expr),
+		})
+	}
+	return ms, nil
+}
@@ -71,6 +71,8 @@ var (
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
 
+	mysqlCompressionAlgorithms string
+
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
 
@@ -97,6 +99,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
+	fs.StringVar(&mysqlCompressionAlgorithms, "mysql_server_compression_algorithms", mysqlCompressionAlgorithms, "Comma separated list of the compression algorithms clients can use with the compressed protocol. Options: zlib, zstd. Compression is disabled if empty.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
 }
 
@@ -485,6 +488,10 @@ func initMySQLProtocol() {
 			_ = initTLSConfig(mysqlListener, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		mysqlListener.AllowClearTextWithoutTLS.Set(mysqlAllowClearTextWithoutTLS)
+		mysqlListener.CompressionAlgorithms, err = mysql.ValidateCompressionAlgorithms(mysqlCompressionAlgorithms)
+		if err != nil {
+			log.Exitf("-mysql_server_compression_algorithms: %v", err)
+		}
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
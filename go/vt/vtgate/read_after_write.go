@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// With @@read_after_write_consistency, the replica reads of a session see the
+// writes that the session committed before. After each commit on a shard,
+// vtgate fetches the GTID set executed by the primary of that shard and keeps
+// it in the session. The next reads of the session that go to a replica of
+// that shard carry the GTID set in their ExecuteOptions, and the replica
+// waits until it has caught up with it before it runs the query.
+
+const primaryPositionQuery = "select @@global.gtid_executed"
+
+// recordShardPosition remembers the position of the primary of the given shard,
+// after the session committed a write on it.
+func recordShardPosition(ctx context.Context, qs queryservice.QueryService, session *SafeSession, target *querypb.Target) {
+	if target.TabletType != topodatapb.TabletType_PRIMARY || !session.ReadAfterWriteConsistency() {
+		return
+	}
+	qr, err := qs.Execute(ctx, target, primaryPositionQuery, nil, 0, 0, nil)
+	if err == nil && (len(qr.Rows) != 1 || len(qr.Rows[0]) != 1) {
+		err = fmt.Errorf("unexpected result of %s: %v", primaryPositionQuery, qr.Rows)
+	}
+	if err != nil {
+		// The write went through, only the replica reads that follow may not see it.
+		log.Warningf("cannot fetch the position of %s/%s after a commit: %v", target.Keyspace, target.Shard, err)
+		session.RecordWarning(&querypb.QueryWarning{Message: fmt.Sprintf("read after write consistency is not guaranteed on %s/%s: %v", target.Keyspace, target.Shard, err)})
+		return
+	}
+	session.SetShardPosition(target, qr.Rows[0][0].ToString())
+}
+
+// readAfterWriteOptions returns the options to send a read of the session to the given target with.
+// If the target is a replica of a shard that the session wrote to, the options make the replica
+// wait for the position of that write.
+func readAfterWriteOptions(session *SafeSession, target *querypb.Target, opts *querypb.ExecuteOptions) *querypb.ExecuteOptions {
+	if target == nil || target.TabletType == topodatapb.TabletType_PRIMARY {
+		return opts
+	}
+	position, timeout := session.ShardPosition(target)
+	if position == "" {
+		return opts
+	}
+	// The options are shared by all the shards of the query, every shard waits for its own position.
+	if opts == nil {
+		opts = &querypb.ExecuteOptions{}
+	} else {
+		opts = proto.Clone(opts).(*querypb.ExecuteOptions)
+	}
+	opts.WaitForGtidSet = position
+	opts.WaitForGtidSetTimeout = timeout
+	return opts
+}
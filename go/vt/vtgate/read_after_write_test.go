@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/discovery"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/srvtopo"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestReadAfterWriteConsistency(t *testing.T) {
+	name := "TestReadAfterWrite"
+	createSandbox(name)
+	hc := discovery.NewFakeHealthCheck(nil)
+	sc := newTestScatterConn(hc, newSandboxForCells([]string{"aa"}), "aa")
+	primary := hc.AddTestTablet("aa", "0", 1, name, "0", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	replica := hc.AddTestTablet("aa", "1", 1, name, "0", topodatapb.TabletType_REPLICA, true, 1, nil)
+	res := srvtopo.NewResolver(newSandboxForCells([]string{"aa"}), sc.gateway, "aa")
+	primaryRss, err := res.ResolveDestination(ctx, name, topodatapb.TabletType_PRIMARY, key.DestinationShard("0"))
+	require.NoError(t, err)
+	replicaRss, err := res.ResolveDestination(ctx, name, topodatapb.TabletType_REPLICA, key.DestinationShard("0"))
+	require.NoError(t, err)
+	target := primaryRss[0].Target
+	position := func(gtid string) *sqltypes.Result {
+		return sqltypes.MakeTestResult(sqltypes.MakeTestFields("@@global.gtid_executed", "varchar"), gtid)
+	}
+	write := []*querypb.BoundQuery{{Sql: "update t set a = 1"}}
+	read := []*querypb.BoundQuery{{Sql: "select a from t"}}
+
+	// without read after write consistency, the position is not fetched.
+	session := NewSafeSession(&vtgatepb.Session{})
+	_, errs := sc.ExecuteMultiShard(ctx, nil, primaryRss, write, session, true, false)
+	require.Empty(t, errs)
+	assert.EqualValues(t, 1, primary.ExecCount.Get())
+	pos, _ := session.ShardPosition(target)
+	assert.Empty(t, pos)
+
+	session.SetReadAfterWriteConsistency(true)
+	session.SetReadAfterWriteTimeout(2.5)
+
+	// an autocommitted write remembers the position of the primary.
+	primary.SetResults([]*sqltypes.Result{{RowsAffected: 1}, position("uuid:1-10")})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, primaryRss, write, session, true, false)
+	require.Empty(t, errs)
+	assert.Equal(t, primaryPositionQuery, primary.Queries[len(primary.Queries)-1].Sql)
+	pos, timeout := session.ShardPosition(target)
+	assert.Equal(t, "uuid:1-10", pos)
+	assert.Equal(t, 2.5, timeout)
+
+	// a replica read waits for it.
+	_, errs = sc.ExecuteMultiShard(ctx, nil, replicaRss, read, session, false, false)
+	require.Empty(t, errs)
+	require.Len(t, replica.Options, 1)
+	assert.Equal(t, "uuid:1-10", replica.Options[0].WaitForGtidSet)
+	assert.Equal(t, 2.5, replica.Options[0].WaitForGtidSetTimeout)
+
+	// a primary read does not.
+	primary.Options = nil
+	_, errs = sc.ExecuteMultiShard(ctx, nil, primaryRss, read, session, false, false)
+	require.Empty(t, errs)
+	require.Len(t, primary.Options, 1)
+	assert.Empty(t, primary.Options[0].GetWaitForGtidSet())
+
+	// a commit remembers the new position.
+	session.Session.InTransaction = true
+	primary.SetResults([]*sqltypes.Result{{RowsAffected: 1}, position("uuid:1-12")})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, primaryRss, write, session, false, false)
+	require.Empty(t, errs)
+	require.NoError(t, sc.txConn.Commit(ctx, session))
+	assert.EqualValues(t, 1, primary.CommitCount.Get())
+	pos, _ = session.ShardPosition(target)
+	assert.Equal(t, "uuid:1-12", pos)
+
+	replica.Options = nil
+	errs = sc.StreamExecuteMulti(ctx, nil, "select a from t", replicaRss, []map[string]*querypb.BindVariable{nil}, session, false, func(*sqltypes.Result) error { return nil })
+	require.Empty(t, errs)
+	require.Len(t, replica.Options, 1)
+	assert.Equal(t, "uuid:1-12", replica.Options[0].WaitForGtidSet)
+
+	// the position cannot be fetched, the session gets a warning.
+	primary.SetResults([]*sqltypes.Result{{RowsAffected: 1}, {}})
+	_, errs = sc.ExecuteMultiShard(ctx, nil, primaryRss, write, session, true, false)
+	require.Empty(t, errs)
+	require.Len(t, session.Warnings, 1)
+	assert.Contains(t, session.Warnings[0].Message, "read after write consistency is not guaranteed on TestReadAfterWrite/0")
+
+	// turning it off forgets the positions.
+	session.SetReadAfterWriteConsistency(false)
+	pos, _ = session.ShardPosition(target)
+	assert.Empty(t, pos)
+	replica.Options = nil
+	_, errs = sc.ExecuteMultiShard(ctx, nil, replicaRss, read, session, false, false)
+	require.Empty(t, errs)
+	assert.Empty(t, replica.Options[0].GetWaitForGtidSet())
+}
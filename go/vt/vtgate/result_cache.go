@@ -0,0 +1,340 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/cache"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The result cache keeps the results of the selects that carry a CACHE_TTL
+// directive, keyed on the normalized query, its bind variables, its target and
+// its callers, so that a result is never served to a caller that the table ACLs
+// of the tablets would not have allowed to read it.
+// An entry lives at most for its TTL, and is dropped earlier when one of the
+// tables it was read from changes.
+//
+// Changes are observed through a VStream per keyspace and tablet type, which
+// is started the first time a query of that keyspace and tablet type is
+// cached. Every row event bumps the version of its table, a DDL or the end of
+// the stream bumps the version of the whole stream. An entry remembers the
+// version of the cache at the time its query started, and it is only valid as
+// long as none of its tables or streams has been bumped past it. Results are
+// not cached while one of their streams is down, or until it is positioned.
+
+var (
+	resultCacheHits          = stats.NewCounter("ResultCacheHits", "Result cache hits")
+	resultCacheMisses        = stats.NewCounter("ResultCacheMisses", "Result cache misses")
+	resultCacheInvalidations = stats.NewCountersWithSingleLabel("ResultCacheInvalidations", "Result cache invalidations per keyspace", "Keyspace")
+)
+
+// resultCacheRetryDelay is the time to wait before restarting an invalidation stream that ended.
+var resultCacheRetryDelay = 5 * time.Second
+
+type vstreamFunc func(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error
+
+type resultCache struct {
+	vstream vstreamFunc
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	entries *cache.LRUCache
+
+	mu sync.Mutex
+	// version is bumped by every invalidation.
+	version uint64
+	// tables holds the version of the last change of each table, per stream.
+	tables  map[string]uint64
+	streams map[string]*resultCacheStream
+}
+
+type resultCacheStream struct {
+	keyspace   string
+	tabletType topodatapb.TabletType
+	// running is set once the stream delivered its first events, and is
+	// positioned: the changes made from then on are seen by the stream.
+	running bool
+	// flushed is the version at which the stream last lost track of its tables.
+	flushed uint64
+}
+
+type resultCacheEntry struct {
+	result  *sqltypes.Result
+	tables  []string
+	streams []*resultCacheStream
+	version uint64
+	expires time.Time
+}
+
+func newResultCache(capacity int64, vstream vstreamFunc) *resultCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &resultCache{
+		vstream: vstream,
+		ctx:     ctx,
+		cancel:  cancel,
+		entries: cache.NewLRUCache(capacity, func(v any) int64 {
+			return v.(*resultCacheEntry).result.CachedSize(true)
+		}),
+		tables:  make(map[string]uint64),
+		streams: make(map[string]*resultCacheStream),
+	}
+}
+
+// resultCacheKey returns the key of the result of the plan in the result cache, or an
+// empty string if the result of the plan cannot be cached.
+func (e *Executor) resultCacheKey(ctx context.Context, safeSession *SafeSession, plan *engine.Plan, vcursor *vcursorImpl, bindVars map[string]*querypb.BindVariable) string {
+	if e.resultCache == nil || vcursor.resultCacheTTL == 0 || plan.Type != sqlparser.StmtSelect {
+		return ""
+	}
+	// Transactions and session settings can make the session see other results than the rest.
+	if safeSession.InTransaction() || safeSession.InReservedConn() || len(safeSession.SystemVariables) > 0 {
+		return ""
+	}
+	return resultCacheKey(vcursor.planPrefixKey(ctx), callerid.ImmediateCallerIDFromContext(ctx), callerid.EffectiveCallerIDFromContext(ctx), plan.Original, bindVars)
+}
+
+// resultCacheKey returns the key of the result of query on the given target, for the given callers.
+func resultCacheKey(target string, im *querypb.VTGateCallerID, ef *vtrpcpb.CallerID, query string, bindVars map[string]*querypb.BindVariable) string {
+	h := sha256.New()
+	writeKeyPart(h, target)
+	writeKeyPart(h, callerid.GetUsername(im))
+	writeKeyPart(h, strings.Join(im.GetGroups(), "\n"))
+	writeKeyPart(h, callerid.GetPrincipal(ef))
+	writeKeyPart(h, callerid.GetComponent(ef))
+	writeKeyPart(h, callerid.GetSubcomponent(ef))
+	writeKeyPart(h, strings.Join(ef.GetGroups(), "\n"))
+	writeKeyPart(h, query)
+	names := make([]string, 0, len(bindVars))
+	for name := range bindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		bv := bindVars[name]
+		writeKeyPart(h, name)
+		writeKeyPart(h, bv.Type.String())
+		writeKeyPart(h, string(bv.Value))
+		for _, v := range bv.Values {
+			writeKeyPart(h, v.Type.String())
+			writeKeyPart(h, string(v.Value))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeKeyPart(h hash.Hash, part string) {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(part)))
+	_, _ = h.Write(size[:])
+	_, _ = h.Write([]byte(part))
+}
+
+// streamKey returns the keyspace of the qualified table name, and the key of the stream that follows it.
+func streamKey(table string, tabletType topodatapb.TabletType) (string, string, bool) {
+	keyspace, _, ok := strings.Cut(table, ".")
+	if !ok || keyspace == "" {
+		return "", "", false
+	}
+	return keyspace, keyspace + "@" + strings.ToLower(tabletType.String()), true
+}
+
+func tableKey(table string, tabletType topodatapb.TabletType) string {
+	return table + "@" + strings.ToLower(tabletType.String())
+}
+
+// Get returns a copy of the cached result of the key, if it is still valid.
+func (rc *resultCache) Get(key string) (*sqltypes.Result, bool) {
+	v, ok := rc.entries.Get(key)
+	if !ok {
+		resultCacheMisses.Add(1)
+		return nil, false
+	}
+	entry := v.(*resultCacheEntry)
+	if time.Now().After(entry.expires) || !rc.isValid(entry) {
+		rc.entries.Delete(key)
+		resultCacheMisses.Add(1)
+		return nil, false
+	}
+	resultCacheHits.Add(1)
+	return entry.result.Copy(), true
+}
+
+// Begin must be called before the query of an entry is executed. It returns the
+// version to Set the result with, and false if the result cannot be cached.
+// It starts the streams that follow the tables if they are not running yet.
+func (rc *resultCache) Begin(tables []string, tabletType topodatapb.TabletType) (uint64, bool) {
+	if len(tables) == 0 {
+		return 0, false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.ctx.Err() != nil {
+		return 0, false
+	}
+	ready := true
+	for _, table := range tables {
+		keyspace, key, ok := streamKey(table, tabletType)
+		if !ok {
+			return 0, false
+		}
+		stream := rc.streams[key]
+		if stream == nil {
+			stream = &resultCacheStream{keyspace: keyspace, tabletType: tabletType}
+			rc.streams[key] = stream
+			rc.startStream(stream, 0)
+			// The stream may miss the changes made before it is positioned.
+			ready = false
+		}
+		if !stream.running {
+			ready = false
+		}
+	}
+	return rc.version, ready
+}
+
+// Set caches the result of the key, unless one of its tables changed since the given version.
+func (rc *resultCache) Set(key string, tables []string, tabletType topodatapb.TabletType, version uint64, ttl time.Duration, qr *sqltypes.Result) {
+	entry := &resultCacheEntry{
+		tables:  make([]string, 0, len(tables)),
+		streams: make([]*resultCacheStream, 0, len(tables)),
+		version: version,
+		expires: time.Now().Add(ttl),
+	}
+	rc.mu.Lock()
+	for _, table := range tables {
+		_, key, ok := streamKey(table, tabletType)
+		if !ok || rc.streams[key] == nil {
+			rc.mu.Unlock()
+			return
+		}
+		entry.tables = append(entry.tables, tableKey(table, tabletType))
+		entry.streams = append(entry.streams, rc.streams[key])
+	}
+	rc.mu.Unlock()
+	if !rc.isValid(entry) {
+		return
+	}
+	entry.result = qr.Copy()
+	rc.entries.Set(key, entry)
+}
+
+// isValid returns true if none of the tables of the entry changed since its query started.
+func (rc *resultCache) isValid(entry *resultCacheEntry) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, table := range entry.tables {
+		if rc.tables[table] > entry.version {
+			return false
+		}
+	}
+	for _, stream := range entry.streams {
+		if !stream.running || stream.flushed > entry.version {
+			return false
+		}
+	}
+	return true
+}
+
+// startStream starts following the changes of the keyspace of the stream after the given delay.
+// It must be called with the lock held.
+func (rc *resultCache) startStream(stream *resultCacheStream, delay time.Duration) {
+	rc.wg.Add(1)
+	go func() {
+		defer rc.wg.Done()
+		select {
+		case <-rc.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: stream.keyspace,
+			Gtid:     "current",
+		}}}
+		filter := &binlogdatapb.Filter{Rules: []*binlogdatapb.Rule{{Match: "/.*/"}}}
+		err := rc.vstream(rc.ctx, stream.tabletType, vgtid, filter, &vtgatepb.VStreamFlags{}, func(events []*binlogdatapb.VEvent) error {
+			rc.invalidate(stream, events)
+			return nil
+		})
+
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		stream.running = false
+		rc.flush(stream)
+		if rc.ctx.Err() != nil {
+			return
+		}
+		log.Warningf("result cache invalidation stream of %s ended, restarting in %v: %v", stream.keyspace, resultCacheRetryDelay, err)
+		rc.startStream(stream, resultCacheRetryDelay)
+	}()
+}
+
+func (rc *resultCache) invalidate(stream *resultCacheStream, events []*binlogdatapb.VEvent) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_ROW:
+			rc.version++
+			rc.tables[tableKey(event.RowEvent.TableName, stream.tabletType)] = rc.version
+			resultCacheInvalidations.Add(stream.keyspace, 1)
+		case binlogdatapb.VEventType_DDL, binlogdatapb.VEventType_JOURNAL:
+			rc.flush(stream)
+		}
+	}
+	// The stream is positioned once it delivered its first events, usually its VGTID.
+	stream.running = true
+}
+
+// flush invalidates all the entries that depend on the stream. It must be called with the lock held.
+func (rc *resultCache) flush(stream *resultCacheStream) {
+	rc.version++
+	stream.flushed = rc.version
+	resultCacheInvalidations.Add(stream.keyspace, 1)
+}
+
+// Close stops the invalidation streams and empties the cache.
+func (rc *resultCache) Close() {
+	rc.mu.Lock()
+	rc.cancel()
+	rc.mu.Unlock()
+	rc.wg.Wait()
+	rc.entries.Clear()
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeInvalidationStream serves the events it is given as the VStream of the result cache.
+type fakeInvalidationStream struct {
+	started chan string
+	events  chan []*binlogdatapb.VEvent
+	sent    chan struct{}
+}
+
+func newFakeInvalidationStream() *fakeInvalidationStream {
+	return &fakeInvalidationStream{
+		started: make(chan string, 10),
+		events:  make(chan []*binlogdatapb.VEvent),
+		sent:    make(chan struct{}),
+	}
+}
+
+func (f *fakeInvalidationStream) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error {
+	f.started <- vgtid.ShardGtids[0].Keyspace + "@" + tabletType.String()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case events := <-f.events:
+			if events == nil {
+				return io.EOF
+			}
+			if err := send(events); err != nil {
+				return err
+			}
+			f.sent <- struct{}{}
+		}
+	}
+}
+
+func (f *fakeInvalidationStream) send(events ...*binlogdatapb.VEvent) {
+	f.events <- events
+	<-f.sent
+}
+
+// position sends the first event of the stream, which positions it.
+func (f *fakeInvalidationStream) position() {
+	f.send(&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_VGTID, Vgtid: &binlogdatapb.VGtid{}})
+}
+
+func (f *fakeInvalidationStream) waitStarted(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-f.started:
+		assert.Equal(t, want, got)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("stream %s did not start", want)
+	}
+}
+
+func rowEvent(table string) *binlogdatapb.VEvent {
+	return &binlogdatapb.VEvent{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: table}}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	bv := func(v int64) map[string]*querypb.BindVariable {
+		return map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(v)}
+	}
+	im := callerid.NewImmediateCallerID("user1")
+	ef := callerid.NewEffectiveCallerID("principal1", "", "")
+	key := resultCacheKey("ks@primary", im, ef, "select a from t where id = :id", bv(1))
+	assert.Equal(t, key, resultCacheKey("ks@primary", im, ef, "select a from t where id = :id", bv(1)))
+	assert.NotEqual(t, key, resultCacheKey("ks@primary", im, ef, "select a from t where id = :id", bv(2)))
+	assert.NotEqual(t, key, resultCacheKey("ks@replica", im, ef, "select a from t where id = :id", bv(1)))
+	assert.NotEqual(t, key, resultCacheKey("ks@primary", im, ef, "select b from t where id = :id", bv(1)))
+	assert.NotEqual(t,
+		resultCacheKey("ks", nil, nil, "q", map[string]*querypb.BindVariable{"a": sqltypes.StringBindVariable("bc")}),
+		resultCacheKey("ks", nil, nil, "q", map[string]*querypb.BindVariable{"ab": sqltypes.StringBindVariable("c")}))
+
+	// Each caller has its own entries, since the table ACLs may not allow all of them to read the result.
+	assert.NotEqual(t, key, resultCacheKey("ks@primary", callerid.NewImmediateCallerID("user2"), ef, "select a from t where id = :id", bv(1)))
+	assert.NotEqual(t, key, resultCacheKey("ks@primary", im, callerid.NewEffectiveCallerID("principal2", "", ""), "select a from t where id = :id", bv(1)))
+	assert.NotEqual(t, key, resultCacheKey("ks@primary", im, nil, "select a from t where id = :id", bv(1)))
+}
+
+func TestResultCache(t *testing.T) {
+	stream := newFakeInvalidationStream()
+	rc := newResultCache(1024*1024, stream.VStream)
+	defer rc.Close()
+	qr := sqltypes.MakeTestResult(sqltypes.MakeTestFields("a", "int64"), "1")
+	tables := []string{"ks.t1", "ks.t2"}
+	primary := topodatapb.TabletType_PRIMARY
+
+	// The first query starts the stream and cannot be cached yet.
+	_, ok := rc.Begin(tables, primary)
+	require.False(t, ok)
+	stream.waitStarted(t, "ks@PRIMARY")
+
+	// Nor can the queries that run before the stream is positioned, since
+	// it may miss the changes made until then.
+	_, ok = rc.Begin(tables, primary)
+	require.False(t, ok)
+	stream.position()
+
+	version, ok := rc.Begin(tables, primary)
+	require.True(t, ok)
+	rc.Set("k1", tables, primary, version, time.Minute, qr)
+	got, ok := rc.Get("k1")
+	require.True(t, ok)
+	assert.Equal(t, qr, got)
+
+	// A change of another table or another tablet type keeps the entry.
+	stream.send(rowEvent("ks.t3"), rowEvent("other.t1"))
+	_, ok = rc.Get("k1")
+	require.True(t, ok)
+
+	// A change of one of its tables drops it.
+	stream.send(rowEvent("ks.t2"))
+	_, ok = rc.Get("k1")
+	require.False(t, ok)
+
+	// A result of a query that started before a change is not cached.
+	version, ok = rc.Begin(tables, primary)
+	require.True(t, ok)
+	stream.send(rowEvent("ks.t1"))
+	rc.Set("k1", tables, primary, version, time.Minute, qr)
+	_, ok = rc.Get("k1")
+	require.False(t, ok)
+
+	// An entry expires after its TTL.
+	version, _ = rc.Begin(tables, primary)
+	rc.Set("k1", tables, primary, version, -time.Second, qr)
+	_, ok = rc.Get("k1")
+	require.False(t, ok)
+
+	// A DDL drops all the entries of the keyspace.
+	version, _ = rc.Begin(tables, primary)
+	rc.Set("k1", tables, primary, version, time.Minute, qr)
+	rc.Set("k2", []string{"ks.t3"}, primary, version, time.Minute, qr)
+	stream.send(&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_DDL})
+	_, ok = rc.Get("k1")
+	require.False(t, ok)
+	_, ok = rc.Get("k2")
+	require.False(t, ok)
+
+	// The end of the stream drops all the entries of the keyspace, and nothing is cached until it is restarted.
+	defer func(delay time.Duration) { resultCacheRetryDelay = delay }(resultCacheRetryDelay)
+	resultCacheRetryDelay = time.Hour
+	version, _ = rc.Begin(tables, primary)
+	rc.Set("k1", tables, primary, version, time.Minute, qr)
+	stream.events <- nil
+	require.Eventually(t, func() bool {
+		_, ok := rc.Begin(tables, primary)
+		return !ok
+	}, 5*time.Second, time.Millisecond)
+	_, ok = rc.Get("k1")
+	require.False(t, ok)
+
+	// Each tablet type has its own stream.
+	_, ok = rc.Begin(tables, topodatapb.TabletType_REPLICA)
+	require.False(t, ok)
+	stream.waitStarted(t, "ks@REPLICA")
+
+	// Tables that are not qualified by a keyspace are not cached.
+	_, ok = rc.Begin([]string{"t1"}, primary)
+	require.False(t, ok)
+	_, ok = rc.Begin(nil, primary)
+	require.False(t, ok)
+}
+
+func TestExecutorResultCache(t *testing.T) {
+	executor, sbc1, _, _ := createExecutorEnv()
+	// The tables a plan reads from are only known by the gen4 planner.
+	executor.pv = querypb.ExecuteOptions_Gen4
+	stream := newFakeInvalidationStream()
+	executor.resultCache = newResultCache(1024*1024, stream.VStream)
+	defer executor.resultCache.Close()
+
+	sql := "select /*vt+ CACHE_TTL=1m */ id from user where id = :id"
+	bv := map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)}
+	exec := func(sql string, bv map[string]*querypb.BindVariable) {
+		t.Helper()
+		_, err := executorExecSession(executor, sql, bv, &vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+		require.NoError(t, err)
+	}
+
+	// The first execution starts the stream, nothing is cached until it is positioned.
+	exec(sql, bv)
+	stream.waitStarted(t, "TestExecutor@PRIMARY")
+	exec(sql, bv)
+	assert.EqualValues(t, 2, sbc1.ExecCount.Get())
+	stream.position()
+
+	// The next one fills the cache, the one after hits it.
+	exec(sql, bv)
+	exec(sql, bv)
+	assert.EqualValues(t, 3, sbc1.ExecCount.Get())
+
+	// Other bind variables are another entry.
+	exec(sql, map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(2)})
+	assert.EqualValues(t, 4, sbc1.ExecCount.Get())
+
+	// A query without the directive is not cached.
+	exec("select id from user where id = :id", bv)
+	exec("select id from user where id = :id", bv)
+	assert.EqualValues(t, 6, sbc1.ExecCount.Get())
+
+	// A change of the table invalidates the result.
+	stream.send(rowEvent("TestExecutor.user"))
+	exec(sql, bv)
+	exec(sql, bv)
+	assert.EqualValues(t, 7, sbc1.ExecCount.Get())
+
+	// Another caller does not see the results cached for the others.
+	ctx := callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("other", "", ""), callerid.NewImmediateCallerID("other"))
+	_, err := executor.Execute(ctx, "TestExecute", NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true}), sql, bv)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, sbc1.ExecCount.Get())
+
+	// Transactions do not use the cache.
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary"})
+	_, err = executor.Execute(context.Background(), "TestExecute", session, sql, bv)
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, sbc1.ExecCount.Get())
+}
@@ -28,6 +28,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/sysvars"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 
@@ -732,6 +733,68 @@ func (session *SafeSession) SetReadAfterWriteTimeout(timeout float64) {
 	session.ReadAfterWrite.ReadAfterWriteTimeout = timeout
 }
 
+// SetReadAfterWriteConsistency set the ReadAfterWriteConsistency setting.
+// Turning it off forgets the positions of the previous writes.
+func (session *SafeSession) SetReadAfterWriteConsistency(enable bool) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ReadAfterWrite == nil {
+		session.ReadAfterWrite = &vtgatepb.ReadAfterWrite{}
+	}
+	session.ReadAfterWrite.ReadAfterWriteConsistency = enable
+	if !enable {
+		session.ReadAfterWrite.ShardPositions = nil
+	}
+}
+
+// ReadAfterWriteConsistency returns true if the replica reads of the session have to see its own writes.
+func (session *SafeSession) ReadAfterWriteConsistency() bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.GetReadAfterWrite().GetReadAfterWriteConsistency()
+}
+
+// transactionTargets returns the targets of all the shards that have an open transaction.
+func (session *SafeSession) transactionTargets() []*querypb.Target {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	var targets []*querypb.Target
+	for _, shardSessions := range [][]*vtgatepb.Session_ShardSession{session.PreSessions, session.ShardSessions, session.PostSessions} {
+		for _, shardSession := range shardSessions {
+			if shardSession.TransactionId != 0 {
+				targets = append(targets, shardSession.Target)
+			}
+		}
+	}
+	return targets
+}
+
+// SetShardPosition records the position of the last commit of the session on the given shard.
+func (session *SafeSession) SetShardPosition(target *querypb.Target, position string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.ReadAfterWrite == nil {
+		session.ReadAfterWrite = &vtgatepb.ReadAfterWrite{}
+	}
+	if session.ReadAfterWrite.ShardPositions == nil {
+		session.ReadAfterWrite.ShardPositions = make(map[string]string)
+	}
+	session.ReadAfterWrite.ShardPositions[topoproto.KeyspaceShardString(target.Keyspace, target.Shard)] = position
+}
+
+// ShardPosition returns the position that a replica of the given shard has to reach
+// before it answers a read of the session, along with the timeout for the wait.
+// The position is empty if the session does not need to wait.
+func (session *SafeSession) ShardPosition(target *querypb.Target) (string, float64) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	raw := session.GetReadAfterWrite()
+	if !raw.GetReadAfterWriteConsistency() {
+		return "", 0
+	}
+	return raw.ShardPositions[topoproto.KeyspaceShardString(target.Keyspace, target.Shard)], raw.ReadAfterWriteTimeout
+}
+
 // SetSessionTrackGtids set the SessionTrackGtids setting.
 func (session *SafeSession) SetSessionTrackGtids(enable bool) {
 	session.mu.Lock()
@@ -176,7 +176,7 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			reservedID := info.reservedID
 
 			if session != nil && session.Session != nil {
-				opts = session.Session.Options
+				opts = readAfterWriteOptions(session, rs.Target, session.Session.Options)
 			}
 
 			if autocommit {
@@ -255,6 +255,9 @@ func (stc *ScatterConn) ExecuteMultiShard(
 			if err != nil {
 				return newInfo, err
 			}
+			if autocommit {
+				recordShardPosition(ctx, rs.Gateway, session, rs.Target)
+			}
 			mu.Lock()
 			defer mu.Unlock()
 
@@ -374,7 +377,7 @@ func (stc *ScatterConn) StreamExecuteMulti(
 			reservedID := info.reservedID
 
 			if session != nil && session.Session != nil {
-				opts = session.Session.Options
+				opts = readAfterWriteOptions(session, rs.Target, session.Session.Options)
 			}
 
 			if autocommit {
@@ -453,6 +456,9 @@ func (stc *ScatterConn) StreamExecuteMulti(
 			if err != nil {
 				return newInfo, err
 			}
+			if autocommit {
+				recordShardPosition(ctx, rs.Gateway, session, rs.Target)
+			}
 
 			return newInfo, nil
 		},
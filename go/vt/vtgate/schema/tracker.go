@@ -18,6 +18,7 @@ package schema
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -51,6 +52,7 @@ type (
 		mu     sync.Mutex
 		tables *tableMap
 		views  *viewMap
+		fks    *fkMap
 		ctx    context.Context
 		signal func() // a function that we'll call whenever we have new schema data
 
@@ -67,7 +69,7 @@ const defaultConsumeDelay = 1 * time.Second
 const aclErrorMessageLog = "Table ACL might be enabled, --schema_change_signal_user needs to be passed to VTGate for schema tracking to work. Check 'schema tracking' docs on vitess.io"
 
 // NewTracker creates the tracker object.
-func NewTracker(ch chan *discovery.TabletHealth, user string, enableViews bool) *Tracker {
+func NewTracker(ch chan *discovery.TabletHealth, user string, enableViews, enableForeignKeys bool) *Tracker {
 	ctx := context.Background()
 	// Set the caller on the context if the user is provided.
 	// This user that will be sent down to vttablet calls.
@@ -78,7 +80,7 @@ func NewTracker(ch chan *discovery.TabletHealth, user string, enableViews bool)
 	t := &Tracker{
 		ctx:          ctx,
 		ch:           ch,
-		tables:       &tableMap{m: map[keyspaceStr]map[tableNameStr][]vindexes.Column{}},
+		tables:       newTableMap(),
 		tracked:      map[keyspaceStr]*updateController{},
 		consumeDelay: defaultConsumeDelay,
 	}
@@ -86,6 +88,9 @@ func NewTracker(ch chan *discovery.TabletHealth, user string, enableViews bool)
 	if enableViews {
 		t.views = &viewMap{m: map[keyspaceStr]map[viewNameStr]sqlparser.SelectStatement{}}
 	}
+	if enableForeignKeys {
+		t.fks = &fkMap{m: map[keyspaceStr]map[tableNameStr][]vindexes.ForeignKey{}}
+	}
 	return t
 }
 
@@ -99,6 +104,10 @@ func (t *Tracker) LoadKeyspace(conn queryservice.QueryService, target *querypb.T
 	if err != nil {
 		return err
 	}
+	err = t.loadForeignKeys(conn, target)
+	if err != nil {
+		return err
+	}
 
 	t.tracked[target.Keyspace].setLoaded(true)
 	return nil
@@ -151,6 +160,26 @@ func (t *Tracker) loadViews(conn queryservice.QueryService, target *querypb.Targ
 	return nil
 }
 
+func (t *Tracker) loadForeignKeys(conn queryservice.QueryService, target *querypb.Target) error {
+	if t.fks == nil {
+		// This happens only when foreign keys are not managed by vtgate.
+		return nil
+	}
+
+	fkRes, err := conn.Execute(t.ctx, target, mysql.FetchForeignKeys, nil, 0, 0, nil)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clearKeyspaceForeignKeys(target.Keyspace)
+	t.updateForeignKeys(target.Keyspace, fkRes)
+	log.Infof("finished loading foreign keys for keyspace %s. Found %d foreign key columns in total", target.Keyspace, len(fkRes.Rows))
+
+	return nil
+}
+
 // Start starts the schema tracking.
 func (t *Tracker) Start() {
 	log.Info("Starting schema tracking")
@@ -228,6 +257,14 @@ func (t *Tracker) Tables(ks string) map[string][]vindexes.Column {
 	return m
 }
 
+// PrimaryKeys returns the columns of the primary key of the known tables in the keyspace that have one.
+func (t *Tracker) PrimaryKeys(ks string) map[string]sqlparser.Columns {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.tables.pks[ks]
+}
+
 // Views returns all known views in the keyspace with their definition.
 func (t *Tracker) Views(ks string) map[string]sqlparser.SelectStatement {
 	t.mu.Lock()
@@ -271,6 +308,16 @@ func (t *Tracker) updatedTableSchema(th *discovery.TabletHealth) bool {
 		return false
 	}
 
+	var fkRes *sqltypes.Result
+	if t.fks != nil {
+		fkRes, err = th.Conn.Execute(t.ctx, th.Target, mysql.FetchUpdatedForeignKeys, bv, 0, 0, nil)
+		if err != nil {
+			t.tracked[th.Target.Keyspace].setLoaded(false)
+			log.Warningf("error fetching foreign keys for %v: %v", tablesUpdated, err)
+			return false
+		}
+	}
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -280,6 +327,13 @@ func (t *Tracker) updatedTableSchema(th *discovery.TabletHealth) bool {
 		t.tables.delete(th.Target.Keyspace, tbl)
 	}
 	t.updateTables(th.Target.Keyspace, res)
+
+	if fkRes != nil {
+		for _, tbl := range tablesUpdated {
+			t.fks.delete(th.Target.Keyspace, tbl)
+		}
+		t.updateForeignKeys(th.Target.Keyspace, fkRes)
+	}
 	return true
 }
 
@@ -289,12 +343,63 @@ func (t *Tracker) updateTables(keyspace string, res *sqltypes.Result) {
 		colName := row[1].ToString()
 		colType := row[2].ToString()
 		collation := row[3].ToString()
+		colKey := row[4].ToString()
 
 		cType := sqlparser.ColumnType{Type: colType}
 		col := vindexes.Column{Name: sqlparser.NewIdentifierCI(colName), Type: cType.SQLType(), CollationName: collation}
 		cols := t.tables.get(keyspace, tbl)
 
 		t.tables.set(keyspace, tbl, append(cols, col))
+		if colKey == "PRI" {
+			t.tables.addPrimaryKeyColumn(keyspace, tbl, col.Name)
+		}
+	}
+}
+
+// updateForeignKeys reads the rows of FetchForeignKeys, which come ordered so that
+// the columns of each constraint are adjacent and in ordinal position.
+func (t *Tracker) updateForeignKeys(keyspace string, res *sqltypes.Result) {
+	var fk *vindexes.ForeignKey
+	var fkTable string
+	flush := func() {
+		if fk != nil {
+			t.fks.add(keyspace, fkTable, *fk)
+		}
+	}
+	for _, row := range res.Rows {
+		tbl := row[0].ToString()
+		name := row[1].ToString()
+		if fk == nil || fkTable != tbl || fk.Name != name {
+			flush()
+			fkTable = tbl
+			fk = &vindexes.ForeignKey{
+				Name:        name,
+				ParentTable: row[3].ToString(),
+				OnUpdate:    referenceAction(row[5].ToString()),
+				OnDelete:    referenceAction(row[6].ToString()),
+			}
+		}
+		fk.ChildColumns = append(fk.ChildColumns, sqlparser.NewIdentifierCI(row[2].ToString()))
+		fk.ParentColumns = append(fk.ParentColumns, sqlparser.NewIdentifierCI(row[4].ToString()))
+	}
+	flush()
+}
+
+// referenceAction maps the rules found in information_schema.referential_constraints.
+func referenceAction(rule string) sqlparser.ReferenceAction {
+	switch strings.ToUpper(rule) {
+	case "CASCADE":
+		return sqlparser.Cascade
+	case "SET NULL":
+		return sqlparser.SetNull
+	case "SET DEFAULT":
+		return sqlparser.SetDefault
+	case "RESTRICT":
+		return sqlparser.Restrict
+	case "NO ACTION":
+		return sqlparser.NoAction
+	default:
+		return sqlparser.DefaultAction
 	}
 }
 
@@ -339,6 +444,17 @@ func (t *Tracker) updateViews(keyspace string, res *sqltypes.Result) {
 	}
 }
 
+// ForeignKeys returns the foreign keys defined on the tables of the keyspace, keyed by child table.
+func (t *Tracker) ForeignKeys(ks string) map[string][]vindexes.ForeignKey {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.fks == nil {
+		return nil
+	}
+	return t.fks.m[ks]
+}
+
 // RegisterSignalReceiver allows a function to register to be called when new schema is available
 func (t *Tracker) RegisterSignalReceiver(f func()) {
 	t.mu.Lock()
@@ -361,7 +477,15 @@ func (t *Tracker) AddNewKeyspace(conn queryservice.QueryService, target *querypb
 }
 
 type tableMap struct {
-	m map[keyspaceStr]map[tableNameStr][]vindexes.Column
+	m   map[keyspaceStr]map[tableNameStr][]vindexes.Column
+	pks map[keyspaceStr]map[tableNameStr]sqlparser.Columns
+}
+
+func newTableMap() *tableMap {
+	return &tableMap{
+		m:   map[keyspaceStr]map[tableNameStr][]vindexes.Column{},
+		pks: map[keyspaceStr]map[tableNameStr]sqlparser.Columns{},
+	}
 }
 
 func (tm *tableMap) set(ks, tbl string, cols []vindexes.Column) {
@@ -381,7 +505,17 @@ func (tm *tableMap) get(ks, tbl string) []vindexes.Column {
 	return m[tbl]
 }
 
+func (tm *tableMap) addPrimaryKeyColumn(ks, tbl string, col sqlparser.IdentifierCI) {
+	m := tm.pks[ks]
+	if m == nil {
+		m = make(map[tableNameStr]sqlparser.Columns)
+		tm.pks[ks] = m
+	}
+	m[tbl] = append(m[tbl], col)
+}
+
 func (tm *tableMap) delete(ks, tbl string) {
+	delete(tm.pks[ks], tbl)
 	m := tm.m[ks]
 	if m == nil {
 		return
@@ -395,6 +529,7 @@ func (tm *tableMap) delete(ks, tbl string) {
 func (t *Tracker) clearKeyspaceTables(ks string) {
 	if t.tables != nil && t.tables.m != nil {
 		delete(t.tables.m, ks)
+		delete(t.tables.pks, ks)
 	}
 }
 
@@ -450,3 +585,30 @@ func (t *Tracker) GetViews(ks string, tbl string) sqlparser.SelectStatement {
 
 	return t.views.get(ks, tbl)
 }
+
+type fkMap struct {
+	m map[keyspaceStr]map[tableNameStr][]vindexes.ForeignKey
+}
+
+func (fm *fkMap) add(ks, tbl string, fk vindexes.ForeignKey) {
+	m := fm.m[ks]
+	if m == nil {
+		m = make(map[tableNameStr][]vindexes.ForeignKey)
+		fm.m[ks] = m
+	}
+	m[tbl] = append(m[tbl], fk)
+}
+
+func (fm *fkMap) delete(ks, tbl string) {
+	m := fm.m[ks]
+	if m == nil {
+		return
+	}
+	delete(m, tbl)
+}
+
+func (t *Tracker) clearKeyspaceForeignKeys(ks string) {
+	if t.fks != nil && t.fks.m != nil {
+		delete(t.fks.m, ks)
+	}
+}
@@ -51,8 +51,8 @@ func TestTracking(t *testing.T) {
 		Type:     target.TabletType,
 	}
 	fields := sqltypes.MakeTestFields(
-		"table_name|col_name|col_type|collation_name",
-		"varchar|varchar|varchar|varchar",
+		"table_name|col_name|col_type|collation_name|column_key",
+		"varchar|varchar|varchar|varchar|varchar",
 	)
 
 	type delta struct {
@@ -63,7 +63,7 @@ func TestTracking(t *testing.T) {
 		d0 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"prior|id|int|",
+				"prior|id|int||PRI",
 			),
 			updTbl: []string{"prior"},
 		}
@@ -71,9 +71,9 @@ func TestTracking(t *testing.T) {
 		d1 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t1|id|int|",
-				"t1|name|varchar|utf8_bin",
-				"t2|id|varchar|utf8_bin",
+				"t1|id|int||PRI",
+				"t1|name|varchar|utf8_bin|",
+				"t2|id|varchar|utf8_bin|PRI",
 			),
 			updTbl: []string{"t1", "t2"},
 		}
@@ -81,9 +81,9 @@ func TestTracking(t *testing.T) {
 		d2 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t2|id|varchar|utf8_bin",
-				"t2|name|varchar|utf8_bin",
-				"t3|id|datetime|",
+				"t2|id|varchar|utf8_bin|PRI",
+				"t2|name|varchar|utf8_bin|",
+				"t3|id|datetime||",
 			),
 			updTbl: []string{"prior", "t1", "t2", "t3"},
 		}
@@ -91,7 +91,7 @@ func TestTracking(t *testing.T) {
 		d3 = delta{
 			result: sqltypes.MakeTestResult(
 				fields,
-				"t4|name|varchar|utf8_bin",
+				"t4|name|varchar|utf8_bin|",
 			),
 			updTbl: []string{"t4"},
 		}
@@ -101,6 +101,7 @@ func TestTracking(t *testing.T) {
 		tName  string
 		deltas []delta
 		exp    map[string][]vindexes.Column
+		expPKs map[string]sqlparser.Columns
 	}{{
 		tName:  "new tables",
 		deltas: []delta{d0, d1},
@@ -113,6 +114,7 @@ func TestTracking(t *testing.T) {
 			"prior": {
 				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_INT32}},
 		},
+		expPKs: map[string]sqlparser.Columns{"t1": {sqlparser.NewIdentifierCI("id")}, "t2": {sqlparser.NewIdentifierCI("id")}, "prior": {sqlparser.NewIdentifierCI("id")}},
 	}, {
 		tName:  "delete t1 and prior, updated t2 and new t3",
 		deltas: []delta{d0, d1, d2},
@@ -123,6 +125,7 @@ func TestTracking(t *testing.T) {
 			"t3": {
 				{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_DATETIME}},
 		},
+		expPKs: map[string]sqlparser.Columns{"t2": {sqlparser.NewIdentifierCI("id")}, "t3": nil},
 	}, {
 		tName:  "new t4",
 		deltas: []delta{d0, d1, d2, d3},
@@ -135,13 +138,14 @@ func TestTracking(t *testing.T) {
 			"t4": {
 				{Name: sqlparser.NewIdentifierCI("name"), Type: querypb.Type_VARCHAR, CollationName: "utf8_bin"}},
 		},
+		expPKs: map[string]sqlparser.Columns{"t2": {sqlparser.NewIdentifierCI("id")}, "t3": nil, "t4": nil},
 	},
 	}
 	for i, tcase := range testcases {
 		t.Run(fmt.Sprintf("%d - %s", i, tcase.tName), func(t *testing.T) {
 			sbc := sandboxconn.NewSandboxConn(tablet)
 			ch := make(chan *discovery.TabletHealth)
-			tracker := NewTracker(ch, "", false)
+			tracker := NewTracker(ch, "", false, false)
 			tracker.consumeDelay = 1 * time.Millisecond
 			tracker.Start()
 			defer tracker.Stop()
@@ -191,6 +195,9 @@ func TestTracking(t *testing.T) {
 			for k, v := range tcase.exp {
 				utils.MustMatch(t, v, tracker.GetColumns("ks", k), "mismatch for table: ", k)
 			}
+			for k, v := range tcase.expPKs {
+				utils.MustMatch(t, v, tracker.PrimaryKeys("ks")[k], "mismatch of primary key for table: ", k)
+			}
 		})
 	}
 }
@@ -210,7 +217,7 @@ func TestTrackingUnHealthyTablet(t *testing.T) {
 
 	sbc := sandboxconn.NewSandboxConn(tablet)
 	ch := make(chan *discovery.TabletHealth)
-	tracker := NewTracker(ch, "", false)
+	tracker := NewTracker(ch, "", false, false)
 	tracker.consumeDelay = 1 * time.Millisecond
 	tracker.Start()
 	defer tracker.Stop()
@@ -378,7 +385,7 @@ func TestViewsTracking(t *testing.T) {
 		t.Run(fmt.Sprintf("%d - %s", i, tcase.vName), func(t *testing.T) {
 			sbc := sandboxconn.NewSandboxConn(tablet)
 			ch := make(chan *discovery.TabletHealth)
-			tracker := NewTracker(ch, "", true)
+			tracker := NewTracker(ch, "", true, false)
 			tracker.tables = nil // making tables map nil - so load keyspace does not try to load the tables information.
 			tracker.consumeDelay = 1 * time.Millisecond
 			tracker.Start()
@@ -437,3 +444,77 @@ func getResultSet(deltas []delta) []*sqltypes.Result {
 	}
 	return []*sqltypes.Result{{Fields: deltas[0].result.Fields, Rows: rows}}
 }
+
+func TestForeignKeysTracking(t *testing.T) {
+	target := &querypb.Target{Cell: "aa", Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY}
+	tablet := &topodatapb.Tablet{Keyspace: target.Keyspace, Shard: target.Shard, Type: target.TabletType}
+	fields := sqltypes.MakeTestFields(
+		"table_name|constraint_name|column_name|referenced_table_name|referenced_column_name|update_rule|delete_rule",
+		"varchar|varchar|varchar|varchar|varchar|varchar|varchar",
+	)
+
+	sbc := sandboxconn.NewSandboxConn(tablet)
+	ch := make(chan *discovery.TabletHealth)
+	tracker := NewTracker(ch, "", false, true)
+	tracker.consumeDelay = 1 * time.Millisecond
+	tracker.Start()
+	defer tracker.Stop()
+
+	sbc.SetResults([]*sqltypes.Result{{}, sqltypes.MakeTestResult(fields,
+		"child|fk_1|a|parent|x|CASCADE|SET NULL",
+		"child|fk_1|b|parent|y|CASCADE|SET NULL",
+		"child|fk_2|c|other|id|NO ACTION|RESTRICT",
+		"t2|fk_3|pid|parent|x|RESTRICT|CASCADE",
+	)})
+	sbc.Queries = nil
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	tracker.RegisterSignalReceiver(func() {
+		wg.Done()
+	})
+
+	ch <- &discovery.TabletHealth{
+		Conn:    sbc,
+		Tablet:  tablet,
+		Target:  target,
+		Serving: true,
+		Stats:   &querypb.RealtimeStats{TableSchemaChanged: []string{"child"}},
+	}
+
+	require.False(t, waitTimeout(&wg, time.Second), "schema was updated but received no signal")
+	require.Equal(t, []string{mysql.FetchTables, mysql.FetchForeignKeys}, sbc.StringQueries())
+
+	cols := func(names ...string) sqlparser.Columns {
+		var res sqlparser.Columns
+		for _, name := range names {
+			res = append(res, sqlparser.NewIdentifierCI(name))
+		}
+		return res
+	}
+	utils.MustMatch(t, map[string][]vindexes.ForeignKey{
+		"child": {{
+			Name:          "fk_1",
+			ChildColumns:  cols("a", "b"),
+			ParentTable:   "parent",
+			ParentColumns: cols("x", "y"),
+			OnDelete:      sqlparser.SetNull,
+			OnUpdate:      sqlparser.Cascade,
+		}, {
+			Name:          "fk_2",
+			ChildColumns:  cols("c"),
+			ParentTable:   "other",
+			ParentColumns: cols("id"),
+			OnDelete:      sqlparser.Restrict,
+			OnUpdate:      sqlparser.NoAction,
+		}},
+		"t2": {{
+			Name:          "fk_3",
+			ChildColumns:  cols("pid"),
+			ParentTable:   "parent",
+			ParentColumns: cols("x"),
+			OnDelete:      sqlparser.Cascade,
+			OnUpdate:      sqlparser.Restrict,
+		}},
+	}, tracker.ForeignKeys("ks"))
+}
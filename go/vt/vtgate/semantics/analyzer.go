@@ -68,6 +68,11 @@ func newAnalyzer(dbName string, si SchemaInformation) *analyzer {
 func Analyze(statement sqlparser.Statement, currentDb string, si SchemaInformation) (*SemTable, error) {
 	analyzer := newAnalyzer(currentDb, newSchemaInfo(si))
 
+	// Non-recursive common table expressions are turned into derived tables before the analysis
+	if err := rewriteCTEs(statement); err != nil {
+		return nil, err
+	}
+
 	// Analysis for initial scope
 	err := analyzer.analyze(statement)
 	if err != nil {
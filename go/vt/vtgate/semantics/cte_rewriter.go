@@ -0,0 +1,194 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// cteScope holds the common table expressions that are visible from a part of the query.
+// A nil entry means that the name is a recursive CTE, which shadows outer
+// definitions but can't be inlined.
+type cteScope struct {
+	parent *cteScope
+	ctes   map[string]*sqlparser.CommonTableExpr
+}
+
+func (s *cteScope) lookup(name string) *sqlparser.CommonTableExpr {
+	for scope := s; scope != nil; scope = scope.parent {
+		if cte, found := scope.ctes[name]; found {
+			return cte
+		}
+	}
+	return nil
+}
+
+// rewriteCTEs replaces all references to non-recursive common table expressions
+// with derived tables, so the rest of the planning only has to deal with derived tables.
+// Recursive CTEs can't be inlined; they are left in the WITH clause of the top level
+// statement, which the planner handles separately.
+func rewriteCTEs(statement sqlparser.Statement) error {
+	return rewriteCTEsIn(statement, nil, true)
+}
+
+func rewriteCTEsIn(node sqlparser.SQLNode, scope *cteScope, root bool) error {
+	var err error
+	_ = sqlparser.Rewrite(node, func(cursor *sqlparser.Cursor) bool {
+		if err != nil {
+			return false
+		}
+		switch n := cursor.Node().(type) {
+		case sqlparser.Statement:
+			with := getWith(n)
+			if with == nil {
+				return true
+			}
+			err = rewriteWith(n, with, scope, root && n == node)
+			return false
+		case *sqlparser.AliasedTableExpr:
+			tbl, ok := n.Expr.(sqlparser.TableName)
+			if !ok || !tbl.Qualifier.IsEmpty() {
+				return true
+			}
+			cte := scope.lookup(tbl.Name.String())
+			if cte == nil {
+				return true
+			}
+			n.Expr = &sqlparser.DerivedTable{Select: cloneCTEBody(cte.Subquery.Select)}
+			if n.As.IsEmpty() {
+				n.As = cte.ID
+			}
+			if len(n.Columns) == 0 {
+				n.Columns = sqlparser.CloneColumns(cte.Columns)
+			}
+			return false
+		}
+		return true
+	}, nil)
+	return err
+}
+
+// cloneCTEBody returns a copy of the CTE query that shares no nodes with the original.
+// CloneSelectStatement does not copy column names, and since the semantic analysis keys its
+// dependencies on them, every inlined reference needs its own ColName instances to be
+// bound to its own tables.
+func cloneCTEBody(sel sqlparser.SelectStatement) sqlparser.SelectStatement {
+	return sqlparser.Rewrite(sqlparser.CloneSelectStatement(sel), func(cursor *sqlparser.Cursor) bool {
+		if col, ok := cursor.Node().(*sqlparser.ColName); ok {
+			newCol := *col
+			cursor.Replace(&newCol)
+		}
+		return true
+	}, nil).(sqlparser.SelectStatement)
+}
+
+// rewriteWith inlines the CTEs of a WITH clause in the statement it belongs to
+func rewriteWith(stmt sqlparser.Statement, with *sqlparser.With, parent *cteScope, root bool) error {
+	scope := &cteScope{parent: parent, ctes: map[string]*sqlparser.CommonTableExpr{}}
+	var recursive []*sqlparser.CommonTableExpr
+	for _, cte := range with.CTEs {
+		name := cte.ID.String()
+		if with.Recursive && referencesTable(cte.Subquery, name) {
+			if !root {
+				return NewError(RecursiveCTEInSubquery, cte.ID.String())
+			}
+			// the recursive CTE can see itself and the CTEs defined before it
+			scope.ctes[name] = nil
+			if err := rewriteCTEsIn(cte.Subquery, scope, false); err != nil {
+				return err
+			}
+			recursive = append(recursive, cte)
+			continue
+		}
+
+		// a non-recursive CTE can only see the CTEs defined before it
+		if err := rewriteCTEsIn(cte.Subquery, scope, false); err != nil {
+			return err
+		}
+		if err := checkCTEColumns(cte); err != nil {
+			return err
+		}
+		scope.ctes[name] = cte
+	}
+
+	setWith(stmt, nil)
+	if err := rewriteCTEsIn(stmt, scope, false); err != nil {
+		return err
+	}
+	if len(recursive) > 0 {
+		setWith(stmt, &sqlparser.With{CTEs: recursive, Recursive: true})
+	}
+	return nil
+}
+
+func checkCTEColumns(cte *sqlparser.CommonTableExpr) error {
+	if len(cte.Columns) == 0 {
+		return nil
+	}
+	exprs := sqlparser.GetFirstSelect(cte.Subquery.Select).SelectExprs
+	if containsStar(exprs) {
+		// we can't check star expressions until they have been expanded
+		return nil
+	}
+	if len(exprs) != len(cte.Columns) {
+		return NewError(CTEColumnCountMismatch)
+	}
+	return nil
+}
+
+// referencesTable returns true if the node has an unqualified table reference to the given name
+func referencesTable(node sqlparser.SQLNode, name string) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			// column qualifiers are not table references
+			return false, nil
+		case sqlparser.TableName:
+			found = node.Qualifier.IsEmpty() && node.Name.String() == name
+		}
+		return !found, nil
+	}, node)
+	return found
+}
+
+func getWith(stmt sqlparser.Statement) *sqlparser.With {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		return stmt.With
+	case *sqlparser.Union:
+		return stmt.With
+	case *sqlparser.Update:
+		return stmt.With
+	case *sqlparser.Delete:
+		return stmt.With
+	}
+	return nil
+}
+
+func setWith(stmt sqlparser.Statement, with *sqlparser.With) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		stmt.With = with
+	case *sqlparser.Union:
+		stmt.With = with
+	case *sqlparser.Update:
+		stmt.With = with
+	case *sqlparser.Delete:
+		stmt.With = with
+	}
+}
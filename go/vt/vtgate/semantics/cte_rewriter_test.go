@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestRewriteCTEs(t *testing.T) {
+	tcases := []struct {
+		sql    string
+		expSQL string
+		expErr string
+	}{{
+		sql:    "with x as (select id from t1) select id from x",
+		expSQL: "select id from (select id from t1) as x",
+	}, {
+		sql:    "with x as (select id from t1) select a.id from x as a join x on a.id = x.id",
+		expSQL: "select a.id from (select id from t1) as a join (select id from t1) as x on a.id = x.id",
+	}, {
+		sql:    "with x as (select id from t1), y as (select id from x where id > 10) select id from y",
+		expSQL: "select id from (select id from (select id from t1) as x where id > 10) as y",
+	}, {
+		sql:    "with x(a, b) as (select id, col from t1) select a from x",
+		expSQL: "select a from (select id, col from t1) as x(a, b)",
+	}, {
+		sql:    "with x as (select id from t1) select id from db.x",
+		expSQL: "select id from db.x",
+	}, {
+		sql:    "select id from t1 where id in (with x as (select id from t2) select id from x)",
+		expSQL: "select id from t1 where id in (select id from (select id from t2) as x)",
+	}, {
+		sql:    "with x as (select id from t1) select id from x where id in (with x as (select id from t2) select id from x)",
+		expSQL: "select id from (select id from t1) as x where id in (select id from (select id from t2) as x)",
+	}, {
+		sql:    "with x as (select id from t1) select id from x union select id from x",
+		expSQL: "select id from (select id from t1) as x union select id from (select id from t1) as x",
+	}, {
+		sql:    "with recursive x as (select id from t1), t as (select id from x union all select t.id + 1 from t where t.id < 3) select id from t",
+		expSQL: "with recursive t as (select id from (select id from t1) as x union all select t.id + 1 from t where t.id < 3) select id from t",
+	}, {
+		sql:    "with x(a, b) as (select id from t1) select a from x",
+		expErr: "In definition of view, derived table or common table expression, SELECT list and column names list have different column counts",
+	}, {
+		sql:    "select id from t1 where id in (with recursive t as (select 1 as id union all select id + 1 from t where id < 3) select id from t)",
+		expErr: "VT12001: unsupported: recursive common table expression t in a subquery",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			ast, err := sqlparser.Parse(tcase.sql)
+			require.NoError(t, err)
+			err = rewriteCTEs(ast)
+			if tcase.expErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tcase.expSQL, sqlparser.String(ast))
+			} else {
+				require.EqualError(t, err, tcase.expErr)
+			}
+		})
+	}
+}
+
+func TestRewriteCTEsCopiesColumns(t *testing.T) {
+	ast, err := sqlparser.Parse("with x as (select id from t1) select a.id from x as a join x as b on a.id = b.id")
+	require.NoError(t, err)
+	require.NoError(t, rewriteCTEs(ast))
+
+	// every inlined reference must have its own column names, so they can be bound to different tables
+	var cols []*sqlparser.ColName
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if col, ok := node.(*sqlparser.ColName); ok && col.Qualifier.IsEmpty() {
+			cols = append(cols, col)
+		}
+		return true, nil
+	}, ast)
+	require.Len(t, cols, 2)
+	assert.NotSame(t, cols[0], cols[1])
+}
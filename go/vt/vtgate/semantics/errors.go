@@ -65,6 +65,8 @@ const (
 	Buggy
 	ColumnNotFound
 	AmbiguousColumn
+	RecursiveCTEInSubquery
+	CTEColumnCountMismatch
 )
 
 func NewError(code ErrorCode, args ...any) *Error {
@@ -143,6 +145,15 @@ var errors = map[ErrorCode]info{
 		state:  vterrors.BadFieldError,
 		code:   vtrpcpb.Code_INVALID_ARGUMENT,
 	},
+	RecursiveCTEInSubquery: {
+		format: "recursive common table expression %s in a subquery",
+		typ:    Unsupported,
+	},
+	CTEColumnCountMismatch: {
+		format: "In definition of view, derived table or common table expression, SELECT list and column names list have different column counts",
+		state:  vterrors.WrongNumberOfColumnsInSelect,
+		code:   vtrpcpb.Code_INVALID_ARGUMENT,
+	},
 }
 
 func (n *Error) Error() string {
@@ -92,10 +92,25 @@ func (txc *TxConn) Commit(ctx context.Context, session *SafeSession) error {
 		twopc = txc.mode == vtgatepb.TransactionMode_TWOPC
 	}
 
+	// The targets have to be collected before the commit, which clears the transaction ids.
+	var targets []*querypb.Target
+	if session.ReadAfterWriteConsistency() {
+		targets = session.transactionTargets()
+	}
+
+	var err error
 	if twopc {
-		return txc.commit2PC(ctx, session)
+		err = txc.commit2PC(ctx, session)
+	} else {
+		err = txc.commitNormal(ctx, session)
+	}
+	if err != nil {
+		return err
 	}
-	return txc.commitNormal(ctx, session)
+	for _, target := range targets {
+		recordShardPosition(ctx, txc.tabletGateway, session, target)
+	}
+	return nil
 }
 
 func (txc *TxConn) queryService(alias *topodatapb.TabletAlias) (queryservice.QueryService, error) {
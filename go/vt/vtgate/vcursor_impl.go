@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"vitess.io/vitess/go/vt/vtgate/logstats"
 
@@ -102,6 +103,7 @@ type vcursorImpl struct {
 	collation      collations.ID
 
 	ignoreMaxMemoryRows bool
+	resultCacheTTL      time.Duration
 	vschema             *vindexes.VSchema
 	vm                  VSchemaOperator
 	semTable            *semantics.SemTable
@@ -197,11 +199,21 @@ func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
 	return !vc.ignoreMaxMemoryRows && numRows > maxMemoryRows
 }
 
+// HashJoinMemoryLimit implements the VCursor interface.
+func (vc *vcursorImpl) HashJoinMemoryLimit() int64 {
+	return hashJoinMemoryLimit
+}
+
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
 func (vc *vcursorImpl) SetIgnoreMaxMemoryRows(ignoreMaxMemoryRows bool) {
 	vc.ignoreMaxMemoryRows = ignoreMaxMemoryRows
 }
 
+// SetResultCacheTTL sets how long the result of the query may be cached.
+func (vc *vcursorImpl) SetResultCacheTTL(ttl time.Duration) {
+	vc.resultCacheTTL = ttl
+}
+
 // RecordWarning stores the given warning in the current session
 func (vc *vcursorImpl) RecordWarning(warning *querypb.QueryWarning) {
 	vc.safeSession.RecordWarning(warning)
@@ -893,6 +905,12 @@ func (vc *vcursorImpl) SetReadAfterWriteTimeout(timeout float64) {
 	vc.safeSession.SetReadAfterWriteTimeout(timeout)
 }
 
+// SetReadAfterWriteConsistency implements the SessionActions interface
+func (vc *vcursorImpl) SetReadAfterWriteConsistency(_ context.Context, enable bool) error {
+	vc.safeSession.SetReadAfterWriteConsistency(enable)
+	return nil
+}
+
 // SetSessionTrackGTIDs implements the SessionActions interface
 func (vc *vcursorImpl) SetSessionTrackGTIDs(enable bool) {
 	vc.safeSession.SetSessionTrackGtids(enable)
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// ForeignKey is a foreign key constraint as it is defined on the child table.
+// The parent table is only known by name, it gets resolved against the
+// vschema by AddForeignKey.
+type ForeignKey struct {
+	Name          string
+	ChildColumns  sqlparser.Columns
+	ParentTable   string
+	ParentColumns sqlparser.Columns
+	OnDelete      sqlparser.ReferenceAction
+	OnUpdate      sqlparser.ReferenceAction
+}
+
+// ParentFKInfo describes a foreign key from the point of view of the child table.
+type ParentFKInfo struct {
+	Table         *Table
+	ParentColumns sqlparser.Columns
+	ChildColumns  sqlparser.Columns
+}
+
+// ChildFKInfo describes a foreign key from the point of view of the parent table.
+type ChildFKInfo struct {
+	Table         *Table
+	ChildColumns  sqlparser.Columns
+	ParentColumns sqlparser.Columns
+	OnDelete      sqlparser.ReferenceAction
+	OnUpdate      sqlparser.ReferenceAction
+}
+
+// MarshalJSON returns a JSON representation of ParentFKInfo.
+func (fk ParentFKInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name          string            `json:"parent_table"`
+		ParentColumns sqlparser.Columns `json:"parent_columns"`
+		ChildColumns  sqlparser.Columns `json:"child_columns"`
+	}{
+		Name:          qualifiedTableName(fk.Table),
+		ChildColumns:  fk.ChildColumns,
+		ParentColumns: fk.ParentColumns,
+	})
+}
+
+// MarshalJSON returns a JSON representation of ChildFKInfo.
+func (fk ChildFKInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name          string            `json:"child_table"`
+		ChildColumns  sqlparser.Columns `json:"child_columns"`
+		ParentColumns sqlparser.Columns `json:"parent_columns"`
+		OnDelete      string            `json:"on_delete,omitempty"`
+		OnUpdate      string            `json:"on_update,omitempty"`
+	}{
+		Name:          qualifiedTableName(fk.Table),
+		ChildColumns:  fk.ChildColumns,
+		ParentColumns: fk.ParentColumns,
+		OnDelete:      sqlparser.String(fk.OnDelete),
+		OnUpdate:      sqlparser.String(fk.OnUpdate),
+	})
+}
+
+// CrossKeyspace returns true if the child table lives in another keyspace than the parent.
+// MySQL cannot enforce such a foreign key, so vtgate has to do it.
+func (fk ParentFKInfo) CrossKeyspace(child *Table) bool {
+	return fk.Table.Keyspace.Name != child.Keyspace.Name
+}
+
+// CrossShard returns true if the rows related by this foreign key can live on different shards
+// of the same keyspace. Neither MySQL nor vtgate can enforce such a foreign key.
+func (fk ParentFKInfo) CrossShard(child *Table) bool {
+	return !fk.CrossKeyspace(child) && !isShardScoped(fk.Table, child, fk.ParentColumns, fk.ChildColumns)
+}
+
+// CrossKeyspace returns true if the child table lives in another keyspace than the parent.
+// MySQL cannot enforce such a foreign key, so vtgate has to do it.
+func (fk ChildFKInfo) CrossKeyspace(parent *Table) bool {
+	return fk.Table.Keyspace.Name != parent.Keyspace.Name
+}
+
+// CrossShard returns true if the rows related by this foreign key can live on different shards
+// of the same keyspace. Neither MySQL nor vtgate can enforce such a foreign key.
+func (fk ChildFKInfo) CrossShard(parent *Table) bool {
+	return !fk.CrossKeyspace(parent) && !isShardScoped(parent, fk.Table, fk.ParentColumns, fk.ChildColumns)
+}
+
+// isShardScoped returns true if a parent row and all the child rows referencing it
+// are guaranteed to live on the same shard. For a sharded keyspace this is the case when
+// both tables use the same primary vindex, and the foreign key maps the child's
+// primary vindex columns onto the parent's primary vindex columns.
+func isShardScoped(parent, child *Table, pCols, cCols sqlparser.Columns) bool {
+	if !parent.Keyspace.Sharded {
+		return true
+	}
+	if len(parent.ColumnVindexes) == 0 || len(child.ColumnVindexes) == 0 {
+		return false
+	}
+	pPrimary := parent.ColumnVindexes[0]
+	cPrimary := child.ColumnVindexes[0]
+	if pPrimary.Name != cPrimary.Name || len(pPrimary.Columns) != len(cPrimary.Columns) {
+		return false
+	}
+	for i, cCol := range cPrimary.Columns {
+		idx := cCols.FindColumn(cCol)
+		if idx == -1 || !pCols[idx].Equal(pPrimary.Columns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddForeignKey resolves the parent table of the given foreign key and links it to the child table.
+// The parent table is looked up in the keyspace of the child first, and then amongst
+// the tables that are unique across all keyspaces.
+func (vschema *VSchema) AddForeignKey(ksname, childTableName string, fk ForeignKey) error {
+	ks, ok := vschema.Keyspaces[ksname]
+	if !ok {
+		return fmt.Errorf("keyspace %s not found in vschema", ksname)
+	}
+	child, ok := ks.Tables[childTableName]
+	if !ok {
+		return fmt.Errorf("child table %s not found in keyspace %s", childTableName, ksname)
+	}
+	if len(fk.ChildColumns) == 0 || len(fk.ChildColumns) != len(fk.ParentColumns) {
+		return fmt.Errorf("foreign key %s on %s has mismatched column lists", fk.Name, childTableName)
+	}
+	parent, ok := ks.Tables[fk.ParentTable]
+	if !ok {
+		parent, ok = vschema.globalTables[fk.ParentTable]
+		if !ok {
+			return fmt.Errorf("parent table %s of foreign key %s not found", fk.ParentTable, fk.Name)
+		}
+		if parent == nil {
+			return fmt.Errorf("parent table %s of foreign key %s is ambiguous", fk.ParentTable, fk.Name)
+		}
+	}
+	child.ParentForeignKeys = append(child.ParentForeignKeys, ParentFKInfo{
+		Table:         parent,
+		ParentColumns: fk.ParentColumns,
+		ChildColumns:  fk.ChildColumns,
+	})
+	parent.ChildForeignKeys = append(parent.ChildForeignKeys, ChildFKInfo{
+		Table:         child,
+		ChildColumns:  fk.ChildColumns,
+		ParentColumns: fk.ParentColumns,
+		OnDelete:      fk.OnDelete,
+		OnUpdate:      fk.OnUpdate,
+	})
+	return nil
+}
+
+func qualifiedTableName(t *Table) string {
+	if t.Keyspace == nil {
+		return t.Name.String()
+	}
+	return t.Keyspace.Name + "." + t.Name.String()
+}
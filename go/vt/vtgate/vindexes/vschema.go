@@ -101,6 +101,9 @@ type Table struct {
 	Columns                 []Column               `json:"columns,omitempty"`
 	Pinned                  []byte                 `json:"pinned,omitempty"`
 	ColumnListAuthoritative bool                   `json:"column_list_authoritative,omitempty"`
+	// PrimaryKey holds the columns of the primary key of the table, in the order of the table
+	// definition. It is only known when the schema tracking is enabled.
+	PrimaryKey sqlparser.Columns `json:"primary_key,omitempty"`
 	// ReferencedBy is an inverse mapping of tables in other keyspaces that
 	// reference this table via Source.
 	//
@@ -110,6 +113,11 @@ type Table struct {
 	// Source is a keyspace-qualified table name that points to the source of a
 	// reference table. Only applicable for tables with Type set to "reference".
 	Source *Source `json:"source,omitempty"`
+
+	// ParentForeignKeys are the foreign keys defined on this table, pointing to its parents.
+	ParentForeignKeys []ParentFKInfo `json:"parent_foreign_keys,omitempty"`
+	// ChildForeignKeys are the foreign keys of other tables that reference this table.
+	ChildForeignKeys []ChildFKInfo `json:"child_foreign_keys,omitempty"`
 }
 
 // Keyspace contains the keyspcae info for each Table.
@@ -50,6 +50,8 @@ type VSchemaManager struct {
 type SchemaInfo interface {
 	Tables(ks string) map[string][]vindexes.Column
 	Views(ks string) map[string]sqlparser.SelectStatement
+	ForeignKeys(ks string) map[string][]vindexes.ForeignKey
+	PrimaryKeys(ks string) map[string]sqlparser.Columns
 }
 
 // GetCurrentSrvVschema returns a copy of the latest SrvVschema from the
@@ -191,6 +193,7 @@ func (vm *VSchemaManager) buildAndEnhanceVSchema(v *vschemapb.SrvVSchema) *vinde
 func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 	for ksName, ks := range vschema.Keyspaces {
 		m := vm.schema.Tables(ksName)
+		pks := vm.schema.PrimaryKeys(ksName)
 
 		for tblName, columns := range m {
 			vTbl := ks.Tables[tblName]
@@ -201,9 +204,11 @@ func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 					Keyspace:                ks.Keyspace,
 					Columns:                 columns,
 					ColumnListAuthoritative: true,
+					PrimaryKey:              pks[tblName],
 				}
 				continue
 			}
+			vTbl.PrimaryKey = pks[tblName]
 			if !vTbl.ColumnListAuthoritative {
 				// if we found the matching table and the vschema view of it is not authoritative, then we just update the columns of the table
 				vTbl.Columns = columns
@@ -219,4 +224,16 @@ func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 			}
 		}
 	}
+
+	// foreign keys are added once all the tables are known, since the parent
+	// of a foreign key can live in another keyspace than the child.
+	for ksName := range vschema.Keyspaces {
+		for tblName, fks := range vm.schema.ForeignKeys(ksName) {
+			for _, fk := range fks {
+				if err := vschema.AddForeignKey(ksName, tblName, fk); err != nil {
+					log.Warningf("ignoring foreign key %s on %s.%s: %v", fk.Name, ksName, tblName, err)
+				}
+			}
+		}
+	}
 }
@@ -3,6 +3,9 @@ package vtgate
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"vitess.io/vitess/go/test/utils"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -28,12 +31,14 @@ func TestVSchemaUpdate(t *testing.T) {
 	tblCol1 := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols1, ColumnListAuthoritative: true}
 	tblCol2 := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2, ColumnListAuthoritative: true}
 	tblCol2NA := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2}
+	tblCol2PK := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2, ColumnListAuthoritative: true, PrimaryKey: sqlparser.Columns{sqlparser.NewIdentifierCI("uid")}}
 
 	tcases := []struct {
 		name           string
 		srvVschema     *vschemapb.SrvVSchema
 		currentVSchema *vindexes.VSchema
 		schema         map[string][]vindexes.Column
+		pks            map[string]sqlparser.Columns
 		expected       *vindexes.VSchema
 	}{{
 		name: "0 Schematracking- 1 srvVSchema",
@@ -85,6 +90,18 @@ func TestVSchemaUpdate(t *testing.T) {
 		schema: map[string][]vindexes.Column{"tbl": cols1},
 		// schema tracker will be ignored for authoritative tables.
 		expected: makeTestVSchema("ks", false, map[string]*vindexes.Table{"tbl": tblCol2}),
+	}, {
+		name: "1 Schematracking with a primary key - 1 srvVSchema (have columns) authoritative",
+		srvVschema: makeTestSrvVSchema("ks", false, map[string]*vschemapb.Table{
+			"tbl": {
+				Columns:                 []*vschemapb.Column{{Name: "uid", Type: querypb.Type_INT64}, {Name: "name", Type: querypb.Type_VARCHAR}},
+				ColumnListAuthoritative: true,
+			},
+		}),
+		schema: map[string][]vindexes.Column{"tbl": cols2},
+		pks:    map[string]sqlparser.Columns{"tbl": {sqlparser.NewIdentifierCI("uid")}},
+		// the primary key is taken from the schema tracker even for authoritative tables.
+		expected: makeTestVSchema("ks", false, map[string]*vindexes.Table{"tbl": tblCol2PK}),
 	}, {
 		name:     "srvVschema received as nil",
 		schema:   map[string][]vindexes.Column{"tbl": cols1},
@@ -104,7 +121,7 @@ func TestVSchemaUpdate(t *testing.T) {
 	for _, tcase := range tcases {
 		t.Run(tcase.name, func(t *testing.T) {
 			vs = nil
-			vm.schema = &fakeSchema{t: tcase.schema}
+			vm.schema = &fakeSchema{t: tcase.schema, pks: tcase.pks}
 			vm.currentSrvVschema = nil
 			vm.currentVschema = tcase.currentVSchema
 			vm.VSchemaUpdate(tcase.srvVschema, nil)
@@ -216,6 +233,59 @@ func TestRebuildVSchema(t *testing.T) {
 	}
 }
 
+func TestVSchemaUpdateWithForeignKeys(t *testing.T) {
+	cols := []vindexes.Column{{
+		Name: sqlparser.NewIdentifierCI("id"),
+		Type: querypb.Type_INT64,
+	}, {
+		Name: sqlparser.NewIdentifierCI("pid"),
+		Type: querypb.Type_INT64,
+	}}
+	vm := &VSchemaManager{}
+	var vs *vindexes.VSchema
+	vm.subscriber = func(vschema *vindexes.VSchema, _ *VSchemaStats) {
+		vs = vschema
+	}
+	vm.schema = &fakeSchema{
+		t: map[string][]vindexes.Column{"parent": cols, "child": cols},
+		fks: map[string][]vindexes.ForeignKey{
+			"child": {{
+				Name:          "fk_child_parent",
+				ChildColumns:  sqlparser.Columns{sqlparser.NewIdentifierCI("pid")},
+				ParentTable:   "parent",
+				ParentColumns: sqlparser.Columns{sqlparser.NewIdentifierCI("id")},
+				OnDelete:      sqlparser.Cascade,
+				OnUpdate:      sqlparser.Restrict,
+			}},
+			// the parent of this foreign key is unknown, it gets ignored.
+			"parent": {{
+				Name:          "fk_parent_missing",
+				ChildColumns:  sqlparser.Columns{sqlparser.NewIdentifierCI("pid")},
+				ParentTable:   "missing",
+				ParentColumns: sqlparser.Columns{sqlparser.NewIdentifierCI("id")},
+			}},
+		},
+	}
+	vm.VSchemaUpdate(makeTestSrvVSchema("ks", false, nil), nil)
+
+	parent := vs.Keyspaces["ks"].Tables["parent"]
+	child := vs.Keyspaces["ks"].Tables["child"]
+	require.Len(t, child.ParentForeignKeys, 1)
+	require.Len(t, parent.ChildForeignKeys, 1)
+	require.Empty(t, parent.ParentForeignKeys)
+
+	pFk := child.ParentForeignKeys[0]
+	assert.Same(t, parent, pFk.Table)
+	assert.Equal(t, "(pid)", sqlparser.String(pFk.ChildColumns))
+	assert.False(t, pFk.CrossKeyspace(child))
+	assert.False(t, pFk.CrossShard(child))
+
+	cFk := parent.ChildForeignKeys[0]
+	assert.Same(t, child, cFk.Table)
+	assert.Equal(t, sqlparser.Cascade, cFk.OnDelete)
+	assert.Equal(t, sqlparser.Restrict, cFk.OnUpdate)
+}
+
 func makeTestVSchema(ks string, sharded bool, tbls map[string]*vindexes.Table) *vindexes.VSchema {
 	keyspaceSchema := &vindexes.KeyspaceSchema{
 		Keyspace: &vindexes.Keyspace{
@@ -248,7 +318,9 @@ func makeTestSrvVSchema(ks string, sharded bool, tbls map[string]*vschemapb.Tabl
 }
 
 type fakeSchema struct {
-	t map[string][]vindexes.Column
+	t   map[string][]vindexes.Column
+	fks map[string][]vindexes.ForeignKey
+	pks map[string]sqlparser.Columns
 }
 
 func (f *fakeSchema) Tables(string) map[string][]vindexes.Column {
@@ -259,4 +331,12 @@ func (f *fakeSchema) Views(ks string) map[string]sqlparser.SelectStatement {
 	return nil
 }
 
+func (f *fakeSchema) ForeignKeys(string) map[string][]vindexes.ForeignKey {
+	return f.fks
+}
+
+func (f *fakeSchema) PrimaryKeys(string) map[string]sqlparser.Columns {
+	return f.pks
+}
+
 var _ SchemaInfo = (*fakeSchema)(nil)
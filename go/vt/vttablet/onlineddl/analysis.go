@@ -19,6 +19,7 @@ package onlineddl
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"vitess.io/vitess/go/mysql"
@@ -32,6 +33,7 @@ type specialAlterOperation string
 
 const (
 	instantDDLSpecialOperation         specialAlterOperation = "instant-ddl"
+	inplaceDDLSpecialOperation         specialAlterOperation = "inplace-ddl"
 	dropRangePartitionSpecialOperation specialAlterOperation = "drop-range-partition"
 	addRangePartitionSpecialOperation  specialAlterOperation = "add-range-partition"
 )
@@ -244,6 +246,12 @@ func alterOptionAvailableViaInstantDDL(alterOption sqlparser.AlterOption, create
 		//    in another table. Which is a bit too much to compute here.
 		return false, nil
 	case *sqlparser.AddColumns:
+		for _, col := range opt.Columns {
+			if col.Type.Options != nil && col.Type.Options.As != nil && col.Type.Options.Storage == sqlparser.StoredStorage {
+				// A stored generated column needs to be computed for all rows, which is never instant
+				return false, nil
+			}
+		}
 		if opt.First || opt.After != nil {
 			// not a "last" column. Only supported as of 8.0.29
 			return capableOf(mysql.InstantAddDropColumnFlavorCapability)
@@ -263,6 +271,12 @@ func alterOptionAvailableViaInstantDDL(alterOption sqlparser.AlterOption, create
 		}
 		return capableOf(mysql.InstantAddDropColumnFlavorCapability)
 	case *sqlparser.ModifyColumn:
+		if opt.First || opt.After != nil {
+			// Reordering a column. Only supported as of MariaDB 10.4
+			if capable, err := capableOf(mysql.InstantReorderColumnFlavorCapability); err != nil || !capable {
+				return false, err
+			}
+		}
 		if col := findColumn(opt.NewColDefinition.Name.String()); col != nil {
 			// Check if only diff is change of default
 			// we temporarily remove the DEFAULT expression (if any) from both
@@ -345,6 +359,255 @@ func AnalyzeInstantDDL(alterTable *sqlparser.AlterTable, createTable *sqlparser.
 	return op, nil
 }
 
+// AlterTableAlgorithm is the cheapest algorithm with which InnoDB can run an ALTER TABLE, on a given server.
+// reference: https://dev.mysql.com/doc/refman/8.0/en/innodb-online-ddl-operations.html
+// reference: https://mariadb.com/kb/en/innodb-online-ddl-operations-with-the-inplace-alter-algorithm/
+type AlterTableAlgorithm string
+
+const (
+	// InstantAlterTableAlgorithm only changes metadata, with ALGORITHM=INSTANT
+	InstantAlterTableAlgorithm AlterTableAlgorithm = "instant"
+	// InplaceNoRebuildAlterTableAlgorithm runs with ALGORITHM=INPLACE, and does not rebuild the table. It may
+	// still take long, e.g. when adding an index.
+	InplaceNoRebuildAlterTableAlgorithm AlterTableAlgorithm = "inplace-no-rebuild"
+	// InplaceRebuildAlterTableAlgorithm runs with ALGORITHM=INPLACE, and rebuilds the table
+	InplaceRebuildAlterTableAlgorithm AlterTableAlgorithm = "inplace-rebuild"
+	// CopyAlterTableAlgorithm runs with ALGORITHM=COPY, and blocks writes to the table
+	CopyAlterTableAlgorithm AlterTableAlgorithm = "copy"
+)
+
+var alterTableAlgorithmCost = map[AlterTableAlgorithm]int{
+	InstantAlterTableAlgorithm:          0,
+	InplaceNoRebuildAlterTableAlgorithm: 1,
+	InplaceRebuildAlterTableAlgorithm:   2,
+	CopyAlterTableAlgorithm:             3,
+}
+
+// costlier returns the costlier of the two algorithms
+func (a AlterTableAlgorithm) costlier(other AlterTableAlgorithm) AlterTableAlgorithm {
+	if alterTableAlgorithmCost[other] > alterTableAlgorithmCost[a] {
+		return other
+	}
+	return a
+}
+
+// alterOptionInplaceAlgorithm returns the algorithm with which InnoDB can run the given alter option, when it
+// cannot run with ALGORITHM=INSTANT.
+func alterOptionInplaceAlgorithm(alterOption sqlparser.AlterOption, alterTable *sqlparser.AlterTable, createTable *sqlparser.CreateTable) AlterTableAlgorithm {
+	findColumn := func(colName string) *sqlparser.ColumnDefinition {
+		for _, col := range createTable.TableSpec.Columns {
+			if strings.EqualFold(colName, col.Name.String()) {
+				return col
+			}
+		}
+		return nil
+	}
+	hasFulltextIndex := func() bool {
+		for _, index := range createTable.TableSpec.Indexes {
+			if index.Info.Fulltext {
+				return true
+			}
+		}
+		return false
+	}
+	addsPrimaryKey := func() bool {
+		for _, opt := range alterTable.AlterOptions {
+			if addIndex, ok := opt.(*sqlparser.AddIndexDefinition); ok && addIndex.IndexDefinition.Info.Primary {
+				return true
+			}
+		}
+		return false
+	}
+	isGeneratedColumn := func(col *sqlparser.ColumnDefinition, storage sqlparser.ColumnStorage) bool {
+		return col != nil && col.Type.Options != nil && col.Type.Options.As != nil && col.Type.Options.Storage == storage
+	}
+
+	switch opt := alterOption.(type) {
+	case *sqlparser.AddIndexDefinition:
+		switch {
+		case opt.IndexDefinition.Info.Primary:
+			return InplaceRebuildAlterTableAlgorithm
+		case opt.IndexDefinition.Info.Fulltext && !hasFulltextIndex():
+			// The first FULLTEXT index adds a hidden FTS_DOC_ID column
+			return InplaceRebuildAlterTableAlgorithm
+		}
+		return InplaceNoRebuildAlterTableAlgorithm
+	case *sqlparser.DropKey:
+		if opt.Type == sqlparser.PrimaryKeyType {
+			if addsPrimaryKey() {
+				return InplaceRebuildAlterTableAlgorithm
+			}
+			// InnoDB cannot rebuild the table in place without a primary key
+			return CopyAlterTableAlgorithm
+		}
+		return InplaceNoRebuildAlterTableAlgorithm
+	case *sqlparser.RenameIndex, *sqlparser.AlterIndex, *sqlparser.RenameColumn, *sqlparser.AlterColumn:
+		return InplaceNoRebuildAlterTableAlgorithm
+	case *sqlparser.AddColumns:
+		algorithm := InplaceNoRebuildAlterTableAlgorithm
+		for _, col := range opt.Columns {
+			switch {
+			case isGeneratedColumn(col, sqlparser.StoredStorage):
+				algorithm = algorithm.costlier(CopyAlterTableAlgorithm)
+			case isGeneratedColumn(col, sqlparser.VirtualStorage):
+			default:
+				algorithm = algorithm.costlier(InplaceRebuildAlterTableAlgorithm)
+			}
+		}
+		return algorithm
+	case *sqlparser.DropColumn:
+		if isGeneratedColumn(findColumn(opt.Name.Name.String()), sqlparser.VirtualStorage) {
+			return InplaceNoRebuildAlterTableAlgorithm
+		}
+		return InplaceRebuildAlterTableAlgorithm
+	case *sqlparser.ModifyColumn:
+		return changeColumnInplaceAlgorithm(findColumn(opt.NewColDefinition.Name.String()), opt.NewColDefinition, opt.First || opt.After != nil)
+	case *sqlparser.ChangeColumn:
+		return changeColumnInplaceAlgorithm(findColumn(opt.OldColumn.Name.String()), opt.NewColDefinition, opt.First || opt.After != nil)
+	case sqlparser.TableOptions:
+		algorithm := InplaceNoRebuildAlterTableAlgorithm
+		for _, tableOption := range opt {
+			switch strings.TrimPrefix(strings.ToUpper(tableOption.Name), "DEFAULT ") {
+			case "AUTO_INCREMENT", "COMMENT", "STATS_PERSISTENT", "STATS_AUTO_RECALC", "STATS_SAMPLE_PAGES":
+			case "ROW_FORMAT", "KEY_BLOCK_SIZE", "ENGINE", "CHARSET", "CHARACTER SET", "COLLATE":
+				algorithm = algorithm.costlier(InplaceRebuildAlterTableAlgorithm)
+			default:
+				algorithm = algorithm.costlier(CopyAlterTableAlgorithm)
+			}
+		}
+		return algorithm
+	case *sqlparser.Force:
+		return InplaceRebuildAlterTableAlgorithm
+	case sqlparser.AlgorithmValue, *sqlparser.LockOption, *sqlparser.Validation, *sqlparser.KeyState:
+		// These do not change the table
+		return InstantAlterTableAlgorithm
+	}
+	// Adding a foreign key is only INPLACE with foreign_key_checks disabled. Adding a CHECK constraint,
+	// changing the character set of the data, partitioning and ORDER BY all copy the table.
+	return CopyAlterTableAlgorithm
+}
+
+// changeColumnInplaceAlgorithm returns the algorithm with which InnoDB can change an existing column into the
+// given new column, when it cannot do so with ALGORITHM=INSTANT.
+func changeColumnInplaceAlgorithm(col *sqlparser.ColumnDefinition, newCol *sqlparser.ColumnDefinition, reorder bool) AlterTableAlgorithm {
+	if col == nil || col.Type.Options == nil || newCol.Type.Options == nil {
+		return CopyAlterTableAlgorithm
+	}
+	col = sqlparser.CloneRefOfColumnDefinition(col)
+	newCol = sqlparser.CloneRefOfColumnDefinition(newCol)
+	// Renaming a column and changing its default or its comment only change metadata
+	newCol.Name = col.Name
+	for _, c := range []*sqlparser.ColumnDefinition{col, newCol} {
+		c.Type.Options.Default = nil
+		c.Type.Options.Comment = nil
+	}
+	algorithm := InplaceNoRebuildAlterTableAlgorithm
+	if reorder {
+		algorithm = InplaceRebuildAlterTableAlgorithm
+	}
+	if sqlparser.CanonicalString(col) == sqlparser.CanonicalString(newCol) {
+		return algorithm
+	}
+	if strings.EqualFold(col.Type.Type, newCol.Type.Type) && strings.EqualFold(col.Type.Type, "varchar") &&
+		col.Type.Length != nil && newCol.Type.Length != nil {
+		// Extending a VARCHAR is in place as long as its length still takes as many bytes. We do not know the
+		// character set here, so we assume it takes up to 4 bytes per character.
+		length, err := strconv.Atoi(col.Type.Length.Val)
+		if err != nil {
+			return CopyAlterTableAlgorithm
+		}
+		newLength, err := strconv.Atoi(newCol.Type.Length.Val)
+		if err != nil {
+			return CopyAlterTableAlgorithm
+		}
+		if newLength >= length && (newLength*4 < 256 || length >= 256) {
+			newCol.Type.Length = col.Type.Length
+		}
+	}
+	if len(col.Type.EnumValues) > 0 && len(newCol.Type.EnumValues) > len(col.Type.EnumValues) {
+		// Appending values to an ENUM or a SET is in place as long as its storage size does not change
+		isPrefix := true
+		for i, val := range col.Type.EnumValues {
+			isPrefix = isPrefix && val == newCol.Type.EnumValues[i]
+		}
+		storageSize := func(count int) int {
+			if strings.EqualFold(col.Type.Type, "set") {
+				return (count + 7) / 8
+			}
+			if count <= 255 {
+				return 1
+			}
+			return 2
+		}
+		if isPrefix && storageSize(len(col.Type.EnumValues)) == storageSize(len(newCol.Type.EnumValues)) {
+			newCol.Type.EnumValues = col.Type.EnumValues
+		}
+	}
+	if sqlparser.CanonicalString(col) == sqlparser.CanonicalString(newCol) {
+		return algorithm
+	}
+	// Changing the nullability of a column rebuilds the table
+	newCol.Type.Options.Null = col.Type.Options.Null
+	if sqlparser.CanonicalString(col) == sqlparser.CanonicalString(newCol) {
+		return InplaceRebuildAlterTableAlgorithm
+	}
+	// Any other change of the column, e.g. of its data type, copies the table
+	return CopyAlterTableAlgorithm
+}
+
+// ClassifyAlterTable returns the cheapest algorithm with which InnoDB can run the given ALTER TABLE on the given
+// table, on a server with the given capabilities.
+// This function is INTENTIONALLY public, even though we do not guarantee that it will remain so.
+func ClassifyAlterTable(alterTable *sqlparser.AlterTable, createTable *sqlparser.CreateTable, capableOf mysql.CapableOf) (AlterTableAlgorithm, error) {
+	if alterTable.PartitionOption != nil {
+		// repartitioning, or removing partitioning
+		return CopyAlterTableAlgorithm, nil
+	}
+	if spec := alterTable.PartitionSpec; spec != nil {
+		part := createTable.TableSpec.PartitionOption
+		switch {
+		case len(alterTable.AlterOptions) > 0:
+			return CopyAlterTableAlgorithm, nil
+		case part == nil || (part.Type != sqlparser.RangeType && part.Type != sqlparser.ListType):
+			// Adding or dropping HASH and KEY partitions redistributes the rows
+			return CopyAlterTableAlgorithm, nil
+		case spec.Action == sqlparser.AddAction, spec.Action == sqlparser.DropAction:
+			return InplaceNoRebuildAlterTableAlgorithm, nil
+		default:
+			return CopyAlterTableAlgorithm, nil
+		}
+	}
+	instantPlan, err := AnalyzeInstantDDL(alterTable, createTable, capableOf)
+	if err != nil {
+		return "", err
+	}
+	if instantPlan != nil {
+		return InstantAlterTableAlgorithm, nil
+	}
+	algorithm := InplaceNoRebuildAlterTableAlgorithm
+	for _, alterOption := range alterTable.AlterOptions {
+		algorithm = algorithm.costlier(alterOptionInplaceAlgorithm(alterOption, alterTable, createTable))
+	}
+	return algorithm, nil
+}
+
+// analyzeAlterTableAlgorithm classifies the ALTER TABLE of the given online DDL, see ClassifyAlterTable()
+func (e *Executor) analyzeAlterTableAlgorithm(ctx context.Context, onlineDDL *schema.OnlineDDL, capableOf mysql.CapableOf) (AlterTableAlgorithm, error) {
+	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
+	if err != nil {
+		return "", err
+	}
+	alterTable, ok := ddlStmt.(*sqlparser.AlterTable)
+	if !ok {
+		return "", vterrors.Errorf(vtrpcpb.Code_INTERNAL, "expected ALTER TABLE. Got %v", sqlparser.CanonicalString(ddlStmt))
+	}
+	createTable, err := e.getCreateTableStatement(ctx, onlineDDL.Table)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "in Executor.analyzeAlterTableAlgorithm(), uuid=%v, table=%v", onlineDDL.UUID, onlineDDL.Table)
+	}
+	return ClassifyAlterTable(alterTable, createTable, capableOf)
+}
+
 // analyzeSpecialAlterPlan checks if the given ALTER onlineDDL, and for the current state of affected table,
 // can be executed in a special way. If so, it returns with a "special plan"
 func (e *Executor) analyzeSpecialAlterPlan(ctx context.Context, onlineDDL *schema.OnlineDDL, capableOf mysql.CapableOf) (*SpecialAlterPlan, error) {
@@ -386,5 +649,21 @@ func (e *Executor) analyzeSpecialAlterPlan(ctx context.Context, onlineDDL *schem
 			return op, nil
 		}
 	}
+	if onlineDDL.StrategySetting().IsPreferInplaceDDL() {
+		// The user explicitly accepts INPLACE, which does not rebuild the table but may take long, e.g. to build
+		// an index, and is replicated as a single ALTER that lags the replicas for as long. We pick the cheapest
+		// of INSTANT and INPLACE without rebuilding the table. Anything that rebuilds the table is best done by
+		// the online schema change.
+		algorithm, err := ClassifyAlterTable(alterTable, createTable, capableOf)
+		if err != nil {
+			return nil, err
+		}
+		switch algorithm {
+		case InstantAlterTableAlgorithm:
+			return NewSpecialAlterOperation(instantDDLSpecialOperation, alterTable, createTable), nil
+		case InplaceNoRebuildAlterTableAlgorithm:
+			return NewSpecialAlterOperation(inplaceDDLSpecialOperation, alterTable, createTable), nil
+		}
+	}
 	return nil, nil
 }
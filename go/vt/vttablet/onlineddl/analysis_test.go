@@ -237,3 +237,204 @@ func TestAnalyzeInstantDDL(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyAlterTable(t *testing.T) {
+	tt := []struct {
+		version   string
+		create    string
+		alter     string
+		algorithm AlterTableAlgorithm
+	}{
+		// add/drop columns
+		{
+			version:   "5.7.28",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int not null",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int not null",
+			algorithm: InstantAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t drop column i1",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int as (i1 + 1) stored",
+			algorithm: CopyAlterTableAlgorithm,
+		},
+		{
+			version:   "5.7.28",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int as (i1 + 1) virtual",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "10.2.9-MariaDB",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int not null",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "10.3.2-MariaDB-log",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add column i2 int not null",
+			algorithm: InstantAlterTableAlgorithm,
+		},
+		{
+			version:   "10.3.2-MariaDB-log",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t drop column i1",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "5.5.5-10.4.12-MariaDB",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t drop column i1",
+			algorithm: InstantAlterTableAlgorithm,
+		},
+		// indexes
+		{
+			version:   "5.7.28",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add key i1_idx(i1)",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id), key i1_idx(i1))",
+			alter:     "alter table t drop key i1_idx",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id), key i1_idx(i1))",
+			alter:     "alter table t rename index i1_idx to i1_key",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int not null, i1 int not null)",
+			alter:     "alter table t add primary key(id)",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t drop primary key",
+			algorithm: CopyAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t drop primary key, add primary key(id, i1)",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t add key i1_idx(i1), drop column i1",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		// modify columns
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t modify column i1 bigint not null",
+			algorithm: CopyAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t modify column i1 int",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, v varchar(10), primary key(id))",
+			alter:     "alter table t modify column v varchar(20)",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, v varchar(10), primary key(id))",
+			alter:     "alter table t modify column v varchar(100)",
+			algorithm: CopyAlterTableAlgorithm,
+		},
+		{
+			version:   "5.7.28",
+			create:    "create table t(id int, e enum('a', 'b'), primary key(id))",
+			alter:     "alter table t modify column e enum('a', 'b', 'c')",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.30",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t modify column i1 int not null first",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "5.5.5-10.4.12-MariaDB",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t modify column i1 int not null first",
+			algorithm: InstantAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t rename column i1 to i2",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		// table options and partitions
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t auto_increment=100",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t engine=innodb",
+			algorithm: InplaceRebuildAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, i1 int not null, primary key(id))",
+			alter:     "alter table t partition by hash (id) partitions 4",
+			algorithm: CopyAlterTableAlgorithm,
+		},
+		{
+			version:   "8.0.21",
+			create:    "create table t(id int, primary key(id)) partition by range (id) (partition p1 values less than (10))",
+			alter:     "alter table t add partition (partition p2 values less than (20))",
+			algorithm: InplaceNoRebuildAlterTableAlgorithm,
+		},
+	}
+	for _, tc := range tt {
+		name := tc.version + " " + tc.alter
+		t.Run(name, func(t *testing.T) {
+			stmt, err := sqlparser.ParseStrictDDL(tc.create)
+			require.NoError(t, err)
+			createTable, ok := stmt.(*sqlparser.CreateTable)
+			require.True(t, ok)
+
+			stmt, err = sqlparser.ParseStrictDDL(tc.alter)
+			require.NoError(t, err)
+			alterTable, ok := stmt.(*sqlparser.AlterTable)
+			require.True(t, ok)
+
+			_, capableOf, _ := mysql.GetFlavor(tc.version, nil)
+			algorithm, err := ClassifyAlterTable(alterTable, createTable, capableOf)
+			require.NoError(t, err)
+			assert.Equal(t, tc.algorithm, algorithm)
+		})
+	}
+}
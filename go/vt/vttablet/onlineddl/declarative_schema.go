@@ -0,0 +1,705 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// A declarative schema migration is submitted with the --declarative-schema strategy flag, as a group of
+// migrations sharing a migration context: one CREATE statement for each table and view of the desired schema,
+// and one DROP statement for each table and view that is not part of it. The group is launched once all of its
+// migrations are submitted.
+//
+// The group is evaluated as a whole by schemadiff, against the actual schema of the tablet. A migration of the
+// group then either has nothing to do and is complete, or is an ALTER TABLE that runs through vreplication, or is
+// an immediate operation: creating or dropping a table or a view, or altering a view. The ALTER TABLE migrations
+// run in parallel, and the group is cut over at once when all of them are ready to complete: their tables are
+// swapped in a single RENAME, and the immediate operations are applied right after, in an order that is valid
+// for the desired schema.
+
+// declarativeSchemaDiff is the evaluation of a declarative schema group.
+type declarativeSchemaDiff struct {
+	// diffs maps the name of a table or view to the change it needs. Names that need no change are missing.
+	diffs map[string]schemadiff.EntityDiff
+	// order is the order in which the changes are applied: drops first, then the rest in the order of the desired schema.
+	order []string
+	// err is the error of the evaluation, which fails the group.
+	err error
+}
+
+// declarativeSchemaMigration is a migration of a declarative schema group.
+type declarativeSchemaMigration struct {
+	onlineDDL *schema.OnlineDDL
+	row       sqltypes.RowNamedValues
+}
+
+func (m *declarativeSchemaMigration) isPending() bool {
+	switch m.onlineDDL.Status {
+	case schema.OnlineDDLStatusQueued, schema.OnlineDDLStatusReady, schema.OnlineDDLStatusRunning:
+		return true
+	}
+	return false
+}
+
+// isSameDeclarativeSchemaGroup returns true when both migrations are part of the same declarative schema group.
+func isSameDeclarativeSchemaGroup(onlineDDL1, onlineDDL2 *schema.OnlineDDL) bool {
+	if onlineDDL1.MigrationContext == "" || onlineDDL1.MigrationContext != onlineDDL2.MigrationContext {
+		return false
+	}
+	return onlineDDL1.StrategySetting().IsDeclarativeSchema() && onlineDDL2.StrategySetting().IsDeclarativeSchema()
+}
+
+// evaluateDeclarativeSchemaDiff evaluates the changes of a declarative schema group against the current schema.
+// Both the current schema and the changes map the name of a table or view to its CREATE statement. An empty
+// change stands for a DROP.
+func evaluateDeclarativeSchemaDiff(current map[string]string, changes map[string]string) (*declarativeSchemaDiff, error) {
+	desired := map[string]string{}
+	for name, query := range current {
+		desired[name] = query
+	}
+	for name, query := range changes {
+		if query == "" {
+			delete(desired, name)
+		} else {
+			desired[name] = query
+		}
+	}
+	sortedQueries := func(queries map[string]string) []string {
+		result := make([]string, 0, len(queries))
+		for _, query := range queries {
+			result = append(result, query)
+		}
+		sort.Strings(result)
+		return result
+	}
+	currentSchema, err := schemadiff.NewSchemaFromQueries(sortedQueries(current))
+	if err != nil {
+		return nil, err
+	}
+	desiredSchema, err := schemadiff.NewSchemaFromQueries(sortedQueries(desired))
+	if err != nil {
+		return nil, err
+	}
+	hints := &schemadiff.DiffHints{AutoIncrementStrategy: schemadiff.AutoIncrementApplyHigher}
+	diffs, err := currentSchema.Diff(desiredSchema, hints)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &declarativeSchemaDiff{diffs: map[string]schemadiff.EntityDiff{}}
+	var drops, others []string
+	for _, diff := range diffs {
+		from, to := diff.Entities()
+		var name string
+		if to != nil {
+			name = to.Name()
+		} else {
+			name = from.Name()
+		}
+		if _, ok := d.diffs[name]; ok {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cannot change %s both ways in a declarative schema migration: is it turning from a table into a view, or the other way around?", name)
+		}
+		d.diffs[name] = diff
+		switch diff.(type) {
+		case *schemadiff.DropTableEntityDiff, *schemadiff.DropViewEntityDiff:
+			drops = append(drops, name)
+		default:
+			others = append(others, name)
+		}
+	}
+	// Views depend on tables and on other views. The current schema lists its entities so that each comes after the
+	// ones it depends on, hence we drop them in reverse order. The desired schema lists its entities in a valid order.
+	for i, j := 0, len(drops)-1; i < j; i, j = i+1, j-1 {
+		drops[i], drops[j] = drops[j], drops[i]
+	}
+	desiredIndex := map[string]int{}
+	for i, name := range desiredSchema.EntityNames() {
+		desiredIndex[name] = i
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return desiredIndex[others[i]] < desiredIndex[others[j]]
+	})
+	d.order = append(drops, others...)
+	return d, nil
+}
+
+// readSchemaQueries returns the CREATE statements of the tables and views of the schema, by name, except for
+// the tables that are internal to online DDL and to table GC.
+func (e *Executor) readSchemaQueries(ctx context.Context) (map[string]string, error) {
+	r, err := e.execQuery(ctx, sqlShowFullTables)
+	if err != nil {
+		return nil, err
+	}
+	queries := map[string]string{}
+	for _, row := range r.Rows {
+		name := row[0].ToString()
+		if schema.IsInternalOperationTableName(name) {
+			continue
+		}
+		query, err := e.showCreateTable(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		queries[name] = query
+	}
+	return queries, nil
+}
+
+// readDeclarativeSchemaGroup reads all the migrations of the declarative schema group of the given context.
+func (e *Executor) readDeclarativeSchemaGroup(ctx context.Context, migrationContext string) (group []*declarativeSchemaMigration, err error) {
+	query, err := sqlparser.ParseAndBind(sqlSelectMigrationsByContext,
+		sqltypes.StringBindVariable(migrationContext),
+	)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.execQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, uuidRow := range r.Named().Rows {
+		onlineDDL, row, err := e.readMigration(ctx, uuidRow["migration_uuid"].ToString())
+		if err != nil {
+			return nil, err
+		}
+		if !onlineDDL.StrategySetting().IsDeclarativeSchema() {
+			continue
+		}
+		group = append(group, &declarativeSchemaMigration{onlineDDL: onlineDDL, row: row})
+	}
+	return group, nil
+}
+
+// declarativeSchemaChanges returns the changes that the pending migrations of a declarative schema group make to
+// the schema: the CREATE statement of each table and view, or an empty string for a DROP. The CREATE statements of
+// the tables and views that exist in the given current schema are first normalized by MySQL, so that they compare
+// with their SHOW CREATE statements; see showCreateComparisonTable().
+func (e *Executor) declarativeSchemaChanges(ctx context.Context, group []*declarativeSchemaMigration, current map[string]string) (map[string]string, error) {
+	changes := map[string]string{}
+	for _, m := range group {
+		if !m.isPending() {
+			continue
+		}
+		ddlStmt, action, err := schema.ParseOnlineDDLStatement(m.onlineDDL.SQL)
+		if err != nil {
+			return nil, err
+		}
+		ddlStmt.SetComments(sqlparser.Comments{})
+		switch action {
+		case sqlparser.DropDDLAction:
+			changes[m.onlineDDL.Table] = ""
+		case sqlparser.CreateDDLAction:
+			query := sqlparser.String(ddlStmt)
+			if _, exists := current[m.onlineDDL.Table]; exists {
+				normalized, err := e.showCreateComparisonTable(ctx, m.onlineDDL, ddlStmt)
+				switch {
+				case err == nil:
+					// The comparison table has a different name. We restore the original one.
+					stmt, err := sqlparser.ParseStrictDDL(normalized)
+					if err != nil {
+						return nil, err
+					}
+					normalizedStmt, ok := stmt.(sqlparser.DDLStatement)
+					if !ok {
+						return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected statement: %s", normalized)
+					}
+					normalizedStmt.SetTable("", m.onlineDDL.Table)
+					query = sqlparser.String(normalizedStmt)
+				case m.row.AsBool("is_view", false):
+					// The view may depend on tables and views that the group has yet to create or change.
+					// We compare it as it is.
+				default:
+					return nil, err
+				}
+			}
+			changes[m.onlineDDL.Table] = query
+		default:
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "declarative schema migration %s: expected CREATE or DROP statement, got: %s", m.onlineDDL.UUID, m.onlineDDL.SQL)
+		}
+	}
+	return changes, nil
+}
+
+// reviewDeclarativeSchemaMigration reviews a queued migration of a declarative schema group. Its group is evaluated
+// once all of its migrations are launched, and only once per review, by means of groupDiffs. It returns false when
+// the migration cannot be reviewed yet, or when its group failed. Otherwise it returns whether the migration is an
+// immediate operation. A migration that has nothing to do is completed right away.
+func (e *Executor) reviewDeclarativeSchemaMigration(ctx context.Context, onlineDDL *schema.OnlineDDL, groupDiffs map[string]*declarativeSchemaDiff) (reviewed bool, isImmediate bool, err error) {
+	d, ok := groupDiffs[onlineDDL.MigrationContext]
+	if !ok {
+		group, err := e.readDeclarativeSchemaGroup(ctx, onlineDDL.MigrationContext)
+		if err != nil {
+			return false, false, err
+		}
+		for _, m := range group {
+			if m.isPending() && m.row.AsBool("postpone_launch", false) {
+				// The group is not fully launched yet
+				return false, false, nil
+			}
+		}
+		current, err := e.readSchemaQueries(ctx)
+		if err != nil {
+			return false, false, err
+		}
+		d, err = func() (*declarativeSchemaDiff, error) {
+			changes, err := e.declarativeSchemaChanges(ctx, group, current)
+			if err != nil {
+				return nil, err
+			}
+			return evaluateDeclarativeSchemaDiff(current, changes)
+		}()
+		if err != nil {
+			for _, m := range group {
+				if m.isPending() {
+					_ = e.failMigration(ctx, m.onlineDDL, err)
+				}
+			}
+			d = &declarativeSchemaDiff{err: err}
+		}
+		groupDiffs[onlineDDL.MigrationContext] = d
+	}
+	if d.err != nil {
+		return false, false, nil
+	}
+	diff, ok := d.diffs[onlineDDL.Table]
+	if !ok {
+		_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, rowsCopiedUnknown, emptyHint)
+		_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, "no change")
+		return false, false, nil
+	}
+	_ = e.updateMigrationMessage(ctx, onlineDDL.UUID, diff.CanonicalStatementString())
+	_, isAlterTable := diff.(*schemadiff.AlterTableEntityDiff)
+	return true, !isAlterTable, nil
+}
+
+// reviewDeclarativeSchemaGroups cuts over the declarative schema groups whose migrations are all ready: the
+// immediate operations are reviewed and queued, and the ALTER TABLE migrations are ready to complete. It returns
+// the pending migrations of the groups where a migration failed or was cancelled, so that they get cancelled.
+func (e *Executor) reviewDeclarativeSchemaGroups(ctx context.Context) (cancellable []*cancellableMigration, err error) {
+	e.migrationMutex.Lock()
+	defer e.migrationMutex.Unlock()
+
+	r, err := e.execQuery(ctx, sqlSelectPendingMigrationContexts)
+	if err != nil {
+		return cancellable, err
+	}
+	var migrationContexts []string
+	for _, row := range r.Named().Rows {
+		strategySetting := schema.NewDDLStrategySetting(schema.DDLStrategy(row["strategy"].ToString()), row["options"].ToString())
+		if strategySetting.IsDeclarativeSchema() {
+			migrationContexts = append(migrationContexts, row["migration_context"].ToString())
+		}
+	}
+	for _, migrationContext := range migrationContexts {
+		group, err := e.readDeclarativeSchemaGroup(ctx, migrationContext)
+		if err != nil {
+			return cancellable, err
+		}
+		var pending []*declarativeSchemaMigration
+		failedUUID := ""
+		isReady := true
+		for _, m := range group {
+			switch m.onlineDDL.Status {
+			case schema.OnlineDDLStatusFailed, schema.OnlineDDLStatusCancelled:
+				failedUUID = m.onlineDDL.UUID
+				continue
+			case schema.OnlineDDLStatusQueued:
+				// Immediate operations wait in queue for the cut-over
+				if m.row["reviewed_timestamp"].IsNull() || !m.row.AsBool("is_immediate_operation", false) {
+					isReady = false
+				}
+			case schema.OnlineDDLStatusReady:
+				isReady = false
+			case schema.OnlineDDLStatusRunning:
+				if !m.row.AsBool("ready_to_complete", false) {
+					isReady = false
+				}
+			default:
+				continue
+			}
+			if m.row.AsBool("postpone_launch", false) || m.row.AsBool("postpone_completion", false) {
+				isReady = false
+			}
+			pending = append(pending, m)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		if failedUUID != "" {
+			for _, m := range pending {
+				cancellable = append(cancellable, newCancellableMigration(m.onlineDDL.UUID, fmt.Sprintf("migration %s of declarative schema group %s did not complete", failedUUID, migrationContext)))
+			}
+			continue
+		}
+		if !isReady {
+			continue
+		}
+		if err := e.cutOverDeclarativeSchema(ctx, pending); err != nil {
+			for _, m := range pending {
+				_ = e.updateMigrationMessage(ctx, m.onlineDDL.UUID, err.Error())
+			}
+			return cancellable, err
+		}
+	}
+	return cancellable, nil
+}
+
+// lockTablesWriteQuery returns a LOCK TABLES query that write-locks the given tables.
+func lockTablesWriteQuery(tableNames ...string) string {
+	locks := make([]string, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		locks = append(locks, sqlescape.EscapeID(tableName)+" WRITE")
+	}
+	return "LOCK TABLES " + strings.Join(locks, ", ")
+}
+
+// swapTablesQuery returns a single RENAME TABLE query that swaps each of the given tables with its vreplication
+// table, by way of the sentry table.
+func swapTablesQuery(sentryTableName string, tableNames []string, vreplTableNames []string) string {
+	sentry := sqlescape.EscapeID(sentryTableName)
+	renames := make([]string, 0, 3*len(tableNames))
+	for i, tableName := range tableNames {
+		table := sqlescape.EscapeID(tableName)
+		vreplTable := sqlescape.EscapeID(vreplTableNames[i])
+		renames = append(renames,
+			fmt.Sprintf("%s TO %s", table, sentry),
+			fmt.Sprintf("%s TO %s", vreplTable, table),
+			fmt.Sprintf("%s TO %s", sentry, vreplTable),
+		)
+	}
+	return "RENAME TABLE " + strings.Join(renames, ", ")
+}
+
+// cutOverDeclarativeSchema cuts over the pending migrations of a declarative schema group at once. The tables of
+// the running migrations are swapped with their vreplication tables in a single RENAME, under a single LOCK, much
+// like cutOverVReplMigration() does for a single migration. The immediate operations of the group are applied right
+// after. Queries on all the tables and views of the group are buffered throughout.
+func (e *Executor) cutOverDeclarativeSchema(ctx context.Context, group []*declarativeSchemaMigration) error {
+	tmClient := e.tabletManagerClient()
+	defer tmClient.Close()
+
+	tablet, err := e.ts.GetTablet(ctx, e.tabletAlias)
+	if err != nil {
+		return err
+	}
+	updateStage := func(stage string, args ...any) {
+		for _, m := range group {
+			_ = e.updateMigrationStage(ctx, m.onlineDDL.UUID, stage, args...)
+		}
+	}
+	updateStage("starting cut-over")
+
+	var vreplMigrations []*declarativeSchemaMigration
+	var streams []*VReplStream
+	var tableNames, vreplTableNames []string
+	immediateMigrations := map[string]*declarativeSchemaMigration{}
+	for _, m := range group {
+		if m.onlineDDL.Status != schema.OnlineDDLStatusRunning {
+			immediateMigrations[m.onlineDDL.Table] = m
+			continue
+		}
+		s, err := e.readVReplStream(ctx, m.onlineDDL.UUID, false)
+		if err != nil {
+			return err
+		}
+		if err := e.incrementCutoverAttempts(ctx, m.onlineDDL.UUID); err != nil {
+			return err
+		}
+		vreplTable, err := getVreplTable(ctx, s)
+		if err != nil {
+			return err
+		}
+		vreplMigrations = append(vreplMigrations, m)
+		streams = append(streams, s)
+		tableNames = append(tableNames, m.onlineDDL.Table)
+		vreplTableNames = append(vreplTableNames, vreplTable)
+	}
+
+	// We evaluate the immediate operations while nothing happened yet. The tables of the running migrations are
+	// about to change, but not their names, which is all that matters for the order of the operations.
+	current, err := e.readSchemaQueries(ctx)
+	if err != nil {
+		return err
+	}
+	changes, err := e.declarativeSchemaChanges(ctx, group, nil)
+	if err != nil {
+		return err
+	}
+	d, err := evaluateDeclarativeSchemaDiff(current, changes)
+	if err != nil {
+		return err
+	}
+	var immediateOperations []*declarativeSchemaMigration
+	for _, name := range d.order {
+		if m, ok := immediateMigrations[name]; ok {
+			immediateOperations = append(immediateOperations, m)
+		}
+	}
+
+	waitForPos := func(s *VReplStream, pos mysql.Position) error {
+		ctx, cancel := context.WithTimeout(ctx, vreplicationCutOverThreshold)
+		defer cancel()
+		return tmClient.VReplicationWaitForPos(ctx, tablet.Tablet, s.id, mysql.EncodePosition(pos))
+	}
+
+	var sentryTableName string
+	if len(streams) > 0 {
+		// We create the sentry table before toggling writes, because this involves waiting for the position of
+		// all the streams, which takes some time. See cutOverVReplMigration().
+		sentryTableName, err = schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+		if err != nil {
+			return err
+		}
+		if err := e.updateArtifacts(ctx, vreplMigrations[0].onlineDDL.UUID, sentryTableName); err != nil {
+			return err
+		}
+		parsed := sqlparser.BuildParsedQuery(sqlCreateSentryTable, sentryTableName)
+		if _, err := e.execQuery(ctx, parsed.Query); err != nil {
+			return err
+		}
+		updateStage("sentry table created: %s", sentryTableName)
+
+		postSentryPos, err := e.primaryPosition(ctx)
+		if err != nil {
+			return err
+		}
+		updateStage("waiting for post-sentry pos: %v", mysql.EncodePosition(postSentryPos))
+		for _, s := range streams {
+			if err := waitForPos(s, postSentryPos); err != nil {
+				return err
+			}
+		}
+		updateStage("post-sentry pos reached")
+	}
+
+	bufferedTableNames := append([]string{}, tableNames...)
+	for _, m := range immediateOperations {
+		bufferedTableNames = append(bufferedTableNames, m.onlineDDL.Table)
+	}
+	bufferingCtx, bufferingContextCancel := context.WithCancel(ctx)
+	defer bufferingContextCancel()
+	toggleBuffering := func(bufferQueries bool) error {
+		log.Infof("toggling buffering: %t in declarative schema migration %v", bufferQueries, group[0].onlineDDL.MigrationContext)
+		for _, tableName := range bufferedTableNames {
+			e.toggleBufferTableFunc(bufferingCtx, tableName, bufferQueries)
+		}
+		if !bufferQueries {
+			// unbuffer existing queries, and force re-read of tables
+			bufferingContextCancel()
+			if err := tmClient.RefreshState(ctx, tablet.Tablet); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var reenableOnce sync.Once
+	reenableWritesOnce := func() {
+		reenableOnce.Do(func() {
+			toggleBuffering(false)
+		})
+	}
+	updateStage("buffering queries")
+	err = toggleBuffering(true)
+	defer reenableWritesOnce()
+	if err != nil {
+		return err
+	}
+	// Give a fraction of a second to queries that are about to execute, see cutOverVReplMigration()
+	updateStage("graceful wait for buffering")
+	time.Sleep(100 * time.Millisecond)
+
+	if len(streams) > 0 {
+		lockConn, err := e.pool.Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer lockConn.Recycle()
+		defer lockConn.Exec(ctx, sqlUnlockTables, 1, false)
+
+		renameConn, err := e.pool.Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer renameConn.Recycle()
+		defer renameConn.Kill("premature exit while renaming tables", 0)
+		renameQuery := swapTablesQuery(sentryTableName, tableNames, vreplTableNames)
+
+		waitForRenameProcess := func() error {
+			// See cutOverVReplMigration()
+			renameWaitCtx, cancel := context.WithTimeout(ctx, vreplicationCutOverThreshold)
+			defer cancel()
+
+			for {
+				renameProcessFound, err := e.doesConnectionInfoMatch(renameWaitCtx, renameConn.ID(), "rename")
+				if err != nil {
+					return err
+				}
+				if renameProcessFound {
+					return nil
+				}
+				select {
+				case <-renameWaitCtx.Done():
+					return vterrors.Errorf(vtrpcpb.Code_ABORTED, "timeout for rename query: %s", renameQuery)
+				case <-time.After(time.Second):
+					// sleep
+				}
+			}
+		}
+
+		updateStage("locking tables")
+		lockCtx, cancel := context.WithTimeout(ctx, vreplicationCutOverThreshold)
+		defer cancel()
+		if _, err := lockConn.Exec(lockCtx, lockTablesWriteQuery(append([]string{sentryTableName}, tableNames...)...), 1, false); err != nil {
+			return err
+		}
+
+		updateStage("renaming tables")
+		renameCompleteChan := make(chan error, 1)
+		go func() {
+			_, err := renameConn.Exec(ctx, renameQuery, 1, false)
+			renameCompleteChan <- err
+		}()
+		// the rename should block, because of the LOCK. Wait for it to show up.
+		updateStage("waiting for RENAME to block")
+		if err := waitForRenameProcess(); err != nil {
+			return err
+		}
+		updateStage("RENAME found")
+
+		updateStage("reading post-lock pos")
+		postWritesPos, err := e.primaryPosition(ctx)
+		if err != nil {
+			return err
+		}
+		updateStage("waiting for post-lock pos: %v", mysql.EncodePosition(postWritesPos))
+		for i, s := range streams {
+			_ = e.updateMigrationTimestamp(ctx, "liveness_timestamp", s.workflow)
+			// Writes are now disabled on all tables. Read up-to-date vreplication info, to get the latest pos
+			s, err = e.readVReplStream(ctx, s.workflow, false)
+			if err != nil {
+				return err
+			}
+			streams[i] = s
+			if err := waitForPos(s, postWritesPos); err != nil {
+				updateStage("timeout while waiting for post-lock pos: %v", err)
+				return err
+			}
+		}
+		updateStage("stopping vreplication")
+		for _, s := range streams {
+			if _, err := e.vreplicationExec(ctx, tablet.Tablet, binlogplayer.StopVReplication(s.id, "stopped for online DDL cutover")); err != nil {
+				return err
+			}
+		}
+
+		updateStage("validating rename is still in place")
+		if err := waitForRenameProcess(); err != nil {
+			return err
+		}
+		updateStage("dropping sentry table")
+		{
+			dropTableQuery := sqlparser.BuildParsedQuery(sqlDropTable, sentryTableName)
+			lockCtx, cancel := context.WithTimeout(ctx, vreplicationCutOverThreshold)
+			defer cancel()
+			if _, err := lockConn.Exec(lockCtx, dropTableQuery.Query, 1, false); err != nil {
+				return err
+			}
+		}
+		{
+			lockCtx, cancel := context.WithTimeout(ctx, vreplicationCutOverThreshold)
+			defer cancel()
+			updateStage("unlocking tables")
+			if _, err := lockConn.Exec(lockCtx, sqlUnlockTables, 1, false); err != nil {
+				return err
+			}
+		}
+		updateStage("waiting for RENAME to complete")
+		if err := <-renameCompleteChan; err != nil {
+			return err
+		}
+	}
+	// Tables are swapped. The ALTER TABLE migrations are complete, whatever happens to the immediate operations.
+	for i, m := range vreplMigrations {
+		_ = e.updateMigrationStage(ctx, m.onlineDDL.UUID, "cut-over complete")
+		e.ownedRunningMigrations.Delete(m.onlineDDL.UUID)
+		_ = e.onSchemaMigrationStatus(ctx, m.onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, streams[i].rowsCopied, emptyHint)
+	}
+
+	for _, m := range immediateOperations {
+		delete(immediateMigrations, m.onlineDDL.Table)
+		if err := e.applyDeclarativeSchemaOperation(ctx, m.onlineDDL, d.diffs[m.onlineDDL.Table]); err != nil {
+			return err
+		}
+	}
+	for _, m := range immediateMigrations {
+		// Someone else made this change since the group was reviewed
+		_ = e.onSchemaMigrationStatus(ctx, m.onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, rowsCopiedUnknown, emptyHint)
+		_ = e.updateMigrationMessage(ctx, m.onlineDDL.UUID, "no change")
+	}
+
+	go func() {
+		// See cutOverVReplMigration()
+		if err := e.reloadSchema(ctx); err != nil {
+			log.Errorf("Error on ReloadSchema while cutting over declarative schema migration %v: %v", group[0].onlineDDL.MigrationContext, err)
+		}
+	}()
+	reenableWritesOnce()
+	return nil
+}
+
+// applyDeclarativeSchemaOperation applies an immediate operation of a declarative schema group, which is the given diff.
+func (e *Executor) applyDeclarativeSchemaOperation(ctx context.Context, onlineDDL *schema.OnlineDDL, diff schemadiff.EntityDiff) error {
+	_ = e.updateMigrationStage(ctx, onlineDDL.UUID, "cut-over: %s", diff.CanonicalStatementString())
+	// We strip out any VT query comments, like runNextMigration() does
+	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
+	if err != nil {
+		return e.failMigration(ctx, onlineDDL, err)
+	}
+	ddlStmt.SetComments(sqlparser.Comments{})
+	onlineDDL.SQL = sqlparser.String(ddlStmt)
+
+	switch diff.(type) {
+	case *schemadiff.DropTableEntityDiff, *schemadiff.DropViewEntityDiff:
+		return e.executeDropDDLActionMigration(ctx, onlineDDL)
+	case *schemadiff.CreateTableEntityDiff, *schemadiff.CreateViewEntityDiff:
+		return e.executeCreateDDLActionMigration(ctx, onlineDDL)
+	case *schemadiff.AlterViewEntityDiff:
+		if err := e.executeAlterViewOnline(ctx, onlineDDL); err != nil {
+			return e.failMigration(ctx, onlineDDL, err)
+		}
+		return nil
+	}
+	return e.failMigration(ctx, onlineDDL, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected change in immediate operation of declarative schema migration: %s", diff.CanonicalStatementString()))
+}
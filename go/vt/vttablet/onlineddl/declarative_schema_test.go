@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package onlineddl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestEvaluateDeclarativeSchemaDiff(t *testing.T) {
+	current := map[string]string{
+		"t1": "create table t1 (id int primary key)",
+		"t2": "create table t2 (id int primary key)",
+		"t3": "create table t3 (id int primary key)",
+		"v1": "create view v1 as select id from t1",
+	}
+	tcases := []struct {
+		name    string
+		changes map[string]string
+		order   []string
+		diffs   map[string]string
+		wantErr string
+	}{
+		{
+			name: "no change",
+			changes: map[string]string{
+				"t1": "create table t1 (id int primary key)",
+				"v1": "create view v1 as select id from t1",
+			},
+		},
+		{
+			name: "alter table",
+			changes: map[string]string{
+				"t1": "create table t1 (id int primary key, i int)",
+				"t2": "create table t2 (id int primary key)",
+			},
+			order: []string{"t1"},
+			diffs: map[string]string{
+				"t1": "ALTER TABLE `t1` ADD COLUMN `i` int",
+			},
+		},
+		{
+			name: "drops come first, views come after the tables they read from",
+			changes: map[string]string{
+				"v2": "create view v2 as select id from t4",
+				"t4": "create table t4 (id int primary key)",
+				"t2": "",
+				"v1": "",
+			},
+			order: []string{"v1", "t2", "t4", "v2"},
+			diffs: map[string]string{
+				"v1": "DROP VIEW `v1`",
+				"t2": "DROP TABLE `t2`",
+				"t4": "CREATE TABLE `t4` (\n\t`id` int,\n\tPRIMARY KEY (`id`)\n)",
+				"v2": "CREATE VIEW `v2` AS SELECT `id` FROM `t4`",
+			},
+		},
+		{
+			name: "view reads from a dropped table",
+			changes: map[string]string{
+				"t1": "",
+			},
+			wantErr: "view `v1` has unresolved/loop dependencies",
+		},
+		{
+			name: "table turns into a view",
+			changes: map[string]string{
+				"t3": "create view t3 as select id from t1",
+			},
+			wantErr: "cannot change t3 both ways",
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			d, err := evaluateDeclarativeSchemaDiff(current, tcase.changes)
+			if tcase.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tcase.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.order, d.order)
+			diffs := map[string]string{}
+			for name, diff := range d.diffs {
+				diffs[name] = diff.CanonicalStatementString()
+			}
+			if tcase.diffs == nil {
+				tcase.diffs = map[string]string{}
+			}
+			assert.Equal(t, tcase.diffs, diffs)
+		})
+	}
+}
+
+func TestIsSameDeclarativeSchemaGroup(t *testing.T) {
+	migration := func(options string, migrationContext string) *schema.OnlineDDL {
+		return &schema.OnlineDDL{Strategy: schema.DDLStrategyVitess, Options: options, MigrationContext: migrationContext}
+	}
+	assert.True(t, isSameDeclarativeSchemaGroup(migration("--declarative-schema", "ctx1"), migration("--declarative-schema", "ctx1")))
+	assert.False(t, isSameDeclarativeSchemaGroup(migration("--declarative-schema", "ctx1"), migration("--declarative-schema", "ctx2")))
+	assert.False(t, isSameDeclarativeSchemaGroup(migration("--declarative-schema", ""), migration("--declarative-schema", "")))
+	assert.False(t, isSameDeclarativeSchemaGroup(migration("--declarative-schema", "ctx1"), migration("--declarative", "ctx1")))
+}
+
+func TestSwapTablesQuery(t *testing.T) {
+	assert.Equal(t, "LOCK TABLES `sentry` WRITE, `t1` WRITE, `t2` WRITE", lockTablesWriteQuery("sentry", "t1", "t2"))
+	assert.Equal(t,
+		"RENAME TABLE `t1` TO `sentry`, `vrepl1` TO `t1`, `sentry` TO `vrepl1`, `t2` TO `sentry`, `vrepl2` TO `t2`, `sentry` TO `vrepl2`",
+		swapTablesQuery("sentry", []string{"t1", "t2"}, []string{"vrepl1", "vrepl2"}),
+	)
+}
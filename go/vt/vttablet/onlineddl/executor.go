@@ -207,7 +207,10 @@ type Executor struct {
 	// - be adopted by this executor (possible for vreplication migrations), or
 	// - be terminated (example: pt-osc migration gone rogue, process still running even as the migration failed)
 	// The Executor auto-reviews the map and cleans up migrations thought to be running which are not running.
-	ownedRunningMigrations        sync.Map
+	ownedRunningMigrations sync.Map
+	// inplaceMigrationConnIDs maps the UUIDs of the running ALGORITHM=INPLACE migrations to the ID of the
+	// MySQL connection that runs their ALTER, so that they can be terminated.
+	inplaceMigrationConnIDs       sync.Map
 	tickReentranceFlag            int64
 	reviewedRunningMigrationsFlag bool
 
@@ -409,6 +412,10 @@ func (e *Executor) proposedMigrationConflictsWithRunningMigration(runningMigrati
 		// migrations operate on same table
 		return true
 	}
+	if isSameDeclarativeSchemaGroup(runningMigration, proposedMigration) {
+		// migrations of a declarative schema group run in parallel, and are cut over together
+		return false
+	}
 	_, isRunningMigrationAllowConcurrent := e.allowConcurrentMigration(runningMigration)
 	proposedMigrationAction, isProposedMigrationAllowConcurrent := e.allowConcurrentMigration(proposedMigration)
 	if !isRunningMigrationAllowConcurrent && !isProposedMigrationAllowConcurrent {
@@ -1929,6 +1936,14 @@ func (e *Executor) terminateMigration(ctx context.Context, onlineDDL *schema.Onl
 	// Whatever happens in this function, this executor stops owning the given migration.
 	defer e.ownedRunningMigrations.Delete(onlineDDL.UUID)
 
+	if connID, ok := e.inplaceMigrationConnIDs.Load(onlineDDL.UUID); ok {
+		// An ALGORITHM=INPLACE migration, which runs a plain ALTER TABLE
+		foundRunning = true
+		if _, err := e.execQuery(ctx, sqlparser.BuildParsedQuery(sqlKillQuery, connID.(int64)).Query); err != nil {
+			return foundRunning, fmt.Errorf("Error terminating INPLACE migration: %+v", err)
+		}
+		return foundRunning, nil
+	}
 	switch onlineDDL.Strategy {
 	case schema.DDLStrategyOnline, schema.DDLStrategyVitess:
 		// migration could have started by a different tablet. We need to actively verify if it is running
@@ -2150,6 +2165,7 @@ func (e *Executor) scheduleNextMigration(ctx context.Context) error {
 		postponeCompletion := row.AsBool("postpone_completion", false)
 		readyToComplete := row.AsBool("ready_to_complete", false)
 		isImmediateOperation := row.AsBool("is_immediate_operation", false)
+		isDeclarativeSchema := schema.NewDDLStrategySetting(schema.DDLStrategy(row["strategy"].ToString()), row["options"].ToString()).IsDeclarativeSchema()
 
 		if postponeLaunch {
 			// We don't even look into this migration until its postpone_launch flag is cleared
@@ -2167,9 +2183,10 @@ func (e *Executor) scheduleNextMigration(ctx context.Context) error {
 			}
 		}
 
-		if !(isImmediateOperation && postponeCompletion) {
+		if !(isImmediateOperation && (postponeCompletion || isDeclarativeSchema)) {
 			// Any non-postponed migration can be scheduled
 			// postponed ALTER can be scheduled (because gh-ost or vreplication will postpone the cut-over)
+			// Immediate operations of a declarative schema migration are applied by the cut-over of their group
 			// We only schedule a single migration in the execution of this function
 			onlyScheduleOneMigration.Do(func() {
 				err = e.updateMigrationStatus(ctx, uuid, schema.OnlineDDLStatusReady)
@@ -2240,6 +2257,7 @@ func (e *Executor) reviewEmptyTableRevertMigrations(ctx context.Context, onlineD
 // - All VIEW operations
 // - An INSTANT DDL accompanied by relevant ddl strategy flags
 // Non immediate operations are:
+// - An INPLACE DDL, which may take long to build indexes, even though it does not rebuild the table
 // - A gh-ost migration
 // - A vitess (vreplication) migration
 func (e *Executor) reviewImmediateOperations(ctx context.Context, capableOf mysql.CapableOf, onlineDDL *schema.OnlineDDL, ddlAction string, isRevert bool, isView bool) (bool, error) {
@@ -2258,16 +2276,29 @@ func (e *Executor) reviewImmediateOperations(ctx context.Context, capableOf mysq
 			if err != nil {
 				return false, err
 			}
-			return (specialPlan != nil), nil
+			return (specialPlan != nil && specialPlan.operation != inplaceDDLSpecialOperation), nil
 		}
 	}
 	return false, nil
 }
 
+// reviewAlterTableAlgorithm classifies a queued ALTER TABLE migration by the cheapest algorithm MySQL could
+// run it with, and records the classification on the migration, for users to see in SHOW VITESS_MIGRATIONS.
+func (e *Executor) reviewAlterTableAlgorithm(ctx context.Context, capableOf mysql.CapableOf, onlineDDL *schema.OnlineDDL, ddlAction string, isRevert bool, isView bool) error {
+	if ddlAction != sqlparser.AlterStr || isRevert || isView {
+		return nil
+	}
+	algorithm, err := e.analyzeAlterTableAlgorithm(ctx, onlineDDL, capableOf)
+	if err != nil {
+		return err
+	}
+	return e.updateMigrationAlterAlgorithm(ctx, onlineDDL.UUID, algorithm)
+}
+
 // reviewQueuedMigrations iterates through queued migrations and sees if any information needs to be updated.
 // The function analyzes the queued migration and fills in some blanks:
 // - If this is a REVERT migration, what table is affected? What's the operation?
-// - Is this migration an "immediate operation"?
+// - Is this migration an "immediate operation"? Declarative schema migrations are evaluated by group.
 func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
 	if err != nil {
@@ -2284,6 +2315,8 @@ func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 		return err
 	}
 
+	// declarative schema groups are evaluated once per review, for all of their migrations
+	groupDiffs := map[string]*declarativeSchemaDiff{}
 	for _, uuidRow := range r.Named().Rows {
 		uuid := uuidRow["migration_uuid"].ToString()
 		onlineDDL, row, err := e.readMigration(ctx, uuid)
@@ -2309,9 +2342,24 @@ func (e *Executor) reviewQueuedMigrations(ctx context.Context) error {
 			}
 		}
 		isView := row.AsBool("is_view", false)
-		isImmediate, err := e.reviewImmediateOperations(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView)
-		if err != nil {
-			return err
+		var isImmediate bool
+		if onlineDDL.StrategySetting().IsDeclarativeSchema() {
+			reviewed, immediate, err := e.reviewDeclarativeSchemaMigration(ctx, onlineDDL, groupDiffs)
+			if err != nil {
+				return err
+			}
+			if !reviewed {
+				continue
+			}
+			isImmediate = immediate
+		} else {
+			isImmediate, err = e.reviewImmediateOperations(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView)
+			if err != nil {
+				return err
+			}
+			if err := e.reviewAlterTableAlgorithm(ctx, capableOf, onlineDDL, ddlAction, isRevert, isView); err != nil {
+				return err
+			}
 		}
 		if isImmediate {
 			if err := e.updateMigrationSetImmediateOperation(ctx, onlineDDL.UUID); err != nil {
@@ -2548,37 +2596,66 @@ func (e *Executor) executeRevert(ctx context.Context, onlineDDL *schema.OnlineDD
 // - empty, in which case the migration is noop and implicitly successful, or
 // - non-empty, in which case the migration turns to be an ALTER
 func (e *Executor) evaluateDeclarativeDiff(ctx context.Context, onlineDDL *schema.OnlineDDL) (diff schemadiff.EntityDiff, err error) {
-
-	// Modify the CREATE TABLE statement to indicate a different, made up table name, known as the "comparison table"
 	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
 	if err != nil {
 		return nil, err
 	}
-	// Is this CREATE TABLE or CREATE VIEW?
-	comparisonTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	existingShowCreateTable, err := e.showCreateTable(ctx, onlineDDL.Table)
+	if err != nil {
+		return nil, err
+	}
+	if existingShowCreateTable == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "unexpected: cannot find table or view %v", onlineDDL.Table)
+	}
+	newShowCreateTable, err := e.showCreateComparisonTable(ctx, onlineDDL, ddlStmt)
+	if err != nil {
+		return nil, err
+	}
+	hints := &schemadiff.DiffHints{AutoIncrementStrategy: schemadiff.AutoIncrementApplyHigher}
+	switch ddlStmt.(type) {
+	case *sqlparser.CreateTable:
+		diff, err = schemadiff.DiffCreateTablesQueries(existingShowCreateTable, newShowCreateTable, hints)
+	case *sqlparser.CreateView:
+		diff, err = schemadiff.DiffCreateViewsQueries(existingShowCreateTable, newShowCreateTable, hints)
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected CREATE TABLE or CREATE VIEW in online DDL statement: %v", onlineDDL.SQL)
+	}
 	if err != nil {
 		return nil, err
 	}
+	return diff, nil
+}
+
+// showCreateComparisonTable creates the table or view of the given CREATE statement under a different, made up
+// name, known as the "comparison table", and returns its SHOW CREATE statement. The comparison table is dropped
+// right away. This lets MySQL normalize the statement, so that it can be compared with the SHOW CREATE statement
+// of an existing table or view.
+func (e *Executor) showCreateComparisonTable(ctx context.Context, onlineDDL *schema.OnlineDDL, ddlStmt sqlparser.DDLStatement) (string, error) {
+	comparisonTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
+	if err != nil {
+		return "", err
+	}
 
 	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer conn.Close()
 
 	{
 		// Create the comparison table
+		ddlStmt = sqlparser.CloneDDLStatement(ddlStmt)
 		ddlStmt.SetTable("", comparisonTableName)
 		modifiedCreateSQL := sqlparser.String(ddlStmt)
 
 		restoreSQLModeFunc, err := e.initMigrationSQLMode(ctx, onlineDDL, conn)
 		defer restoreSQLModeFunc()
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 
 		if _, err := conn.ExecuteFetch(modifiedCreateSQL, 0, false); err != nil {
-			return nil, err
+			return "", err
 		}
 
 		defer func() {
@@ -2590,33 +2667,14 @@ func (e *Executor) evaluateDeclarativeDiff(ctx context.Context, onlineDDL *schem
 		}()
 	}
 
-	existingShowCreateTable, err := e.showCreateTable(ctx, onlineDDL.Table)
-	if err != nil {
-		return nil, err
-	}
-	if existingShowCreateTable == "" {
-		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "unexpected: cannot find table or view %v", onlineDDL.Table)
-	}
 	newShowCreateTable, err := e.showCreateTable(ctx, comparisonTableName)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	if newShowCreateTable == "" {
-		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected: cannot find table or view even as it was just created: %v", onlineDDL.Table)
-	}
-	hints := &schemadiff.DiffHints{AutoIncrementStrategy: schemadiff.AutoIncrementApplyHigher}
-	switch ddlStmt.(type) {
-	case *sqlparser.CreateTable:
-		diff, err = schemadiff.DiffCreateTablesQueries(existingShowCreateTable, newShowCreateTable, hints)
-	case *sqlparser.CreateView:
-		diff, err = schemadiff.DiffCreateViewsQueries(existingShowCreateTable, newShowCreateTable, hints)
-	default:
-		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "expected CREATE TABLE or CREATE VIEW in online DDL statement: %v", onlineDDL.SQL)
+		return "", vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected: cannot find table or view even as it was just created: %v", onlineDDL.Table)
 	}
-	if err != nil {
-		return nil, err
-	}
-	return diff, nil
+	return newShowCreateTable, nil
 }
 
 // getCompletedMigrationByContextAndSQL chceks if there exists a completed migration with exact same
@@ -2659,9 +2717,6 @@ func (e *Executor) executeDropDDLActionMigration(ctx context.Context, onlineDDL
 	failMigration := func(err error) error {
 		return e.failMigration(ctx, onlineDDL, err)
 	}
-	e.migrationMutex.Lock()
-	defer e.migrationMutex.Unlock()
-
 	// Drop statement.
 	// Normally, we're going to modify DROP to RENAME (see later on). But if table name is
 	// already a GC-lifecycle table, then we don't put it through yet another GC lifecycle,
@@ -2711,9 +2766,6 @@ func (e *Executor) executeCreateDDLActionMigration(ctx context.Context, onlineDD
 	failMigration := func(err error) error {
 		return e.failMigration(ctx, onlineDDL, err)
 	}
-	e.migrationMutex.Lock()
-	defer e.migrationMutex.Unlock()
-
 	ddlStmt, _, err := schema.ParseOnlineDDLStatement(onlineDDL.SQL)
 	if err != nil {
 		return failMigration(err)
@@ -2883,16 +2935,26 @@ func (e *Executor) executeAlterViewOnline(ctx context.Context, onlineDDL *schema
 
 // addInstantAlgorithm adds or modifies the AlterTable's ALGORITHM to INSTANT
 func (e *Executor) addInstantAlgorithm(alterTable *sqlparser.AlterTable) {
-	instantOpt := sqlparser.AlgorithmValue("INSTANT")
+	e.addAlgorithm(alterTable, "INSTANT")
+}
+
+// addInplaceAlgorithm adds or modifies the AlterTable's ALGORITHM to INPLACE
+func (e *Executor) addInplaceAlgorithm(alterTable *sqlparser.AlterTable) {
+	e.addAlgorithm(alterTable, "INPLACE")
+}
+
+// addAlgorithm adds or modifies the AlterTable's ALGORITHM to the given one
+func (e *Executor) addAlgorithm(alterTable *sqlparser.AlterTable, algorithm string) {
+	algorithmOpt := sqlparser.AlgorithmValue(algorithm)
 	for i, opt := range alterTable.AlterOptions {
 		if _, ok := opt.(sqlparser.AlgorithmValue); ok {
 			// replace an existing algorithm
-			alterTable.AlterOptions[i] = instantOpt
+			alterTable.AlterOptions[i] = algorithmOpt
 			return
 		}
 	}
 	// append an algorithm
-	alterTable.AlterOptions = append(alterTable.AlterOptions, instantOpt)
+	alterTable.AlterOptions = append(alterTable.AlterOptions, algorithmOpt)
 }
 
 // executeSpecialAlterDDLActionMigrationIfApplicable sees if the given migration can be executed via special execution path, that isn't a full blown online schema change process.
@@ -2921,6 +2983,12 @@ func (e *Executor) executeSpecialAlterDDLActionMigrationIfApplicable(ctx context
 		if _, err := e.executeDirectly(ctx, onlineDDL); err != nil {
 			return false, err
 		}
+	case inplaceDDLSpecialOperation:
+		// This may take long, so it runs in the background, and completes the migration by itself
+		if err := e.executeInplaceAlterMigration(ctx, onlineDDL, specialPlan); err != nil {
+			return false, err
+		}
+		return true, nil
 	case dropRangePartitionSpecialOperation:
 		dropPartition := func() error {
 			artifactTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, newGCTableRetainTime())
@@ -2970,6 +3038,67 @@ func (e *Executor) executeSpecialAlterDDLActionMigrationIfApplicable(ctx context
 	return true, nil
 }
 
+// executeInplaceAlterMigration runs the ALTER of the migration with ALGORITHM=INPLACE, in the background: adding an
+// index, for example, does not rebuild the table but may still take long, and the executor must keep reviewing and
+// running the other migrations meanwhile. Terminating the migration kills the ALTER.
+func (e *Executor) executeInplaceAlterMigration(ctx context.Context, onlineDDL *schema.OnlineDDL, specialPlan *SpecialAlterPlan) error {
+	e.addInplaceAlgorithm(specialPlan.alterTable)
+	onlineDDL.SQL = sqlparser.CanonicalString(specialPlan.alterTable)
+	if err := e.updateMigrationSpecialPlan(ctx, onlineDDL.UUID, specialPlan.String()); err != nil {
+		return err
+	}
+
+	conn, err := dbconnpool.NewDBConnection(ctx, e.env.Config().DB.DbaWithDB())
+	if err != nil {
+		return err
+	}
+	restoreSQLModeFunc, err := e.initMigrationSQLMode(ctx, onlineDDL, conn)
+	if err != nil {
+		restoreSQLModeFunc()
+		conn.Close()
+		return err
+	}
+
+	e.ownedRunningMigrations.Store(onlineDDL.UUID, onlineDDL)
+	e.inplaceMigrationConnIDs.Store(onlineDDL.UUID, conn.ID())
+	_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusRunning, false, progressPctStarted, etaSecondsUnknown, rowsCopiedUnknown, emptyHint)
+
+	go func() {
+		defer conn.Close()
+		defer restoreSQLModeFunc()
+		defer e.inplaceMigrationConnIDs.Delete(onlineDDL.UUID)
+		defer e.ownedRunningMigrations.Delete(onlineDDL.UUID)
+
+		// The ALTER does not report any progress, so we keep the migration from looking stale while it runs
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					_ = e.updateMigrationTimestamp(ctx, "liveness_timestamp", onlineDDL.UUID)
+				}
+			}
+		}()
+
+		startedMigrations.Add(1)
+		if _, err := conn.ExecuteFetch(onlineDDL.SQL, 0, false); err != nil {
+			failedMigrations.Add(1)
+			_ = e.failMigration(ctx, onlineDDL, err)
+			log.Errorf("Error running INPLACE migration %s: %+v", onlineDDL.UUID, err)
+			return
+		}
+		successfulMigrations.Add(1)
+		defer e.reloadSchema(ctx)
+		_ = e.onSchemaMigrationStatus(ctx, onlineDDL.UUID, schema.OnlineDDLStatusComplete, false, progressPctFull, etaSecondsNow, rowsCopiedUnknown, emptyHint)
+	}()
+	return nil
+}
+
 // executeAlterDDLActionMigration
 func (e *Executor) executeAlterDDLActionMigration(ctx context.Context, onlineDDL *schema.OnlineDDL) error {
 	failMigration := func(err error) error {
@@ -3090,7 +3219,7 @@ func (e *Executor) executeMigration(ctx context.Context, onlineDDL *schema.Onlin
 		}
 	}
 
-	if onlineDDL.StrategySetting().IsDeclarative() {
+	if onlineDDL.StrategySetting().IsDeclarative() || onlineDDL.StrategySetting().IsDeclarativeSchema() {
 		switch ddlAction {
 		case sqlparser.RevertDDLAction:
 			// No special action. Declarative Revert migrations are handled like any normal Revert migration.
@@ -3183,10 +3312,16 @@ func (e *Executor) executeMigration(ctx context.Context, onlineDDL *schema.Onlin
 	switch ddlAction {
 	case sqlparser.DropDDLAction:
 		go func() error {
+			e.migrationMutex.Lock()
+			defer e.migrationMutex.Unlock()
+
 			return e.executeDropDDLActionMigration(ctx, onlineDDL)
 		}()
 	case sqlparser.CreateDDLAction:
 		go func() error {
+			e.migrationMutex.Lock()
+			defer e.migrationMutex.Unlock()
+
 			return e.executeCreateDDLActionMigration(ctx, onlineDDL)
 		}()
 	case sqlparser.AlterDDLAction:
@@ -3563,6 +3698,11 @@ func (e *Executor) reviewRunningMigrations(ctx context.Context) (countRunnning i
 						// override. Even if migration is ready, we do not complete it.
 						isReady = false
 					}
+					if onlineDDL.StrategySetting().IsDeclarativeSchema() {
+						// The migration is cut over along with the rest of its declarative schema group,
+						// see reviewDeclarativeSchemaGroups()
+						isReady = false
+					}
 					if isReady && onlineDDL.StrategySetting().IsInOrderCompletion() {
 						if len(pendingMigrationsUUIDs) > 0 && pendingMigrationsUUIDs[0] != onlineDDL.UUID {
 							// wait for earlier pending migrations to complete
@@ -3902,6 +4042,11 @@ func (e *Executor) onMigrationCheckTick() {
 	} else if err := e.cancelMigrations(ctx, cancellable, false); err != nil {
 		log.Error(err)
 	}
+	if cancellable, err := e.reviewDeclarativeSchemaGroups(ctx); err != nil {
+		log.Error(err)
+	} else if err := e.cancelMigrations(ctx, cancellable, false); err != nil {
+		log.Error(err)
+	}
 	if err := e.reviewStaleMigrations(ctx); err != nil {
 		log.Error(err)
 	}
@@ -3997,6 +4142,18 @@ func (e *Executor) updateMigrationSpecialPlan(ctx context.Context, uuid string,
 	return err
 }
 
+func (e *Executor) updateMigrationAlterAlgorithm(ctx context.Context, uuid string, algorithm AlterTableAlgorithm) error {
+	query, err := sqlparser.ParseAndBind(sqlUpdateMigrationAlterAlgorithm,
+		sqltypes.StringBindVariable(string(algorithm)),
+		sqltypes.StringBindVariable(uuid),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = e.execQuery(ctx, query)
+	return err
+}
+
 func (e *Executor) updateMigrationStage(ctx context.Context, uuid string, stage string, args ...interface{}) error {
 	msg := fmt.Sprintf(stage, args...)
 	log.Infof("updateMigrationStage: uuid=%s, stage=%s", uuid, msg)
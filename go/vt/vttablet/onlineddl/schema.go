@@ -43,6 +43,8 @@ const (
 
 	sqlSelectQueuedMigrations = `SELECT
 			migration_uuid,
+			strategy,
+			options,
 			ddl_action,
 			is_view,
 			is_immediate_operation,
@@ -141,6 +143,11 @@ const (
 		WHERE
 			migration_uuid=%a
 	`
+	sqlUpdateMigrationAlterAlgorithm = `UPDATE _vt.schema_migrations
+			SET alter_algorithm=%a
+		WHERE
+			migration_uuid=%a
+	`
 	sqlUpdateStage = `UPDATE _vt.schema_migrations
 			SET stage=%a
 		WHERE
@@ -318,6 +325,22 @@ const (
 			migration_status IN ('queued', 'ready', 'running')
 		ORDER BY id
 	`
+	sqlSelectPendingMigrationContexts = `SELECT DISTINCT
+			migration_context,
+			strategy,
+			options
+		FROM _vt.schema_migrations
+		WHERE
+			migration_status IN ('queued', 'ready', 'running')
+			AND migration_context != ''
+	`
+	sqlSelectMigrationsByContext = `SELECT
+			migration_uuid
+		FROM _vt.schema_migrations
+		WHERE
+			migration_context=%a
+		ORDER BY id
+	`
 	sqlSelectQueuedUnreviewedMigrations = `SELECT
 			migration_uuid
 		FROM _vt.schema_migrations
@@ -497,6 +520,7 @@ const (
 	sqlShowColumnsFrom  = "SHOW COLUMNS FROM `%a`"
 	sqlShowTableStatus  = "SHOW TABLE STATUS LIKE '%a'"
 	sqlShowCreateTable  = "SHOW CREATE TABLE `%a`"
+	sqlShowFullTables   = "SHOW FULL TABLES"
 	sqlGetAutoIncrement = `
 		SELECT
 			AUTO_INCREMENT
@@ -542,6 +566,7 @@ const (
 	sqlUnlockTables       = "UNLOCK TABLES"
 	sqlCreateSentryTable  = "CREATE TABLE IF NOT EXISTS `%a` (id INT PRIMARY KEY)"
 	sqlFindProcess        = "SELECT id, Info as info FROM information_schema.processlist WHERE id=%a AND Info LIKE %a"
+	sqlKillQuery          = "KILL QUERY %d"
 )
 
 const (
@@ -198,6 +198,12 @@ func (tm *TabletManager) restoreDataLocked(ctx context.Context, logger logutil.L
 		}
 		params.RestoreToPos = pos
 	}
+	if restoreToTimestamp := logutil.ProtoToTime(request.RestoreToTimestamp); !restoreToTimestamp.IsZero() {
+		if request.RestoreToPos != "" {
+			return vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "restore failed: --restore_to_pos and --restore_to_timestamp are mutually exclusive")
+		}
+		params.RestoreToTimestamp = restoreToTimestamp
+	}
 	params.Logger.Infof("Restore: original tablet type=%v", originalType)
 
 	// Check whether we're going to restore before changing to RESTORE type,
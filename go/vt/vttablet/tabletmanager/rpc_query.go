@@ -29,6 +29,7 @@ import (
 )
 
 // ExecuteFetchAsDba will execute the given query, possibly disabling binlogs and reload schema.
+// The query can be made of multiple statements, in which case the result of the first one is returned.
 func (tm *TabletManager) ExecuteFetchAsDba(ctx context.Context, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
 	// get a connection
 	conn, err := tm.MysqlDaemon.GetDbaConnection(ctx)
@@ -63,8 +64,11 @@ func (tm *TabletManager) ExecuteFetchAsDba(ctx context.Context, req *tabletmanag
 			return nil, err
 		}
 	}
-	// run the query
-	result, err := conn.ExecuteFetch(string(req.Query), int(req.MaxRows), true /*wantFields*/)
+	// run the query, all the statements of a multi-statement query have to succeed
+	result, more, err := conn.ExecuteFetchMulti(string(req.Query), int(req.MaxRows), true /*wantFields*/)
+	for more && err == nil {
+		_, more, _, err = conn.ReadQueryResult(int(req.MaxRows), false /*wantFields*/)
+	}
 
 	// re-enable binlogs if necessary
 	if req.DisableBinlogs && !conn.IsClosed() {
@@ -100,8 +104,11 @@ func (tm *TabletManager) ExecuteFetchAsAllPrivs(ctx context.Context, req *tablet
 		_, _ = conn.ExecuteFetch("USE "+sqlescape.EscapeID(req.DbName), 1, false)
 	}
 
-	// run the query
-	result, err := conn.ExecuteFetch(string(req.Query), int(req.MaxRows), true /*wantFields*/)
+	// run the query, all the statements of a multi-statement query have to succeed
+	result, more, err := conn.ExecuteFetchMulti(string(req.Query), int(req.MaxRows), true /*wantFields*/)
+	for more && err == nil {
+		_, more, _, err = conn.ReadQueryResult(int(req.MaxRows), false /*wantFields*/)
+	}
 
 	if err == nil && req.ReloadSchema {
 		reloadErr := tm.QueryServiceControl.ReloadSchema(ctx)
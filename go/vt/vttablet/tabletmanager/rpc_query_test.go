@@ -18,6 +18,7 @@ package tabletmanager
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -62,3 +63,35 @@ func TestTabletManager_ExecuteFetchAsDba(t *testing.T) {
 		require.Contains(t, got, w)
 	}
 }
+
+func TestTabletManager_ExecuteFetchAsDbaMultiStatement(t *testing.T) {
+	ctx := context.Background()
+	cp := mysql.ConnParams{}
+	db := fakesqldb.New(t)
+	db.AddQueryPattern(".*", &sqltypes.Result{RowsAffected: 1})
+	db.AddRejectedQuery("delete from t", errors.New("delete failed"))
+	daemon := mysqlctl.NewFakeMysqlDaemon(db)
+
+	tm := &TabletManager{
+		MysqlDaemon:         daemon,
+		DBConfigs:           dbconfigs.NewTestDBConfigs(cp, cp, "db"),
+		QueryServiceControl: tabletservermock.NewController(),
+	}
+
+	qr, err := tm.ExecuteFetchAsDba(ctx, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte("insert into t values (1); insert into t values (2)"),
+		DbName:  "db",
+		MaxRows: 10,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, qr.RowsAffected)
+	require.Contains(t, db.QueryLog(), "insert into t values (2)")
+
+	// an error of a statement other than the first one is not lost
+	_, err = tm.ExecuteFetchAsDba(ctx, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte("insert into t values (3);delete from t"),
+		DbName:  "db",
+		MaxRows: 10,
+	})
+	require.ErrorContains(t, err, "delete failed")
+}
@@ -40,6 +40,8 @@ var (
 	vreplicationExperimentalFlags       = int64(0x01) // enable vreplicationExperimentalFlagOptimizeInserts by default
 	vreplicationStoreCompressedGTID     = false
 	vreplicationParallelInsertWorkers   = 1
+
+	vreplicationParallelReplicationWorkers = 1
 )
 
 func registerVReplicationFlags(fs *pflag.FlagSet) {
@@ -71,6 +73,7 @@ func registerVReplicationFlags(fs *pflag.FlagSet) {
 	fs.Duration("vreplication_healthcheck_timeout", 1*time.Minute, "healthcheck retry delay")
 
 	fs.IntVar(&vreplicationParallelInsertWorkers, "vreplication-parallel-insert-workers", vreplicationParallelInsertWorkers, "Number of parallel insertion workers to use during copy phase. Set <= 1 to disable parallelism, or > 1 to enable concurrent insertion during copy phase.")
+	fs.IntVar(&vreplicationParallelReplicationWorkers, "vreplication-parallel-replication-workers", vreplicationParallelReplicationWorkers, "Number of workers applying the replicated transactions in parallel, each on its own connection. Transactions changing the same rows are applied in order, and all of them are committed in the order of the source. Set <= 1 to apply the transactions serially.")
 }
 
 func init() {
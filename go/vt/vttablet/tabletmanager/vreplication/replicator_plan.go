@@ -199,7 +199,15 @@ type TablePlan struct {
 	ConvertIntToEnum map[string]bool
 	// PKReferences is used to check if an event changed
 	// a primary key column (row move).
-	PKReferences            []string
+	PKReferences []string
+	// UniqueKeys are the columns of the unique keys of the target table, other than its
+	// primary key, by index name. They are only set by the parallel apply, for which
+	// they are part of the writeset.
+	UniqueKeys map[string][]string
+	// SourceColumns maps the lowered names of the target columns that are copies of a
+	// source column to the name of that column in the stream. The columns computed from
+	// an expression are not in it.
+	SourceColumns           map[string]string
 	Stats                   *binlogplayer.Stats
 	FieldsToSkip            map[string]bool
 	ConvertCharset          map[string](*binlogdatapb.CharsetConversion)
@@ -339,6 +339,13 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 	}
 	sort.Strings(pkrefs)
 
+	sourceColumns := make(map[string]string)
+	for _, cexpr := range tpb.colExprs {
+		if col, ok := cexpr.expr.(*sqlparser.ColName); ok && cexpr.operation == opExpr {
+			sourceColumns[cexpr.colName.Lowered()] = col.Name.String()
+		}
+	}
+
 	bvf := &bindvarFormatter{}
 
 	fieldsToSkip := make(map[string]bool)
@@ -358,6 +365,7 @@ func (tpb *tablePlanBuilder) generate() *TablePlan {
 		Update:                  tpb.generateUpdateStatement(),
 		Delete:                  tpb.generateDeleteStatement(),
 		PKReferences:            pkrefs,
+		SourceColumns:           sourceColumns,
 		Stats:                   tpb.stats,
 		FieldsToSkip:            fieldsToSkip,
 		HasExtraSourcePkColumns: (len(tpb.extraSourcePkCols) > 0),
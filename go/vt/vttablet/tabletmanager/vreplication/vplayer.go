@@ -67,6 +67,9 @@ type vplayer struct {
 	phase string
 
 	throttlerAppName string
+
+	// applier is set when the transactions are applied in parallel.
+	applier *parallelApplier
 }
 
 // newVPlayer creates a new vplayer. Parameters:
@@ -220,11 +223,18 @@ func (vp *vplayer) applyRowEvent(ctx context.Context, rowEvent *binlogdatapb.Row
 	if tplan == nil {
 		return fmt.Errorf("unexpected event on table %s", rowEvent.TableName)
 	}
+	return vp.applyRowChanges(tplan, rowEvent, func(sql string) (*sqltypes.Result, error) {
+		return vp.vr.dbClient.ExecuteWithRetry(ctx, sql)
+	})
+}
+
+// applyRowChanges applies the row changes of the event using the given table plan. The statements are run by execute.
+func (vp *vplayer) applyRowChanges(tplan *TablePlan, rowEvent *binlogdatapb.RowEvent, execute func(string) (*sqltypes.Result, error)) error {
 	for _, change := range rowEvent.RowChanges {
 		_, err := tplan.applyChange(change, func(sql string) (*sqltypes.Result, error) {
 			stats := NewVrLogStats("ROWCHANGE")
 			start := time.Now()
-			qr, err := vp.vr.dbClient.ExecuteWithRetry(ctx, sql)
+			qr, err := execute(sql)
 			vp.vr.stats.QueryCount.Add(vp.phase, 1)
 			vp.vr.stats.QueryTimings.Record(vp.phase, start)
 			stats.Send(sql)
@@ -329,6 +339,20 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 	// can estimate this value more accurately.
 	defer vp.vr.stats.ReplicationLagSeconds.Set(math.MaxInt64)
 	defer vp.vr.stats.VReplicationLags.Add(strconv.Itoa(int(vp.vr.id)), math.MaxInt64)
+
+	// The copy phase relies on the catchup and fast-forward of the vplayer to be applied in one go,
+	// so the transactions are only applied in parallel when replicating.
+	if vreplicationParallelReplicationWorkers > 1 && len(vp.copyState) == 0 {
+		applier, err := newParallelApplier(ctx, vp, vreplicationParallelReplicationWorkers)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			applier.close()
+			vp.applier = nil
+		}()
+		vp.applier = applier
+	}
 	var sbm int64 = -1
 	for {
 		if ctx.Err() != nil {
@@ -358,6 +382,12 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 		// In both cases, now > timeLastSaved. If so, the GTID of the last unsavedEvent
 		// must be saved.
 		if time.Since(vp.timeLastSaved) >= idleTimeout && vp.unsavedEvent != nil {
+			if vp.applier != nil {
+				// the position can only move forward once the in-flight transactions are committed
+				if err := vp.applier.drain(); err != nil {
+					return err
+				}
+			}
 			posReached, err := vp.updatePos(vp.unsavedEvent.Timestamp)
 			if err != nil {
 				return err
@@ -374,6 +404,16 @@ func (vp *vplayer) applyEvents(ctx context.Context, relay *relayLog) error {
 					vp.timeOffsetNs = time.Now().UnixNano() - event.CurrentTime
 					sbm = event.CurrentTime/1e9 - event.Timestamp
 				}
+				if vp.applier != nil {
+					if err := vp.applier.applyEvent(ctx, event); err != nil {
+						if err != io.EOF {
+							vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+							log.Errorf("Error applying event: %s", err.Error())
+						}
+						return err
+					}
+					continue
+				}
 				mustSave := false
 				switch event.Type {
 				case binlogdatapb.VEventType_COMMIT:
@@ -0,0 +1,614 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/log"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+var errParallelApplierClosed = errors.New("parallel applier closed")
+
+const sqlSelectUniqueKeys = "select index_name, column_name from information_schema.statistics where table_schema=%s and table_name=%s and non_unique=0 and index_name!='PRIMARY' order by index_name, seq_in_index"
+
+// parallelApplier applies the transactions received by a vplayer concurrently, using
+// one connection per worker. It is used instead of the serial apply when
+// --vreplication-parallel-replication-workers is greater than one.
+//
+// Two transactions conflict if they change rows with the same primary key, or the same values of
+// a unique key, in the same table. The writeset of a transaction is made of those keys, for the
+// before and after images of its rows, with the text values compared by the weight strings of their
+// collations. A transaction is only applied once all the earlier transactions it conflicts with are
+// committed. Transactions on tables without a primary key, or with a unique key the writeset can't
+// be computed for, conflict with all the other transactions on that table.
+//
+// Workers always commit in the order of the source. The position is updated in the same
+// transaction as the rows, so _vt.vreplication never points past a transaction that is
+// not committed, and a restart resumes exactly where the last commit left off.
+//
+// Everything the parallel apply can't reason about (statement based events, DDLs,
+// journals, transactions that reach the stop position...) waits for all the in-flight
+// transactions to be committed, and is then applied serially by the vplayer.
+type parallelApplier struct {
+	vp      *vplayer
+	workers []*parallelWorker
+	idle    chan *parallelWorker
+	wg      sync.WaitGroup
+
+	// txn is the transaction being received from the relay log, it is nil until its first row event.
+	txn *parallelTxn
+	// serial is set when the current transaction has to be applied by the vplayer.
+	serial bool
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// nextSeq is the sequence number of the next dispatched transaction.
+	nextSeq int64
+	// nextCommit is the sequence number of the transaction allowed to commit next.
+	// All the transactions with a lower sequence number are committed.
+	nextCommit int64
+	// rows, tables and tablesWithoutPK map the writesets to the
+	// sequence number of the last transaction that wrote them.
+	rows            map[string]int64
+	tables          map[string]int64
+	tablesWithoutPK map[string]int64
+	inflight        map[int64]*parallelTxn
+	err             error
+
+	// uniqueKeys caches the unique keys of the target tables, by table name.
+	uniqueKeys map[string]map[string][]string
+}
+
+// parallelWorker applies one transaction at a time on its own connection.
+type parallelWorker struct {
+	dbClient *vdbClient
+}
+
+type parallelTxn struct {
+	seq int64
+	// dependsOn is the sequence number of the last transaction that has to be committed
+	// before this one can be applied, or -1.
+	dependsOn int64
+	pos       mysql.Position
+	timestamp int64
+	rows      []parallelRowEvent
+	// keys are the primary and unique keys of the rows changed by the transaction.
+	keys []string
+	// tables are the tables changed by the transaction, the value is true if the rows of the table
+	// can't be told apart by their keys.
+	tables map[string]bool
+	// reapply is set when an older transaction waits for locks held by this one.
+	reapply bool
+}
+
+type parallelRowEvent struct {
+	tplan    *TablePlan
+	rowEvent *binlogdatapb.RowEvent
+}
+
+func newParallelApplier(ctx context.Context, vp *vplayer, workers int) (*parallelApplier, error) {
+	a := &parallelApplier{
+		vp:              vp,
+		idle:            make(chan *parallelWorker, workers),
+		rows:            make(map[string]int64),
+		tables:          make(map[string]int64),
+		tablesWithoutPK: make(map[string]int64),
+		inflight:        make(map[int64]*parallelTxn),
+		uniqueKeys:      make(map[string]map[string][]string),
+	}
+	a.cond = sync.NewCond(&a.mu)
+	for i := 0; i < workers; i++ {
+		dbClient, err := vp.vr.newClientConnection(ctx)
+		if err != nil {
+			a.close()
+			return nil, err
+		}
+		w := &parallelWorker{dbClient: dbClient}
+		a.workers = append(a.workers, w)
+		a.idle <- w
+	}
+	log.Infof("VReplication player id %v is applying transactions with %d parallel workers", vp.vr.id, workers)
+	return a, nil
+}
+
+// close stops the in-flight transactions that are not committed yet, and closes the connections of the workers.
+func (a *parallelApplier) close() {
+	a.fail(errParallelApplierClosed)
+	a.wg.Wait()
+	for _, w := range a.workers {
+		_ = w.dbClient.Rollback()
+		w.dbClient.Close()
+	}
+}
+
+// fail records the first error that happened, and wakes up the waiting workers so they can stop.
+func (a *parallelApplier) fail(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err == nil {
+		a.err = err
+	}
+	a.cond.Broadcast()
+}
+
+func (a *parallelApplier) getErr() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// applyEvent is called by the vplayer for every event received from the relay log.
+func (a *parallelApplier) applyEvent(ctx context.Context, event *binlogdatapb.VEvent) error {
+	if err := a.getErr(); err != nil {
+		return err
+	}
+	vp := a.vp
+	switch event.Type {
+	case binlogdatapb.VEventType_BEGIN:
+		return nil
+	case binlogdatapb.VEventType_GTID, binlogdatapb.VEventType_HEARTBEAT:
+		return vp.applyEvent(ctx, event, false)
+	case binlogdatapb.VEventType_FIELD:
+		if a.serial {
+			return vp.applyEvent(ctx, event, false)
+		}
+		tplan, err := vp.replicatorPlan.buildExecutionPlan(event.FieldEvent)
+		if err != nil {
+			return err
+		}
+		if tplan.UniqueKeys, err = a.uniqueKeysOf(ctx, tplan.TargetName); err != nil {
+			return err
+		}
+		vp.tablePlans[event.FieldEvent.TableName] = tplan
+		return nil
+	case binlogdatapb.VEventType_ROW:
+		if a.serial {
+			return vp.applyEvent(ctx, event, false)
+		}
+		tplan := vp.tablePlans[event.RowEvent.TableName]
+		if tplan == nil {
+			return fmt.Errorf("unexpected event on table %s", event.RowEvent.TableName)
+		}
+		if a.txn == nil {
+			a.txn = &parallelTxn{tables: make(map[string]bool)}
+		}
+		a.txn.addRowEvent(tplan, event.RowEvent)
+		return nil
+	case binlogdatapb.VEventType_COMMIT:
+		// the stop position has to be saved by the vplayer, even if the transaction is empty
+		mustSave := !vp.stopPos.IsZero() && vp.pos.AtLeast(vp.stopPos)
+		if a.serial || mustSave {
+			if !a.serial {
+				if err := a.switchToSerial(ctx); err != nil {
+					return err
+				}
+			}
+			a.serial = false
+			return vp.applyEvent(ctx, event, mustSave)
+		}
+		if a.txn == nil {
+			// an empty transaction, the vplayer remembers it as an unsaved event
+			return vp.applyEvent(ctx, event, false)
+		}
+		txn := a.txn
+		a.txn = nil
+		txn.pos = vp.pos
+		txn.timestamp = event.Timestamp
+		if err := a.dispatch(ctx, txn); err != nil {
+			return err
+		}
+		vp.unsavedEvent = nil
+		vp.timeLastSaved = time.Now()
+		return nil
+	case binlogdatapb.VEventType_INSERT, binlogdatapb.VEventType_DELETE, binlogdatapb.VEventType_UPDATE,
+		binlogdatapb.VEventType_REPLACE, binlogdatapb.VEventType_SAVEPOINT:
+		// statements are part of a transaction, the rest of which has to be applied serially too
+		if !a.serial {
+			if err := a.switchToSerial(ctx); err != nil {
+				return err
+			}
+		}
+		return vp.applyEvent(ctx, event, false)
+	default:
+		if err := a.switchToSerial(ctx); err != nil {
+			return err
+		}
+		a.serial = false
+		return vp.applyEvent(ctx, event, false)
+	}
+}
+
+// switchToSerial waits for all the in-flight transactions to be committed, and applies the rows received
+// so far for the current transaction on the connection of the vplayer, so the vplayer can take over.
+func (a *parallelApplier) switchToSerial(ctx context.Context) error {
+	if err := a.drain(); err != nil {
+		return err
+	}
+	a.serial = true
+	txn := a.txn
+	a.txn = nil
+	if txn == nil {
+		return nil
+	}
+	vp := a.vp
+	if err := vp.vr.dbClient.Begin(); err != nil {
+		return err
+	}
+	for _, row := range txn.rows {
+		if err := vp.applyRowChanges(row.tplan, row.rowEvent, func(sql string) (*sqltypes.Result, error) {
+			return vp.vr.dbClient.ExecuteWithRetry(ctx, sql)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drain waits until all the dispatched transactions are committed.
+func (a *parallelApplier) drain() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.nextCommit < a.nextSeq && a.err == nil {
+		a.cond.Wait()
+	}
+	return a.err
+}
+
+// dispatch computes the dependencies of the transaction, and hands it over to an idle worker.
+func (a *parallelApplier) dispatch(ctx context.Context, txn *parallelTxn) error {
+	var w *parallelWorker
+	select {
+	case w = <-a.idle:
+	case <-ctx.Done():
+		return io.EOF
+	}
+
+	a.mu.Lock()
+	if a.err != nil {
+		a.mu.Unlock()
+		a.idle <- w
+		return a.err
+	}
+	a.register(txn)
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.apply(ctx, w, txn); err != nil {
+			if err != io.EOF && err != errParallelApplierClosed {
+				a.vp.vr.stats.ErrorCounts.Add([]string{"Apply"}, 1)
+				log.Errorf("Error applying transaction at position %v: %s", txn.pos, err.Error())
+			}
+			a.fail(err)
+		}
+		a.idle <- w
+	}()
+	return nil
+}
+
+// register gives the transaction its sequence number, computes its dependency, and records its
+// writeset so the later transactions can depend on it. It must be called with a.mu held.
+func (a *parallelApplier) register(txn *parallelTxn) {
+	txn.seq = a.nextSeq
+	a.nextSeq++
+	txn.dependsOn = a.dependencyOf(txn)
+	for _, key := range txn.keys {
+		a.rows[key] = txn.seq
+	}
+	for table, withoutPK := range txn.tables {
+		a.tables[table] = txn.seq
+		if withoutPK {
+			a.tablesWithoutPK[table] = txn.seq
+		}
+	}
+	a.inflight[txn.seq] = txn
+}
+
+// dependencyOf returns the sequence number of the last in-flight transaction conflicting with txn, or -1.
+// Since the transactions commit in order, waiting for that one to commit is enough.
+func (a *parallelApplier) dependencyOf(txn *parallelTxn) int64 {
+	dep := int64(-1)
+	check := func(seq int64, ok bool) {
+		if ok && seq >= a.nextCommit && seq > dep {
+			dep = seq
+		}
+	}
+	for _, key := range txn.keys {
+		seq, ok := a.rows[key]
+		check(seq, ok)
+	}
+	for table, withoutPK := range txn.tables {
+		if withoutPK {
+			seq, ok := a.tables[table]
+			check(seq, ok)
+		} else {
+			seq, ok := a.tablesWithoutPK[table]
+			check(seq, ok)
+		}
+	}
+	return dep
+}
+
+// apply applies the transaction on the connection of the worker, and commits it once all
+// the earlier transactions are committed.
+func (a *parallelApplier) apply(ctx context.Context, w *parallelWorker, txn *parallelTxn) error {
+	if err := a.waitForCommit(txn.dependsOn); err != nil {
+		return err
+	}
+	for {
+		execErr := a.execute(w, txn)
+		switch {
+		case execErr == nil:
+			atTurn, err := a.waitForTurn(txn)
+			if err != nil {
+				return err
+			}
+			if atTurn {
+				return a.commit(w, txn)
+			}
+			// an older transaction is waiting for the locks we hold
+		case isRetryableLockError(execErr), isDuplicateKeyError(execErr) && !a.isNextToCommit(txn):
+			// the writeset uses the collations of the source, so a duplicate key can still come
+			// from an older transaction that is not committed yet
+			log.Infof("retryable error: %v, rolling back transaction at position %v and retrying", execErr, txn.pos)
+			// younger transactions may hold the locks we need while they wait for us to commit
+			a.reapplyYoungerThan(txn.seq)
+		default:
+			return execErr
+		}
+		if err := w.dbClient.Rollback(); err != nil {
+			return err
+		}
+		// we try again once all the older transactions are committed, so none of them can be waiting for our locks
+		if err := a.waitForCommit(txn.seq - 1); err != nil {
+			return err
+		}
+		if execErr != nil {
+			time.Sleep(dbLockRetryDelay)
+			select {
+			case <-ctx.Done():
+				return io.EOF
+			default:
+			}
+		}
+	}
+}
+
+func (a *parallelApplier) execute(w *parallelWorker, txn *parallelTxn) error {
+	if err := w.dbClient.Begin(); err != nil {
+		return err
+	}
+	for _, row := range txn.rows {
+		if err := a.vp.applyRowChanges(row.tplan, row.rowEvent, w.dbClient.Execute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit saves the position of the transaction along with its rows.
+func (a *parallelApplier) commit(w *parallelWorker, txn *parallelTxn) error {
+	vp := a.vp
+	update := binlogplayer.GenerateUpdatePos(vp.vr.id, txn.pos, time.Now().Unix(), txn.timestamp, vp.vr.stats.CopyRowCount.Get(), vreplicationStoreCompressedGTID)
+	if _, err := w.dbClient.Execute(update); err != nil {
+		return fmt.Errorf("error %v updating position", err)
+	}
+	if err := w.dbClient.Commit(); err != nil {
+		return err
+	}
+	vp.vr.stats.SetLastPosition(txn.pos)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextCommit = txn.seq + 1
+	delete(a.inflight, txn.seq)
+	for _, key := range txn.keys {
+		if a.rows[key] == txn.seq {
+			delete(a.rows, key)
+		}
+	}
+	for table := range txn.tables {
+		if a.tables[table] == txn.seq {
+			delete(a.tables, table)
+		}
+		if a.tablesWithoutPK[table] == txn.seq {
+			delete(a.tablesWithoutPK, table)
+		}
+	}
+	a.cond.Broadcast()
+	return nil
+}
+
+// waitForCommit waits until the transaction with the given sequence number is committed.
+func (a *parallelApplier) waitForCommit(seq int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.nextCommit <= seq && a.err == nil {
+		a.cond.Wait()
+	}
+	return a.err
+}
+
+// waitForTurn waits until the transaction is the next one to commit. It returns false if
+// the transaction has to release its locks first, so an older transaction can make progress.
+func (a *parallelApplier) waitForTurn(txn *parallelTxn) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.nextCommit != txn.seq && !txn.reapply && a.err == nil {
+		a.cond.Wait()
+	}
+	if a.err != nil {
+		return false, a.err
+	}
+	atTurn := a.nextCommit == txn.seq
+	txn.reapply = false
+	return atTurn, nil
+}
+
+// isNextToCommit returns true if all the transactions older than txn are committed.
+func (a *parallelApplier) isNextToCommit(txn *parallelTxn) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.nextCommit == txn.seq
+}
+
+// reapplyYoungerThan asks all the in-flight transactions younger than seq to roll back,
+// and to apply their rows again once it is their turn to commit.
+func (a *parallelApplier) reapplyYoungerThan(seq int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for s, txn := range a.inflight {
+		if s > seq {
+			txn.reapply = true
+		}
+	}
+	a.cond.Broadcast()
+}
+
+// addRowEvent adds the row event to the transaction, along with the primary and unique keys of its rows.
+func (txn *parallelTxn) addRowEvent(tplan *TablePlan, rowEvent *binlogdatapb.RowEvent) {
+	txn.rows = append(txn.rows, parallelRowEvent{tplan: tplan, rowEvent: rowEvent})
+	if len(tplan.PKReferences) == 0 {
+		txn.tables[tplan.TargetName] = true
+		return
+	}
+	if _, ok := txn.tables[tplan.TargetName]; !ok {
+		txn.tables[tplan.TargetName] = false
+	}
+	for _, change := range rowEvent.RowChanges {
+		for _, row := range []*querypb.Row{change.Before, change.After} {
+			if row == nil {
+				continue
+			}
+			vals := sqltypes.MakeRowTrusted(tplan.Fields, row)
+			txn.addKey(tplan, vals, "", tplan.PKReferences)
+			for name, cols := range tplan.UniqueKeys {
+				txn.addKey(tplan, vals, name, cols)
+			}
+		}
+	}
+}
+
+func (txn *parallelTxn) addKey(tplan *TablePlan, vals []sqltypes.Value, keyName string, cols []string) {
+	key, ok := rowKey(tplan, vals, keyName, cols)
+	switch {
+	case !ok:
+		// one of the columns of the key is not replicated as is
+		txn.tables[tplan.TargetName] = true
+	case key != "":
+		txn.keys = append(txn.keys, key)
+	}
+}
+
+// rowKey returns the name of the table and of the key, followed by the values of the columns of the key in
+// the row. The text values are replaced with their weight strings, so values that are equal for their
+// collation have the same key. The columns of the primary key are the fields of the stream it references,
+// while the columns of a unique key are target columns, found in the row through the source columns they
+// are copies of. rowKey returns an empty key if one of the values is null, since unique keys allow duplicate
+// nulls, and false if one of the columns is not in the row.
+func rowKey(tplan *TablePlan, vals []sqltypes.Value, keyName string, cols []string) (string, bool) {
+	var key strings.Builder
+	key.WriteString(tplan.TargetName)
+	key.WriteByte(0)
+	key.WriteString(keyName)
+	for _, col := range cols {
+		if keyName != "" {
+			var ok bool
+			if col, ok = tplan.SourceColumns[strings.ToLower(col)]; !ok {
+				return "", false
+			}
+		}
+		i := fieldIndex(tplan.Fields, col)
+		if i == -1 {
+			return "", false
+		}
+		key.WriteByte(0)
+		if vals[i].IsNull() {
+			if keyName != "" {
+				return "", true
+			}
+			key.WriteByte('-')
+			continue
+		}
+		raw := vals[i].Raw()
+		if coll := collations.Local().LookupByID(collations.ID(tplan.Fields[i].Charset)); coll != nil && sqltypes.IsText(tplan.Fields[i].Type) {
+			raw = coll.WeightString(nil, raw, 0)
+		}
+		key.WriteString(strconv.Itoa(len(raw)))
+		key.WriteByte(':')
+		key.Write(raw)
+	}
+	return key.String(), true
+}
+
+func fieldIndex(fields []*querypb.Field, name string) int {
+	for i, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// uniqueKeysOf returns the columns of the unique keys of the target table, other than its primary key, by index name.
+func (a *parallelApplier) uniqueKeysOf(ctx context.Context, table string) (map[string][]string, error) {
+	if uniqueKeys, ok := a.uniqueKeys[table]; ok {
+		return uniqueKeys, nil
+	}
+	vr := a.vp.vr
+	query := fmt.Sprintf(sqlSelectUniqueKeys, encodeString(vr.dbClient.DBName()), encodeString(table))
+	qr, err := vr.mysqld.FetchSuperQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	uniqueKeys := make(map[string][]string)
+	for _, row := range qr.Rows {
+		name := row[0].ToString()
+		uniqueKeys[name] = append(uniqueKeys[name], row[1].ToString())
+	}
+	a.uniqueKeys[table] = uniqueKeys
+	return uniqueKeys, nil
+}
+
+func isRetryableLockError(err error) bool {
+	sqlErr, ok := err.(*mysql.SQLError)
+	if !ok {
+		return false
+	}
+	return sqlErr.Number() == mysql.ERLockDeadlock || sqlErr.Number() == mysql.ERLockWaitTimeout
+}
+
+func isDuplicateKeyError(err error) bool {
+	sqlErr, ok := err.(*mysql.SQLError)
+	return ok && sqlErr.Number() == mysql.ERDupEntry
+}
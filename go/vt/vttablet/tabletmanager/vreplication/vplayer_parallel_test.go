@@ -0,0 +1,263 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vreplication
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestParallelApplierDependencies(t *testing.T) {
+	withPK := &TablePlan{
+		TargetName:   "t1",
+		PKReferences: []string{"id"},
+		Fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "val", Type: querypb.Type_VARCHAR},
+		},
+	}
+	withUK := &TablePlan{
+		TargetName:   "t3",
+		PKReferences: []string{"id"},
+		UniqueKeys:    map[string][]string{"uk_val": {"val"}},
+		SourceColumns: map[string]string{"id": "id", "val": "val"},
+		Fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "val", Type: querypb.Type_VARCHAR, Charset: collations.CollationUtf8mb4ID},
+		},
+	}
+	withoutPK := &TablePlan{
+		TargetName: "t2",
+		Fields: []*querypb.Field{
+			{Name: "val", Type: querypb.Type_VARCHAR},
+		},
+	}
+	rowEvent := func(tplan *TablePlan, before, after []sqltypes.Value) *binlogdatapb.RowEvent {
+		change := &binlogdatapb.RowChange{}
+		if before != nil {
+			change.Before = sqltypes.RowToProto3(before)
+		}
+		if after != nil {
+			change.After = sqltypes.RowToProto3(after)
+		}
+		return &binlogdatapb.RowEvent{TableName: tplan.TargetName, RowChanges: []*binlogdatapb.RowChange{change}}
+	}
+	row := func(id int64, val string) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NewVarChar(val)}
+	}
+
+	a := &parallelApplier{
+		rows:            make(map[string]int64),
+		tables:          make(map[string]int64),
+		tablesWithoutPK: make(map[string]int64),
+		inflight:        make(map[int64]*parallelTxn),
+	}
+	// dispatch registers the transaction like parallelApplier.dispatch, without handing it over to a worker.
+	dispatch := func(events ...parallelRowEvent) *parallelTxn {
+		txn := &parallelTxn{tables: make(map[string]bool)}
+		for _, ev := range events {
+			txn.addRowEvent(ev.tplan, ev.rowEvent)
+		}
+		a.register(txn)
+		return txn
+	}
+
+	txn0 := dispatch(parallelRowEvent{withPK, rowEvent(withPK, nil, row(1, "a"))})
+	assert.EqualValues(t, -1, txn0.dependsOn)
+
+	// another row of the same table doesn't conflict
+	txn1 := dispatch(parallelRowEvent{withPK, rowEvent(withPK, nil, row(2, "b"))})
+	assert.EqualValues(t, -1, txn1.dependsOn)
+
+	// the same row conflicts, even if it's only the before image
+	txn2 := dispatch(parallelRowEvent{withPK, rowEvent(withPK, row(1, "a"), row(3, "a"))})
+	assert.EqualValues(t, 0, txn2.dependsOn)
+
+	// the last writer of the row is the dependency
+	txn3 := dispatch(parallelRowEvent{withPK, rowEvent(withPK, row(3, "a"), nil)}, parallelRowEvent{withPK, rowEvent(withPK, row(2, "b"), nil)})
+	assert.EqualValues(t, 2, txn3.dependsOn)
+
+	// a table without a primary key conflicts with every transaction on that table
+	txn4 := dispatch(parallelRowEvent{withoutPK, rowEvent(withoutPK, nil, []sqltypes.Value{sqltypes.NewVarChar("x")})})
+	assert.EqualValues(t, -1, txn4.dependsOn)
+	txn5 := dispatch(parallelRowEvent{withoutPK, rowEvent(withoutPK, nil, []sqltypes.Value{sqltypes.NewVarChar("y")})})
+	assert.EqualValues(t, 4, txn5.dependsOn)
+
+	// committed transactions are not dependencies anymore
+	a.nextCommit = 4
+	txn6 := dispatch(parallelRowEvent{withPK, rowEvent(withPK, row(1, "a"), row(1, "c"))})
+	assert.EqualValues(t, -1, txn6.dependsOn)
+
+	// a unique key conflicts, even with another primary key
+	txn7 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, nil, row(1, "a"))})
+	assert.EqualValues(t, -1, txn7.dependsOn)
+	txn8 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, nil, row(2, "b"))})
+	assert.EqualValues(t, -1, txn8.dependsOn)
+	txn9 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, row(2, "b"), row(2, "c"))}, parallelRowEvent{withUK, rowEvent(withUK, nil, row(3, "b"))})
+	assert.EqualValues(t, 8, txn9.dependsOn)
+
+	// the values are compared with their collation
+	txn10 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, nil, row(4, "A"))})
+	assert.EqualValues(t, 7, txn10.dependsOn)
+
+	// null values of a unique key don't conflict
+	nullRow := []sqltypes.Value{sqltypes.NewInt64(5), sqltypes.NULL}
+	txn11 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, nil, nullRow)})
+	assert.EqualValues(t, -1, txn11.dependsOn)
+	nullRow = []sqltypes.Value{sqltypes.NewInt64(6), sqltypes.NULL}
+	txn12 := dispatch(parallelRowEvent{withUK, rowEvent(withUK, nil, nullRow)})
+	assert.EqualValues(t, -1, txn12.dependsOn)
+
+	// a unique key on a column that is not replicated as is conflicts with every transaction on that table
+	computed := &TablePlan{
+		TargetName:    "t4",
+		PKReferences:  []string{"id"},
+		UniqueKeys:    map[string][]string{"uk_val": {"computed_val"}},
+		SourceColumns: map[string]string{"id": "id", "val": "val"},
+		Fields:        withPK.Fields,
+	}
+	txn13 := dispatch(parallelRowEvent{computed, rowEvent(computed, nil, row(1, "a"))})
+	assert.EqualValues(t, -1, txn13.dependsOn)
+	txn14 := dispatch(parallelRowEvent{computed, rowEvent(computed, nil, row(2, "b"))})
+	assert.EqualValues(t, 13, txn14.dependsOn)
+
+	// the columns of a unique key are found through the source columns they are copies of,
+	// as with "select id, other as val, val as other from t5"
+	swapped := &TablePlan{
+		TargetName:    "t5",
+		PKReferences:  []string{"id"},
+		UniqueKeys:    map[string][]string{"uk_val": {"val"}},
+		SourceColumns: map[string]string{"id": "id", "val": "other", "other": "val"},
+		Fields: []*querypb.Field{
+			{Name: "id", Type: querypb.Type_INT64},
+			{Name: "val", Type: querypb.Type_VARCHAR},
+			{Name: "other", Type: querypb.Type_VARCHAR},
+		},
+	}
+	swappedRow := func(id int64, val, other string) []sqltypes.Value {
+		return []sqltypes.Value{sqltypes.NewInt64(id), sqltypes.NewVarChar(val), sqltypes.NewVarChar(other)}
+	}
+	txn15 := dispatch(parallelRowEvent{swapped, rowEvent(swapped, nil, swappedRow(1, "a", "x"))})
+	assert.EqualValues(t, -1, txn15.dependsOn)
+	txn16 := dispatch(parallelRowEvent{swapped, rowEvent(swapped, nil, swappedRow(2, "a", "y"))})
+	assert.EqualValues(t, -1, txn16.dependsOn)
+	txn17 := dispatch(parallelRowEvent{swapped, rowEvent(swapped, nil, swappedRow(3, "b", "x"))})
+	assert.EqualValues(t, 15, txn17.dependsOn)
+
+	// keys can't be confused by concatenating the values
+	key1, _ := rowKey(withPK, row(12, "a"), "", []string{"id"})
+	key2, _ := rowKey(&TablePlan{TargetName: "t", Fields: withPK.Fields}, row(112, "a"), "", []string{"id"})
+	assert.NotEqual(t, key1, key2)
+}
+
+func TestPlayerParallelApply(t *testing.T) {
+	defer func(workers int) {
+		vreplicationParallelReplicationWorkers = workers
+	}(vreplicationParallelReplicationWorkers)
+	vreplicationParallelReplicationWorkers = 4
+
+	defer deleteTablet(addTablet(100))
+
+	execStatements(t, []string{
+		"create table src1(id int, val int, primary key(id))",
+		fmt.Sprintf("create table %s.dst1(id int, val int, primary key(id))", vrepldb),
+		"create table src2(val int)",
+		fmt.Sprintf("create table %s.dst2(val int)", vrepldb),
+	})
+	defer execStatements(t, []string{
+		"drop table src1",
+		fmt.Sprintf("drop table %s.dst1", vrepldb),
+		"drop table src2",
+		fmt.Sprintf("drop table %s.dst2", vrepldb),
+	})
+	env.SchemaEngine.Reload(context.Background())
+
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "dst1",
+			Filter: "select * from src1",
+		}, {
+			Match:  "dst2",
+			Filter: "select * from src2",
+		}},
+	}
+	bls := &binlogdatapb.BinlogSource{
+		Keyspace: env.KeyspaceName,
+		Shard:    env.ShardName,
+		Filter:   filter,
+		OnDdl:    binlogdatapb.OnDDLAction_IGNORE,
+	}
+	cancel, _ := startVReplication(t, bls, "")
+	defer cancel()
+
+	// every statement is its own transaction, the later ones depend on the earlier ones
+	var queries []string
+	for i := 1; i <= 20; i++ {
+		queries = append(queries, fmt.Sprintf("insert into src1 values(%d, 0)", i))
+	}
+	for i := 0; i < 40; i++ {
+		queries = append(queries, fmt.Sprintf("update src1 set val = val + 1 where id = %d", i%4+1))
+		queries = append(queries, fmt.Sprintf("insert into src2 values(%d)", i))
+	}
+	queries = append(queries,
+		"update src1 set id = 21 where id = 5",
+		"update src1 set val = 100 where id = 21",
+		"delete from src1 where id > 4 and id < 21",
+		"delete from src2 where val >= 3",
+	)
+	execStatements(t, queries)
+
+	want1 := [][]string{{"1", "10"}, {"2", "10"}, {"3", "10"}, {"4", "10"}, {"21", "100"}}
+	want2 := [][]string{{"0"}, {"1"}, {"2"}}
+	waitForParallelApply(t, func() error {
+		if err := compareQueryResults(t, fmt.Sprintf("select * from %s.dst1", vrepldb), want1, env.Mysqld.FetchSuperQuery); err != nil {
+			return err
+		}
+		return compareQueryResults(t, fmt.Sprintf("select * from %s.dst2 order by val", vrepldb), want2, env.Mysqld.FetchSuperQuery)
+	})
+	expectData(t, "dst1", want1)
+}
+
+// waitForParallelApply waits for check to succeed, the order of the queries run by the workers being undefined.
+func waitForParallelApply(t *testing.T, check func() error) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case <-globalDBQueries:
+			continue
+		case <-timeout:
+			require.NoError(t, check())
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+		if check() == nil {
+			return
+		}
+	}
+}
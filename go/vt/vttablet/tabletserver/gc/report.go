@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+// TableReport describes a single GC table, and what the collector is going to do with it
+type TableReport struct {
+	TableName string
+	// OriginTable is the name the table had before entering the GC lifecycle, if known
+	OriginTable string `json:",omitempty"`
+	State       schema.TableGCState
+	IsBaseTable bool
+	TableRows   int64
+	SizeBytes   int64
+	// RetentionPolicy is the policy that determines TransitionTime, if any
+	RetentionPolicy string `json:",omitempty"`
+	// TransitionTime is when the table is due to move into NextState. Tables in PURGE state move on once
+	// purged; for these, this is when purging begins.
+	TransitionTime time.Time
+	// NextState is empty when the table is due to be dropped
+	NextState schema.TableGCState `json:",omitempty"`
+	// DataLossTime is the time at which the collector begins to destroy the table's data, either by
+	// purging or by dropping it. Until then, the table can be recovered by renaming it. It is unset
+	// for tables whose data may already be gone.
+	DataLossTime *time.Time `json:",omitempty"`
+	IsPurging    bool
+}
+
+// Report lists all GC tables along with their expected lifecycle. Generating a report does not change
+// any table.
+type Report struct {
+	Keyspace string
+	Shard    string
+	IsOpen   bool
+
+	Lifecycle          []schema.TableGCState
+	RetentionPolicies  []string
+	PurgeRowsPerSecond int
+
+	Tables []*TableReport
+}
+
+// Report reads the GC tables and reports their state and upcoming transitions
+func (collector *TableGC) Report(ctx context.Context) (*Report, error) {
+	report := &Report{
+		Keyspace:           collector.keyspace,
+		Shard:              collector.shard,
+		IsOpen:             (atomic.LoadInt64(&collector.isOpen) > 0),
+		PurgeRowsPerSecond: purgeRowsPerSecond,
+		Tables:             []*TableReport{},
+	}
+	for _, state := range []schema.TableGCState{schema.HoldTableGCState, schema.PurgeTableGCState, schema.EvacTableGCState, schema.DropTableGCState} {
+		if collector.lifecycleStates[state] {
+			report.Lifecycle = append(report.Lifecycle, state)
+		}
+	}
+	for _, policy := range collector.retentionPolicies {
+		report.RetentionPolicies = append(report.RetentionPolicies, policy.String())
+	}
+	if !report.IsOpen {
+		return report, nil
+	}
+
+	conn, err := collector.pool.Get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Recycle()
+
+	res, err := conn.Exec(ctx, sqlShowVtTablesSize, math.MaxInt32, true)
+	if err != nil {
+		return nil, err
+	}
+	collector.refreshTableOrigins(ctx, conn)
+
+	purgingTables := collector.Status().purgingTables
+	for _, row := range res.Rows {
+		tableName := row[0].ToString()
+		isGCTable, state, gcUUID, hint, err := schema.AnalyzeGCTableName(tableName)
+		if err != nil || !isGCTable {
+			continue
+		}
+		tableReport := &TableReport{
+			TableName:   tableName,
+			OriginTable: collector.originTable(gcUUID),
+			State:       state,
+			IsBaseTable: (row[1].ToString() == "BASE TABLE"),
+		}
+		tableReport.TableRows, _ = row[2].ToInt64()
+		tableReport.SizeBytes, _ = row[3].ToInt64()
+		for _, purgingTable := range purgingTables {
+			if purgingTable == tableName {
+				tableReport.IsPurging = true
+			}
+		}
+		collector.fillTableReport(tableReport, gcUUID, hint)
+		report.Tables = append(report.Tables, tableReport)
+	}
+	sort.SliceStable(report.Tables, func(i, j int) bool {
+		return report.Tables[i].TransitionTime.Before(report.Tables[j].TransitionTime)
+	})
+	return report, nil
+}
+
+// fillTableReport computes the upcoming transition of a table, and when its data is going to be lost,
+// based on the table's state and time hint
+func (collector *TableGC) fillTableReport(tableReport *TableReport, gcUUID string, hint time.Time) {
+	state := tableReport.State
+	tableReport.TransitionTime = hint
+	if _, ok := collector.lifecycleStates[state]; ok {
+		tableReport.TransitionTime = collector.transitionTime(tableReport.TableName, state, gcUUID, hint)
+		if state == schema.HoldTableGCState {
+			if policy := collector.retentionPolicyFor(state, tableReport.TableName, tableReport.OriginTable); policy != nil {
+				tableReport.RetentionPolicy = policy.String()
+			}
+		}
+	} else {
+		// Not in our lifecycle: the table moves on as soon as we see it
+		tableReport.TransitionTime = time.Now().UTC()
+	}
+	if state == schema.PurgeTableGCState && !tableReport.IsBaseTable {
+		// Views are not purged
+		tableReport.TransitionTime = time.Now().UTC()
+	}
+	if next := collector.nextState(state); next != nil {
+		tableReport.NextState = *next
+	}
+
+	dataIntact := false
+	switch state {
+	case schema.HoldTableGCState:
+		dataIntact = true
+	case schema.EvacTableGCState:
+		// Unless purged on the way here
+		dataIntact = !collector.lifecycleStates[schema.PurgeTableGCState]
+	}
+	if !dataIntact {
+		return
+	}
+	dataLossTime := tableReport.TransitionTime
+	if tableReport.NextState == schema.EvacTableGCState {
+		// EVAC keeps the table's data; it is only lost once the table is dropped
+		dataLossTime = dataLossTime.Add(collector.stateRetention(schema.EvacTableGCState, tableReport.TableName, gcUUID, tableReport.IsBaseTable))
+	}
+	tableReport.DataLossTime = &dataLossTime
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestFillTableReport(t *testing.T) {
+	gcUUID := "6ace8bcef73211ea87e9f875a4d24e90"
+	hint := time.Date(2020, 9, 15, 12, 4, 10, 0, time.UTC)
+	tt := []struct {
+		lifecycle    string
+		state        schema.TableGCState
+		nextState    schema.TableGCState
+		dataLossTime time.Time
+	}{
+		{
+			lifecycle:    "hold,purge,evac,drop",
+			state:        schema.HoldTableGCState,
+			nextState:    schema.PurgeTableGCState,
+			dataLossTime: hint,
+		},
+		{
+			lifecycle:    "hold,evac,drop",
+			state:        schema.HoldTableGCState,
+			nextState:    schema.EvacTableGCState,
+			dataLossTime: hint.Add(evacHours * time.Hour),
+		},
+		{
+			lifecycle:    "hold,evac,drop",
+			state:        schema.EvacTableGCState,
+			nextState:    schema.DropTableGCState,
+			dataLossTime: hint,
+		},
+		{
+			lifecycle: "hold,purge,evac,drop",
+			state:     schema.EvacTableGCState,
+			nextState: schema.DropTableGCState,
+		},
+		{
+			lifecycle: "hold,purge,evac,drop",
+			state:     schema.PurgeTableGCState,
+			nextState: schema.EvacTableGCState,
+		},
+		{
+			lifecycle: "hold,purge,evac,drop",
+			state:     schema.DropTableGCState,
+		},
+	}
+	for _, ts := range tt {
+		t.Run(ts.lifecycle+" "+string(ts.state), func(t *testing.T) {
+			lifecycleStates, err := schema.ParseGCLifecycle(ts.lifecycle)
+			require.NoError(t, err)
+			collector := &TableGC{
+				lifecycleStates: lifecycleStates,
+			}
+			tableReport := &TableReport{
+				TableName:   "_vt_" + string(ts.state) + "_" + gcUUID + "_20200915120410",
+				State:       ts.state,
+				IsBaseTable: true,
+			}
+			collector.fillTableReport(tableReport, gcUUID, hint)
+			assert.Equal(t, hint, tableReport.TransitionTime)
+			assert.Equal(t, ts.nextState, tableReport.NextState)
+			if ts.dataLossTime.IsZero() {
+				assert.Nil(t, tableReport.DataLossTime)
+			} else {
+				require.NotNil(t, tableReport.DataLossTime)
+				assert.Equal(t, ts.dataLossTime, *tableReport.DataLossTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vitess.io/vitess/go/textutil"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+)
+
+// retentionPolicy defines how long a GC table is kept in a given state. A policy applies to tables whose
+// original name (or, when the original name is unknown, whose GC name) matches a glob pattern, optionally
+// restricted to a single keyspace.
+type retentionPolicy struct {
+	keyspace string
+	pattern  string
+	state    schema.TableGCState
+	duration time.Duration
+}
+
+// String returns the policy in the same format it is configured with
+func (p *retentionPolicy) String() string {
+	pattern := p.pattern
+	if p.keyspace != "" {
+		pattern = p.keyspace + "." + pattern
+	}
+	return fmt.Sprintf("%s:%s:%v", pattern, strings.ToLower(string(p.state)), p.duration)
+}
+
+// matches answers 'true' when the policy applies to the given keyspace, state and table
+func (p *retentionPolicy) matches(keyspace string, state schema.TableGCState, tableName string) bool {
+	if p.state != state {
+		return false
+	}
+	if p.keyspace != "" && p.keyspace != keyspace {
+		return false
+	}
+	matched, _ := path.Match(p.pattern, tableName)
+	return matched
+}
+
+// parseRetentionPolicies parses a comma separated list of retention policies, each in the format
+// [<keyspace>.]<table-pattern>:<state>:<duration>, e.g. "commerce.customer*:hold:168h,*:evac:24h".
+// The table pattern is a glob pattern.
+func parseRetentionPolicies(policies string) (result []*retentionPolicy, err error) {
+	for _, token := range textutil.SplitDelimitedList(policies) {
+		parts := strings.Split(token, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid retention policy %q: expected [<keyspace>.]<table-pattern>:<state>:<duration>", token)
+		}
+		policy := &retentionPolicy{pattern: parts[0]}
+		if keyspace, pattern, ok := strings.Cut(policy.pattern, "."); ok {
+			policy.keyspace, policy.pattern = keyspace, pattern
+		}
+		if _, err := path.Match(policy.pattern, ""); err != nil || policy.pattern == "" {
+			return nil, fmt.Errorf("invalid table pattern in retention policy %q", token)
+		}
+		state, ok := schema.ParseGCTableState(parts[1])
+		if !ok {
+			return nil, fmt.Errorf("unknown GC state in retention policy %q", token)
+		}
+		policy.state = state
+		policy.duration, err = time.ParseDuration(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in retention policy %q: %v", token, err)
+		}
+		if policy.duration < 0 {
+			return nil, fmt.Errorf("negative duration in retention policy %q", token)
+		}
+		result = append(result, policy)
+	}
+	return result, nil
+}
+
+// retentionPolicyFor returns the first configured policy that applies to the given table in the given state,
+// or nil if there is none. originTable is the name the table had before entering the GC lifecycle, if known.
+func (collector *TableGC) retentionPolicyFor(state schema.TableGCState, tableName string, originTable string) *retentionPolicy {
+	name := originTable
+	if name == "" {
+		name = tableName
+	}
+	for _, policy := range collector.retentionPolicies {
+		if policy.matches(collector.keyspace, state, name) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// gcUUIDTime returns the time at which a GC UUID was generated. GC UUIDs are time based (version 1)
+// and are generated when the table enters the GC lifecycle.
+func gcUUIDTime(gcUUID string) (time.Time, bool) {
+	u, err := uuid.Parse(gcUUID)
+	if err != nil || u.Version() != 1 {
+		return time.Time{}, false
+	}
+	sec, nsec := u.Time().UnixTime()
+	return time.Unix(sec, nsec).UTC(), true
+}
+
+// transitionTime returns the time at which a table in the given state is due to transition into its next state.
+// This is normally the time hint encoded in the table's name. A retention policy for the HOLD state overrides the
+// hint, and counts from the time the table entered the GC lifecycle.
+func (collector *TableGC) transitionTime(tableName string, state schema.TableGCState, gcUUID string, hint time.Time) time.Time {
+	if state != schema.HoldTableGCState {
+		// We stamp the retention period into the table name when transitioning into any other state.
+		return hint
+	}
+	policy := collector.retentionPolicyFor(state, tableName, collector.originTable(gcUUID))
+	if policy == nil {
+		return hint
+	}
+	entered, ok := gcUUIDTime(gcUUID)
+	if !ok {
+		return hint
+	}
+	return entered.Add(policy.duration)
+}
+
+// stateRetention returns how long a table should spend in the given state, once transitioned into it.
+// Unless a retention policy says otherwise, tables are purged and dropped immediately, and base tables
+// spend evacHours in EVAC state.
+func (collector *TableGC) stateRetention(state schema.TableGCState, tableName string, gcUUID string, isBaseTable bool) time.Duration {
+	if state == schema.EvacTableGCState && !isBaseTable {
+		// Views don't need evac.
+		return 0
+	}
+	if policy := collector.retentionPolicyFor(state, tableName, collector.originTable(gcUUID)); policy != nil {
+		return policy.duration
+	}
+	if state == schema.EvacTableGCState {
+		// in EVAC state  we want the table pages to evacuate from the buffer pool
+		return evacHours * time.Hour
+	}
+	return 0
+}
+
+// originTable returns the name a GC table had before entering the GC lifecycle, or an empty string if unknown
+func (collector *TableGC) originTable(gcUUID string) string {
+	collector.originMutex.Lock()
+	defer collector.originMutex.Unlock()
+
+	return collector.tableOrigins[gcUUID]
+}
+
+// refreshTableOrigins reads the original names of GC tables, as recorded by online DDL. Tables dropped by
+// online DDL keep the migration's UUID, and tables replaced by online DDL are listed as migration artifacts.
+// This is best effort: GC tables not created by online DDL have no known origin.
+func (collector *TableGC) refreshTableOrigins(ctx context.Context, conn *connpool.DBConn) {
+	res, err := conn.Exec(ctx, sqlSelectTableOrigins, math.MaxInt32, true)
+	if err != nil {
+		log.Infof("TableGC: could not read table origins: %v", err)
+		return
+	}
+	origins := map[string]string{}
+	for _, row := range res.Named().Rows {
+		mysqlTable := row.AsString("mysql_table", "")
+		origins[schema.OnlineDDLToGCUUID(row.AsString("migration_uuid", ""))] = mysqlTable
+		for _, artifact := range textutil.SplitDelimitedList(row.AsString("artifacts", "")) {
+			if isGCTable, _, gcUUID, _, _ := schema.AnalyzeGCTableName(artifact); isGCTable {
+				origins[gcUUID] = mysqlTable
+			}
+		}
+	}
+
+	collector.originMutex.Lock()
+	defer collector.originMutex.Unlock()
+	collector.tableOrigins = origins
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/schema"
+)
+
+func TestParseRetentionPolicies(t *testing.T) {
+	tt := []struct {
+		policies string
+		expect   []string
+		isError  bool
+	}{
+		{
+			policies: "",
+		},
+		{
+			policies: "*:hold:168h",
+			expect:   []string{"*:hold:168h0m0s"},
+		},
+		{
+			policies: "commerce.customer*:HOLD:24h, *:evac:1h",
+			expect:   []string{"commerce.customer*:hold:24h0m0s", "*:evac:1h0m0s"},
+		},
+		{
+			policies: "*:hold",
+			isError:  true,
+		},
+		{
+			policies: "*:keep:1h",
+			isError:  true,
+		},
+		{
+			policies: "*:hold:1 day",
+			isError:  true,
+		},
+		{
+			policies: "*:hold:-1h",
+			isError:  true,
+		},
+		{
+			policies: "commerce.[:hold:1h",
+			isError:  true,
+		},
+		{
+			policies: "commerce.:hold:1h",
+			isError:  true,
+		},
+	}
+	for _, ts := range tt {
+		t.Run(ts.policies, func(t *testing.T) {
+			policies, err := parseRetentionPolicies(ts.policies)
+			if ts.isError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, policy := range policies {
+				got = append(got, policy.String())
+			}
+			assert.Equal(t, ts.expect, got)
+		})
+	}
+}
+
+func TestRetentionPolicyFor(t *testing.T) {
+	policies, err := parseRetentionPolicies("commerce.customer*:hold:168h,other.*:hold:1h,*:hold:24h,*:evac:2h")
+	require.NoError(t, err)
+	collector := &TableGC{
+		keyspace:          "commerce",
+		retentionPolicies: policies,
+	}
+	tt := []struct {
+		state       schema.TableGCState
+		origin      string
+		expectMatch string
+	}{
+		{
+			state:       schema.HoldTableGCState,
+			origin:      "customer",
+			expectMatch: "commerce.customer*:hold:168h0m0s",
+		},
+		{
+			state:       schema.HoldTableGCState,
+			origin:      "corder",
+			expectMatch: "*:hold:24h0m0s",
+		},
+		{
+			state:       schema.HoldTableGCState,
+			expectMatch: "*:hold:24h0m0s",
+		},
+		{
+			state:       schema.EvacTableGCState,
+			origin:      "customer",
+			expectMatch: "*:evac:2h0m0s",
+		},
+		{
+			state:  schema.PurgeTableGCState,
+			origin: "customer",
+		},
+	}
+	for _, ts := range tt {
+		t.Run(string(ts.state)+" "+ts.origin, func(t *testing.T) {
+			policy := collector.retentionPolicyFor(ts.state, "_vt_HOLD_6ace8bcef73211ea87e9f875a4d24e90_20200915120410", ts.origin)
+			if ts.expectMatch == "" {
+				assert.Nil(t, policy)
+				return
+			}
+			require.NotNil(t, policy)
+			assert.Equal(t, ts.expectMatch, policy.String())
+		})
+	}
+}
+
+func TestTransitionTime(t *testing.T) {
+	gcUUID, err := schema.CreateUUIDWithDelimiter("")
+	require.NoError(t, err)
+	hint := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	holdTableName, err := schema.GenerateGCTableName(schema.HoldTableGCState, hint)
+	require.NoError(t, err)
+
+	policies, err := parseRetentionPolicies("customer:hold:168h,customer:evac:1h,customer:purge:30m")
+	require.NoError(t, err)
+	collector := &TableGC{
+		retentionPolicies: policies,
+		tableOrigins:      map[string]string{gcUUID: "customer"},
+	}
+
+	// No matching policy: the hint prevails
+	assert.Equal(t, hint, collector.transitionTime(holdTableName, schema.HoldTableGCState, "6ace8bcef73211ea87e9f875a4d24e90", hint))
+	// Matching policy: retention counts from the time the table entered the lifecycle
+	transitionTime := collector.transitionTime(holdTableName, schema.HoldTableGCState, gcUUID, hint)
+	assert.WithinDuration(t, time.Now().Add(168*time.Hour), transitionTime, time.Minute)
+	// Other states have their retention stamped into the name
+	assert.Equal(t, hint, collector.transitionTime(holdTableName, schema.EvacTableGCState, gcUUID, hint))
+
+	assert.Equal(t, time.Hour, collector.stateRetention(schema.EvacTableGCState, holdTableName, gcUUID, true))
+	assert.Equal(t, time.Duration(0), collector.stateRetention(schema.EvacTableGCState, holdTableName, gcUUID, false))
+	assert.Equal(t, 30*time.Minute, collector.stateRetention(schema.PurgeTableGCState, holdTableName, gcUUID, true))
+	assert.Equal(t, time.Duration(0), collector.stateRetention(schema.DropTableGCState, holdTableName, gcUUID, true))
+	assert.Equal(t, evacHours*time.Hour, collector.stateRetention(schema.EvacTableGCState, holdTableName, "6ace8bcef73211ea87e9f875a4d24e90", true))
+}
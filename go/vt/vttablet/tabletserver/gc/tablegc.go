@@ -22,11 +22,13 @@ import (
 	"math"
 	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"golang.org/x/time/rate"
 
 	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/timer"
@@ -45,12 +47,19 @@ const (
 	// evacHours is a hard coded, reasonable time for a table to spend in EVAC state
 	evacHours        = 72
 	throttlerAppName = "tablegc"
+	// purgeThrottlerAppName is checked, in addition to throttlerAppName, while purging rows. It allows
+	// throttling the purge alone.
+	purgeThrottlerAppName = "tablegc-purge"
+	// purgeBatchSize is the number of rows deleted by a single purge statement. See sqlPurgeTable
+	purgeBatchSize = 50
 )
 
 var (
 	checkInterval           = 1 * time.Hour
 	purgeReentranceInterval = 1 * time.Minute
 	gcLifecycle             = "hold,purge,evac,drop"
+	gcRetention             = ""
+	purgeRowsPerSecond      = 0
 )
 
 func init() {
@@ -65,13 +74,22 @@ func registerGCFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&purgeReentranceInterval, "gc_purge_check_interval", purgeReentranceInterval, "Interval between purge discovery checks")
 	// gcLifecycle is the sequence of steps the table goes through in the process of getting dropped
 	fs.StringVar(&gcLifecycle, "table_gc_lifecycle", gcLifecycle, "States for a DROP TABLE garbage collection cycle. Default is 'hold,purge,evac,drop', use any subset ('drop' implcitly always included)")
+	// gcRetention overrides the time tables spend in a GC state, per keyspace and table pattern
+	fs.StringVar(&gcRetention, "table_gc_retention", gcRetention, "Comma separated list of retention policies for GC tables, each in the format [<keyspace>.]<table-pattern>:<state>:<duration>, e.g. 'commerce.customer*:hold:168h'. The pattern is matched against the original table name. The first matching policy applies")
+	// purgeRowsPerSecond limits the rate at which rows are purged from PURGE tables
+	fs.IntVar(&purgeRowsPerSecond, "table_gc_purge_rows_per_second", purgeRowsPerSecond, "Maximum number of rows per second purged from tables in PURGE state. Zero means no limit, other than the throttler")
 }
 
 var (
+	purgeThrottlerAppNames = strings.Join([]string{throttlerAppName, purgeThrottlerAppName}, ":")
+
 	sqlPurgeTable       = `delete from %a limit 50`
 	sqlShowVtTables     = `show full tables like '\_vt\_%'`
-	sqlDropTable        = "drop table if exists `%a`"
-	purgeReentranceFlag int64
+	sqlShowVtTablesSize = `select table_name, table_type, ifnull(table_rows, 0), ifnull(data_length, 0) + ifnull(index_length, 0)
+		from information_schema.tables where table_schema = database() and table_name like '\_vt\_%'`
+	sqlSelectTableOrigins = `select migration_uuid, mysql_table, artifacts from _vt.schema_migrations`
+	sqlDropTable          = "drop table if exists `%a`"
+	purgeReentranceFlag   int64
 )
 
 // transitionRequest encapsulates a request to transition a table to next state
@@ -94,6 +112,7 @@ func init() {
 // - when due time, it transitions a table (via RENAME TABLE) to the next state
 // - finally, it issues a DROP TABLE
 // The sequence of steps is controlled by the command line variable --table_gc_lifecycle
+// The time spent in each step can be overridden per table by --table_gc_retention
 type TableGC struct {
 	keyspace string
 	shard    string
@@ -115,6 +134,14 @@ type TableGC struct {
 	// lifecycleStates indicates what states a GC table goes through. The user can set
 	// this with --table_gc_lifecycle, such that some states can be skipped.
 	lifecycleStates map[schema.TableGCState]bool
+	// retentionPolicies override the time tables spend in GC states. The user can set these
+	// with --table_gc_retention.
+	retentionPolicies []*retentionPolicy
+
+	originMutex sync.Mutex
+	// tableOrigins maps GC UUIDs onto the names tables had before entering the GC lifecycle,
+	// as recorded by online DDL. It is refreshed on each table check.
+	tableOrigins map[string]string
 }
 
 // Status published some status valus from the collector
@@ -171,6 +198,10 @@ func (collector *TableGC) Open() (err error) {
 	if err != nil {
 		return fmt.Errorf("Error parsing --table_gc_lifecycle flag: %+v", err)
 	}
+	collector.retentionPolicies, err = parseRetentionPolicies(gcRetention)
+	if err != nil {
+		return fmt.Errorf("Error parsing --table_gc_retention flag: %+v", err)
+	}
 
 	log.Info("TableGC: opening")
 	collector.pool.Open(collector.env.Config().DB.AllPrivsWithDB(), collector.env.Config().DB.DbaWithDB(), collector.env.Config().DB.AppDebugWithDB())
@@ -367,9 +398,10 @@ func (collector *TableGC) shouldTransitionTable(tableName string) (shouldTransit
 		return false, state, uuid, nil
 	}
 	if _, ok := collector.lifecycleStates[state]; ok {
-		// this state is in our expected lifecycle. Let's check table's time hint:
+		// this state is in our expected lifecycle. Let's check table's time hint, as possibly
+		// overridden by a retention policy:
 		timeNow := time.Now().UTC()
-		if timeNow.Before(t) {
+		if timeNow.Before(collector.transitionTime(tableName, state, uuid, t)) {
 			// not yet time to operate on this table
 			return false, state, uuid, nil
 		}
@@ -394,6 +426,7 @@ func (collector *TableGC) checkTables(ctx context.Context, dropTablesChan chan<-
 	if err != nil {
 		return err
 	}
+	collector.refreshTableOrigins(ctx, conn)
 
 	for _, row := range res.Rows {
 		tableName := row[0].ToString()
@@ -502,15 +535,23 @@ func (collector *TableGC) purge(ctx context.Context) (tableName string, err erro
 		}
 	}()
 
+	limiter := rate.NewLimiter(rate.Inf, purgeBatchSize)
+	if purgeRowsPerSecond > 0 {
+		limiter.SetLimit(rate.Limit(purgeRowsPerSecond))
+	}
 	log.Infof("TableGC: purge begin for %s", tableName)
 	for {
 		if ctx.Err() != nil {
 			// cancelled
 			return tableName, err
 		}
-		if !collector.throttlerClient.ThrottleCheckOKOrWait(ctx) {
+		if !collector.throttlerClient.ThrottleCheckOKOrWaitAppName(ctx, purgeThrottlerAppNames) {
 			continue
 		}
+		if err := limiter.WaitN(ctx, purgeBatchSize); err != nil {
+			// cancelled
+			return tableName, err
+		}
 		// OK, we're clear to go!
 
 		// Issue a DELETE
@@ -555,19 +596,7 @@ func (collector *TableGC) transitionTable(ctx context.Context, transition *trans
 	}
 	defer conn.Recycle()
 
-	// when we transition into PURGE, that means we want to begin purging immediately
-	// when we transition into DROP, that means we want to drop immediately
-	// Thereforce the default timestamp is Now
-	t := time.Now().UTC()
-	switch transition.toGCState {
-	case schema.EvacTableGCState:
-		if transition.isBaseTable {
-			// in EVAC state  we want the table pages to evacuate from the buffer pool. We therefore
-			// set the timestamp to some point the future, which we self determine
-			t = t.Add(evacHours * time.Hour)
-		}
-		// Views don't need evac. t remains "now"
-	}
+	t := time.Now().UTC().Add(collector.stateRetention(transition.toGCState, transition.fromTableName, transition.uuid, transition.isBaseTable))
 
 	renameStatement, toTableName, err := schema.GenerateRenameStatementWithUUID(transition.fromTableName, transition.toGCState, transition.uuid, t)
 	if err != nil {
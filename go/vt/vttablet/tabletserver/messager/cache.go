@@ -39,6 +39,19 @@ type MessageRow struct {
 	// defunct is set if the row was asked to be removed
 	// from cache.
 	defunct bool
+
+	// index and evictIndex are the positions of the row
+	// in the send and eviction queues of the cache.
+	index      int
+	evictIndex int
+}
+
+// sendsBefore returns true if mr1 is sent before mr2.
+// Lower priority is more important.
+// If priorities match, newer messages are more important.
+func sendsBefore(mr1, mr2 *MessageRow) bool {
+	return mr1.Priority < mr2.Priority ||
+		(mr1.Priority == mr2.Priority && mr1.TimeNext > mr2.TimeNext)
 }
 
 type messageHeap []*MessageRow
@@ -48,18 +61,19 @@ func (mh messageHeap) Len() int {
 }
 
 func (mh messageHeap) Less(i, j int) bool {
-	// Lower epoch is more important.
-	// If epochs match, newer messages are more important.
-	return mh[i].Priority < mh[j].Priority ||
-		(mh[i].Priority == mh[j].Priority && mh[i].TimeNext > mh[j].TimeNext)
+	return sendsBefore(mh[i], mh[j])
 }
 
 func (mh messageHeap) Swap(i, j int) {
 	mh[i], mh[j] = mh[j], mh[i]
+	mh[i].index = i
+	mh[j].index = j
 }
 
 func (mh *messageHeap) Push(x any) {
-	*mh = append(*mh, x.(*MessageRow))
+	mr := x.(*MessageRow)
+	mr.index = len(*mh)
+	*mh = append(*mh, mr)
 }
 
 func (mh *messageHeap) Pop() any {
@@ -70,6 +84,58 @@ func (mh *messageHeap) Pop() any {
 	return x
 }
 
+// evictionHeap orders the messages in the reverse order
+// of messageHeap, the defunct messages coming first since
+// they can be dropped for free.
+type evictionHeap []*MessageRow
+
+func (eh evictionHeap) Len() int {
+	return len(eh)
+}
+
+func (eh evictionHeap) Less(i, j int) bool {
+	if eh[i].defunct != eh[j].defunct {
+		return eh[i].defunct
+	}
+	return sendsBefore(eh[j], eh[i])
+}
+
+func (eh evictionHeap) Swap(i, j int) {
+	eh[i], eh[j] = eh[j], eh[i]
+	eh[i].evictIndex = i
+	eh[j].evictIndex = j
+}
+
+func (eh *evictionHeap) Push(x any) {
+	mr := x.(*MessageRow)
+	mr.evictIndex = len(*eh)
+	*eh = append(*eh, mr)
+}
+
+func (eh *evictionHeap) Pop() any {
+	old := *eh
+	n := len(old)
+	x := old[n-1]
+	*eh = old[0 : n-1]
+	return x
+}
+
+// addResult is the outcome of cache.Add.
+type addResult int
+
+const (
+	// cacheFull means the message was not added
+	// because the cache is full.
+	cacheFull = addResult(iota)
+	// messageAdded means the message was added, or
+	// was already in the cache.
+	messageAdded
+	// messageAddedWithEviction means the message took
+	// the place of the last message of the send queue,
+	// which is left to be read again by the poller.
+	messageAddedWithEviction
+)
+
 //_______________________________________________
 
 // cache is the cache for the messager. Messages initially
@@ -85,6 +151,10 @@ type cache struct {
 	size int
 
 	sendQueue messageHeap
+	// evictQueue holds the messages of sendQueue in the
+	// reverse order, to find the one to evict when the
+	// cache is full.
+	evictQueue evictionHeap
 	// inQueue is used to efficiently find items in sendQueue.
 	// The message id is the key.
 	inQueue map[string]*MessageRow
@@ -118,29 +188,49 @@ func (mc *cache) Clear() {
 	log.Infof("messager cache - acquired lock")
 	defer mc.mu.Unlock()
 	mc.sendQueue = nil
+	mc.evictQueue = nil
 	mc.inQueue = make(map[string]*MessageRow)
 	mc.inFlight = make(map[string]bool)
 	log.Infof("messager cache - cache cleared")
 }
 
 // Add adds a MessageRow to the cache. It returns
-// false if the cache is full.
-func (mc *cache) Add(mr *MessageRow) bool {
+// cacheFull if the cache is full. Even then, a message
+// that has a lower priority than the last message of
+// the send queue takes its place, and that message is
+// left to be read again by the poller. This way, urgent
+// messages are not held back behind other ones when
+// there are more messages pending than the cache holds.
+func (mc *cache) Add(mr *MessageRow) addResult {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
-	if len(mc.sendQueue) >= mc.size {
-		return false
-	}
 	id := mr.Row[0].ToString()
 	if mc.inFlight[id] {
-		return true
+		return messageAdded
 	}
 	if _, ok := mc.inQueue[id]; ok {
-		return true
+		return messageAdded
+	}
+	result := messageAdded
+	if len(mc.sendQueue) >= mc.size {
+		if len(mc.evictQueue) == 0 {
+			return cacheFull
+		}
+		lmr := mc.evictQueue[0]
+		if !lmr.defunct {
+			if lmr.Priority <= mr.Priority {
+				return cacheFull
+			}
+			delete(mc.inQueue, lmr.Row[0].ToString())
+			result = messageAddedWithEviction
+		}
+		heap.Remove(&mc.sendQueue, lmr.index)
+		heap.Remove(&mc.evictQueue, lmr.evictIndex)
 	}
 	heap.Push(&mc.sendQueue, mr)
+	heap.Push(&mc.evictQueue, mr)
 	mc.inQueue[id] = mr
-	return true
+	return result
 }
 
 // Pop removes the next MessageRow. Once the
@@ -157,6 +247,7 @@ func (mc *cache) Pop() *MessageRow {
 			return nil
 		}
 		mr := heap.Pop(&mc.sendQueue).(*MessageRow)
+		heap.Remove(&mc.evictQueue, mr.evictIndex)
 		// If message was previously marked as defunct, drop
 		// it and continue.
 		if mr.defunct {
@@ -180,6 +271,7 @@ func (mc *cache) Discard(ids []string) {
 			// The row is still in the queue somewhere. Mark
 			// it as defunct. It will be "garbage collected" later.
 			mr.defunct = true
+			heap.Fix(&mc.evictQueue, mr.evictIndex)
 		}
 		delete(mc.inQueue, id)
 		delete(mc.inFlight, id)
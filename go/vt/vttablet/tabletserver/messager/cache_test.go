@@ -17,6 +17,7 @@ limitations under the License.
 package messager
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -25,45 +26,45 @@ import (
 
 func TestMessagerCacheOrder(t *testing.T) {
 	mc := newCache(10)
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		Priority: 1,
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		Priority: 1,
 		TimeNext: 2,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row02")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		Priority: 2,
 		TimeNext: 2,
 		Epoch:    1,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row12")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		Priority: 2,
 		TimeNext: 1,
 		Epoch:    1,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row11")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		Priority: 1,
 		TimeNext: 3,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row03")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	var rows []string
 	for i := 0; i < 5; i++ {
@@ -83,69 +84,69 @@ func TestMessagerCacheOrder(t *testing.T) {
 
 func TestMessagerCacheDupKey(t *testing.T) {
 	mc := newCache(10)
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Error("Add(dup): returned false, want true")
+	}) != messageAdded {
+		t.Error("Add(dup): message not added")
 	}
 	_ = mc.Pop()
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Error("Add(dup): returned false, want true")
+	}) != messageAdded {
+		t.Error("Add(dup): message not added")
 	}
 	mc.Discard([]string{"row01"})
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 }
 
 func TestMessagerCacheDiscard(t *testing.T) {
 	mc := newCache(10)
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	mc.Discard([]string{"row01"})
 	if row := mc.Pop(); row != nil {
 		t.Errorf("Pop: want nil, got %v", row.Row[0])
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	if row := mc.Pop(); row == nil || row.Row[0].ToString() != "row01" {
 		t.Errorf("Pop: want row01, got %v", row)
 	}
 
 	// Add will be a no-op.
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	if row := mc.Pop(); row != nil {
 		t.Errorf("Pop: want nil, got %v", row.Row[0])
@@ -153,12 +154,12 @@ func TestMessagerCacheDiscard(t *testing.T) {
 	mc.Discard([]string{"row01"})
 
 	// Now we can add.
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	if row := mc.Pop(); row == nil || row.Row[0].ToString() != "row01" {
 		t.Errorf("Pop: want row01, got %v", row)
@@ -167,48 +168,156 @@ func TestMessagerCacheDiscard(t *testing.T) {
 
 func TestMessagerCacheFull(t *testing.T) {
 	mc := newCache(2)
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 2,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row02")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	if mc.Add(&MessageRow{
 		TimeNext: 2,
 		Epoch:    1,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row12")},
-	}) {
-		t.Error("Add(full): returned true, want false")
+	}) != cacheFull {
+		t.Error("Add(full): message added, want cache full")
+	}
+}
+
+func TestMessagerCacheFullPriority(t *testing.T) {
+	mc := newCache(2)
+	if mc.Add(&MessageRow{
+		Priority: 1,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
+	}
+	if mc.Add(&MessageRow{
+		Priority: 2,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row02")},
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
+	}
+	// The same priority does not make room.
+	if mc.Add(&MessageRow{
+		Priority: 2,
+		TimeNext: 2,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row03")},
+	}) != cacheFull {
+		t.Error("Add(full): message added, want cache full")
+	}
+	// A lower priority takes the place of the last message, but
+	// the cache is still full.
+	if mc.Add(&MessageRow{
+		Priority: 0,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row00")},
+	}) != messageAddedWithEviction {
+		t.Error("Add(full): want the message added with an eviction")
+	}
+	// A defunct message makes room for free.
+	mc.Discard([]string{"row01"})
+	if mc.Add(&MessageRow{
+		Priority: 3,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row04")},
+	}) != messageAdded {
+		t.Error("Add: message not added")
+	}
+	var rows []string
+	for mr := mc.Pop(); mr != nil; mr = mc.Pop() {
+		rows = append(rows, mr.Row[0].ToString())
+	}
+	want := []string{
+		"row00",
+		"row04",
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Pop order: %+v, want %+v", rows, want)
+	}
+	// The message that was dropped can be added again.
+	if mc.Add(&MessageRow{
+		Priority: 2,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row02")},
+	}) != messageAdded {
+		t.Error("Add: message not added")
+	}
+}
+
+func TestMessagerCacheEvictionOrder(t *testing.T) {
+	mc := newCache(10)
+	// Priorities 99 down to 0: every message from the eleventh
+	// one on takes the place of the last one of the cache.
+	for i := 0; i < 100; i++ {
+		want := messageAdded
+		if i >= 10 {
+			want = messageAddedWithEviction
+		}
+		if got := mc.Add(&MessageRow{
+			Priority: int64(99 - i),
+			TimeNext: 1,
+			Row:      []sqltypes.Value{sqltypes.NewVarBinary(fmt.Sprintf("row%02d", 99-i))},
+		}); got != want {
+			t.Fatalf("Add(row%02d): %v, want %v", 99-i, got, want)
+		}
+	}
+	// The defunct messages are dropped first.
+	mc.Discard([]string{"row03", "row07"})
+	for _, id := range []string{"row50", "row51"} {
+		if mc.Add(&MessageRow{
+			Priority: 50,
+			TimeNext: 1,
+			Row:      []sqltypes.Value{sqltypes.NewVarBinary(id)},
+		}) != messageAdded {
+			t.Fatalf("Add(%s): message not added", id)
+		}
+	}
+	if mc.Add(&MessageRow{
+		Priority: 60,
+		TimeNext: 1,
+		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row60")},
+	}) != cacheFull {
+		t.Error("Add(full): message added, want cache full")
+	}
+	var rows []string
+	for mr := mc.Pop(); mr != nil; mr = mc.Pop() {
+		rows = append(rows, mr.Row[0].ToString())
+	}
+	want := []string{"row00", "row01", "row02", "row04", "row05", "row06", "row08", "row09", "row51", "row50"}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Pop order: %+v, want %+v", rows, want)
 	}
 }
 
 func TestMessagerCacheEmpty(t *testing.T) {
 	mc := newCache(2)
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	mc.Clear()
 	if row := mc.Pop(); row != nil {
 		t.Errorf("Pop(empty): %v, want nil", row)
 	}
-	if !mc.Add(&MessageRow{
+	if mc.Add(&MessageRow{
 		TimeNext: 1,
 		Epoch:    0,
 		Row:      []sqltypes.Value{sqltypes.NewVarBinary("row01")},
-	}) {
-		t.Fatal("Add returned false")
+	}) != messageAdded {
+		t.Fatal("Add: message not added")
 	}
 	if row := mc.Pop(); row == nil {
 		t.Errorf("Pop(non-empty): nil, want %v", row)
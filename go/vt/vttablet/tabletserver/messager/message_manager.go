@@ -51,6 +51,7 @@ type QueryGenerator interface {
 	GenerateAckQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePostponeQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePurgeQuery(timeCutoff int64) (string, map[string]*querypb.BindVariable)
+	GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery
 }
 
 type messageReceiver struct {
@@ -164,6 +165,22 @@ type receiverWithStatus struct {
 // The Purge thread
 // This thread is mostly independent. It wakes up periodically
 // to delete old rows that were successfully acked.
+//
+// Priority
+// If the table has a priority column, messages with a lower priority
+// are sent first, by the poller as well as by the cache. When the cache
+// is full, a message with a lower priority than the last message of the
+// cache takes its place, and that message is left for the poller to read
+// again. Without the column, all the messages have the same priority.
+//
+// Dead letters
+// If the table has a max number of retries, a message that comes up
+// for sending after it has been resent that many times is not sent
+// any more. Instead, it is moved to the dead letter table of the
+// message table in a single transaction, or it is dropped (acked) if
+// there is no dead letter table. Dead letters can then be moved back
+// to the message table to be retried with the RequeueDeadLetterMessages
+// vtctld command.
 type messageManager struct {
 	tsv TabletService
 	vs  VStreamer
@@ -174,6 +191,8 @@ type messageManager struct {
 	purgeAfter   time.Duration
 	minBackoff   time.Duration
 	maxBackoff   time.Duration
+	maxRetries   int
+	hasPriority  bool
 	batchSize    int
 	pollerTicks  *timer.Timer
 	purgeTicks   *timer.Timer
@@ -234,6 +253,9 @@ type messageManager struct {
 	ackQuery                  *sqlparser.ParsedQuery
 	postponeQuery             *sqlparser.ParsedQuery
 	purgeQuery                *sqlparser.ParsedQuery
+	// deadLetterQueries are run in a single transaction to
+	// give up on messages that ran out of retries.
+	deadLetterQueries []*sqlparser.ParsedQuery
 }
 
 // newMessageManager creates a new message manager.
@@ -251,6 +273,8 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		purgeAfter:      table.MessageInfo.PurgeAfterDuration,
 		minBackoff:      table.MessageInfo.MinBackoff,
 		maxBackoff:      table.MessageInfo.MaxBackoff,
+		maxRetries:      table.MessageInfo.MaxRetries,
+		hasPriority:     table.MessageInfo.HasPriority,
 		batchSize:       table.MessageInfo.BatchSize,
 		cache:           newCache(table.MessageInfo.CacheSize),
 		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
@@ -261,7 +285,13 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 	mm.cond.L = &mm.mu
 
 	columnList := buildSelectColumnList(table)
-	vsQuery := fmt.Sprintf("select priority, time_next, epoch, time_acked, %s from %v", columnList, mm.name)
+	// The priority column is optional. Without it, all the messages
+	// have the same priority and are only ordered by time_next.
+	priority := "priority, "
+	if !mm.hasPriority {
+		priority = ""
+	}
+	vsQuery := fmt.Sprintf("select %stime_next, epoch, time_acked, %s from %v", priority, columnList, mm.name)
 	mm.vsFilter = &binlogdatapb.Filter{
 		Rules: []*binlogdatapb.Rule{{
 			Match:  table.Name.String(),
@@ -271,8 +301,8 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 	mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
 		// There should be a poller_idx defined on (time_acked, priority, time_next desc)
 		// for this to be as effecient as possible
-		"select priority, time_next, epoch, time_acked, %s from %v where time_acked is null and time_next < %a order by priority, time_next desc limit %a",
-		columnList, mm.name, ":time_next", ":max")
+		"select %stime_next, epoch, time_acked, %s from %v where time_acked is null and time_next < %a order by %stime_next desc limit %a",
+		priority, columnList, mm.name, ":time_next", priority, ":max")
 	mm.ackQuery = sqlparser.BuildParsedQuery(
 		"update %v set time_acked = %a, time_next = null where id in %a and time_acked is null",
 		mm.name, ":time_acked", "::ids")
@@ -281,6 +311,21 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 
 	mm.postponeQuery = buildPostponeQuery(mm.name, mm.minBackoff, mm.maxBackoff)
 
+	if deadLetterTable := table.MessageInfo.DeadLetterTable; deadLetterTable != "" {
+		// All the columns of the message are kept, the dead letter table
+		// can have more columns as long as they have default values.
+		tableColumnList := buildColumnList(table.Fields)
+		mm.deadLetterQueries = []*sqlparser.ParsedQuery{
+			sqlparser.BuildParsedQuery(
+				"insert into %v(%s) select %s from %v where id in %a and time_acked is null",
+				sqlparser.NewIdentifierCS(deadLetterTable), tableColumnList, tableColumnList, mm.name, "::ids"),
+			sqlparser.BuildParsedQuery(
+				"delete from %v where id in %a and time_acked is null", mm.name, "::ids"),
+		}
+	} else {
+		mm.deadLetterQueries = []*sqlparser.ParsedQuery{mm.ackQuery}
+	}
+
 	return mm
 }
 
@@ -333,8 +378,13 @@ func buildPostponeQuery(name sqlparser.IdentifierCS, minBackoff, maxBackoff time
 // buildSelectColumnList is a convenience function that
 // builds a 'select' list for the user-defined columns.
 func buildSelectColumnList(t *schema.Table) string {
+	return buildColumnList(t.MessageInfo.Fields)
+}
+
+// buildColumnList builds a column list for the given fields.
+func buildColumnList(fields []*querypb.Field) string {
 	buf := sqlparser.NewTrackedBuffer(nil)
-	for i, c := range t.MessageInfo.Fields {
+	for i, c := range fields {
 		// Column names may have to be escaped.
 		if i == 0 {
 			buf.Myprintf("%v", sqlparser.NewIdentifierCI(c.Name))
@@ -496,10 +546,14 @@ func (mm *messageManager) Add(mr *MessageRow) bool {
 	if mm.cache.IsEmpty() {
 		defer mm.cond.Broadcast()
 	}
-	if !mm.cache.Add(mr) {
+	switch mm.cache.Add(mr) {
+	case cacheFull:
 		// Cache is full. Enter "messagesPending" mode.
 		mm.messagesPending = true
 		return false
+	case messageAddedWithEviction:
+		// The evicted message has to be read again.
+		mm.messagesPending = true
 	}
 	return true
 }
@@ -547,17 +601,27 @@ func (mm *messageManager) runSend() {
 
 			// Fetch rows from cache.
 			lateCount := int64(0)
+			var deadIDs []string
 			for i := 0; i < mm.batchSize; i++ {
 				mr := mm.cache.Pop()
 				if mr == nil {
 					break
 				}
+				if mm.maxRetries > 0 && mr.Epoch > int64(mm.maxRetries) {
+					// The message was already resent maxRetries times.
+					deadIDs = append(deadIDs, mr.Row[0].ToString())
+					continue
+				}
 				if mr.Epoch >= 1 {
 					lateCount++
 				}
 				rows = append(rows, mr.Row)
 			}
 			MessageStats.Add([]string{mm.name.String(), "Delayed"}, lateCount)
+			if deadIDs != nil {
+				mm.wg.Add(1)
+				go mm.deadLetter(deadIDs) // calls the offsetting mm.wg.Done()
+			}
 
 			// If we have rows to send, break out of this loop.
 			if rows != nil {
@@ -634,6 +698,46 @@ func (mm *messageManager) postpone(tsv TabletService, ackWaitTime time.Duration,
 	}
 }
 
+// deadLetter gives up on messages that ran out of retries. If that
+// fails, the messages are postponed instead, to be tried again later.
+func (mm *messageManager) deadLetter(ids []string) {
+	defer func() {
+		mm.tsv.LogError()
+		mm.wg.Done()
+	}()
+
+	defer func() {
+		// Same as for send: hold cacheManagementMu to prevent the
+		// poller from requeuing the messages before they are gone.
+		mm.cacheManagementMu.Lock()
+		defer mm.cacheManagementMu.Unlock()
+		mm.cache.Discard(ids)
+	}()
+
+	if err := mm.moveToDeadLetters(ids); err != nil {
+		MessageStats.Add([]string{mm.name.String(), "DeadLetterFailed"}, 1)
+		log.Errorf("Unable to move messages %v of %v to dead letters: %v", ids, mm.name, err)
+		mm.postpone(mm.tsv, mm.ackWaitTime, ids)
+	}
+}
+
+func (mm *messageManager) moveToDeadLetters(ids []string) error {
+	// Use the semaphore to limit parallelism.
+	if !mm.postponeSema.Acquire() {
+		// Unreachable.
+		return nil
+	}
+	defer mm.postponeSema.Release()
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.ackWaitTime)
+	defer cancel()
+	count, err := mm.tsv.DeadLetterMessages(ctx, nil, mm, ids)
+	if err != nil {
+		return err
+	}
+	MessageStats.Add([]string{mm.name.String(), "DeadLettered"}, count)
+	return nil
+}
+
 func (mm *messageManager) startVStream() {
 	if mm.streamCancel != nil {
 		return
@@ -750,7 +854,7 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 			continue
 		}
 		row := sqltypes.MakeRowTrusted(fields, rc.After)
-		mr, err := BuildMessageRow(row)
+		mr, err := mm.buildMessageRow(row)
 		if err != nil {
 			return err
 		}
@@ -804,15 +908,20 @@ func (mm *messageManager) runPoller() {
 		defer mm.cond.Broadcast()
 	}
 	for _, row := range qr.Rows {
-		mr, err := BuildMessageRow(row)
+		mr, err := mm.buildMessageRow(row)
 		if err != nil {
 			mm.tsv.Stats().InternalErrors.Add("Messages", 1)
 			log.Errorf("Error reading message row: %v", err)
 			continue
 		}
-		if !mm.cache.Add(mr) {
+		switch mm.cache.Add(mr) {
+		case cacheFull:
 			mm.messagesPending = true
 			return
+		case messageAddedWithEviction:
+			// The next rows can still take the place of other
+			// messages, the evicted one will be read again.
+			mm.messagesPending = true
 		}
 	}
 }
@@ -893,6 +1002,30 @@ func (mm *messageManager) GeneratePurgeQuery(timeCutoff int64) (string, map[stri
 	}
 }
 
+// GenerateDeadLetterQueries returns the queries to run in a transaction
+// to give up on messages that ran out of retries.
+func (mm *messageManager) GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery {
+	idbvs := &querypb.BindVariable{
+		Type:   querypb.Type_TUPLE,
+		Values: make([]*querypb.Value, 0, len(ids)),
+	}
+	for _, id := range ids {
+		idbvs.Values = append(idbvs.Values, &querypb.Value{
+			Type:  querypb.Type_VARBINARY,
+			Value: []byte(id),
+		})
+	}
+	bvs := map[string]*querypb.BindVariable{
+		"time_acked": sqltypes.Int64BindVariable(time.Now().UnixNano()),
+		"ids":        idbvs,
+	}
+	queries := make([]*querypb.BoundQuery, 0, len(mm.deadLetterQueries))
+	for _, query := range mm.deadLetterQueries {
+		queries = append(queries, &querypb.BoundQuery{Sql: query.Query, BindVariables: bvs})
+	}
+	return queries
+}
+
 // BuildMessageRow builds a MessageRow from a db row.
 func BuildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
 	mr := &MessageRow{Row: row[4:]}
@@ -927,6 +1060,15 @@ func BuildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
 	return mr, nil
 }
 
+// buildMessageRow builds a MessageRow from a row read from the message table,
+// which does not start with the priority if the table has no priority column.
+func (mm *messageManager) buildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
+	if !mm.hasPriority {
+		row = append([]sqltypes.Value{sqltypes.NULL}, row...)
+	}
+	return BuildMessageRow(row)
+}
+
 func (mm *messageManager) readPending(ctx context.Context, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	query, err := mm.readByPriorityAndTimeNext.GenerateQuery(bindVars, nil)
 	if err != nil {
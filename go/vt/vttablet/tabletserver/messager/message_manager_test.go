@@ -32,6 +32,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/sync2"
@@ -74,6 +75,7 @@ func newMMTable() *schema.Table {
 			BatchSize:          1,
 			CacheSize:          10,
 			PollInterval:       1 * time.Second,
+			HasPriority:        true,
 		},
 	}
 }
@@ -91,10 +93,26 @@ func newMMTableWithBackoff() *schema.Table {
 			BatchSize:          1,
 			CacheSize:          10,
 			PollInterval:       1 * time.Second,
+			HasPriority:        true,
 		},
 	}
 }
 
+func newMMTableWithDeadLetters() *schema.Table {
+	table := newMMTable()
+	table.Fields = []*querypb.Field{
+		{Name: "priority", Type: sqltypes.Int64},
+		{Name: "time_next", Type: sqltypes.Int64},
+		{Name: "epoch", Type: sqltypes.Int64},
+		{Name: "time_acked", Type: sqltypes.Int64},
+		{Name: "id", Type: sqltypes.Int64},
+		{Name: "message", Type: sqltypes.VarBinary},
+	}
+	table.MessageInfo.MaxRetries = 2
+	table.MessageInfo.DeadLetterTable = "foo_dead"
+	return table
+}
+
 func newMMRow(id int64) *querypb.Row {
 	return sqltypes.RowToProto3([]sqltypes.Value{
 		sqltypes.NewInt64(1),
@@ -298,6 +316,41 @@ func TestMessageManagerSend(t *testing.T) {
 	<-r1.ch
 }
 
+func TestMessageManagerDeadLetter(t *testing.T) {
+	tsv := newFakeTabletServer()
+	mm := newMessageManager(tsv, newFakeVStreamer(), newMMTableWithDeadLetters(), sync2.NewSemaphore(1, 0))
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	ch := make(chan string, 20)
+	tsv.SetChannel(ch)
+
+	// The message was resent twice already: it is sent one last time.
+	mm.Add(&MessageRow{Epoch: 2, Row: []sqltypes.Value{sqltypes.NewVarBinary("1")}})
+	want := &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{sqltypes.NewVarBinary("1")}},
+	}
+	got := <-r1.ch
+	assert.True(t, got.Equal(want), "Received: %v, want %v", got, want)
+	assert.Equal(t, "postpone", <-ch)
+
+	// The message ran out of retries: it is not sent any more.
+	mm.Add(&MessageRow{Epoch: 3, Row: []sqltypes.Value{sqltypes.NewVarBinary("2")}})
+	assert.Equal(t, "deadletter", <-ch)
+	assert.EqualValues(t, 1, tsv.deadLetterCount.Get())
+
+	mm.Add(&MessageRow{Epoch: 1, Row: []sqltypes.Value{sqltypes.NewVarBinary("3")}})
+	want = &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{sqltypes.NewVarBinary("3")}},
+	}
+	got = <-r1.ch
+	assert.True(t, got.Equal(want), "Received: %v, want %v", got, want)
+}
+
 func TestMessageManagerPostponeThrottle(t *testing.T) {
 	tsv := newFakeTabletServer()
 	mm := newMessageManager(tsv, newFakeVStreamer(), newMMTable(), sync2.NewSemaphore(1, 0))
@@ -637,6 +690,46 @@ func TestMessageManagerPoller(t *testing.T) {
 	}
 }
 
+func TestMessageManagerWithoutPriority(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.HasPriority = false
+	ti.MessageInfo.PollInterval = 20 * time.Second
+	fvs := newFakeVStreamer()
+	fvs.setPollerResponse([]*binlogdatapb.VStreamResultsResponse{{
+		Fields: testDBFields[1:],
+		Gtid:   "MySQL56/33333333-3333-3333-3333-333333333333:1-100",
+	}, {
+		Rows: []*querypb.Row{
+			sqltypes.RowToProto3([]sqltypes.Value{
+				sqltypes.NewInt64(1),
+				sqltypes.NewInt64(0),
+				sqltypes.NULL,
+				sqltypes.NewInt64(1),
+				sqltypes.NewVarBinary("1"),
+			}),
+		},
+	}})
+	mm := newMessageManager(newFakeTabletServer(), fvs, ti, sync2.NewSemaphore(1, 0))
+	assert.Equal(t, "select time_next, epoch, time_acked, id, message from foo", mm.vsFilter.Rules[0].Filter)
+	assert.Equal(t, "select time_next, epoch, time_acked, id, message from foo where time_acked is null and time_next < :time_next order by time_next desc limit :max", mm.readByPriorityAndTimeNext.Query)
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	want := &sqltypes.Result{
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt64(1),
+			sqltypes.NewVarBinary("1"),
+		}},
+	}
+	if got := <-r1.ch; !got.Equal(want) {
+		t.Errorf("Received: %v, want %v", got, want)
+	}
+}
+
 // TestMessagesPending1 tests for the case where you can't
 // add items because the cache is full.
 func TestMessagesPending1(t *testing.T) {
@@ -787,6 +880,21 @@ func TestMMGenerate(t *testing.T) {
 	}
 }
 
+func TestMMGenerateDeadLetter(t *testing.T) {
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTable(), sync2.NewSemaphore(1, 0))
+	queries := mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	require.Len(t, queries, 1)
+	assert.Equal(t, "update foo set time_acked = :time_acked, time_next = null where id in ::ids and time_acked is null", queries[0].Sql)
+
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTableWithDeadLetters(), sync2.NewSemaphore(1, 0))
+	queries = mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	require.Len(t, queries, 2)
+	assert.Equal(t, "insert into foo_dead(priority, time_next, epoch, time_acked, id, message) select priority, time_next, epoch, time_acked, id, message from foo where id in ::ids and time_acked is null", queries[0].Sql)
+	assert.Equal(t, "delete from foo where id in ::ids and time_acked is null", queries[1].Sql)
+	wantids := sqltypes.TestBindVariable([]any{[]byte{'1'}, []byte{'2'}})
+	utils.MustMatch(t, wantids, queries[1].BindVariables["ids"], "did not match")
+}
+
 func TestMMGenerateWithBackoff(t *testing.T) {
 	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTableWithBackoff(), sync2.NewSemaphore(1, 0))
 	mm.Open()
@@ -824,8 +932,9 @@ func TestMMGenerateWithBackoff(t *testing.T) {
 
 type fakeTabletServer struct {
 	tabletenv.Env
-	postponeCount sync2.AtomicInt64
-	purgeCount    sync2.AtomicInt64
+	postponeCount   sync2.AtomicInt64
+	purgeCount      sync2.AtomicInt64
+	deadLetterCount sync2.AtomicInt64
 
 	mu sync.Mutex
 	ch chan string
@@ -868,6 +977,17 @@ func (fts *fakeTabletServer) PurgeMessages(ctx context.Context, target *querypb.
 	return 0, nil
 }
 
+func (fts *fakeTabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, gen QueryGenerator, ids []string) (count int64, err error) {
+	fts.deadLetterCount.Add(1)
+	fts.mu.Lock()
+	ch := fts.ch
+	fts.mu.Unlock()
+	if ch != nil {
+		ch <- "deadletter"
+	}
+	return int64(len(ids)), nil
+}
+
 type fakeVStreamer struct {
 	streamInvocations sync2.AtomicInt64
 	mu                sync.Mutex
@@ -144,6 +144,10 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		return nil, err
 	}
 
+	if err = qre.waitForGTIDSet(); err != nil {
+		return nil, err
+	}
+
 	if qre.plan.PlanID == p.PlanNextval {
 		return qre.execNextval()
 	}
@@ -433,6 +437,10 @@ func (qre *QueryExecutor) Stream(callback StreamCallback) error {
 		return err
 	}
 
+	if err := qre.waitForGTIDSet(); err != nil {
+		return err
+	}
+
 	switch qre.plan.PlanID {
 	case p.PlanSelectStream:
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
@@ -876,6 +884,46 @@ func (qre *QueryExecutor) execOther() (*sqltypes.Result, error) {
 	return qre.execDBConn(conn, qre.query, true)
 }
 
+// waitForGTIDSet makes a replica wait until it has executed the GTID set
+// that the caller passed in the options. vtgate uses it to make the replica
+// reads of a session see the writes that the session committed on the primary.
+func (qre *QueryExecutor) waitForGTIDSet() error {
+	gtidSet := qre.options.GetWaitForGtidSet()
+	if gtidSet == "" || qre.tsv.sm.Target().TabletType == topodatapb.TabletType_PRIMARY {
+		return nil
+	}
+
+	// Without a timeout, the wait is only bounded by the context of the query,
+	// which kills it once it expires.
+	query := "select wait_for_executed_gtid_set(%a)"
+	bindVars := []*querypb.BindVariable{sqltypes.StringBindVariable(gtidSet)}
+	if timeout := qre.options.GetWaitForGtidSetTimeout(); timeout > 0 {
+		query = "select wait_for_executed_gtid_set(%a, %a)"
+		bindVars = append(bindVars, sqltypes.Float64BindVariable(timeout))
+	}
+	sql, err := sqlparser.ParseAndBind(query, bindVars...)
+	if err != nil {
+		return err
+	}
+
+	conn, err := qre.getConn()
+	if err != nil {
+		return err
+	}
+	defer conn.Recycle()
+	qr, err := qre.execDBConn(conn, sql, false)
+	if err != nil {
+		return err
+	}
+	if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result of %s: %v", sql, qr.Rows)
+	}
+	if qr.Rows[0][0].ToString() != "0" {
+		return vterrors.Errorf(vtrpcpb.Code_DEADLINE_EXCEEDED, "timed out waiting for GTID set %s", gtidSet)
+	}
+	return nil
+}
+
 func (qre *QueryExecutor) getConn() (*connpool.DBConn, error) {
 	span, ctx := trace.NewSpan(qre.ctx, "QueryExecutor.getConn")
 	defer span.Finish()
@@ -467,6 +467,49 @@ func TestQueryExecutorSelectImpossible(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorWaitForGTIDSet(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	input := "select * from t where 1 != 1"
+	fieldResult := sqltypes.MakeTestResult(sqltypes.MakeTestFields("a|b", "int64|varchar"))
+	db.AddQuery("select * from t where 1 != 1 limit 10001", fieldResult)
+	waitFields := sqltypes.MakeTestFields("wait", "int64")
+	db.AddQuery("select wait_for_executed_gtid_set('uuid:1-5', 1.5)", sqltypes.MakeTestResult(waitFields, "0"))
+	db.AddQuery("select wait_for_executed_gtid_set('uuid:1-9', 1.5)", sqltypes.MakeTestResult(waitFields, "1"))
+	db.AddQuery("select wait_for_executed_gtid_set('uuid:1-5')", sqltypes.MakeTestResult(waitFields, "0"))
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	defer tsv.StopService()
+
+	// a primary has all the writes already, it does not wait.
+	qre := newTestQueryExecutor(ctx, tsv, input, 0)
+	qre.options = &querypb.ExecuteOptions{WaitForGtidSet: "uuid:1-1000", WaitForGtidSetTimeout: 1.5}
+	_, err := qre.Execute()
+	require.NoError(t, err)
+
+	tsv.sm.mu.Lock()
+	tsv.sm.target.TabletType = topodatapb.TabletType_REPLICA
+	tsv.sm.mu.Unlock()
+
+	qre = newTestQueryExecutor(ctx, tsv, input, 0)
+	qre.options = &querypb.ExecuteOptions{WaitForGtidSet: "uuid:1-5", WaitForGtidSetTimeout: 1.5}
+	got, err := qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, fieldResult, got)
+
+	qre = newTestQueryExecutor(ctx, tsv, input, 0)
+	qre.options = &querypb.ExecuteOptions{WaitForGtidSet: "uuid:1-5"}
+	_, err = qre.Execute()
+	require.NoError(t, err)
+
+	qre = newTestQueryExecutor(ctx, tsv, input, 0)
+	qre.options = &querypb.ExecuteOptions{WaitForGtidSet: "uuid:1-9", WaitForGtidSetTimeout: 1.5}
+	_, err = qre.Execute()
+	require.EqualError(t, err, "timed out waiting for GTID set uuid:1-9")
+	assert.Equal(t, vtrpcpb.Code_DEADLINE_EXCEEDED, vterrors.Code(err))
+}
+
 // TestDisableOnlineDDL checks whether disabling online DDLs throws the correct error or not
 func TestDisableOnlineDDL(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
@@ -645,6 +645,7 @@ func initialSchema() map[string]*Table {
 				BatchSize:          1,
 				CacheSize:          10,
 				PollInterval:       30 * time.Second,
+				HasPriority:        true,
 			},
 		},
 	}
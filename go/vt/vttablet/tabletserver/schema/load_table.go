@@ -65,9 +65,8 @@ func fetchColumns(ta *Table, conn *connpool.DBConn, databaseName, sqlTableName s
 	return nil
 }
 
-func loadMessageInfo(ta *Table, comment string) error {
-	ta.MessageInfo = &MessageInfo{}
-	// Extract keyvalues.
+// parseMessageComment extracts the key values of the comment of a message table.
+func parseMessageComment(comment string) map[string]string {
 	keyvals := make(map[string]string)
 	inputs := strings.Split(comment, ",")
 	for _, input := range inputs {
@@ -77,6 +76,18 @@ func loadMessageInfo(ta *Table, comment string) error {
 		}
 		keyvals[kv[0]] = kv[1]
 	}
+	return keyvals
+}
+
+// MessageDeadLetterTable returns the dead letter table given by the comment of a message table, if any.
+func MessageDeadLetterTable(comment string) string {
+	return parseMessageComment(comment)["vt_dead_letter_table"]
+}
+
+func loadMessageInfo(ta *Table, comment string) error {
+	ta.MessageInfo = &MessageInfo{}
+	// Extract keyvalues.
+	keyvals := parseMessageComment(comment)
 
 	var err error
 	if ta.MessageInfo.AckWaitDuration, err = getDuration(keyvals, "vt_ack_wait"); err != nil {
@@ -104,16 +115,28 @@ func loadMessageInfo(ta *Table, comment string) error {
 
 	ta.MessageInfo.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
 
+	// by default, messages are retried forever
+	ta.MessageInfo.MaxRetries, _ = getNum(keyvals, "vt_max_retries")
+	ta.MessageInfo.DeadLetterTable = keyvals["vt_dead_letter_table"]
+	if ta.MessageInfo.DeadLetterTable != "" && ta.MessageInfo.MaxRetries == 0 {
+		return fmt.Errorf("vt_dead_letter_table requires vt_max_retries: %s", ta.Name.String())
+	}
+	if strings.EqualFold(ta.MessageInfo.DeadLetterTable, ta.Name.String()) {
+		return fmt.Errorf("vt_dead_letter_table cannot be the message table itself: %s", ta.Name.String())
+	}
+
 	// these columns are required for message manager to function properly, but only
 	// id is required to be streamed to subscribers
 	requiredCols := []string{
 		"id",
-		"priority",
 		"time_next",
 		"epoch",
 		"time_acked",
 	}
 
+	// priority is optional, messages all have the same priority without it
+	ta.MessageInfo.HasPriority = ta.FindColumn(sqlparser.NewIdentifierCI("priority")) != -1
+
 	// by default, these columns are loaded for the message manager, but not sent to subscribers
 	// via stream * from msg_tbl
 	hiddenCols := map[string]struct{}{
@@ -123,6 +123,7 @@ func TestLoadTableMessage(t *testing.T) {
 			BatchSize:          1,
 			CacheSize:          10,
 			PollInterval:       30 * time.Second,
+			HasPriority:        true,
 		},
 	}
 	assert.Equal(t, want, table)
@@ -134,6 +135,21 @@ func TestLoadTableMessage(t *testing.T) {
 	want.MessageInfo.MaxBackoff = 100 * time.Second
 	assert.Equal(t, want, table)
 
+	// Test loading max retries and dead letter table
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_retries=5,vt_dead_letter_table=test_table_dead", db)
+	require.NoError(t, err)
+	want.MessageInfo.MaxRetries = 5
+	want.MessageInfo.DeadLetterTable = "test_table_dead"
+	assert.Equal(t, want, table)
+	want.MessageInfo.MaxRetries = 0
+	want.MessageInfo.DeadLetterTable = ""
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_dead_letter_table=test_table_dead", db)
+	require.EqualError(t, err, "vt_dead_letter_table requires vt_max_retries: test_table")
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_retries=5,vt_dead_letter_table=test_table", db)
+	require.EqualError(t, err, "vt_dead_letter_table cannot be the message table itself: test_table")
+
 	//
 	// multiple tests for vt_message_cols
 	//
@@ -187,6 +203,13 @@ func TestLoadTableMessage(t *testing.T) {
 	// end vt_message_cols tests
 	//
 
+	// The priority column is optional
+	mockMessageTableQueriesWithoutPriority(db)
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30", db)
+	require.NoError(t, err)
+	assert.False(t, table.MessageInfo.HasPriority)
+	assert.Equal(t, origFields, table.MessageInfo.Fields)
+
 	// Missing property
 	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30", db)
 	wanterr := "not specified for message table"
@@ -260,3 +283,25 @@ func mockMessageTableQueries(db *fakesqldb.DB) {
 		}},
 	})
 }
+
+func mockMessageTableQueriesWithoutPriority(db *fakesqldb.DB) {
+	db.ClearQueryPattern()
+	db.MockQueriesForTable("test_table", &sqltypes.Result{
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "time_next",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "epoch",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "time_acked",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "message",
+			Type: sqltypes.VarBinary,
+		}},
+	})
+}
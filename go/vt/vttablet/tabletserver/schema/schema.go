@@ -104,6 +104,19 @@ type MessageInfo struct {
 	// MaxBackoff specifies the longest duration message manager
 	// should wait before rescheduling a message
 	MaxBackoff time.Duration
+
+	// MaxRetries specifies how many times a message is resent
+	// after its first delivery before it is given up on.
+	// Zero means that messages are resent forever.
+	MaxRetries int
+
+	// DeadLetterTable is the table to which messages are moved
+	// when they run out of retries. If empty, they are dropped.
+	DeadLetterTable string
+
+	// HasPriority is set if the table has the optional priority
+	// column. Messages with a lower priority are sent first.
+	HasPriority bool
 }
 
 // NewTable creates a new Table.
@@ -86,6 +86,7 @@ var (
       <a href="{{.Prefix}}/livequeryz/">Real-time Queries</a></br>
       <a href="{{.Prefix}}/debug/status_details">JSON Status Details</a></br>
       <a href="{{.Prefix}}/debug/env">View/Change Environment variables</a></br>
+      <a href="{{.Prefix}}/debug/tablegc">Table GC Report</a></br>
     </td>
   </tr>
 </table>